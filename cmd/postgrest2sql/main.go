@@ -8,28 +8,98 @@ import (
 	"os"
 	"strings"
 
+	"sql2postgrest/pkg/convert"
 	"sql2postgrest/pkg/reverse"
 )
 
 const version = "2.0.0"
 
+// headerFlags collects repeated -header "Name: value" flags
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	return fmt.Sprint(map[string]string(h))
+}
+
+func (h headerFlags) Set(value string) error {
+	name, val, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must look like \"Name: value\"")
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+// isMethod reports whether s is an HTTP method starting a request line
+func isMethod(s string) bool {
+	switch s {
+	case "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// isRawRequest reports whether input is a raw HTTP request, i.e. starts
+// with a request line like "GET /users HTTP/1.1"
+func isRawRequest(input string) bool {
+	line, _, _ := strings.Cut(input, "\n")
+	fields := strings.Fields(line)
+	return len(fields) == 3 && isMethod(fields[0]) && strings.HasPrefix(fields[2], "HTTP/")
+}
+
 func main() {
 	var (
 		pretty       = flag.Bool("pretty", false, "Pretty print output")
 		showVersion  = flag.Bool("version", false, "Show version")
 		showWarnings = flag.Bool("warnings", false, "Show conversion warnings")
-		method       = flag.String("method", "GET", "HTTP method (GET, POST, PATCH, DELETE)")
+		formatSQL    = flag.Bool("format-sql", false, "Format the SQL over multiple indented lines")
+		method       = flag.String("method", "GET", "HTTP method (GET, HEAD, POST, PUT, PATCH, DELETE)")
 		path         = flag.String("path", "", "Request path (e.g., /users)")
 		body         = flag.String("body", "", "Request body (JSON)")
+		ndjson       = flag.Bool("ndjson", false, "Read one request line, URL or query per stdin line and write one JSON result per line")
+		jsonErrors   = flag.Bool("json-errors", false, "Print errors as JSON {code, type, message, hint, input} on stderr")
 	)
 
+	headers := headerFlags{}
+	flag.Var(headers, "header", "Request header as \"Name: value\" (repeatable), e.g. -header 'Prefer: resolution=merge-duplicates'")
+
 	flag.Parse()
 
+	// fail reports err and exits with the code for its class
+	fail := func(err error, input string) {
+		os.Exit(convert.WriteError(os.Stderr, err, input, *jsonErrors))
+	}
+
 	if *showVersion {
 		fmt.Printf("postgrest2sql version %s\n", version)
 		return
 	}
 
+	// Ensure path starts with /
+	if *path != "" && !strings.HasPrefix(*path, "/") {
+		*path = "/" + *path
+	}
+
+	var opts []reverse.Option
+	if *formatSQL {
+		opts = append(opts, reverse.WithPrettySQL())
+	}
+	conv := reverse.NewConverter(opts...)
+
+	if *ndjson {
+		err := convert.StreamNDJSON(os.Stdin, os.Stdout, func(query string) (interface{}, error) {
+			result, err := convertInput(conv, query, *method, *path, *body, headers)
+			if err != nil {
+				return nil, err
+			}
+			return jsonOutput(result, *showWarnings), nil
+		})
+		if err != nil {
+			fail(err, "")
+		}
+		return
+	}
+
 	// Get query from args or stdin
 	var query string
 	if flag.NArg() > 0 {
@@ -41,8 +111,7 @@ func main() {
 			// Read from stdin
 			bytes, err := io.ReadAll(os.Stdin)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
-				os.Exit(1)
+				fail(&convert.IOError{Err: fmt.Errorf("reading stdin: %w", err)}, "")
 			}
 			query = strings.TrimSpace(string(bytes))
 		}
@@ -56,52 +125,20 @@ func main() {
 		fmt.Fprintln(os.Stderr, "  postgrest2sql \"age=gte.18\" --path=/users")
 		fmt.Fprintln(os.Stderr, "  postgrest2sql --method=POST --path=/users --body='{\"name\":\"Alice\"}'")
 		fmt.Fprintln(os.Stderr, "  echo \"status=eq.active\" | postgrest2sql --path=/users")
-		os.Exit(1)
-	}
-
-	// If query contains full URL format (e.g., "GET /users?age=gte.18"), parse it
-	if strings.HasPrefix(query, "GET ") || strings.HasPrefix(query, "POST ") ||
-		strings.HasPrefix(query, "PATCH ") || strings.HasPrefix(query, "DELETE ") {
-		parts := strings.SplitN(query, " ", 2)
-		if len(parts) == 2 {
-			*method = parts[0]
-			urlParts := strings.SplitN(parts[1], "?", 2)
-			*path = urlParts[0]
-			if len(urlParts) == 2 {
-				query = urlParts[1]
-			} else {
-				query = ""
-			}
-		}
+		fmt.Fprintln(os.Stderr, "  postgrest2sql --method=POST --path=/users --header='Prefer: resolution=merge-duplicates' --body='{\"id\":1,\"name\":\"Alice\"}' \"on_conflict=id\"")
+		fmt.Fprintln(os.Stderr, "  pbpaste | postgrest2sql   # a raw HTTP request: request line, headers, blank line, body")
+		fmt.Fprintln(os.Stderr, "  postgrest2sql --ndjson < requests.txt   # one request line or URL per line")
+		os.Exit(convert.ExitUsage)
 	}
 
-	// Ensure path starts with /
-	if *path != "" && !strings.HasPrefix(*path, "/") {
-		*path = "/" + *path
-	}
-
-	// Convert
-	conv := reverse.NewConverter()
-	result, err := conv.Convert(*method, *path, query, *body)
+	result, err := convertInput(conv, query, *method, *path, *body, headers)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fail(err, query)
 	}
 
 	// Output
 	if *pretty {
-		output := map[string]interface{}{
-			"sql": result.SQL,
-		}
-		if *showWarnings && len(result.Warnings) > 0 {
-			output["warnings"] = result.Warnings
-		}
-		if len(result.Metadata) > 0 {
-			output["metadata"] = result.Metadata
-		}
-		if result.HTTPRequest != nil {
-			output["http"] = result.HTTPRequest
-		}
+		output := jsonOutput(result, *showWarnings)
 
 		jsonBytes, err := json.MarshalIndent(output, "", "  ")
 		if err != nil {
@@ -123,3 +160,42 @@ func main() {
 		}
 	}
 }
+
+// convertInput converts one input: a raw HTTP request, a request line
+// ("GET /users?age=gte.18"), a full URL, or a query string for path
+func convertInput(conv *reverse.Converter, input, method, path, body string, headers map[string]string) (*reverse.SQLResult, error) {
+	// A request line or full URL carries its own path and query
+	rawURL := ""
+	if m, target, found := strings.Cut(input, " "); found && isMethod(m) {
+		method = m
+		rawURL = strings.TrimSpace(target)
+	} else if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		rawURL = input
+	}
+
+	switch {
+	case isRawRequest(input):
+		// A raw HTTP request carries its own method, headers and body
+		return conv.ConvertRawRequest(input)
+	case rawURL != "":
+		return conv.ConvertURL(method, rawURL, body, headers)
+	}
+	return conv.ConvertWithHeaders(method, path, input, body, headers)
+}
+
+// jsonOutput builds the --pretty and --ndjson output for a result
+func jsonOutput(result *reverse.SQLResult, showWarnings bool) map[string]interface{} {
+	output := map[string]interface{}{
+		"sql": result.SQL,
+	}
+	if showWarnings && len(result.Warnings) > 0 {
+		output["warnings"] = result.Warnings
+	}
+	if len(result.Metadata) > 0 {
+		output["metadata"] = result.Metadata
+	}
+	if result.HTTPRequest != nil {
+		output["http"] = result.HTTPRequest
+	}
+	return output
+}
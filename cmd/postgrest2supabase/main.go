@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sql2postgrest/pkg/codegen"
+	"sql2postgrest/pkg/convert"
+)
+
+// headerFlags collects repeated -header "Name: value" flags
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	return fmt.Sprint(map[string]string(h))
+}
+
+func (h headerFlags) Set(value string) error {
+	name, val, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must look like \"Name: value\"")
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+// isMethod reports whether s is an HTTP method starting a request line
+func isMethod(s string) bool {
+	switch s {
+	case "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+func main() {
+	var (
+		pretty       = flag.Bool("pretty", false, "Print JSON with the code and warnings")
+		showWarnings = flag.Bool("warnings", false, "Show generation warnings")
+		statement    = flag.Bool("statement", false, "Print an awaited statement with one call per line")
+		client       = flag.String("client", "supabase", "Name of the Supabase client variable")
+		method       = flag.String("method", "GET", "HTTP method (GET, HEAD, POST, PUT, PATCH, DELETE)")
+		path         = flag.String("path", "", "Request path (e.g., /users)")
+		body         = flag.String("body", "", "Request body (JSON)")
+		ndjson       = flag.Bool("ndjson", false, "Read one request line, URL or query per stdin line and write one JSON result per line")
+		jsonErrors   = flag.Bool("json-errors", false, "Print errors as JSON {code, type, message, hint, input} on stderr")
+	)
+
+	headers := headerFlags{}
+	flag.Var(headers, "header", "Request header as \"Name: value\" (repeatable), e.g. -header 'Prefer: count=exact'")
+
+	flag.Parse()
+
+	// fail reports err and exits with the code for its class
+	fail := func(err error, input string) {
+		os.Exit(convert.WriteError(os.Stderr, err, input, *jsonErrors))
+	}
+
+	gen := codegen.NewGenerator(codegen.WithClient(*client))
+
+	if *ndjson {
+		err := convert.StreamNDJSON(os.Stdin, os.Stdout, func(query string) (interface{}, error) {
+			result, err := generate(gen, query, *method, *path, *body, headers)
+			if err != nil {
+				return nil, err
+			}
+			return jsonOutput(result, *statement), nil
+		})
+		if err != nil {
+			fail(err, "")
+		}
+		return
+	}
+
+	// Get query from args or stdin
+	var query string
+	if flag.NArg() > 0 {
+		query = flag.Arg(0)
+	} else {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeCharDevice) == 0 {
+			bytes, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fail(&convert.IOError{Err: fmt.Errorf("reading stdin: %w", err)}, "")
+			}
+			query = strings.TrimSpace(string(bytes))
+		}
+	}
+
+	if query == "" && *path == "" {
+		fmt.Fprintln(os.Stderr, "Usage: postgrest2supabase [OPTIONS] <query>")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Examples:")
+		fmt.Fprintln(os.Stderr, "  postgrest2supabase \"GET /users?select=*&age=gte.18\"")
+		fmt.Fprintln(os.Stderr, "  postgrest2supabase \"age=gte.18&order=name.desc\" --path=/users")
+		fmt.Fprintln(os.Stderr, "  postgrest2supabase --method=POST --path=/users --body='{\"name\":\"Alice\"}'")
+		fmt.Fprintln(os.Stderr, "  postgrest2supabase --statement https://xyz.supabase.co/rest/v1/users?id=eq.1")
+		fmt.Fprintln(os.Stderr, "  postgrest2supabase --ndjson < requests.txt")
+		os.Exit(convert.ExitUsage)
+	}
+
+	result, err := generate(gen, query, *method, *path, *body, headers)
+	if err != nil {
+		fail(err, query)
+	}
+
+	code := result.Code
+	if *statement {
+		code = result.Statement()
+	}
+
+	if *pretty {
+		output := jsonOutput(result, *statement)
+
+		jsonBytes, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	fmt.Println(code)
+	if *showWarnings && len(result.Warnings) > 0 {
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Warnings:")
+		for _, w := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "  - %s\n", w)
+		}
+	}
+}
+
+// generate generates supabase-js for one input: a request line
+// ("GET /users?age=gte.18"), a URL or path, or a query string for path
+func generate(gen *codegen.Generator, input, method, path, body string, headers map[string]string) (*codegen.Result, error) {
+	// A request line or full URL carries its own path and query
+	rawURL := ""
+	if m, target, found := strings.Cut(input, " "); found && isMethod(m) {
+		method = m
+		rawURL = strings.TrimSpace(target)
+	} else if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") || strings.HasPrefix(input, "/") {
+		rawURL = input
+	}
+
+	if rawURL != "" {
+		return gen.GenerateURL(method, rawURL, body, headers)
+	}
+	return gen.Generate(codegen.Request{Method: method, Path: path, Query: input, Body: body, Headers: headers})
+}
+
+// jsonOutput builds the --pretty and --ndjson output for a result
+func jsonOutput(result *codegen.Result, statement bool) map[string]interface{} {
+	code := result.Code
+	if statement {
+		code = result.Statement()
+	}
+	output := map[string]interface{}{
+		"supabase": code,
+	}
+	if len(result.Warnings) > 0 {
+		output["warnings"] = result.Warnings
+	}
+	return output
+}
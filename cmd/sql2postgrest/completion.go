@@ -0,0 +1,293 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"sql2postgrest/pkg/convert"
+	"sql2postgrest/pkg/converter"
+)
+
+// completionFlag describes a flag to the shell completion scripts
+type completionFlag struct {
+	name    string
+	usage   string
+	takes   string   // Value the flag takes: "" for a boolean, "file" or "value"
+	choices []string // Fixed values, such as the --format names
+	repeat  bool     // The flag may be given more than once
+}
+
+// completionCommand is the top-level command (name "") or a subcommand
+type completionCommand struct {
+	name  string
+	usage string
+	flags []completionFlag
+	args  []string // Fixed positional arguments, such as the completion shells
+}
+
+// completionShells are the shells `sql2postgrest completion` writes
+// scripts for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// fileFlags are the flags whose value is a path
+var fileFlags = map[string]bool{"watch": true, "cases": true, "psql": true}
+
+// flagChoices returns the fixed values of the flags that have them
+func flagChoices() map[string][]string {
+	var targets []string
+	for _, t := range convert.Targets {
+		targets = append(targets, string(t))
+	}
+	return map[string][]string{"format": formatNames(), "to": targets}
+}
+
+// completionCommands lists the commands with the flags their FlagSets
+// define
+func completionCommands() []completionCommand {
+	return []completionCommand{
+		{flags: completionFlags(newMainFlags().FlagSet)},
+		{name: "convert", usage: "Convert SQL, a PostgREST request or a Supabase query", flags: completionFlags(newConvertFlags().FlagSet)},
+		{name: "conformance", usage: "Compare PostgREST responses with Postgres results", flags: completionFlags(newConformanceFlags().FlagSet)},
+		{name: "verify", usage: "Report what an SQL round trip loses", flags: completionFlags(newVerifyFlags().FlagSet)},
+		{name: "completion", usage: "Print a shell completion script", args: completionShells},
+	}
+}
+
+// completionFlags describes the flags defined on fs
+func completionFlags(fs *flag.FlagSet) []completionFlag {
+	choices := flagChoices()
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		cf := completionFlag{name: f.Name, usage: f.Usage, choices: choices[f.Name]}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+			cf.takes = "value"
+			if fileFlags[f.Name] {
+				cf.takes = "file"
+			}
+		}
+		_, cf.repeat = f.Value.(headerFlags)
+		flags = append(flags, cf)
+	})
+	return flags
+}
+
+// runCompletion implements `sql2postgrest completion`, which prints the
+// completion script for a shell
+func runCompletion(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: sql2postgrest completion bash|zsh|fish")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Examples:")
+		fmt.Fprintln(os.Stderr, "  source <(sql2postgrest completion bash)")
+		fmt.Fprintln(os.Stderr, "  sql2postgrest completion zsh > \"${fpath[1]}/_sql2postgrest\"")
+		fmt.Fprintln(os.Stderr, "  sql2postgrest completion fish > ~/.config/fish/completions/sql2postgrest.fish")
+		os.Exit(convert.ExitUsage)
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion())
+	case "zsh":
+		fmt.Print(zshCompletion())
+	case "fish":
+		fmt.Print(fishCompletion())
+	default:
+		err := convert.NewUsageError(fmt.Sprintf("unknown shell %q (expected %s)", args[0], strings.Join(completionShells, ", ")))
+		os.Exit(convert.WriteError(os.Stderr, err, "", false))
+	}
+}
+
+// flagName returns the flag as it is typed: -H for one letter, --name
+// otherwise. The flag package accepts either dash count.
+func (f completionFlag) flagName() string {
+	if len(f.name) == 1 {
+		return "-" + f.name
+	}
+	return "--" + f.name
+}
+
+// subcommandNames returns the names of the subcommands
+func subcommandNames() []string {
+	var names []string
+	for _, cmd := range completionCommands() {
+		if cmd.name != "" {
+			names = append(names, cmd.name)
+		}
+	}
+	return names
+}
+
+// bashCompletion returns the bash completion script
+func bashCompletion() string {
+	var b strings.Builder
+	b.WriteString(`# bash completion for sql2postgrest
+_sql2postgrest() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local cmd=""
+    if [[ ${COMP_CWORD} -gt 1 ]]; then
+        case "${COMP_WORDS[1]}" in
+            ` + strings.Join(subcommandNames(), "|") + `) cmd="${COMP_WORDS[1]}" ;;
+        esac
+    fi
+
+    # Complete the value of the flag before the cursor
+    local flag="${prev#-}"
+    flag="${flag#-}"
+    case "${cmd}:${flag}" in
+`)
+	for _, cmd := range completionCommands() {
+		for _, f := range cmd.flags {
+			switch {
+			case len(f.choices) > 0:
+				fmt.Fprintf(&b, "        %s:%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", cmd.name, f.name, strings.Join(f.choices, " "))
+			case f.takes == "file":
+				fmt.Fprintf(&b, "        %s:%s) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n", cmd.name, f.name)
+			case f.takes != "":
+				fmt.Fprintf(&b, "        %s:%s) return ;;\n", cmd.name, f.name)
+			}
+		}
+	}
+	b.WriteString(`    esac
+
+    case "${cmd}" in
+`)
+	for _, cmd := range completionCommands() {
+		var flags []string
+		for _, f := range cmd.flags {
+			flags = append(flags, f.flagName())
+		}
+		fmt.Fprintf(&b, "        %q)\n", cmd.name)
+		switch {
+		case cmd.name == "":
+			fmt.Fprintf(&b, "            if [[ ${COMP_CWORD} -eq 1 && \"$cur\" != -* ]]; then\n")
+			fmt.Fprintf(&b, "                COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(subcommandNames(), " "))
+			fmt.Fprintf(&b, "            elif [[ \"$cur\" == -* ]]; then\n")
+			fmt.Fprintf(&b, "                COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(flags, " "))
+			fmt.Fprintf(&b, "            fi\n")
+		case len(cmd.args) > 0:
+			fmt.Fprintf(&b, "            if [[ ${COMP_CWORD} -eq 2 ]]; then\n")
+			fmt.Fprintf(&b, "                COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(cmd.args, " "))
+			fmt.Fprintf(&b, "            fi\n")
+		default:
+			fmt.Fprintf(&b, "            if [[ \"$cur\" == -* ]]; then\n")
+			fmt.Fprintf(&b, "                COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(flags, " "))
+			fmt.Fprintf(&b, "            fi\n")
+		}
+		b.WriteString("            ;;\n")
+	}
+	b.WriteString(`    esac
+}
+
+complete -F _sql2postgrest sql2postgrest
+`)
+	return b.String()
+}
+
+// zshCompletion returns the zsh completion script
+func zshCompletion() string {
+	var b strings.Builder
+	b.WriteString("#compdef sql2postgrest\n\n_sql2postgrest() {\n    local -a commands\n    commands=(\n")
+	for _, cmd := range completionCommands() {
+		if cmd.name != "" {
+			fmt.Fprintf(&b, "        %s\n", converter.ShellQuote(cmd.name+":"+cmd.usage))
+		}
+	}
+	b.WriteString(`    )
+
+    if (( CURRENT == 2 )) && [[ $words[2] != -* ]]; then
+        _describe 'command' commands
+        return
+    fi
+
+    case $words[2] in
+`)
+	for _, cmd := range completionCommands() {
+		if cmd.name == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "        %s)\n            shift words\n            (( CURRENT-- ))\n", cmd.name)
+		writeZshArguments(&b, cmd)
+		b.WriteString("            ;;\n")
+	}
+	b.WriteString("        *)\n")
+	writeZshArguments(&b, completionCommands()[0])
+	b.WriteString("            ;;\n    esac\n}\n\n_sql2postgrest \"$@\"\n")
+	return b.String()
+}
+
+// writeZshArguments writes the _arguments call for a command's flags and
+// positional arguments
+func writeZshArguments(b *strings.Builder, cmd completionCommand) {
+	specs := []string{}
+	for _, f := range cmd.flags {
+		spec := f.flagName() + "[" + zshEscape(f.usage) + "]"
+		if f.repeat {
+			spec = "*" + spec
+		}
+		switch {
+		case len(f.choices) > 0:
+			spec += ":" + f.name + ":(" + strings.Join(f.choices, " ") + ")"
+		case f.takes == "file":
+			spec += ":file:_files"
+		case f.takes != "":
+			spec += ":" + f.name + ":"
+		}
+		specs = append(specs, converter.ShellQuote(spec))
+	}
+	switch {
+	case len(cmd.args) > 0:
+		specs = append(specs, converter.ShellQuote("1:shell:("+strings.Join(cmd.args, " ")+")"))
+	case cmd.name == "" || cmd.name == "verify":
+		specs = append(specs, converter.ShellQuote("*:SQL query:"))
+	case cmd.name == "convert":
+		specs = append(specs, converter.ShellQuote("*:input:"))
+	}
+	b.WriteString("            _arguments \\\n                " + strings.Join(specs, " \\\n                ") + "\n")
+}
+
+// fishCompletion returns the fish completion script
+func fishCompletion() string {
+	var b strings.Builder
+	b.WriteString("# fish completion for sql2postgrest\ncomplete -c sql2postgrest -f\n\n")
+	for _, cmd := range completionCommands() {
+		if cmd.name != "" {
+			fmt.Fprintf(&b, "complete -c sql2postgrest -n __fish_use_subcommand -a %s -d %s\n", cmd.name, converter.ShellQuote(cmd.usage))
+		}
+	}
+
+	for _, cmd := range completionCommands() {
+		condition := "__fish_use_subcommand"
+		if cmd.name != "" {
+			condition = converter.ShellQuote("__fish_seen_subcommand_from " + cmd.name)
+		}
+		b.WriteString("\n")
+		for _, f := range cmd.flags {
+			option := "-l " + f.name
+			if len(f.name) == 1 {
+				option = "-s " + f.name
+			}
+			switch {
+			case len(f.choices) > 0:
+				option += " -x -a " + converter.ShellQuote(strings.Join(f.choices, " "))
+			case f.takes == "file":
+				option += " -r -F"
+			case f.takes != "":
+				option += " -x"
+			}
+			fmt.Fprintf(&b, "complete -c sql2postgrest -n %s %s -d %s\n", condition, option, converter.ShellQuote(f.usage))
+		}
+		if len(cmd.args) > 0 {
+			fmt.Fprintf(&b, "complete -c sql2postgrest -n %s -a %s\n", condition, converter.ShellQuote(strings.Join(cmd.args, " ")))
+		}
+	}
+	return b.String()
+}
+
+// zshEscape escapes the characters _arguments gives a meaning to in a
+// flag description
+func zshEscape(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
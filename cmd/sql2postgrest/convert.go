@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"sql2postgrest/pkg/convert"
+)
+
+// convertFlags are the flags of `sql2postgrest convert`
+type convertFlags struct {
+	*flag.FlagSet
+	baseURL, to                *string
+	pretty, ndjson, jsonErrors *bool
+}
+
+// newConvertFlags defines the flags of `sql2postgrest convert`
+func newConvertFlags() *convertFlags {
+	fs := &convertFlags{FlagSet: flag.NewFlagSet("convert", flag.ExitOnError)}
+	fs.baseURL = fs.String("url", "http://localhost:3000", "PostgREST base URL")
+	fs.to = fs.String("to", "", "Target representation: sql, postgrest, supabase, curl (default: all reachable forms)")
+	fs.pretty = fs.Bool("pretty", false, "Output as pretty JSON")
+	fs.ndjson = fs.Bool("ndjson", false, "Read one input per stdin line and write one JSON result per line")
+	fs.jsonErrors = fs.Bool("json-errors", false, "Print errors as JSON {code, type, message, hint, input} on stderr")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: sql2postgrest convert [options] <SQL | PostgREST URL | Supabase query>")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Examples:")
+		fmt.Fprintln(os.Stderr, "  sql2postgrest convert \"SELECT * FROM users WHERE age >= 18\"")
+		fmt.Fprintln(os.Stderr, "  sql2postgrest convert --to sql \"GET /users?age=gte.18\"")
+		fmt.Fprintln(os.Stderr, "  sql2postgrest convert --to curl \"supabase.from('users').select('*')\"")
+		fmt.Fprintln(os.Stderr, "  sql2postgrest convert --ndjson --to sql < requests.txt")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Options:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runConvert implements `sql2postgrest convert`, which auto-detects whether
+// the input is SQL, a PostgREST request or a Supabase query chain
+func runConvert(args []string) {
+	fs := newConvertFlags()
+	fs.Parse(args)
+
+	// fail reports err and exits with the code for its class
+	fail := func(err error, input string) {
+		os.Exit(convert.WriteError(os.Stderr, err, input, *fs.jsonErrors))
+	}
+
+	conv := convert.NewConverter(*fs.baseURL)
+	if *fs.ndjson {
+		err := convert.StreamNDJSON(os.Stdin, os.Stdout, func(input string) (interface{}, error) {
+			result, err := conv.Convert(input, convert.Target(*fs.to))
+			if err != nil {
+				return nil, err
+			}
+			return resultOutput(result), nil
+		})
+		if err != nil {
+			fail(err, "")
+		}
+		return
+	}
+
+	var input string
+	if fs.NArg() > 0 {
+		input = strings.Join(fs.Args(), " ")
+	} else {
+		scanner := bufio.NewScanner(os.Stdin)
+		var lines []string
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			fail(&convert.IOError{Err: fmt.Errorf("reading input: %w", err)}, "")
+		}
+		input = strings.Join(lines, "\n")
+	}
+
+	input = strings.TrimSpace(input)
+	if input == "" {
+		fs.Usage()
+		os.Exit(convert.ExitUsage)
+	}
+
+	result, err := conv.Convert(input, convert.Target(*fs.to))
+	if err != nil {
+		fail(err, input)
+	}
+
+	switch result.To {
+	case convert.TargetSQL:
+		fmt.Println(result.SQL)
+	case convert.TargetCurl:
+		fmt.Println(result.Curl)
+	case convert.TargetSupabase:
+		fmt.Println(result.Supabase)
+	default:
+		var output []byte
+		if *fs.pretty {
+			output, err = json.MarshalIndent(result, "", "  ")
+		} else {
+			output, err = json.Marshal(result)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(output))
+	}
+
+	if result.To != convert.TargetDefault {
+		for _, w := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+		}
+	}
+}
+
+// resultOutput is the --ndjson line for a result: the converted text for a
+// single target, or the whole result
+func resultOutput(result *convert.Result) interface{} {
+	switch result.To {
+	case convert.TargetSQL:
+		return result.SQL
+	case convert.TargetCurl:
+		return result.Curl
+	case convert.TargetSupabase:
+		return result.Supabase
+	}
+	return result
+}
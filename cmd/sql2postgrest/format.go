@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"sql2postgrest/pkg/codegen"
+	"sql2postgrest/pkg/convert"
+	"sql2postgrest/pkg/converter"
+)
+
+// sqlRequest converts sql to the PostgREST request it maps to, for the
+// output formats that render the request rather than the JSON result
+func sqlRequest(conv *converter.Converter, sql string) (*convert.Request, []string, error) {
+	result, err := conv.Convert(sql)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.NoOp != "" {
+		return nil, nil, &convert.NoRequestError{Code: result.NoOp, Reasons: result.Warnings}
+	}
+
+	req := &convert.Request{
+		Method:  result.Method,
+		URL:     conv.URL(result),
+		Path:    result.Path,
+		Query:   result.QueryParams.Encode(),
+		Headers: result.Headers,
+		Body:    result.Body,
+	}
+	return req, result.Warnings, nil
+}
+
+// requestFormat renders a request in one of the text output formats
+type requestFormat struct {
+	name   string
+	render func(req *convert.Request) (string, error)
+}
+
+// requestFormats are the values of --format besides json, in the order
+// help and shell completion list them
+var requestFormats = []requestFormat{
+	{"curl", func(req *convert.Request) (string, error) { return convert.CurlCommand(req), nil }},
+	{"fetch", func(req *convert.Request) (string, error) { return codegen.Fetch(httpRequest(req)), nil }},
+	{"axios", func(req *convert.Request) (string, error) { return codegen.Axios(httpRequest(req)), nil }},
+	{"supabase-js", supabaseJS},
+	{"go", func(req *convert.Request) (string, error) { return codegen.GoNetHTTP(httpRequest(req)), nil }},
+	{"python", func(req *convert.Request) (string, error) { return codegen.PythonRequests(httpRequest(req)), nil }},
+}
+
+// formatNames returns the values of --format
+func formatNames() []string {
+	names := []string{"json"}
+	for _, f := range requestFormats {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// formats describes the values of --format in flag help and errors
+func formats() string {
+	names := formatNames()
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// formatRequest renders a request in an output format other than json
+func formatRequest(req *convert.Request, format string) (string, error) {
+	for _, f := range requestFormats {
+		if f.name == format {
+			return f.render(req)
+		}
+	}
+	return "", convert.NewUsageError(fmt.Sprintf("unknown format %q (expected %s)", format, formats()))
+}
+
+// httpRequest is req as the codegen HTTP client snippets take it
+func httpRequest(req *convert.Request) codegen.HTTPRequest {
+	return codegen.HTTPRequest{Method: req.Method, URL: req.URL, Headers: req.Headers, Body: req.Body}
+}
+
+// supabaseJS renders req as the equivalent supabase-js call
+func supabaseJS(req *convert.Request) (string, error) {
+	result, err := codegen.NewGenerator().Generate(codegen.Request{
+		Method: req.Method, Path: req.Path, Query: req.Query, Body: req.Body, Headers: req.Headers,
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.Statement(), nil
+}
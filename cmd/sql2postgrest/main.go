@@ -2,28 +2,156 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"sql2postgrest/pkg/convert"
 	"sql2postgrest/pkg/converter"
 )
 
 const version = "0.1.0"
 
+// headerFlags collects repeated -H "Name: value" flags
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	return fmt.Sprint(map[string]string(h))
+}
+
+func (h headerFlags) Set(value string) error {
+	name, val, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must look like \"Name: value\"")
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+// headerShorthand is a flag that sets one header of a headerFlags, as
+// --token sets Authorization: Bearer <token>
+type headerShorthand struct {
+	headers headerFlags
+	name    string
+	prefix  string
+}
+
+func (h headerShorthand) String() string {
+	return ""
+}
+
+func (h headerShorthand) Set(value string) error {
+	h.headers[h.name] = h.prefix + value
+	return nil
+}
+
+// mainFlags are the flags of the top-level command
+type mainFlags struct {
+	*flag.FlagSet
+	baseURL, profile, format, watch                                                          *string
+	showVersion, jsonPretty, rangeHeaders, csv, readable, trace, execute, ndjson, jsonErrors *bool
+	headers                                                                                  headerFlags
+}
+
+// newMainFlags defines the flags of the top-level command
+func newMainFlags() *mainFlags {
+	fs := &mainFlags{FlagSet: flag.NewFlagSet("sql2postgrest", flag.ExitOnError), headers: headerFlags{}}
+	fs.baseURL = fs.String("url", "http://localhost:3000", "PostgREST base URL")
+	fs.showVersion = fs.Bool("version", false, "Show version")
+	fs.jsonPretty = fs.Bool("pretty", false, "Output as pretty JSON")
+	fs.rangeHeaders = fs.Bool("range-headers", false, "Emit LIMIT/OFFSET as a Range header instead of query params")
+	fs.profile = fs.String("profile", "", "Schema to select via Accept-Profile/Content-Profile")
+	fs.csv = fs.Bool("csv", false, "Request CSV output (Accept: text/csv) for SELECTs")
+	fs.readable = fs.Bool("readable", false, "Leave PostgREST syntax characters (,().*:) unescaped in the URL")
+	fs.trace = fs.Bool("trace", false, "Include which SQL clause produced each param, header and body")
+	fs.format = fs.String("format", "json", "Output format: "+formats())
+	fs.execute = fs.Bool("exec", false, "Send the request to --url and print the status, Content-Range and response body")
+	fs.Var(headerShorthand{fs.headers, "Authorization", "Bearer "}, "token", "JWT sent as the header Authorization: Bearer <JWT>, like -H")
+	fs.Var(headerShorthand{fs.headers, "apikey", ""}, "apikey", "Supabase API key sent as the apikey header, like -H")
+	fs.ndjson = fs.Bool("ndjson", false, "Read one SQL statement per stdin line and write one JSON result per line")
+	fs.watch = fs.String("watch", "", "SQL file to re-convert on every change, printing a diff of the generated requests")
+	fs.jsonErrors = fs.Bool("json-errors", false, "Print errors as JSON {code, type, message, hint, input} on stderr")
+	fs.Var(fs.headers, "H", "Header added to the request as \"Name: value\" (repeatable), e.g. -H 'apikey: $ANON_KEY'")
+	fs.Var(fs.headers, "header", "Same as -H")
+	return fs
+}
+
 func main() {
-	baseURL := flag.String("url", "http://localhost:3000", "PostgREST base URL")
-	showVersion := flag.Bool("version", false, "Show version")
-	jsonPretty := flag.Bool("pretty", false, "Output as pretty JSON")
-	flag.Parse()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "convert":
+			runConvert(os.Args[2:])
+			return
+		case "conformance":
+			runConformance(os.Args[2:])
+			return
+		case "verify":
+			runVerify(os.Args[2:])
+			return
+		case "completion":
+			runCompletion(os.Args[2:])
+			return
+		}
+	}
+
+	fs := newMainFlags()
+	fs.Parse(os.Args[1:])
 
-	if *showVersion {
+	// fail reports err and exits with the code for its class
+	fail := func(err error, input string) {
+		os.Exit(convert.WriteError(os.Stderr, err, input, *fs.jsonErrors))
+	}
+
+	if *fs.showVersion {
 		fmt.Printf("sql2postgrest version %s\n", version)
 		os.Exit(0)
 	}
 
-	args := flag.Args()
+	var opts []converter.Option
+	if *fs.rangeHeaders {
+		opts = append(opts, converter.WithRangeHeaders())
+	}
+	if *fs.profile != "" {
+		opts = append(opts, converter.WithProfile(*fs.profile))
+	}
+	if *fs.readable {
+		opts = append(opts, converter.WithReadableURLs())
+	}
+	if *fs.csv {
+		opts = append(opts, converter.WithCSV())
+	}
+	if len(fs.headers) > 0 {
+		opts = append(opts, converter.WithHeaders(fs.headers))
+	}
+	conv := converter.NewConverter(*fs.baseURL, opts...)
+
+	if *fs.watch != "" {
+		if *fs.execute || *fs.ndjson {
+			fail(convert.NewUsageError("--watch cannot be combined with --exec or --ndjson"), "")
+		}
+		if err := runWatch(*fs.watch, conv, *fs.format); err != nil {
+			fail(err, "")
+		}
+		return
+	}
+
+	if *fs.ndjson {
+		if *fs.execute {
+			fail(convert.NewUsageError("--ndjson cannot be combined with --exec"), "")
+		}
+		err := convert.StreamNDJSON(os.Stdin, os.Stdout, func(sql string) (interface{}, error) {
+			return ndjsonResult(conv, sql, *fs.format, *fs.trace)
+		})
+		if err != nil {
+			fail(err, "")
+		}
+		return
+	}
+
+	args := fs.Args()
 
 	var sql string
 	if len(args) > 0 {
@@ -35,8 +163,7 @@ func main() {
 			lines = append(lines, scanner.Text())
 		}
 		if err := scanner.Err(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
-			os.Exit(1)
+			fail(&convert.IOError{Err: fmt.Errorf("reading input: %w", err)}, "")
 		}
 		sql = strings.Join(lines, "\n")
 	}
@@ -45,22 +172,94 @@ func main() {
 	if sql == "" {
 		fmt.Fprintln(os.Stderr, "Usage: sql2postgrest [options] <SQL query>")
 		fmt.Fprintln(os.Stderr, "   or: echo 'SELECT * FROM users' | sql2postgrest")
-		flag.PrintDefaults()
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "   or: sql2postgrest -H 'apikey: KEY' --format curl <SQL query>")
+		fmt.Fprintln(os.Stderr, "   or: sql2postgrest --ndjson < queries.sql")
+		fmt.Fprintln(os.Stderr, "   or: sql2postgrest --watch queries.sql")
+		fmt.Fprintln(os.Stderr, "   or: sql2postgrest convert [--to sql|postgrest|supabase|curl] <input>")
+		fmt.Fprintln(os.Stderr, "   or: sql2postgrest conformance [--url URL] [--db DATABASE_URL]")
+		fmt.Fprintln(os.Stderr, "   or: sql2postgrest verify [--json] <SQL query>")
+		fmt.Fprintln(os.Stderr, "   or: sql2postgrest completion bash|zsh|fish")
+		fs.PrintDefaults()
+		os.Exit(convert.ExitUsage)
 	}
 
-	conv := converter.NewConverter(*baseURL)
+	if *fs.execute {
+		req, warnings, err := sqlRequest(conv, sql)
+		if err != nil {
+			fail(err, sql)
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+		}
+		resp, err := convert.Execute(nil, req, nil)
+		if err != nil {
+			fail(err, sql)
+		}
+		fmt.Println(resp.Format(*fs.jsonPretty))
+		if resp.StatusCode >= 300 {
+			os.Exit(convert.ExitHTTPError)
+		}
+		return
+	}
+
+	if *fs.format != "json" {
+		req, warnings, err := sqlRequest(conv, sql)
+		if err != nil {
+			fail(err, sql)
+		}
+		output, err := formatRequest(req, *fs.format)
+		if err != nil {
+			fail(err, sql)
+		}
+		fmt.Println(output)
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+		}
+		return
+	}
 
 	var output string
 	var err error
-	if *jsonPretty {
+	switch {
+	case *fs.trace:
+		output, err = conv.ConvertWithTraceToJSON(sql)
+		if err == nil && *fs.jsonPretty {
+			var indented bytes.Buffer
+			if err = json.Indent(&indented, []byte(output), "", "  "); err == nil {
+				output = indented.String()
+			}
+		}
+	case *fs.jsonPretty:
 		output, err = conv.ConvertToJSONPretty(sql)
-	} else {
+	default:
 		output, err = conv.ConvertToJSON(sql)
 	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fail(err, sql)
 	}
 	fmt.Println(output)
 }
+
+// ndjsonResult converts one line of --ndjson input: the JSON result, or the
+// request rendered in a text --format as a JSON string
+func ndjsonResult(conv *converter.Converter, sql, format string, trace bool) (interface{}, error) {
+	if format != "json" {
+		req, _, err := sqlRequest(conv, sql)
+		if err != nil {
+			return nil, err
+		}
+		return formatRequest(req, format)
+	}
+
+	var output string
+	var err error
+	if trace {
+		output, err = conv.ConvertWithTraceToJSON(sql)
+	} else {
+		output, err = conv.ConvertToJSON(sql)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(output), nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"sql2postgrest/pkg/convert"
+)
+
+// verifyFlags are the flags of `sql2postgrest verify`
+type verifyFlags struct {
+	*flag.FlagSet
+	jsonOutput, jsonErrors *bool
+}
+
+// newVerifyFlags defines the flags of `sql2postgrest verify`
+func newVerifyFlags() *verifyFlags {
+	fs := &verifyFlags{FlagSet: flag.NewFlagSet("verify", flag.ExitOnError)}
+	fs.jsonOutput = fs.Bool("json", false, "Output the report as JSON")
+	fs.jsonErrors = fs.Bool("json-errors", false, "Print errors as JSON {code, type, message, hint, input} on stderr")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: sql2postgrest verify [options] <SQL query>")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Converts SQL → PostgREST → SQL, normalizes both statements and lists the")
+		fmt.Fprintln(os.Stderr, "clauses that were dropped or altered. Exits 7 when the round trip is lossy.")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Examples:")
+		fmt.Fprintln(os.Stderr, "  sql2postgrest verify \"SELECT * FROM users WHERE age BETWEEN 18 AND 65\"")
+		fmt.Fprintln(os.Stderr, "  sql2postgrest verify --json \"SELECT u.name, p.title FROM users u JOIN posts p ON p.user_id = u.id\"")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Options:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runVerify implements `sql2postgrest verify`, which converts SQL to a
+// PostgREST request and back and reports the clauses lost on the way
+func runVerify(args []string) {
+	fs := newVerifyFlags()
+	fs.Parse(args)
+
+	// fail reports err and exits with the code for its class
+	fail := func(err error, input string) {
+		os.Exit(convert.WriteError(os.Stderr, err, input, *fs.jsonErrors))
+	}
+
+	var sql string
+	if fs.NArg() > 0 {
+		sql = strings.Join(fs.Args(), " ")
+	} else {
+		scanner := bufio.NewScanner(os.Stdin)
+		var lines []string
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			fail(&convert.IOError{Err: fmt.Errorf("reading input: %w", err)}, "")
+		}
+		sql = strings.Join(lines, "\n")
+	}
+
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		fs.Usage()
+		os.Exit(convert.ExitUsage)
+	}
+
+	report, err := convert.Verify(sql)
+	if err != nil {
+		fail(err, sql)
+	}
+
+	if *fs.jsonOutput {
+		output, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(output))
+	} else {
+		report.WriteText(os.Stdout)
+	}
+
+	if !report.Lossless {
+		os.Exit(convert.ExitLossy)
+	}
+}
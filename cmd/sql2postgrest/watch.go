@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"sql2postgrest/pkg/converter"
+	"sql2postgrest/pkg/diff"
+)
+
+// watchInterval is how often --watch checks the file for changes
+const watchInterval = 500 * time.Millisecond
+
+// runWatch converts every statement in file, then polls it and prints a
+// diff of the generated requests each time it is saved. It runs until the
+// process is interrupted.
+func runWatch(file string, conv *converter.Converter, format string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	previous := renderStatements(conv, string(content), format)
+	for i, block := range previous {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("-- statement %d\n%s\n", i+1, strings.Join(block, "\n"))
+	}
+	fmt.Fprintf(os.Stderr, "Watching %s for changes (Ctrl-C to stop)\n", file)
+
+	readFailed := false
+	for range time.Tick(watchInterval) {
+		updated, err := os.ReadFile(file)
+		if err != nil {
+			// Editors that save by renaming leave the file missing briefly;
+			// report the error once and keep polling
+			if !readFailed {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				readFailed = true
+			}
+			continue
+		}
+		readFailed = false
+		if bytes.Equal(updated, content) {
+			continue
+		}
+		content = updated
+
+		current := renderStatements(conv, string(content), format)
+		fmt.Printf("\n=== %s changed at %s\n", file, time.Now().Format("15:04:05"))
+		if diff := diff.Statements(previous, current); diff != "" {
+			fmt.Print(diff)
+		} else {
+			fmt.Println("(generated requests unchanged)")
+		}
+		previous = current
+	}
+	return nil
+}
+
+// renderStatements converts each statement of sql and renders the result
+// as lines to diff: the request in format, or the error or no-op reason
+func renderStatements(conv *converter.Converter, sql, format string) [][]string {
+	var blocks [][]string
+	for _, stmt := range converter.SplitStatements(sql) {
+		var text string
+		req, warnings, err := sqlRequest(conv, stmt)
+		switch {
+		case err != nil:
+			text = "error: " + err.Error()
+		case format == "json":
+			text = requestText(req.Method, req.URL, req.Headers, req.Body)
+		default:
+			text, err = formatRequest(req, format)
+			if err != nil {
+				text = "error: " + err.Error()
+			}
+		}
+		for _, w := range warnings {
+			text += "\nwarning: " + w
+		}
+		blocks = append(blocks, strings.Split(text, "\n"))
+	}
+	return blocks
+}
+
+// requestText renders a request like an HTTP message: the request line,
+// the headers sorted by name and the body after a blank line
+func requestText(method, url string, headers map[string]string, body string) string {
+	lines := []string{method + " " + url}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, name+": "+headers[name])
+	}
+	if body != "" {
+		lines = append(lines, "", body)
+	}
+	return strings.Join(lines, "\n")
+}
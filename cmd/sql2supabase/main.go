@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sql2postgrest/pkg/codegen"
+	"sql2postgrest/pkg/convert"
+	"sql2postgrest/pkg/converter"
+)
+
+func main() {
+	var (
+		pretty       = flag.Bool("pretty", false, "Print JSON with the code, the intermediate PostgREST request and warnings")
+		showWarnings = flag.Bool("warnings", false, "Show conversion warnings")
+		statement    = flag.Bool("statement", false, "Print an awaited statement with one call per line")
+		client       = flag.String("client", "supabase", "Name of the Supabase client variable")
+		baseURL      = flag.String("url", "http://localhost:3000", "Base URL for PostgREST server (used for intermediate conversion)")
+		ndjson       = flag.Bool("ndjson", false, "Read one SQL statement per stdin line and write one JSON result per line")
+		jsonErrors   = flag.Bool("json-errors", false, "Print errors as JSON {code, type, message, hint, input} on stderr")
+	)
+	flag.Parse()
+
+	// fail reports err and exits with the code for its class
+	fail := func(err error, input string) {
+		os.Exit(convert.WriteError(os.Stderr, err, input, *jsonErrors))
+	}
+
+	conv := converter.NewConverter(*baseURL)
+	gen := codegen.NewGenerator(codegen.WithClient(*client))
+
+	if *ndjson {
+		err := convert.StreamNDJSON(os.Stdin, os.Stdout, func(sql string) (interface{}, error) {
+			postgrestResult, generated, err := convertSQL(conv, gen, sql)
+			if err != nil {
+				return nil, err
+			}
+			return jsonOutput(postgrestResult, generated, *statement), nil
+		})
+		if err != nil {
+			fail(err, "")
+		}
+		return
+	}
+
+	// Get SQL from args or stdin
+	var sql string
+	if flag.NArg() > 0 {
+		sql = flag.Arg(0)
+	} else {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeCharDevice) == 0 {
+			bytes, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fail(&convert.IOError{Err: fmt.Errorf("reading stdin: %w", err)}, "")
+			}
+			sql = strings.TrimSpace(string(bytes))
+		}
+	}
+
+	if sql == "" {
+		fmt.Fprintln(os.Stderr, "Usage: sql2supabase [OPTIONS] <sql>")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Examples:")
+		fmt.Fprintln(os.Stderr, "  sql2supabase \"SELECT * FROM users WHERE age >= 18\"")
+		fmt.Fprintln(os.Stderr, "  sql2supabase --statement \"SELECT id, name FROM users ORDER BY name LIMIT 10\"")
+		fmt.Fprintln(os.Stderr, "  sql2supabase \"INSERT INTO users (name, age) VALUES ('Alice', 30)\"")
+		fmt.Fprintln(os.Stderr, "  echo \"DELETE FROM users WHERE id = 1\" | sql2supabase --pretty")
+		fmt.Fprintln(os.Stderr, "  sql2supabase --ndjson < queries.sql")
+		os.Exit(convert.ExitUsage)
+	}
+
+	postgrestResult, generated, err := convertSQL(conv, gen, sql)
+	if err != nil {
+		fail(err, sql)
+	}
+
+	code := generated.Code
+	if *statement {
+		code = generated.Statement()
+	}
+
+	allWarnings := append(append([]string{}, postgrestResult.Warnings...), generated.Warnings...)
+
+	if *pretty {
+		jsonBytes, err := json.MarshalIndent(jsonOutput(postgrestResult, generated, *statement), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	fmt.Println(code)
+	if *showWarnings && len(allWarnings) > 0 {
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Warnings:")
+		for _, w := range allWarnings {
+			fmt.Fprintf(os.Stderr, "  - %s\n", w)
+		}
+	}
+}
+
+// convertSQL converts SQL to PostgREST and generates supabase-js from the
+// PostgREST request
+func convertSQL(conv *converter.Converter, gen *codegen.Generator, sql string) (*converter.ConversionResult, *codegen.Result, error) {
+	postgrestResult, err := conv.Convert(sql)
+	if err != nil {
+		return nil, nil, fmt.Errorf("converting SQL to PostgREST: %w", err)
+	}
+
+	// Session statements (SET, SHOW, GRANT) have no request to generate from
+	if postgrestResult.NoOp != "" {
+		return nil, nil, &convert.NoRequestError{Code: postgrestResult.NoOp, Reasons: postgrestResult.Warnings}
+	}
+
+	generated, err := gen.Generate(codegen.Request{
+		Method:  postgrestResult.Method,
+		Path:    postgrestResult.Path,
+		Query:   postgrestResult.QueryParams.Encode(),
+		Body:    postgrestResult.Body,
+		Headers: postgrestResult.Headers,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating supabase-js: %w", err)
+	}
+	return postgrestResult, generated, nil
+}
+
+// jsonOutput builds the JSON output: the code, the intermediate PostgREST
+// request and the warnings of both steps
+func jsonOutput(postgrestResult *converter.ConversionResult, generated *codegen.Result, statement bool) map[string]interface{} {
+	code := generated.Code
+	if statement {
+		code = generated.Statement()
+	}
+	output := map[string]interface{}{
+		"supabase": code,
+	}
+
+	// Add intermediate PostgREST representation
+	intermediate := map[string]interface{}{
+		"method": postgrestResult.Method,
+		"path":   postgrestResult.Path,
+	}
+	if query := postgrestResult.QueryParams.Encode(); query != "" {
+		intermediate["query"] = query
+	}
+	if postgrestResult.Body != "" {
+		intermediate["body"] = postgrestResult.Body
+	}
+	if len(postgrestResult.Headers) > 0 {
+		intermediate["headers"] = postgrestResult.Headers
+	}
+	output["intermediate_postgrest"] = intermediate
+
+	if warnings := append(append([]string{}, postgrestResult.Warnings...), generated.Warnings...); len(warnings) > 0 {
+		output["warnings"] = warnings
+	}
+	return output
+}
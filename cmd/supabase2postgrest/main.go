@@ -5,19 +5,70 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
+	"sql2postgrest/pkg/convert"
 	"sql2postgrest/pkg/supabase"
 )
 
+// headerFlags collects repeated -H "Name: value" flags
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	return fmt.Sprint(map[string]string(h))
+}
+
+func (h headerFlags) Set(value string) error {
+	name, val, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must look like \"Name: value\"")
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+// headerShorthand is a flag that sets one header of a headerFlags, as
+// --token sets Authorization: Bearer <token>
+type headerShorthand struct {
+	headers headerFlags
+	name    string
+	prefix  string
+}
+
+func (h headerShorthand) String() string {
+	return ""
+}
+
+func (h headerShorthand) Set(value string) error {
+	h.headers[h.name] = h.prefix + value
+	return nil
+}
+
 func main() {
 	// Command line flags
 	pretty := flag.Bool("pretty", false, "Pretty print JSON output")
 	baseURL := flag.String("url", "http://localhost:3000", "Base URL for PostgREST server")
+	dialectName := flag.String("dialect", "js", "Client library syntax of the query: js, python or dart")
+	rangeParams := flag.Bool("range-params", false, "Send .range() as limit/offset query params instead of a Range header")
+	format := flag.String("format", "json", "Output format: json, or curl for a copy-pasteable command")
+	execute := flag.Bool("exec", false, "Send the request to --url and print the status, Content-Range and response body")
+	ndjson := flag.Bool("ndjson", false, "Read one query per stdin line and write one JSON result per line")
+	jsonErrors := flag.Bool("json-errors", false, "Print errors as JSON {code, type, message, hint, input} on stderr")
+	headers := headerFlags{}
+	flag.Var(headers, "H", "Header added to the request as \"Name: value\" (repeatable), e.g. -H 'apikey: $ANON_KEY'")
+	flag.Var(headers, "header", "Same as -H")
+	flag.Var(headerShorthand{headers, "Authorization", "Bearer "}, "token", "JWT sent as the header Authorization: Bearer <JWT>, like -H")
+	flag.Var(headerShorthand{headers, "apikey", ""}, "apikey", "Supabase API key sent as the apikey header, like -H")
 	flag.Parse()
 
+	// fail reports err and exits with the code for its class
+	fail := func(err error, input string) {
+		os.Exit(convert.WriteError(os.Stderr, err, input, *jsonErrors))
+	}
+
 	// Get the Supabase query from arguments
 	args := flag.Args()
-	if len(args) == 0 {
+	if len(args) == 0 && !*ndjson {
 		fmt.Fprintf(os.Stderr, "Usage: supabase2postgrest [options] <supabase-query>\n")
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
 		flag.PrintDefaults()
@@ -26,22 +77,108 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  supabase2postgrest \"supabase.from('users').select('*').eq('age', 18)\"\n")
 		fmt.Fprintf(os.Stderr, "  supabase2postgrest \"supabase.from('users').insert({name: 'John', age: 30})\"\n")
 		fmt.Fprintf(os.Stderr, "  supabase2postgrest --pretty \"supabase.from('posts').select('*').order('created_at', {ascending: false}).limit(10)\"\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "  supabase2postgrest --dialect python 'supabase.table(\"users\").select(\"*\").eq(\"age\", 18).execute()'\n")
+		fmt.Fprintf(os.Stderr, "  supabase2postgrest --format curl \"supabase.from('users').insert({name: 'John'})\"\n")
+		fmt.Fprintf(os.Stderr, "  supabase2postgrest --exec --apikey $ANON_KEY \"supabase.from('users').select('*').limit(5)\"\n")
+		fmt.Fprintf(os.Stderr, "  supabase2postgrest --format curl -H \"apikey: $ANON_KEY\" -H \"Authorization: Bearer $JWT\" \"supabase.from('users').select('*')\"\n")
+		fmt.Fprintf(os.Stderr, "  supabase2postgrest --ndjson < queries.txt\n")
+		fmt.Fprintf(os.Stderr, "  supabase2postgrest --range-params \"supabase.from('posts').select('*').range(20, 29)\"\n")
+		fmt.Fprintf(os.Stderr, "  supabase2postgrest --dialect dart \"supabase.from('posts').select().order('created_at', ascending: false)\"\n")
+		os.Exit(convert.ExitUsage)
 	}
 
-	query := args[0]
+	dialect, err := supabase.ParseDialect(*dialectName)
+	if err != nil {
+		fail(convert.NewUsageError(err.Error()), "")
+	}
 
 	// Create converter
 	converter := supabase.NewConverter(*baseURL)
+	converter.Dialect = dialect
+	converter.RangeParams = *rangeParams
+	converter.Headers = headers
+
+	if *ndjson {
+		if *execute || *format != "json" {
+			fail(convert.NewUsageError("--ndjson writes JSON results and cannot be combined with --exec or --format"), "")
+		}
+		err := convert.StreamNDJSON(os.Stdin, os.Stdout, func(query string) (interface{}, error) {
+			result, err := converter.Convert(query)
+			if err != nil {
+				return nil, err
+			}
+			return jsonOutput(result, *baseURL), nil
+		})
+		if err != nil {
+			fail(err, "")
+		}
+		return
+	}
 
 	// Convert the query
-	result, err := converter.Convert(query)
+	result, err := converter.Convert(args[0])
+	if err != nil {
+		fail(err, args[0])
+	}
+
+	fullURL := requestURL(result, *baseURL)
+
+	if *execute || *format != "json" {
+		if !*execute && *format != "curl" {
+			fail(convert.NewUsageError(fmt.Sprintf("unknown format %q (expected json or curl)", *format)), "")
+		}
+		if result.Method == "" {
+			fail(supabase.NewUnsupportedError(supabase.ErrCodeHTTPOnly, result.Description+" has no single HTTP request", args[0], ""), args[0])
+		}
+		for _, w := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+		}
+		req := &convert.Request{Method: result.Method, URL: fullURL, Path: result.Path, Query: result.Query, Headers: result.Headers, Body: result.Body}
+		if !*execute {
+			fmt.Println(convert.CurlCommand(req))
+			return
+		}
+
+		resp, err := convert.Execute(nil, req, nil)
+		if err != nil {
+			fail(err, args[0])
+		}
+		fmt.Println(resp.Format(*pretty))
+		if resp.StatusCode >= 300 {
+			os.Exit(convert.ExitHTTPError)
+		}
+		return
+	}
+
+	output := jsonOutput(result, *baseURL)
+
+	// Print JSON output
+	var jsonBytes []byte
+	if *pretty {
+		jsonBytes, err = json.MarshalIndent(output, "", "  ")
+	} else {
+		jsonBytes, err = json.Marshal(output)
+	}
+
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error marshaling output: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Build output
+	fmt.Println(string(jsonBytes))
+}
+
+// requestURL is the full URL of a converted request
+func requestURL(result *supabase.PostgRESTOutput, baseURL string) string {
+	fullURL := baseURL + result.Path
+	if result.Query != "" {
+		fullURL += "?" + result.Query
+	}
+	return fullURL
+}
+
+// jsonOutput builds the JSON output for a converted query
+func jsonOutput(result *supabase.PostgRESTOutput, baseURL string) map[string]interface{} {
 	output := map[string]interface{}{
 		"method": result.Method,
 		"path":   result.Path,
@@ -70,25 +207,6 @@ func main() {
 		output["warnings"] = result.Warnings
 	}
 
-	// Full URL
-	fullURL := *baseURL + result.Path
-	if result.Query != "" {
-		fullURL += "?" + result.Query
-	}
-	output["url"] = fullURL
-
-	// Print JSON output
-	var jsonBytes []byte
-	if *pretty {
-		jsonBytes, err = json.MarshalIndent(output, "", "  ")
-	} else {
-		jsonBytes, err = json.Marshal(output)
-	}
-
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling output: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Println(string(jsonBytes))
+	output["url"] = requestURL(result, baseURL)
+	return output
 }
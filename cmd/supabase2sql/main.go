@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"os"
 
-	"sql2postgrest/pkg/reverse"
+	"sql2postgrest/pkg/convert"
 	"sql2postgrest/pkg/supabase"
 )
 
@@ -14,11 +14,19 @@ func main() {
 	// Command line flags
 	pretty := flag.Bool("pretty", false, "Pretty print JSON output")
 	baseURL := flag.String("url", "http://localhost:3000", "Base URL for PostgREST server (used for intermediate conversion)")
+	dialectName := flag.String("dialect", "js", "Client library syntax of the query: js, python or dart")
+	ndjson := flag.Bool("ndjson", false, "Read one query per stdin line and write one JSON result per line")
+	jsonErrors := flag.Bool("json-errors", false, "Print errors as JSON {code, type, message, hint, input} on stderr")
 	flag.Parse()
 
+	// fail reports err and exits with the code for its class
+	fail := func(err error, input string) {
+		os.Exit(convert.WriteError(os.Stderr, err, input, *jsonErrors))
+	}
+
 	// Get the Supabase query from arguments
 	args := flag.Args()
-	if len(args) == 0 {
+	if len(args) == 0 && !*ndjson {
 		fmt.Fprintf(os.Stderr, "Usage: supabase2sql [options] <supabase-query>\n")
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
 		flag.PrintDefaults()
@@ -27,45 +35,62 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  supabase2sql \"supabase.from('users').select('*').eq('age', 18)\"\n")
 		fmt.Fprintf(os.Stderr, "  supabase2sql \"supabase.from('users').insert({name: 'John', age: 30})\"\n")
 		fmt.Fprintf(os.Stderr, "  supabase2sql --pretty \"supabase.from('posts').select('*').order('created_at', {ascending: false}).limit(10)\"\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "  supabase2sql --dialect python 'supabase.table(\"users\").select(\"*\").eq(\"age\", 18).execute()'\n")
+		fmt.Fprintf(os.Stderr, "  supabase2sql --ndjson < queries.txt\n")
+		fmt.Fprintf(os.Stderr, "  supabase2sql --dialect dart \"supabase.from('posts').select().order('created_at', ascending: false)\"\n")
+		os.Exit(convert.ExitUsage)
 	}
 
-	query := args[0]
-
-	// Step 1: Convert Supabase → PostgREST
-	supabaseConverter := supabase.NewConverter(*baseURL)
-	postgrestResult, err := supabaseConverter.Convert(query)
+	dialect, err := supabase.ParseDialect(*dialectName)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error converting Supabase to PostgREST: %v\n", err)
-		os.Exit(1)
+		fail(convert.NewUsageError(err.Error()), "")
 	}
 
-	// Check if it's an HTTP-only operation (can't convert to SQL)
-	if postgrestResult.IsHTTPOnly {
-		fmt.Fprintf(os.Stderr, "Error: Cannot convert to SQL\n")
-		fmt.Fprintf(os.Stderr, "Reason: %s\n", postgrestResult.Description)
-		if len(postgrestResult.Warnings) > 0 {
-			fmt.Fprintf(os.Stderr, "Warnings:\n")
-			for _, warning := range postgrestResult.Warnings {
-				fmt.Fprintf(os.Stderr, "  - %s\n", warning)
+	// Convert Supabase → SQL, keeping the PostgREST request for reference
+	supabaseConverter := supabase.NewConverter(*baseURL)
+	supabaseConverter.Dialect = dialect
+
+	if *ndjson {
+		err := convert.StreamNDJSON(os.Stdin, os.Stdout, func(query string) (interface{}, error) {
+			sqlResult, err := supabaseConverter.ConvertToSQL(query)
+			if err != nil {
+				return nil, err
 			}
+			return jsonOutput(sqlResult), nil
+		})
+		if err != nil {
+			fail(err, "")
 		}
-		os.Exit(1)
+		return
 	}
 
-	// Step 2: Convert PostgREST → SQL
-	reverseConverter := reverse.NewConverter()
-	sqlResult, err := reverseConverter.Convert(
-		postgrestResult.Method,
-		postgrestResult.Path,
-		postgrestResult.Query,
-		postgrestResult.Body,
-	)
+	sqlResult, err := supabaseConverter.ConvertToSQL(args[0])
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error converting PostgREST to SQL: %v\n", err)
+		fail(err, args[0])
+	}
+	output := jsonOutput(sqlResult)
+
+	// Print JSON output
+	var jsonBytes []byte
+	if *pretty {
+		jsonBytes, err = json.MarshalIndent(output, "", "  ")
+	} else {
+		jsonBytes, err = json.Marshal(output)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling output: %v\n", err)
 		os.Exit(1)
 	}
 
+	fmt.Println(string(jsonBytes))
+}
+
+// jsonOutput builds the JSON output for a converted query: the SQL, the
+// intermediate PostgREST request, warnings, metadata and the HTTP request
+func jsonOutput(sqlResult *supabase.SQLOutput) map[string]interface{} {
+	postgrestResult := sqlResult.PostgREST
+
 	// Build output
 	output := map[string]interface{}{
 		"sql": sqlResult.SQL,
@@ -88,15 +113,8 @@ func main() {
 	output["intermediate_postgrest"] = intermediate
 
 	// Add warnings from both conversions
-	allWarnings := []string{}
-	if len(postgrestResult.Warnings) > 0 {
-		allWarnings = append(allWarnings, postgrestResult.Warnings...)
-	}
 	if len(sqlResult.Warnings) > 0 {
-		allWarnings = append(allWarnings, sqlResult.Warnings...)
-	}
-	if len(allWarnings) > 0 {
-		output["warnings"] = allWarnings
+		output["warnings"] = sqlResult.Warnings
 	}
 
 	// Add metadata if present
@@ -114,18 +132,5 @@ func main() {
 		}
 	}
 
-	// Print JSON output
-	var jsonBytes []byte
-	if *pretty {
-		jsonBytes, err = json.MarshalIndent(output, "", "  ")
-	} else {
-		jsonBytes, err = json.Marshal(output)
-	}
-
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling output: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Println(string(jsonBytes))
+	return output
 }
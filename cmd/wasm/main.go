@@ -4,10 +4,12 @@
 package main
 
 import (
-	"syscall/js"
+	"errors"
+	"sql2postgrest/pkg/codegen"
 	"sql2postgrest/pkg/converter"
 	"sql2postgrest/pkg/reverse"
 	"sql2postgrest/pkg/supabase"
+	"syscall/js"
 )
 
 func main() {
@@ -25,6 +27,9 @@ func main() {
 	// Chained converter: Supabase JS → PostgREST → SQL
 	js.Global().Set("supabase2sql", js.FuncOf(convertSupabaseToSQL))
 
+	// Chained converter: SQL → PostgREST → Supabase JS
+	js.Global().Set("sql2supabase", js.FuncOf(convertSQLToSupabase))
+
 	println("sql2postgrest WASM loaded (with reverse, Supabase, and chained converters)")
 	<-c
 }
@@ -45,7 +50,19 @@ func convertSQL(this js.Value, args []js.Value) interface{} {
 
 	conv := converter.NewConverter(baseURL)
 
-	jsonOutput, err := conv.ConvertToJSON(sql)
+	// Optional third argument: { trace: true } adds the clause mapping
+	trace := false
+	if len(args) >= 3 && args[2].Type() == js.TypeObject {
+		trace = args[2].Get("trace").Truthy()
+	}
+
+	var jsonOutput string
+	var err error
+	if trace {
+		jsonOutput, err = conv.ConvertWithTraceToJSON(sql)
+	} else {
+		jsonOutput, err = conv.ConvertToJSON(sql)
+	}
 	if err != nil {
 		return map[string]interface{}{
 			"error": err.Error(),
@@ -57,6 +74,8 @@ func convertSQL(this js.Value, args []js.Value) interface{} {
 
 func convertPostgREST(this js.Value, args []js.Value) interface{} {
 	// Expected input: { method: "GET", path: "/users", query: "age=gte.18", body: "" }
+	// or { method: "GET", url: "https://host/users?age=gte.18" }; formatSQL: true
+	// lays the SQL out over multiple lines
 	if len(args) < 1 {
 		return map[string]interface{}{
 			"error": "PostgREST request object required as first argument",
@@ -86,16 +105,32 @@ func convertPostgREST(this js.Value, args []js.Value) interface{} {
 		body = input.Get("body").String()
 	}
 
+	rawURL := ""
+	if !input.Get("url").IsUndefined() {
+		rawURL = input.Get("url").String()
+	}
+
 	// Validate required fields
-	if path == "" {
+	if path == "" && rawURL == "" {
 		return map[string]interface{}{
-			"error": "path is required (e.g., '/users')",
+			"error": "path or url is required (e.g., '/users')",
 		}
 	}
 
+	var opts []reverse.Option
+	if input.Get("formatSQL").Truthy() {
+		opts = append(opts, reverse.WithPrettySQL())
+	}
+
 	// Convert
-	conv := reverse.NewConverter()
-	result, err := conv.Convert(method, path, query, body)
+	conv := reverse.NewConverter(opts...)
+	var result *reverse.SQLResult
+	var err error
+	if rawURL != "" {
+		result, err = conv.ConvertURL(method, rawURL, body, nil)
+	} else {
+		result, err = conv.Convert(method, path, query, body)
+	}
 	if err != nil {
 		return map[string]interface{}{
 			"error": err.Error(),
@@ -162,6 +197,15 @@ func convertSupabase(this js.Value, args []js.Value) interface{} {
 
 	// Convert
 	conv := supabase.NewConverter(baseURL)
+	if len(args) >= 3 && !args[2].IsNull() && !args[2].IsUndefined() {
+		dialect, err := supabase.ParseDialect(args[2].String())
+		if err != nil {
+			return map[string]interface{}{
+				"error": err.Error(),
+			}
+		}
+		conv.Dialect = dialect
+	}
 	result, err := conv.Convert(query)
 	if err != nil {
 		return map[string]interface{}{
@@ -233,37 +277,30 @@ func convertSupabaseToSQL(this js.Value, args []js.Value) interface{} {
 		baseURL = args[1].String()
 	}
 
-	// Step 1: Convert Supabase → PostgREST
+	// Convert Supabase → SQL, keeping the PostgREST request for reference
 	supabaseConv := supabase.NewConverter(baseURL)
-	postgrestResult, err := supabaseConv.Convert(query)
-	if err != nil {
-		return map[string]interface{}{
-			"error": err.Error(),
-		}
-	}
-
-	// Check if it's an HTTP-only operation (can't convert to SQL)
-	if postgrestResult.IsHTTPOnly {
-		return map[string]interface{}{
-			"error":       "Cannot convert to SQL",
-			"description": postgrestResult.Description,
-			"warnings":    postgrestResult.Warnings,
+	if len(args) >= 3 && !args[2].IsNull() && !args[2].IsUndefined() {
+		dialect, err := supabase.ParseDialect(args[2].String())
+		if err != nil {
+			return map[string]interface{}{
+				"error": err.Error(),
+			}
 		}
+		supabaseConv.Dialect = dialect
 	}
-
-	// Step 2: Convert PostgREST → SQL
-	reverseConv := reverse.NewConverter()
-	sqlResult, err := reverseConv.Convert(
-		postgrestResult.Method,
-		postgrestResult.Path,
-		postgrestResult.Query,
-		postgrestResult.Body,
-	)
+	sqlResult, err := supabaseConv.ConvertToSQL(query)
 	if err != nil {
-		return map[string]interface{}{
+		response := map[string]interface{}{
 			"error": err.Error(),
 		}
+		var convErr *supabase.ConversionError
+		if errors.As(err, &convErr) {
+			response["code"] = convErr.Code
+			response["hint"] = convErr.Hint
+		}
+		return response
 	}
+	postgrestResult := sqlResult.PostgREST
 
 	// Build response
 	response := map[string]interface{}{
@@ -291,18 +328,11 @@ func convertSupabaseToSQL(this js.Value, args []js.Value) interface{} {
 	response["intermediate_postgrest"] = intermediate
 
 	// Add warnings from both conversions
-	allWarnings := []interface{}{}
-	if len(postgrestResult.Warnings) > 0 {
-		for _, w := range postgrestResult.Warnings {
-			allWarnings = append(allWarnings, w)
-		}
-	}
 	if len(sqlResult.Warnings) > 0 {
+		allWarnings := []interface{}{}
 		for _, w := range sqlResult.Warnings {
 			allWarnings = append(allWarnings, w)
 		}
-	}
-	if len(allWarnings) > 0 {
 		response["warnings"] = allWarnings
 	}
 
@@ -327,3 +357,102 @@ func convertSupabaseToSQL(this js.Value, args []js.Value) interface{} {
 
 	return response
 }
+
+func convertSQLToSupabase(this js.Value, args []js.Value) interface{} {
+	// Expected input: SQL query string
+	if len(args) < 1 {
+		return map[string]interface{}{
+			"error": "SQL query required as first argument",
+		}
+	}
+
+	sql := args[0].String()
+
+	baseURL := "http://localhost:3000"
+	if len(args) >= 2 && !args[1].IsNull() && !args[1].IsUndefined() {
+		baseURL = args[1].String()
+	}
+
+	// Optional third argument: { client: "supabaseAdmin" }
+	var opts []codegen.Option
+	if len(args) >= 3 && args[2].Type() == js.TypeObject {
+		if client := args[2].Get("client"); client.Type() == js.TypeString {
+			opts = append(opts, codegen.WithClient(client.String()))
+		}
+	}
+
+	// Step 1: Convert SQL → PostgREST
+	postgrestResult, err := converter.NewConverter(baseURL).Convert(sql)
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	// Session statements (SET, SHOW, GRANT) have no request to generate from
+	if postgrestResult.NoOp != "" {
+		warnings := []interface{}{}
+		for _, w := range postgrestResult.Warnings {
+			warnings = append(warnings, w)
+		}
+		return map[string]interface{}{
+			"error":    "Cannot convert to supabase-js",
+			"warnings": warnings,
+		}
+	}
+
+	// Step 2: Generate supabase-js from the PostgREST request
+	query := postgrestResult.QueryParams.Encode()
+	generated, err := codegen.NewGenerator(opts...).Generate(codegen.Request{
+		Method:  postgrestResult.Method,
+		Path:    postgrestResult.Path,
+		Query:   query,
+		Body:    postgrestResult.Body,
+		Headers: postgrestResult.Headers,
+	})
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	// Build response
+	response := map[string]interface{}{
+		"supabase":  generated.Code,
+		"statement": generated.Statement(),
+	}
+
+	// Add intermediate PostgREST representation
+	intermediate := map[string]interface{}{
+		"method": postgrestResult.Method,
+		"path":   postgrestResult.Path,
+	}
+	if query != "" {
+		intermediate["query"] = query
+	}
+	if postgrestResult.Body != "" {
+		intermediate["body"] = postgrestResult.Body
+	}
+	if len(postgrestResult.Headers) > 0 {
+		headersObj := make(map[string]interface{})
+		for k, v := range postgrestResult.Headers {
+			headersObj[k] = v
+		}
+		intermediate["headers"] = headersObj
+	}
+	response["intermediate_postgrest"] = intermediate
+
+	// Add warnings from both steps
+	allWarnings := []interface{}{}
+	for _, w := range postgrestResult.Warnings {
+		allWarnings = append(allWarnings, w)
+	}
+	for _, w := range generated.Warnings {
+		allWarnings = append(allWarnings, w)
+	}
+	if len(allWarnings) > 0 {
+		response["warnings"] = allWarnings
+	}
+
+	return response
+}
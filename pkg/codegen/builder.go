@@ -0,0 +1,464 @@
+package codegen
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// param is one query string parameter, kept in request order
+type param struct {
+	key   string
+	value string
+}
+
+// builder collects the calls of one generated chain
+type builder struct {
+	method  string
+	body    string
+	headers map[string]string // Lowercased names
+	params  []param
+	prefer  map[string]string
+	rpcArgs map[int]bool // Indexes of params passed as GET /rpc/ arguments
+	result  *Result
+}
+
+// call appends .name(args...) to the chain
+func (b *builder) call(name string, args ...string) {
+	b.result.calls = append(b.result.calls, "."+name+"("+strings.Join(args, ", ")+")")
+}
+
+// warn records a part of the request the generated code leaves out
+func (b *builder) warn(format string, args ...interface{}) {
+	b.result.Warnings = append(b.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// param returns the first value of a query parameter
+func (b *builder) param(key string) (string, bool) {
+	for _, p := range b.params {
+		if p.key == key {
+			return p.value, true
+		}
+	}
+	return "", false
+}
+
+// parseQuery splits the query string into params in request order
+func (b *builder) parseQuery(query string) error {
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return fmt.Errorf("invalid query parameter %q: %w", rawKey, err)
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		b.params = append(b.params, param{key: key, value: value})
+	}
+	return nil
+}
+
+// preferOptions are the Prefer preferences supabase-js methods can set
+var preferOptions = map[string]bool{"count": true, "return": true, "resolution": true, "missing": true}
+
+// parsePrefer reads the Prefer header, warning about preferences no
+// supabase-js method sets
+func (b *builder) parsePrefer() {
+	b.prefer = map[string]string{}
+	var ignored []string
+	for _, item := range strings.Split(b.headers["prefer"], ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		if key == "" {
+			continue
+		}
+		if !preferOptions[key] {
+			ignored = append(ignored, item)
+			continue
+		}
+		b.prefer[key] = value
+	}
+	for _, item := range ignored {
+		b.warn("Prefer: %s has no supabase-js equivalent and was left out", strings.TrimSpace(item))
+	}
+}
+
+// generate writes the whole chain for a request to path
+func (b *builder) generate(path string) error {
+	profile := "accept-profile"
+	if b.method != "GET" && b.method != "HEAD" {
+		profile = "content-profile"
+	}
+	if schema := b.headers[profile]; schema != "" {
+		b.call("schema", jsString(schema))
+	}
+
+	if fn, isRPC := strings.CutPrefix(path, "/rpc/"); isRPC {
+		if err := b.rpc(fn); err != nil {
+			return err
+		}
+	} else {
+		table := strings.TrimPrefix(path, "/")
+		if table == "" || strings.Contains(table, "/") {
+			return fmt.Errorf("path %q does not name a table or an /rpc/ function", path)
+		}
+		b.call("from", jsString(table))
+		if err := b.operation(); err != nil {
+			return err
+		}
+	}
+
+	if err := b.modifiers(); err != nil {
+		return err
+	}
+	b.rangeHeader()
+	b.accept()
+	b.otherHeaders()
+	return nil
+}
+
+// operation writes the select, insert, upsert, update or delete call
+func (b *builder) operation() error {
+	switch b.method {
+	case "GET", "HEAD":
+		b.call("select", b.selectArgs()...)
+		return nil
+
+	case "POST":
+		var opts [][2]string
+		name := "insert"
+		onConflict, hasOnConflict := b.param("on_conflict")
+		switch b.prefer["resolution"] {
+		case "merge-duplicates":
+			name = "upsert"
+		case "ignore-duplicates":
+			name = "upsert"
+			opts = append(opts, [2]string{"ignoreDuplicates", "true"})
+		default:
+			if hasOnConflict {
+				b.warn("on_conflict=%s without a resolution preference has no supabase-js equivalent and was left out", onConflict)
+			}
+		}
+		if name == "upsert" && hasOnConflict {
+			opts = append([][2]string{{"onConflict", jsString(onConflict)}}, opts...)
+		}
+		if b.prefer["missing"] == "default" {
+			opts = append(opts, [2]string{"defaultToNull", "false"})
+		}
+		return b.mutation(name, true, opts)
+
+	case "PATCH":
+		return b.mutation("update", true, nil)
+
+	case "PUT":
+		b.warn("PUT replaces a single row; supabase-js sends .upsert() as a POST with resolution=merge-duplicates")
+		return b.mutation("upsert", true, nil)
+
+	case "DELETE":
+		return b.mutation("delete", false, nil)
+	}
+	return &UnsupportedError{Feature: "HTTP method " + b.method, Hint: "expected GET, HEAD, POST, PUT, PATCH or DELETE"}
+}
+
+// selectArgs returns the arguments of a read's .select(): the columns,
+// then {count, head} when set
+func (b *builder) selectArgs() []string {
+	columns, ok := b.param("select")
+	if !ok || columns == "" {
+		columns = "*"
+	}
+	args := []string{jsString(columns)}
+
+	var opts [][2]string
+	if count := b.prefer["count"]; count != "" {
+		opts = append(opts, [2]string{"count", jsString(count)})
+	}
+	if b.method == "HEAD" {
+		opts = append(opts, [2]string{"head", "true"})
+	}
+	if len(opts) > 0 {
+		args = append(args, jsObject(opts))
+	}
+	return args
+}
+
+// mutation writes an insert, upsert, update or delete call with its body
+// and options, then .select() when the request asks for the rows back
+func (b *builder) mutation(name string, withBody bool, opts [][2]string) error {
+	var args []string
+	if withBody {
+		if b.body == "" {
+			return fmt.Errorf("%s request needs a JSON body for .%s()", b.method, name)
+		}
+		body, err := jsonToJS(b.body)
+		if err != nil {
+			return fmt.Errorf("invalid JSON body: %w", err)
+		}
+		args = append(args, body)
+	}
+	if count := b.prefer["count"]; count != "" {
+		opts = append(opts, [2]string{"count", jsString(count)})
+	}
+	if len(opts) > 0 {
+		args = append(args, jsObject(opts))
+	}
+	b.call(name, args...)
+
+	switch b.prefer["return"] {
+	case "representation":
+		columns, ok := b.param("select")
+		if !ok || columns == "" {
+			columns = "*"
+		}
+		b.call("select", jsString(columns))
+	case "headers-only":
+		b.warn("Prefer: return=headers-only has no supabase-js equivalent and was left out")
+	}
+	return nil
+}
+
+// rpc writes the .rpc() call. POST sends the body as the arguments; GET
+// and HEAD pass the query parameters that are not filters.
+func (b *builder) rpc(fn string) error {
+	if fn == "" || strings.Contains(fn, "/") {
+		return fmt.Errorf("path /rpc/%s does not name a function", fn)
+	}
+
+	args := ""
+	var opts [][2]string
+	switch b.method {
+	case "POST":
+		if b.body != "" {
+			body, err := jsonToJS(b.body)
+			if err != nil {
+				return fmt.Errorf("invalid JSON body: %w", err)
+			}
+			if body != "{}" {
+				args = body
+			}
+		}
+	case "GET", "HEAD":
+		b.rpcArgs = map[int]bool{}
+		var entries [][2]string
+		for i, p := range b.params {
+			if reservedParam(p.key) || isFilterValue(p.value) {
+				continue
+			}
+			b.rpcArgs[i] = true
+			entries = append(entries, [2]string{p.key, jsScalar(p.value)})
+		}
+		if len(entries) > 0 {
+			args = jsObject(entries)
+		}
+		if b.method == "GET" {
+			opts = append(opts, [2]string{"get", "true"})
+		} else {
+			opts = append(opts, [2]string{"head", "true"})
+		}
+	default:
+		return &UnsupportedError{Feature: "HTTP method " + b.method + " for /rpc/" + fn, Hint: "expected GET, HEAD or POST"}
+	}
+	if count := b.prefer["count"]; count != "" {
+		opts = append(opts, [2]string{"count", jsString(count)})
+	}
+
+	callArgs := []string{jsString(fn)}
+	if args != "" || len(opts) > 0 {
+		if args == "" {
+			args = "{}"
+		}
+		callArgs = append(callArgs, args)
+	}
+	if len(opts) > 0 {
+		callArgs = append(callArgs, jsObject(opts))
+	}
+	b.call("rpc", callArgs...)
+
+	if columns, ok := b.param("select"); ok && columns != "" {
+		b.call("select", jsString(columns))
+	}
+	return nil
+}
+
+// reservedParam reports whether a query parameter is not a filter
+func reservedParam(key string) bool {
+	switch key {
+	case "select", "order", "limit", "offset", "columns", "on_conflict":
+		return true
+	}
+	if _, _, found := cutEmbedModifier(key); found {
+		return true
+	}
+	_, _, _, isLogic := logicKey(key)
+	return isLogic
+}
+
+// page is the limit and offset of the main rows or of one embed
+type page struct {
+	limit  *int
+	offset *int
+}
+
+// modifiers writes the filters in request order, then the order, then
+// the limit or range of the rows and of each embed, whatever order the
+// query string lists them in
+func (b *builder) modifiers() error {
+	var orders [][2]string // Referenced table and order value
+	pages := map[string]*page{}
+	var pageOrder []string
+	pageFor := func(table string) *page {
+		if pages[table] == nil {
+			pages[table] = &page{}
+			pageOrder = append(pageOrder, table)
+		}
+		return pages[table]
+	}
+
+	for i, p := range b.params {
+		if b.rpcArgs[i] {
+			continue
+		}
+		table, modifier, isModifier := cutEmbedModifier(p.key)
+		if !isModifier && (p.key == "order" || p.key == "limit" || p.key == "offset") {
+			modifier, isModifier = p.key, true
+		}
+
+		switch {
+		case p.key == "select", p.key == "columns", p.key == "on_conflict":
+		case isModifier && modifier == "order":
+			orders = append(orders, [2]string{table, p.value})
+		case isModifier:
+			n, err := strconv.Atoi(p.value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s value %q: must be a non-negative integer", p.key, p.value)
+			}
+			pg := pageFor(table)
+			if modifier == "limit" {
+				pg.limit = &n
+			} else {
+				pg.offset = &n
+			}
+		default:
+			if table, logic, negated, isLogic := logicKey(p.key); isLogic {
+				b.logic(table, logic, negated, p.value)
+				continue
+			}
+			if err := b.filter(p.key, p.value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, order := range orders {
+		if err := b.orders(order[1], order[0]); err != nil {
+			return err
+		}
+	}
+	for _, table := range pageOrder {
+		b.page(table, pages[table])
+	}
+	return nil
+}
+
+// page writes .limit() or .range() for the main rows or an embed
+func (b *builder) page(table string, pg *page) {
+	var opts []string
+	if table != "" {
+		opts = append(opts, jsObject([][2]string{{"referencedTable", jsString(table)}}))
+	}
+
+	switch {
+	case pg.limit != nil && pg.offset != nil && *pg.limit > 0:
+		b.call("range", append([]string{strconv.Itoa(*pg.offset), strconv.Itoa(*pg.offset + *pg.limit - 1)}, opts...)...)
+	case pg.limit != nil:
+		b.call("limit", append([]string{strconv.Itoa(*pg.limit)}, opts...)...)
+		if pg.offset != nil {
+			b.warn("offset=%d with limit=0 has no supabase-js equivalent and was left out", *pg.offset)
+		}
+	case pg.offset != nil:
+		b.warn("offset=%d without a limit has no supabase-js equivalent; use .range(from, to)", *pg.offset)
+	}
+}
+
+// rangeHeader writes .range() for a Range: first-last header
+func (b *builder) rangeHeader() {
+	value := b.headers["range"]
+	if value == "" {
+		return
+	}
+	first, last, _ := strings.Cut(value, "-")
+	from, errFrom := strconv.Atoi(strings.TrimSpace(first))
+	to, errTo := strconv.Atoi(strings.TrimSpace(last))
+	if errFrom != nil || errTo != nil {
+		b.warn("Range: %s has no supabase-js equivalent; .range() needs both ends", value)
+		return
+	}
+	b.call("range", strconv.Itoa(from), strconv.Itoa(to))
+}
+
+// accept writes .single(), .csv(), .geojson() or .explain() for the
+// Accept header
+func (b *builder) accept() {
+	value := strings.ToLower(b.headers["accept"])
+	switch {
+	case value == "", value == "*/*", strings.HasPrefix(value, "application/json"):
+	case strings.Contains(value, "vnd.pgrst.object"):
+		b.call("single")
+	case strings.HasPrefix(value, "text/csv"):
+		b.call("csv")
+	case strings.HasPrefix(value, "application/geo+json"):
+		b.call("geojson")
+	case strings.Contains(value, "vnd.pgrst.plan"):
+		var opts [][2]string
+		for _, part := range strings.Split(value, ";") {
+			if options, found := strings.CutPrefix(strings.TrimSpace(part), "options="); found {
+				names := strings.Split(strings.Trim(options, `"`), "|")
+				sort.Strings(names)
+				for _, name := range names {
+					opts = append(opts, [2]string{name, "true"})
+				}
+			}
+		}
+		if strings.Contains(value, "plan+json") {
+			opts = append(opts, [2]string{"format", "'json'"})
+		}
+		if len(opts) > 0 {
+			b.call("explain", jsObject(opts))
+		} else {
+			b.call("explain")
+		}
+	default:
+		b.warn("Accept: %s has no supabase-js equivalent and was left out", b.headers["accept"])
+	}
+}
+
+// handledHeaders are set by the generated calls or by the client itself
+var handledHeaders = map[string]bool{
+	"prefer": true, "accept": true, "range": true, "range-unit": true,
+	"accept-profile": true, "content-profile": true, "content-type": true, "content-length": true,
+	"authorization": true, "apikey": true, "host": true, "user-agent": true, "x-client-info": true,
+}
+
+// otherHeaders warns about headers the query builder cannot set
+func (b *builder) otherHeaders() {
+	var names []string
+	for name := range b.headers {
+		if !handledHeaders[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.warn("header %s is not set by supabase-js query methods; pass it in the client's global headers", http.CanonicalHeaderKey(name))
+	}
+	if contentType := b.headers["content-type"]; contentType != "" && !strings.HasPrefix(strings.ToLower(contentType), "application/json") {
+		b.warn("Content-Type: %s bodies are not sent by supabase-js, which always sends JSON", contentType)
+	}
+}
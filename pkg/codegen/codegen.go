@@ -0,0 +1,134 @@
+// Package codegen generates supabase-js v2 code from PostgREST requests,
+// the reverse of pkg/supabase:
+//
+//	GET /users?select=*&age=gte.18&order=name.asc
+//
+// becomes
+//
+//	supabase.from('users').select('*').gte('age', 18).order('name')
+//
+// Fetch, Axios, GoNetHTTP and PythonRequests render the same requests as
+// plain HTTP client calls.
+package codegen
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Generator turns PostgREST requests into supabase-js code
+type Generator struct {
+	client string
+}
+
+// Option configures optional Generator behavior
+type Option func(*Generator)
+
+// WithClient sets the name of the client variable the code calls, e.g.
+// client or supabaseAdmin instead of supabase
+func WithClient(name string) Option {
+	return func(g *Generator) {
+		if name != "" {
+			g.client = name
+		}
+	}
+}
+
+// NewGenerator creates a new supabase-js generator
+func NewGenerator(opts ...Option) *Generator {
+	g := &Generator{client: "supabase"}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Request is a PostgREST request to generate code for
+type Request struct {
+	Method  string            // GET, HEAD, POST, PUT, PATCH, DELETE
+	Path    string            // /users or /rpc/fn, optionally under /rest/v1
+	Query   string            // Raw query string
+	Body    string            // JSON body
+	Headers map[string]string // Prefer, Accept, Range, Accept-Profile, ...
+}
+
+// UnsupportedError reports a request supabase-js has no call for, such as
+// an unknown HTTP method or filter operator
+type UnsupportedError struct {
+	Feature string // What cannot be generated, e.g. "HTTP method OPTIONS"
+	Hint    string // What is accepted instead, if anything
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Hint == "" {
+		return e.Feature + " not supported"
+	}
+	return e.Feature + " not supported - " + e.Hint
+}
+
+// Result is the generated supabase-js code
+type Result struct {
+	Code     string   // One-line chain, e.g. supabase.from('users').select('*')
+	Warnings []string // Parts of the request supabase-js cannot express
+
+	client string
+	calls  []string
+}
+
+// Statement returns the code as an awaited statement with one call per
+// line:
+//
+//	const { data, error } = await supabase
+//	  .from('users')
+//	  .select('*')
+func (r *Result) Statement() string {
+	var b strings.Builder
+	b.WriteString("const { data, error } = await " + r.client)
+	for _, call := range r.calls {
+		b.WriteString("\n  " + call)
+	}
+	return b.String()
+}
+
+// Generate converts a PostgREST request to supabase-js code
+func (g *Generator) Generate(req Request) (*Result, error) {
+	b := &builder{
+		method:  strings.ToUpper(strings.TrimSpace(req.Method)),
+		body:    strings.TrimSpace(req.Body),
+		headers: map[string]string{},
+		result:  &Result{client: g.client},
+	}
+	if b.method == "" {
+		b.method = "GET"
+	}
+	for name, value := range req.Headers {
+		b.headers[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+
+	path := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(req.Path), "/rest/v1"), "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if err := b.parseQuery(strings.TrimPrefix(req.Query, "?")); err != nil {
+		return nil, err
+	}
+	b.parsePrefer()
+
+	if err := b.generate(path); err != nil {
+		return nil, err
+	}
+
+	b.result.Code = g.client + strings.Join(b.result.calls, "")
+	return b.result, nil
+}
+
+// GenerateURL converts a request given as a URL such as
+// https://host/rest/v1/users?age=gte.18 or /users?age=gte.18
+func (g *Generator) GenerateURL(method, rawURL, body string, headers map[string]string) (*Result, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	return g.Generate(Request{Method: method, Path: parsed.Path, Query: parsed.RawQuery, Body: body, Headers: headers})
+}
@@ -0,0 +1,297 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{
+			name: "select with filter and order",
+			req:  Request{Method: "GET", Path: "/users", Query: "select=*&age=gte.18&order=name.asc"},
+			want: "supabase.from('users').select('*').gte('age', 18).order('name')",
+		},
+		{
+			name: "rest/v1 prefix and no select",
+			req:  Request{Method: "GET", Path: "/rest/v1/users", Query: "status=eq.active"},
+			want: "supabase.from('users').select('*').eq('status', 'active')",
+		},
+		{
+			name: "in list, not and or",
+			req:  Request{Method: "GET", Path: "/users", Query: `status=in.(active,"on hold")&email=not.is.null&or=(age.lt.18,age.gt.65)`},
+			want: "supabase.from('users').select('*').in('status', ['active', 'on hold']).not('email', 'is', null).or('age.lt.18,age.gt.65')",
+		},
+		{
+			name: "embedded filter, order and limit",
+			req:  Request{Method: "GET", Path: "/users", Query: "select=id,posts(title)&posts.status=eq.published&posts.order=created_at.desc&posts.limit=5"},
+			want: "supabase.from('users').select('id,posts(title)').eq('posts.status', 'published').order('created_at', {ascending: false, referencedTable: 'posts'}).limit(5, {referencedTable: 'posts'})",
+		},
+		{
+			name: "order after filters whatever the param order",
+			req:  Request{Method: "GET", Path: "/users", Query: "limit=5&order=name.asc&status=eq.active"},
+			want: "supabase.from('users').select('*').eq('status', 'active').order('name').limit(5)",
+		},
+		{
+			name: "limit and offset become a range",
+			req:  Request{Method: "GET", Path: "/users", Query: "order=id.desc.nullslast&limit=10&offset=20"},
+			want: "supabase.from('users').select('*').order('id', {ascending: false, nullsFirst: false}).range(20, 29)",
+		},
+		{
+			name: "count, single and schema headers",
+			req: Request{Method: "GET", Path: "/users", Query: "id=eq.1", Headers: map[string]string{
+				"Prefer": "count=exact", "Accept": "application/vnd.pgrst.object+json", "Accept-Profile": "api",
+			}},
+			want: "supabase.schema('api').from('users').select('*', {count: 'exact'}).eq('id', 1).single()",
+		},
+		{
+			name: "head count",
+			req:  Request{Method: "HEAD", Path: "/users", Headers: map[string]string{"Prefer": "count=exact"}},
+			want: "supabase.from('users').select('*', {count: 'exact', head: true})",
+		},
+		{
+			name: "range header",
+			req:  Request{Method: "GET", Path: "/users", Headers: map[string]string{"Range": "0-9"}},
+			want: "supabase.from('users').select('*').range(0, 9)",
+		},
+		{
+			name: "array, range, text search and pattern operators",
+			req:  Request{Method: "GET", Path: "/posts", Query: "body=wfts(english).cat&tags=cs.{a,b}&during=ov.[1,5)&title=like(any).{a*,b*}&id=eq(any).{1,2}&and=(a.eq.1,b.eq.2)"},
+			want: "supabase.from('posts').select('*').textSearch('body', 'cat', {type: 'websearch', config: 'english'}).contains('tags', ['a', 'b']).overlaps('during', '[1,5)').likeAnyOf('title', ['a*', 'b*']).filter('id', 'eq(any)', '{1,2}').or('and(a.eq.1,b.eq.2)')",
+		},
+		{
+			name: "insert keeps body key order",
+			req:  Request{Method: "POST", Path: "/users", Body: `{"name":"Ann","age":30,"tags":["x"],"meta":{"first login":null}}`},
+			want: "supabase.from('users').insert({name: 'Ann', age: 30, tags: ['x'], meta: {'first login': null}})",
+		},
+		{
+			name: "upsert returning rows",
+			req: Request{Method: "POST", Path: "/users", Query: "on_conflict=email&select=id", Body: `{"email":"a@example.com"}`, Headers: map[string]string{
+				"Prefer": "resolution=merge-duplicates,return=representation",
+			}},
+			want: "supabase.from('users').upsert({email: 'a@example.com'}, {onConflict: 'email'}).select('id')",
+		},
+		{
+			name: "ignore duplicates",
+			req:  Request{Method: "POST", Path: "/users", Body: `[{"id":1}]`, Headers: map[string]string{"Prefer": "resolution=ignore-duplicates"}},
+			want: "supabase.from('users').upsert([{id: 1}], {ignoreDuplicates: true})",
+		},
+		{
+			name: "update",
+			req:  Request{Method: "PATCH", Path: "/users", Query: "id=eq.1", Body: `{"active":false}`},
+			want: "supabase.from('users').update({active: false}).eq('id', 1)",
+		},
+		{
+			name: "delete with count",
+			req:  Request{Method: "DELETE", Path: "/users", Query: "id=in.(1,2)", Headers: map[string]string{"Prefer": "count=exact"}},
+			want: "supabase.from('users').delete({count: 'exact'}).in('id', [1, 2])",
+		},
+		{
+			name: "rpc get with arguments and a filter",
+			req:  Request{Method: "GET", Path: "/rpc/add", Query: "a=1&b=2&result=gt.2"},
+			want: "supabase.rpc('add', {a: 1, b: 2}, {get: true}).gt('result', 2)",
+		},
+		{
+			name: "rpc post",
+			req:  Request{Method: "POST", Path: "/rpc/add", Body: `{"a":1,"b":"x"}`},
+			want: "supabase.rpc('add', {a: 1, b: 'x'})",
+		},
+		{
+			name: "rpc post without arguments",
+			req:  Request{Method: "POST", Path: "/rpc/now"},
+			want: "supabase.rpc('now')",
+		},
+		{
+			name: "quotes in strings",
+			req:  Request{Method: "GET", Path: "/users", Query: "name=eq.O'Brien"},
+			want: `supabase.from('users').select('*').eq('name', 'O\'Brien')`,
+		},
+	}
+
+	g := NewGenerator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := g.Generate(tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Code)
+			assert.Empty(t, result.Warnings)
+		})
+	}
+}
+
+func TestGenerateWarnings(t *testing.T) {
+	g := NewGenerator()
+
+	result, err := g.Generate(Request{Method: "DELETE", Path: "/users", Query: "id=eq.1", Headers: map[string]string{
+		"Prefer": "tx=rollback", "X-Trace": "1",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "supabase.from('users').delete().eq('id', 1)", result.Code)
+	assert.Equal(t, []string{
+		"Prefer: tx=rollback has no supabase-js equivalent and was left out",
+		"header X-Trace is not set by supabase-js query methods; pass it in the client's global headers",
+	}, result.Warnings)
+
+	result, err = g.Generate(Request{Method: "GET", Path: "/users", Query: "offset=5"})
+	require.NoError(t, err)
+	assert.Equal(t, "supabase.from('users').select('*')", result.Code)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "offset=5 without a limit")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	g := NewGenerator()
+
+	tests := []struct {
+		name string
+		req  Request
+	}{
+		{"no table", Request{Method: "GET", Path: "/"}},
+		{"filter without operator", Request{Method: "GET", Path: "/users", Query: "age=18"}},
+		{"unknown operator", Request{Method: "GET", Path: "/users", Query: "age=between.1"}},
+		{"insert without body", Request{Method: "POST", Path: "/users"}},
+		{"invalid JSON body", Request{Method: "PATCH", Path: "/users", Body: "{name:"}},
+		{"unsupported method", Request{Method: "OPTIONS", Path: "/users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := g.Generate(tt.req)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGenerateURL(t *testing.T) {
+	g := NewGenerator(WithClient("client"))
+
+	result, err := g.GenerateURL("GET", "https://abc.supabase.co/rest/v1/users?select=id&id=eq.1", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "client.from('users').select('id').eq('id', 1)", result.Code)
+	assert.Equal(t, "const { data, error } = await client\n  .from('users')\n  .select('id')\n  .eq('id', 1)", result.Statement())
+}
+
+func TestFetch(t *testing.T) {
+	assert.Equal(t,
+		"const response = await fetch('http://localhost:3000/users?age=gt.18')\nconst data = await response.json()",
+		Fetch(HTTPRequest{Method: "GET", URL: "http://localhost:3000/users?age=gt.18"}),
+	)
+
+	assert.Equal(t, `const response = await fetch('http://localhost:3000/users', {
+  method: 'POST',
+  headers: {
+    'Content-Type': 'application/json',
+    Prefer: 'return=representation',
+  },
+  body: JSON.stringify({name: 'O\'Brien', age: 30}),
+})
+const data = await response.json()`, Fetch(HTTPRequest{
+		Method:  "POST",
+		URL:     "http://localhost:3000/users",
+		Headers: map[string]string{"Prefer": "return=representation", "Content-Type": "application/json"},
+		Body:    `{"name":"O'Brien","age":30}`,
+	}))
+
+	// Minimal mutations and HEAD have no body to read; CSV is text
+	assert.Equal(t,
+		"const response = await fetch('http://localhost:3000/users?id=eq.1', {\n  method: 'DELETE',\n})",
+		Fetch(HTTPRequest{Method: "DELETE", URL: "http://localhost:3000/users?id=eq.1"}),
+	)
+	assert.Contains(t, Fetch(HTTPRequest{URL: "http://localhost:3000/users", Headers: map[string]string{"Accept": "text/csv"}}), "await response.text()")
+}
+
+func TestAxios(t *testing.T) {
+	assert.Equal(t, `const { data } = await axios({
+  method: 'patch',
+  url: 'http://localhost:3000/users?id=eq.1',
+  headers: {
+    Prefer: 'return=representation',
+  },
+  data: {active: false},
+})`, Axios(HTTPRequest{
+		Method:  "PATCH",
+		URL:     "http://localhost:3000/users?id=eq.1",
+		Headers: map[string]string{"Prefer": "return=representation"},
+		Body:    `{"active":false}`,
+	}))
+
+	assert.Equal(t, `const { headers } = await axios({
+  method: 'head',
+  url: 'http://localhost:3000/users',
+  headers: {
+    Prefer: 'count=exact',
+  },
+})`, Axios(HTTPRequest{Method: "HEAD", URL: "http://localhost:3000/users", Headers: map[string]string{"Prefer": "count=exact"}}))
+}
+
+func TestGoNetHTTP(t *testing.T) {
+	assert.Equal(t, "body := strings.NewReader(`{\"active\":false}`)\n"+`req, err := http.NewRequest("PATCH", "http://localhost:3000/users?id=eq.1", body)
+if err != nil {
+	return err
+}
+req.Header.Set("Content-Type", "application/json")
+req.Header.Set("Prefer", "return=representation")
+
+resp, err := http.DefaultClient.Do(req)
+if err != nil {
+	return err
+}
+defer resp.Body.Close()
+
+data, err := io.ReadAll(resp.Body)
+if err != nil {
+	return err
+}`, GoNetHTTP(HTTPRequest{
+		Method:  "PATCH",
+		URL:     "http://localhost:3000/users?id=eq.1",
+		Headers: map[string]string{"Prefer": "return=representation", "Content-Type": "application/json"},
+		Body:    `{"active":false}`,
+	}))
+
+	assert.Equal(t, `req, err := http.NewRequest("DELETE", "http://localhost:3000/users?id=eq.1", nil)
+if err != nil {
+	return err
+}
+
+resp, err := http.DefaultClient.Do(req)
+if err != nil {
+	return err
+}
+defer resp.Body.Close()`, GoNetHTTP(HTTPRequest{Method: "DELETE", URL: "http://localhost:3000/users?id=eq.1"}))
+}
+
+func TestPythonRequests(t *testing.T) {
+	assert.Equal(t, `import requests
+
+response = requests.post(
+    "http://localhost:3000/users",
+    headers={
+        "Prefer": "return=representation",
+    },
+    json=[{"name": "Ann", "active": True, "note": None, "age": 30}],
+)
+response.raise_for_status()
+data = response.json()`, PythonRequests(HTTPRequest{
+		Method:  "POST",
+		URL:     "http://localhost:3000/users",
+		Headers: map[string]string{"Prefer": "return=representation"},
+		Body:    `[{"name":"Ann","active":true,"note":null,"age":30}]`,
+	}))
+
+	assert.Equal(t, `import requests
+
+response = requests.get(
+    "http://localhost:3000/users?select=name",
+    headers={
+        "Accept": "text/csv",
+    },
+)
+response.raise_for_status()
+data = response.text`, PythonRequests(HTTPRequest{URL: "http://localhost:3000/users?select=name", Headers: map[string]string{"Accept": "text/csv"}}))
+}
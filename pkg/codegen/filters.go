@@ -0,0 +1,238 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// filterMethods maps PostgREST operators to the supabase-js method that
+// writes them
+var filterMethods = map[string]string{
+	"eq":         "eq",
+	"neq":        "neq",
+	"gt":         "gt",
+	"gte":        "gte",
+	"lt":         "lt",
+	"lte":        "lte",
+	"like":       "like",
+	"ilike":      "ilike",
+	"is":         "is",
+	"in":         "in",
+	"cs":         "contains",
+	"cd":         "containedBy",
+	"ov":         "overlaps",
+	"sl":         "rangeLt",
+	"sr":         "rangeGt",
+	"nxl":        "rangeGte",
+	"nxr":        "rangeLte",
+	"adj":        "rangeAdjacent",
+	"fts":        "textSearch",
+	"plfts":      "textSearch",
+	"phfts":      "textSearch",
+	"wfts":       "textSearch",
+	"match":      "filter",
+	"imatch":     "filter",
+	"isdistinct": "filter",
+}
+
+// textSearchTypes maps full-text operators to the .textSearch() type option
+var textSearchTypes = map[string]string{
+	"plfts": "plain",
+	"phfts": "phrase",
+	"wfts":  "websearch",
+}
+
+// splitOperator splits fts(english) into fts and english, and eq(any) into
+// eq and any
+func splitOperator(op string) (string, string) {
+	if i := strings.IndexByte(op, '('); i > 0 && strings.HasSuffix(op, ")") {
+		return op[:i], op[i+1 : len(op)-1]
+	}
+	return op, ""
+}
+
+// isFilterValue reports whether a value starts with a filter operator, as
+// in gte.18 or not.is.null
+func isFilterValue(value string) bool {
+	op, _, found := strings.Cut(strings.TrimPrefix(value, "not."), ".")
+	if !found {
+		return false
+	}
+	base, _ := splitOperator(op)
+	_, ok := filterMethods[base]
+	return ok
+}
+
+// filter writes the call for a column=op.value filter
+func (b *builder) filter(column, value string) error {
+	negated := false
+	if rest, found := strings.CutPrefix(value, "not."); found {
+		negated, value = true, rest
+	}
+	op, operand, found := strings.Cut(value, ".")
+	if !found {
+		return fmt.Errorf("filter %s=%s has no operator; use column=op.value such as %s=eq.1", column, value, column)
+	}
+	base, modifier := splitOperator(op)
+	method, known := filterMethods[base]
+	if !known {
+		return &UnsupportedError{Feature: fmt.Sprintf("operator %q in filter %s=%s", op, column, value)}
+	}
+	col := jsString(column)
+
+	if negated {
+		b.call("not", col, jsString(op), notValue(base, operand))
+		return nil
+	}
+
+	switch {
+	case (base == "like" || base == "ilike") && (modifier == "all" || modifier == "any"):
+		name := base + map[string]string{"all": "AllOf", "any": "AnyOf"}[modifier]
+		b.call(name, col, "["+strings.Join(listItems(operand), ", ")+"]")
+	case method == "textSearch":
+		var opts [][2]string
+		if typ := textSearchTypes[base]; typ != "" {
+			opts = append(opts, [2]string{"type", jsString(typ)})
+		}
+		if modifier != "" {
+			opts = append(opts, [2]string{"config", jsString(modifier)})
+		}
+		args := []string{col, jsString(operand)}
+		if len(opts) > 0 {
+			args = append(args, jsObject(opts))
+		}
+		b.call("textSearch", args...)
+	case method == "filter", modifier != "":
+		// Operators with no method of their own, and eq(any).{...}
+		b.call("filter", col, jsString(op), jsString(operand))
+	case base == "in":
+		b.call("in", col, "["+strings.Join(listItems(operand), ", ")+"]")
+	case base == "is":
+		switch operand {
+		case "null", "true", "false":
+			b.call("is", col, operand)
+		default:
+			b.call("filter", col, jsString(op), jsString(operand))
+		}
+	case base == "cs" || base == "cd" || base == "ov":
+		b.call(method, col, containerValue(operand))
+	case method != base:
+		// Range operators take the range as a string
+		b.call(method, col, jsString(operand))
+	default:
+		b.call(method, col, jsScalar(operand))
+	}
+	return nil
+}
+
+// notValue renders the value of .not(column, op, value), which supabase-js
+// sends as written: in lists stay a string like '(1,2)'
+func notValue(base, operand string) string {
+	switch {
+	case base == "is" && (operand == "null" || operand == "true" || operand == "false"):
+		return operand
+	case base == "in" || base == "cs" || base == "cd" || base == "ov":
+		return jsString(operand)
+	}
+	return jsScalar(operand)
+}
+
+// containerValue renders the value of contains, containedBy or overlaps:
+// {a,b} arrays as JavaScript arrays, JSON as objects and ranges as strings
+func containerValue(operand string) string {
+	if json.Valid([]byte(operand)) && (strings.HasPrefix(operand, "{") || strings.HasPrefix(operand, "[")) {
+		if js, err := jsonToJS(operand); err == nil {
+			return js
+		}
+	}
+	if strings.HasPrefix(operand, "{") && strings.HasSuffix(operand, "}") {
+		return "[" + strings.Join(listItems(operand), ", ") + "]"
+	}
+	return jsString(operand)
+}
+
+// logicKey splits or, not.and or posts.or into the referenced table, the
+// logical operator and whether it is negated
+func logicKey(key string) (table, logic string, negated, ok bool) {
+	prefix, last := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		prefix, last = key[:i], key[i+1:]
+	}
+	if last != "or" && last != "and" {
+		return "", "", false, false
+	}
+	if rest, found := strings.CutSuffix(prefix, "not"); found && (rest == "" || strings.HasSuffix(rest, ".")) {
+		negated, prefix = true, strings.TrimSuffix(rest, ".")
+	}
+	return prefix, last, negated, true
+}
+
+// logic writes or=(...), and=(...) and their negations as .or(); a single
+// and(...) or not.or(...) condition is what .or() takes for them
+func (b *builder) logic(table, logic string, negated bool, value string) {
+	conditions := strings.TrimSpace(value)
+	if strings.HasPrefix(conditions, "(") && strings.HasSuffix(conditions, ")") {
+		conditions = conditions[1 : len(conditions)-1]
+	}
+	if logic != "or" || negated {
+		conditions = logic + "(" + conditions + ")"
+		if negated {
+			conditions = "not." + conditions
+		}
+	}
+
+	args := []string{jsString(conditions)}
+	if table != "" {
+		args = append(args, jsObject([][2]string{{"referencedTable", jsString(table)}}))
+	}
+	b.call("or", args...)
+}
+
+// orders writes one .order() per column of an order=a.desc,b parameter
+func (b *builder) orders(value, table string) error {
+	for _, item := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ".")
+		if parts[0] == "" {
+			return fmt.Errorf("invalid order %q: missing column", value)
+		}
+
+		var opts [][2]string
+		for _, modifier := range parts[1:] {
+			switch modifier {
+			case "asc":
+			case "desc":
+				opts = append(opts, [2]string{"ascending", "false"})
+			case "nullsfirst":
+				opts = append(opts, [2]string{"nullsFirst", "true"})
+			case "nullslast":
+				opts = append(opts, [2]string{"nullsFirst", "false"})
+			default:
+				return fmt.Errorf("invalid order modifier %q in %q (expected asc, desc, nullsfirst or nullslast)", modifier, item)
+			}
+		}
+		if table != "" {
+			opts = append(opts, [2]string{"referencedTable", jsString(table)})
+		}
+
+		args := []string{jsString(parts[0])}
+		if len(opts) > 0 {
+			args = append(args, jsObject(opts))
+		}
+		b.call("order", args...)
+	}
+	return nil
+}
+
+// cutEmbedModifier splits posts.comments.limit into posts.comments and limit
+func cutEmbedModifier(key string) (string, string, bool) {
+	i := strings.LastIndex(key, ".")
+	if i <= 0 {
+		return "", "", false
+	}
+	switch modifier := key[i+1:]; modifier {
+	case "order", "limit", "offset":
+		return key[:i], modifier, true
+	}
+	return "", "", false
+}
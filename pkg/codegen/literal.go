@@ -0,0 +1,155 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// identifierPattern matches object keys that need no quotes
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+	// numberPattern matches query values written as JavaScript numbers;
+	// leading zeros such as 007 stay strings
+	numberPattern = regexp.MustCompile(`^-?(?:0|[1-9]\d*)(?:\.\d+)?$`)
+)
+
+// jsString quotes s as a single-quoted JavaScript string
+func jsString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`)
+	return "'" + replacer.Replace(s) + "'"
+}
+
+// jsKey renders an object key, quoting it only when it is not an identifier
+func jsKey(key string) string {
+	if identifierPattern.MatchString(key) {
+		return key
+	}
+	return jsString(key)
+}
+
+// jsScalar renders a query string value: numbers, true, false and null
+// as literals and anything else as a string
+func jsScalar(value string) string {
+	switch {
+	case numberPattern.MatchString(value), value == "true", value == "false", value == "null":
+		return value
+	}
+	return jsString(value)
+}
+
+// jsObject renders ordered key/value pairs as {key: value, ...}
+func jsObject(entries [][2]string) string {
+	parts := make([]string, len(entries))
+	for i, entry := range entries {
+		parts[i] = jsKey(entry[0]) + ": " + entry[1]
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// jsonToJS rewrites a JSON document as a JavaScript literal, keeping the
+// key order of the original: {"name":"Ann"} becomes {name: 'Ann'}
+func jsonToJS(raw string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+
+	var b strings.Builder
+	if err := writeJS(dec, &b); err != nil {
+		return "", err
+	}
+	if dec.More() {
+		return "", fmt.Errorf("unexpected data after the JSON value")
+	}
+	return b.String(), nil
+}
+
+// writeJS writes the next JSON value of dec as JavaScript
+func writeJS(dec *json.Decoder, b *strings.Builder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		closing := "]"
+		if t == '{' {
+			closing = "}"
+		}
+		b.WriteString(string(t))
+		for first := true; dec.More(); first = false {
+			if !first {
+				b.WriteString(", ")
+			}
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				b.WriteString(jsKey(fmt.Sprint(key)) + ": ")
+			}
+			if err := writeJS(dec, b); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		b.WriteString(closing)
+	case string:
+		b.WriteString(jsString(t))
+	case json.Number:
+		b.WriteString(t.String())
+	case bool:
+		b.WriteString(fmt.Sprint(t))
+	case nil:
+		b.WriteString("null")
+	}
+	return nil
+}
+
+// listItems splits a PostgREST list such as (1,2,"a,b") or {a,b} into
+// JavaScript literals, unquoting double-quoted items
+func listItems(list string) []string {
+	list = strings.TrimSpace(list)
+	if len(list) >= 2 {
+		list = list[1 : len(list)-1]
+	}
+	if strings.TrimSpace(list) == "" {
+		return []string{}
+	}
+
+	var items []string
+	var current strings.Builder
+	quoted, inQuote := false, false
+	flush := func() {
+		item := current.String()
+		if quoted {
+			items = append(items, jsString(item))
+		} else {
+			items = append(items, jsScalar(strings.TrimSpace(item)))
+		}
+		current.Reset()
+		quoted = false
+	}
+
+	for i := 0; i < len(list); i++ {
+		ch := list[i]
+		switch {
+		case inQuote && ch == '\\' && i+1 < len(list):
+			i++
+			current.WriteByte(list[i])
+		case ch == '"':
+			inQuote = !inQuote
+			quoted = true
+		case ch == ',' && !inQuote:
+			flush()
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	flush()
+	return items
+}
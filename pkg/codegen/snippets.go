@@ -0,0 +1,281 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HTTPRequest is a request to render as a plain HTTP client call: the full
+// URL with the headers and body to send
+type HTTPRequest struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
+}
+
+// Fetch renders a request as a fetch() call, with the headers and JSON
+// body written as JavaScript literals
+func Fetch(req HTTPRequest) string {
+	method := requestMethod(req)
+
+	var opts []string
+	if method != "GET" {
+		opts = append(opts, "method: "+jsString(method))
+	}
+	if len(req.Headers) > 0 {
+		opts = append(opts, "headers: "+jsHeaders(req.Headers, "  "))
+	}
+	if req.Body != "" {
+		body := jsString(req.Body)
+		if literal, ok := bodyLiteral(req.Body); ok {
+			body = "JSON.stringify(" + literal + ")"
+		}
+		opts = append(opts, "body: "+body)
+	}
+
+	call := "fetch(" + jsString(req.URL)
+	if len(opts) > 0 {
+		call += ", {\n  " + strings.Join(opts, ",\n  ") + ",\n}"
+	}
+	code := "const response = await " + call + ")"
+
+	switch {
+	case !returnsBody(req):
+	case strings.HasPrefix(headerValue(req.Headers, "Accept"), "text/"):
+		code += "\nconst data = await response.text()"
+	default:
+		code += "\nconst data = await response.json()"
+	}
+	return code
+}
+
+// Axios renders a request as an axios() call. axios serializes the data
+// object to JSON itself.
+func Axios(req HTTPRequest) string {
+	method := requestMethod(req)
+
+	opts := []string{
+		"method: " + jsString(strings.ToLower(method)),
+		"url: " + jsString(req.URL),
+	}
+	if len(req.Headers) > 0 {
+		opts = append(opts, "headers: "+jsHeaders(req.Headers, "  "))
+	}
+	if req.Body != "" {
+		data, ok := bodyLiteral(req.Body)
+		if !ok {
+			data = jsString(req.Body)
+		}
+		opts = append(opts, "data: "+data)
+	}
+
+	result := "{ data }"
+	if !returnsBody(req) {
+		result = "{ headers }"
+	}
+	return "const " + result + " = await axios({\n  " + strings.Join(opts, ",\n  ") + ",\n})"
+}
+
+// GoNetHTTP renders a request as Go code using net/http
+func GoNetHTTP(req HTTPRequest) string {
+	var b strings.Builder
+	body := "nil"
+	if req.Body != "" {
+		b.WriteString("body := strings.NewReader(" + goString(req.Body) + ")\n")
+		body = "body"
+	}
+	b.WriteString("req, err := http.NewRequest(" + strconv.Quote(requestMethod(req)) + ", " + strconv.Quote(req.URL) + ", " + body + ")\n")
+	b.WriteString("if err != nil {\n\treturn err\n}\n")
+	for _, name := range headerNames(req.Headers) {
+		b.WriteString("req.Header.Set(" + strconv.Quote(name) + ", " + strconv.Quote(req.Headers[name]) + ")\n")
+	}
+
+	b.WriteString("\nresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("if err != nil {\n\treturn err\n}\n")
+	b.WriteString("defer resp.Body.Close()")
+	if returnsBody(req) {
+		b.WriteString("\n\ndata, err := io.ReadAll(resp.Body)\n")
+		b.WriteString("if err != nil {\n\treturn err\n}")
+	}
+	return b.String()
+}
+
+// PythonRequests renders a request as a call with the Python requests
+// library, passing a JSON body as a dict through json=
+func PythonRequests(req HTTPRequest) string {
+	args := []string{pyString(req.URL)}
+	if len(req.Headers) > 0 {
+		var headers strings.Builder
+		headers.WriteString("headers={\n")
+		for _, name := range headerNames(req.Headers) {
+			headers.WriteString("        " + pyString(name) + ": " + pyString(req.Headers[name]) + ",\n")
+		}
+		headers.WriteString("    }")
+		args = append(args, headers.String())
+	}
+	if req.Body != "" {
+		if literal, err := jsonToPython(req.Body); err == nil {
+			args = append(args, "json="+literal)
+		} else {
+			args = append(args, "data="+pyString(req.Body))
+		}
+	}
+
+	code := "import requests\n\nresponse = requests." + strings.ToLower(requestMethod(req)) + "(\n    " + strings.Join(args, ",\n    ") + ",\n)\nresponse.raise_for_status()"
+	switch {
+	case !returnsBody(req):
+	case strings.HasPrefix(headerValue(req.Headers, "Accept"), "text/"):
+		code += "\ndata = response.text"
+	default:
+		code += "\ndata = response.json()"
+	}
+	return code
+}
+
+// requestMethod returns the upper-case method, GET by default
+func requestMethod(req HTTPRequest) string {
+	if method := strings.ToUpper(strings.TrimSpace(req.Method)); method != "" {
+		return method
+	}
+	return "GET"
+}
+
+// returnsBody reports whether PostgREST answers the request with a body:
+// reads and function calls do, mutations only with return=representation
+func returnsBody(req HTTPRequest) bool {
+	switch requestMethod(req) {
+	case "HEAD":
+		return false
+	case "GET":
+		return true
+	}
+	if parsed, err := url.Parse(req.URL); err == nil && strings.Contains(parsed.Path, "/rpc/") {
+		return true
+	}
+	return strings.Contains(headerValue(req.Headers, "Prefer"), "return=representation")
+}
+
+// bodyLiteral renders a JSON body as a JavaScript literal
+func bodyLiteral(body string) (string, bool) {
+	if !json.Valid([]byte(body)) {
+		return "", false
+	}
+	literal, err := jsonToJS(body)
+	return literal, err == nil
+}
+
+// jsHeaders renders headers as a multi-line object literal, sorted by name
+func jsHeaders(headers map[string]string, indent string) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range headerNames(headers) {
+		b.WriteString(indent + "  " + jsKey(name) + ": " + jsString(headers[name]) + ",\n")
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// headerValue looks a header up by name, ignoring case
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// headerNames returns the header names in sorted order
+func headerNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// goString quotes s as a Go raw string literal when it has no backquote,
+// and as an interpreted string otherwise
+func goString(s string) string {
+	if !strings.Contains(s, "`") && !strings.Contains(s, "\r") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// pyString quotes s as a double-quoted Python string; JSON string escapes
+// are valid in Python
+func pyString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// jsonToPython rewrites a JSON document as a Python literal, keeping the
+// key order: {"ok":true,"note":null} becomes {"ok": True, "note": None}
+func jsonToPython(raw string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+
+	var b strings.Builder
+	if err := writePython(dec, &b); err != nil {
+		return "", err
+	}
+	if dec.More() {
+		return "", fmt.Errorf("unexpected data after the JSON value")
+	}
+	return b.String(), nil
+}
+
+// writePython writes the next JSON value of dec as Python
+func writePython(dec *json.Decoder, b *strings.Builder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		closing := "]"
+		if t == '{' {
+			closing = "}"
+		}
+		b.WriteString(string(t))
+		for first := true; dec.More(); first = false {
+			if !first {
+				b.WriteString(", ")
+			}
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				b.WriteString(pyString(fmt.Sprint(key)) + ": ")
+			}
+			if err := writePython(dec, b); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		b.WriteString(closing)
+	case string:
+		b.WriteString(pyString(t))
+	case json.Number:
+		b.WriteString(t.String())
+	case bool:
+		b.WriteString(map[bool]string{true: "True", false: "False"}[t])
+	case nil:
+		b.WriteString("None")
+	}
+	return nil
+}
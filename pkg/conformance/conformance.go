@@ -0,0 +1,368 @@
+// Package conformance checks conversions against a live PostgREST. Each
+// golden case runs the original SQL directly against Postgres, then runs the
+// converted request through PostgREST (and the reverse-converted SQL back
+// through Postgres) and compares the rows.
+package conformance
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"sql2postgrest/pkg/convert"
+	"sql2postgrest/pkg/reverse"
+)
+
+// Converter names used in check results
+const (
+	SQLToPostgREST      = "sql2postgrest"
+	PostgRESTToSQL      = "postgrest2sql"
+	SupabaseToPostgREST = "supabase2postgrest"
+)
+
+//go:embed testdata/cases.json
+var goldenCases []byte
+
+// Case is a golden conformance case. SQL is the reference query; Supabase is
+// an optional equivalent supabase-js chain.
+type Case struct {
+	Name     string `json:"name"`
+	Feature  string `json:"feature"`
+	SQL      string `json:"sql"`
+	Supabase string `json:"supabase,omitempty"`
+	Ordered  bool   `json:"ordered,omitempty"` // Compare rows in order (the SQL has ORDER BY)
+}
+
+// Cases returns the built-in golden cases
+func Cases() ([]Case, error) {
+	return parseCases(goldenCases)
+}
+
+// LoadCases reads golden cases from a JSON file
+func LoadCases(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCases(data)
+}
+
+func parseCases(data []byte) ([]Case, error) {
+	var cases []Case
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("invalid conformance cases: %w", err)
+	}
+	for i, c := range cases {
+		if c.Name == "" || c.Feature == "" || c.SQL == "" {
+			return nil, fmt.Errorf("conformance case %d: name, feature and sql are required", i)
+		}
+	}
+	return cases, nil
+}
+
+// Executor runs a SELECT against Postgres and returns its rows as a JSON array
+type Executor interface {
+	Query(sql string) ([]byte, error)
+}
+
+// PSQL executes queries with the psql command-line client
+type PSQL struct {
+	Binary      string // psql executable, defaults to "psql"
+	DatabaseURL string // Connection string passed to psql
+}
+
+// Query wraps sql in json_agg so psql prints the rows as a single JSON array
+func (p *PSQL) Query(sql string) ([]byte, error) {
+	binary := p.Binary
+	if binary == "" {
+		binary = "psql"
+	}
+
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+	query := fmt.Sprintf("SELECT coalesce(json_agg(t), '[]'::json) FROM (%s) t", sql)
+
+	cmd := exec.Command(binary, p.DatabaseURL, "-X", "-A", "-t", "-q", "-v", "ON_ERROR_STOP=1", "-c", query)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("psql: %s", msg)
+		}
+		return nil, fmt.Errorf("psql: %w", err)
+	}
+	return bytes.TrimSpace(out), nil
+}
+
+// Check is the outcome of one converter on one case
+type Check struct {
+	Converter string `json:"converter"`
+	Pass      bool   `json:"pass"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// CaseResult holds every check run for a case
+type CaseResult struct {
+	Case   Case    `json:"case"`
+	Checks []Check `json:"checks"`
+}
+
+// Passed reports whether every check for the case passed
+func (r CaseResult) Passed() bool {
+	for _, check := range r.Checks {
+		if !check.Pass {
+			return false
+		}
+	}
+	return true
+}
+
+// FeatureSummary counts passing and failing checks for a feature
+type FeatureSummary struct {
+	Feature string `json:"feature"`
+	Passed  int    `json:"passed"`
+	Failed  int    `json:"failed"`
+}
+
+// Report is the result of a conformance run
+type Report struct {
+	Results []CaseResult `json:"results"`
+}
+
+// Passed reports whether every case passed
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Features summarizes the checks per feature, in first-seen order
+func (r *Report) Features() []FeatureSummary {
+	var summaries []FeatureSummary
+	index := map[string]int{}
+	for _, result := range r.Results {
+		i, ok := index[result.Case.Feature]
+		if !ok {
+			i = len(summaries)
+			index[result.Case.Feature] = i
+			summaries = append(summaries, FeatureSummary{Feature: result.Case.Feature})
+		}
+		for _, check := range result.Checks {
+			if check.Pass {
+				summaries[i].Passed++
+			} else {
+				summaries[i].Failed++
+			}
+		}
+	}
+	return summaries
+}
+
+// WriteText prints failed checks followed by the per-feature summary
+func (r *Report) WriteText(w io.Writer) {
+	for _, result := range r.Results {
+		for _, check := range result.Checks {
+			if !check.Pass {
+				fmt.Fprintf(w, "FAIL %s / %s [%s]: %s\n", result.Case.Feature, result.Case.Name, check.Converter, check.Detail)
+			}
+		}
+	}
+
+	total, failed := 0, 0
+	for _, f := range r.Features() {
+		status := "PASS"
+		if f.Failed > 0 {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%-4s %-20s %d/%d\n", status, f.Feature, f.Passed, f.Passed+f.Failed)
+		total += f.Passed + f.Failed
+		failed += f.Failed
+	}
+	fmt.Fprintf(w, "%d/%d checks passed\n", total-failed, total)
+}
+
+// Runner executes golden cases against PostgREST and Postgres
+type Runner struct {
+	BaseURL string
+	DB      Executor
+	Client  *http.Client
+}
+
+// NewRunner creates a runner for the PostgREST instance at baseURL
+func NewRunner(baseURL string, db Executor) *Runner {
+	return &Runner{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		DB:      db,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Run executes every case. Failures are recorded in the report rather than
+// returned, so one broken case does not hide the others.
+func (r *Runner) Run(cases []Case) *Report {
+	report := &Report{}
+	for _, c := range cases {
+		report.Results = append(report.Results, r.runCase(c))
+	}
+	return report
+}
+
+func (r *Runner) runCase(c Case) CaseResult {
+	result := CaseResult{Case: c}
+
+	expected, err := r.DB.Query(c.SQL)
+	if err != nil {
+		result.Checks = append(result.Checks, Check{
+			Converter: SQLToPostgREST,
+			Detail:    "original SQL failed: " + err.Error(),
+		})
+		return result
+	}
+
+	conv := convert.NewConverter(r.BaseURL)
+
+	converted, err := conv.Convert(c.SQL, convert.TargetPostgREST)
+	if err != nil {
+		result.Checks = append(result.Checks, Check{Converter: SQLToPostgREST, Detail: err.Error()})
+	} else {
+		result.Checks = append(result.Checks, r.checkRequest(SQLToPostgREST, converted.Request, expected, c.Ordered))
+		result.Checks = append(result.Checks, r.checkReverse(converted.Request, expected, c.Ordered))
+	}
+
+	if c.Supabase != "" {
+		converted, err := conv.Convert(c.Supabase, convert.TargetPostgREST)
+		if err != nil {
+			result.Checks = append(result.Checks, Check{Converter: SupabaseToPostgREST, Detail: err.Error()})
+		} else {
+			result.Checks = append(result.Checks, r.checkRequest(SupabaseToPostgREST, converted.Request, expected, c.Ordered))
+		}
+	}
+
+	return result
+}
+
+// checkRequest sends the converted request to PostgREST and compares rows
+func (r *Runner) checkRequest(name string, req *convert.Request, expected []byte, ordered bool) Check {
+	check := Check{Converter: name}
+
+	actual, err := r.fetch(req)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	check.Detail, check.Pass = compareRows(expected, actual, ordered)
+	return check
+}
+
+// checkReverse converts the PostgREST request back to SQL, runs it and
+// compares rows, closing the loop on the reverse converter
+func (r *Runner) checkReverse(req *convert.Request, expected []byte, ordered bool) Check {
+	check := Check{Converter: PostgRESTToSQL}
+
+	sqlResult, err := reverse.NewConverter().ConvertWithHeaders(req.Method, req.Path, req.Query, req.Body, req.Headers)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	actual, err := r.DB.Query(sqlResult.SQL)
+	if err != nil {
+		check.Detail = fmt.Sprintf("%s: %v", sqlResult.SQL, err)
+		return check
+	}
+
+	check.Detail, check.Pass = compareRows(expected, actual, ordered)
+	return check
+}
+
+// fetch performs a read-only request and returns the response body
+func (r *Runner) fetch(req *convert.Request) ([]byte, error) {
+	if req.Method != http.MethodGet {
+		return nil, fmt.Errorf("only read-only cases are supported, got %s", req.Method)
+	}
+
+	httpReq, err := http.NewRequest(req.Method, req.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	for key, value := range req.Headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := r.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: HTTP %d: %s", req.Method, req.URL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// compareRows compares two JSON arrays of rows. Rows are compared in their
+// canonical JSON form; unless ordered, both sides are sorted first.
+func compareRows(expected, actual []byte, ordered bool) (string, bool) {
+	want, err := canonicalRows(expected)
+	if err != nil {
+		return "invalid SQL result: " + err.Error(), false
+	}
+	got, err := canonicalRows(actual)
+	if err != nil {
+		return "invalid PostgREST response: " + err.Error(), false
+	}
+
+	if !ordered {
+		sort.Strings(want)
+		sort.Strings(got)
+	}
+
+	if len(want) != len(got) {
+		return fmt.Sprintf("expected %d rows, got %d", len(want), len(got)), false
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			return fmt.Sprintf("row %d: expected %s, got %s", i, want[i], got[i]), false
+		}
+	}
+	return "", true
+}
+
+// canonicalRows re-encodes each row so key order and spacing don't matter
+func canonicalRows(data []byte) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var rows []interface{}
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, err
+	}
+
+	canonical := make([]string, len(rows))
+	for i, row := range rows {
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+		canonical[i] = string(encoded)
+	}
+	return canonical, nil
+}
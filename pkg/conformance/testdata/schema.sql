@@ -0,0 +1,42 @@
+-- Seed schema for the conformance suite. Loaded by the Postgres container on
+-- first start; the golden cases in cases.json query these rows.
+
+CREATE ROLE web_anon NOLOGIN;
+CREATE ROLE authenticator LOGIN NOINHERIT PASSWORD 'authenticator';
+GRANT web_anon TO authenticator;
+
+CREATE TABLE users (
+    id         integer PRIMARY KEY,
+    name       text NOT NULL,
+    email      text,
+    age        integer,
+    status     text NOT NULL,
+    created_at timestamptz NOT NULL,
+    tags       text[] NOT NULL DEFAULT '{}',
+    metadata   jsonb NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE posts (
+    id        integer PRIMARY KEY,
+    user_id   integer NOT NULL REFERENCES users (id),
+    title     text NOT NULL,
+    published boolean NOT NULL,
+    views     integer NOT NULL
+);
+
+INSERT INTO users (id, name, email, age, status, created_at, tags, metadata) VALUES
+    (1, 'Alice', 'alice@example.com', 34, 'active', '2024-01-15 09:30:00+00', '{admin,beta}', '{"plan": "pro", "seats": 5}'),
+    (2, 'Bob', 'bob@example.org', 17, 'active', '2024-02-01 12:00:00+00', '{}', '{"plan": "free"}'),
+    (3, 'Carol', NULL, 28, 'banned', '2024-03-10 18:45:00+00', '{beta}', '{"plan": "pro", "seats": 2}'),
+    (4, 'Dave', 'dave@example.com', NULL, 'pending', '2024-04-22 07:15:00+00', '{}', '{}'),
+    (5, 'Anna', 'anna@example.com', 22, 'active', '2024-05-05 14:00:00+00', '{admin}', '{"plan": "team"}');
+
+INSERT INTO posts (id, user_id, title, published, views) VALUES
+    (1, 1, 'Getting started with Postgres', true, 120),
+    (2, 1, 'Indexing strategies', true, 85),
+    (3, 2, 'My first post', false, 0),
+    (4, 3, 'Postgres full text search', true, 230),
+    (5, 5, 'Draft notes', false, 3);
+
+GRANT USAGE ON SCHEMA public TO web_anon;
+GRANT SELECT ON ALL TABLES IN SCHEMA public TO web_anon;
@@ -0,0 +1,301 @@
+package convert
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"sql2postgrest/pkg/codegen"
+	"sql2postgrest/pkg/converter"
+	"sql2postgrest/pkg/reverse"
+	"sql2postgrest/pkg/supabase"
+)
+
+// Target selects the representation a conversion should produce
+type Target string
+
+const (
+	TargetDefault   Target = ""
+	TargetSQL       Target = "sql"
+	TargetPostgREST Target = "postgrest"
+	TargetSupabase  Target = "supabase"
+	TargetCurl      Target = "curl"
+)
+
+// Targets lists the targets Convert accepts besides TargetDefault
+var Targets = []Target{TargetSQL, TargetPostgREST, TargetSupabase, TargetCurl}
+
+// Request is a PostgREST HTTP request produced or parsed by the facade
+type Request struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Path    string            `json:"-"`
+	Query   string            `json:"-"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// Result holds every form produced while converting an input
+type Result struct {
+	From     Kind     `json:"from"`
+	To       Target   `json:"to,omitempty"`
+	SQL      string   `json:"sql,omitempty"`
+	Request  *Request `json:"request,omitempty"`
+	Supabase string   `json:"supabase,omitempty"`
+	Curl     string   `json:"curl,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Converter converts between SQL, PostgREST requests and Supabase JS queries
+type Converter struct {
+	BaseURL string
+}
+
+// NewConverter creates a new facade converter
+func NewConverter(baseURL string) *Converter {
+	if baseURL == "" {
+		baseURL = "http://localhost:3000"
+	}
+	return &Converter{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Convert detects the input notation and converts it to the requested target.
+// With TargetDefault, every form reachable from the input is filled in.
+func (c *Converter) Convert(input string, to Target) (*Result, error) {
+	input = strings.TrimSpace(input)
+	kind := Detect(input)
+
+	if to != TargetDefault && !slices.Contains(Targets, to) {
+		return nil, NewUsageError(fmt.Sprintf("unknown target %q (expected sql, postgrest, supabase or curl)", to))
+	}
+
+	result := &Result{From: kind, To: to}
+
+	var err error
+	switch kind {
+	case KindSQL:
+		err = c.fromSQL(input, result)
+	case KindPostgREST:
+		err = c.fromPostgREST(input, result)
+	case KindSupabase:
+		err = c.fromSupabase(input, result)
+	default:
+		return nil, &Error{
+			Code:    "ERR_SYNTAX_UNKNOWN_INPUT",
+			Type:    "syntax",
+			Message: "could not detect input type (expected SQL, a PostgREST URL or a Supabase query)",
+			Input:   input,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Converter) fromSQL(input string, result *Result) error {
+	if result.To == TargetSQL {
+		result.SQL = input
+		return nil
+	}
+
+	conv := converter.NewConverter(c.BaseURL)
+	converted, err := conv.Convert(input)
+	if err != nil {
+		return fmt.Errorf("failed to convert SQL: %w", err)
+	}
+	result.Warnings = append(result.Warnings, converted.Warnings...)
+	if converted.NoOp != "" {
+		// Session statements have no request; the warnings explain why
+		return nil
+	}
+
+	req := &Request{
+		Method:  converted.Method,
+		URL:     conv.URL(converted),
+		Path:    converted.Path,
+		Query:   converted.QueryParams.Encode(),
+		Headers: converted.Headers,
+		Body:    converted.Body,
+	}
+	if result.To == TargetSupabase {
+		return c.toSupabase(req, result)
+	}
+	return c.fillRequest(req, result)
+}
+
+func (c *Converter) fromPostgREST(input string, result *Result) error {
+	req, err := c.parseRequest(input)
+	if err != nil {
+		return err
+	}
+
+	switch result.To {
+	case TargetPostgREST, TargetCurl:
+		return c.fillRequest(req, result)
+	case TargetSupabase:
+		return c.toSupabase(req, result)
+	}
+
+	sqlResult, err := reverse.NewConverter().ConvertWithHeaders(req.Method, req.Path, req.Query, req.Body, req.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to convert PostgREST request: %w", err)
+	}
+	result.SQL = sqlResult.SQL
+	result.Warnings = append(result.Warnings, sqlResult.Warnings...)
+	return nil
+}
+
+// toSupabase fills result.Supabase with the supabase-js code for req
+func (c *Converter) toSupabase(req *Request, result *Result) error {
+	generated, err := codegen.NewGenerator().Generate(codegen.Request{
+		Method: req.Method, Path: req.Path, Query: req.Query, Body: req.Body, Headers: req.Headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate supabase-js code: %w", err)
+	}
+	result.Supabase = generated.Code
+	result.Warnings = append(result.Warnings, generated.Warnings...)
+	return nil
+}
+
+func (c *Converter) fromSupabase(input string, result *Result) error {
+	if result.To == TargetSupabase {
+		result.Supabase = input
+		return nil
+	}
+
+	conv := supabase.NewConverter(c.BaseURL)
+	conv.Dialect = supabase.DetectDialect(input)
+	if result.To == TargetSQL {
+		converted, err := conv.ConvertToSQL(input)
+		if err != nil {
+			return fmt.Errorf("failed to convert Supabase query: %w", err)
+		}
+		result.SQL = converted.SQL
+		result.Warnings = append(result.Warnings, converted.Warnings...)
+		return nil
+	}
+
+	output, err := conv.Convert(input)
+	if err != nil {
+		return fmt.Errorf("failed to convert Supabase query: %w", err)
+	}
+	result.Warnings = append(result.Warnings, output.Warnings...)
+
+	req := &Request{
+		Method:  output.Method,
+		URL:     c.BaseURL + output.Path,
+		Path:    output.Path,
+		Query:   output.Query,
+		Headers: output.Headers,
+		Body:    output.Body,
+	}
+	if output.Query != "" {
+		req.URL += "?" + output.Query
+	}
+
+	if result.To == TargetPostgREST || result.To == TargetCurl {
+		return c.fillRequest(req, result)
+	}
+	result.Request = req
+	if output.IsHTTPOnly {
+		return nil
+	}
+
+	converted, err := conv.ConvertToSQL(input)
+	if err != nil {
+		result.Warnings = append(result.Warnings, "SQL form unavailable: "+err.Error())
+		return nil
+	}
+	// Its warnings start with the Supabase ones already added
+	result.SQL = converted.SQL
+	result.Warnings = append(result.Warnings, converted.Warnings[len(output.Warnings):]...)
+	return nil
+}
+
+// fillRequest stores the request (or its curl form) on the result
+func (c *Converter) fillRequest(req *Request, result *Result) error {
+	if result.To == TargetCurl {
+		result.Curl = CurlCommand(req)
+		return nil
+	}
+	result.Request = req
+	return nil
+}
+
+// parseRequest parses "METHOD /path?query [body]", a full URL or a bare path
+func (c *Converter) parseRequest(input string) (*Request, error) {
+	method := "GET"
+	target := input
+	body := ""
+
+	if requestLinePattern.MatchString(input) {
+		parts := strings.SplitN(input, " ", 2)
+		method = strings.ToUpper(parts[0])
+		target = strings.TrimSpace(parts[1])
+	}
+
+	if idx := strings.IndexAny(target, " \t\n"); idx != -1 {
+		body = strings.TrimSpace(target[idx:])
+		target = target[:idx]
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PostgREST URL: %w", err)
+	}
+
+	path := strings.TrimPrefix(parsed.Path, "/rest/v1")
+	if path == "" {
+		return nil, fmt.Errorf("PostgREST URL is missing a table path")
+	}
+
+	base := c.BaseURL
+	if parsed.Scheme != "" && parsed.Host != "" {
+		base = parsed.Scheme + "://" + parsed.Host + strings.TrimSuffix(parsed.Path, path)
+	}
+
+	req := &Request{
+		Method:  method,
+		URL:     base + path,
+		Path:    path,
+		Query:   parsed.RawQuery,
+		Headers: map[string]string{},
+		Body:    body,
+	}
+	if parsed.RawQuery != "" {
+		req.URL += "?" + parsed.RawQuery
+	}
+	if body != "" {
+		req.Headers["Content-Type"] = "application/json"
+	}
+
+	return req, nil
+}
+
+// CurlCommand renders a request as a copy-pasteable curl command. A body
+// without a Content-Type header is sent as JSON, since curl would otherwise
+// label it as a form.
+func CurlCommand(req *Request) string {
+	return converter.Curl(req.Method, req.URL, requestHeaders(req), req.Body)
+}
+
+// requestHeaders returns the headers to send with req, adding
+// Content-Type: application/json for a body that has none
+func requestHeaders(req *Request) map[string]string {
+	headers := make(map[string]string, len(req.Headers)+1)
+	hasContentType := false
+	for k, v := range req.Headers {
+		headers[k] = v
+		if strings.EqualFold(k, "Content-Type") {
+			hasContentType = true
+		}
+	}
+	if req.Body != "" && !hasContentType {
+		headers["Content-Type"] = "application/json"
+	}
+	return headers
+}
@@ -0,0 +1,462 @@
+package convert
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sql2postgrest/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Kind
+	}{
+		{"select", "SELECT * FROM users", KindSQL},
+		{"lowercase insert", "insert into users (name) values ('a')", KindSQL},
+		{"delete statement", "DELETE FROM users WHERE id = 1", KindSQL},
+		{"request line", "GET /users?age=gte.18", KindPostgREST},
+		{"delete request line", "DELETE /users?id=eq.1", KindPostgREST},
+		{"full url", "https://example.com/rest/v1/users?select=*", KindPostgREST},
+		{"bare path", "/users?age=gte.18", KindPostgREST},
+		{"supabase chain", "supabase.from('users').select('*')", KindSupabase},
+		{"awaited supabase chain", "const { data } = await supabase.from('users').select()", KindSupabase},
+		{"supabase rpc", "supabase.rpc('hello')", KindSupabase},
+		{"supabase-py chain", `supabase.table("users").select("*").execute()`, KindSupabase},
+		{"empty", "   ", KindUnknown},
+		{"garbage", "hello world", KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Detect(tt.input))
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	conv := NewConverter("http://localhost:3000")
+
+	t.Run("sql to postgrest by default", func(t *testing.T) {
+		result, err := conv.Convert("SELECT * FROM users WHERE age >= 18", TargetDefault)
+		require.NoError(t, err)
+		assert.Equal(t, KindSQL, result.From)
+		require.NotNil(t, result.Request)
+		assert.Equal(t, "GET", result.Request.Method)
+		assert.Equal(t, "http://localhost:3000/users?age=gte.18", result.Request.URL)
+	})
+
+	t.Run("postgrest to sql", func(t *testing.T) {
+		result, err := conv.Convert("GET /users?age=gte.18", TargetSQL)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users WHERE age >= 18", result.SQL)
+	})
+
+	t.Run("between round-trips as two filters", func(t *testing.T) {
+		forward, err := conv.Convert("SELECT * FROM users WHERE age BETWEEN 18 AND 65", TargetDefault)
+		require.NoError(t, err)
+		require.NotNil(t, forward.Request)
+
+		result, err := conv.Convert("GET "+forward.Request.URL, TargetSQL)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users WHERE age >= 18 AND age <= 65", result.SQL)
+	})
+
+	t.Run("sql to supabase", func(t *testing.T) {
+		result, err := conv.Convert("SELECT id, name FROM users WHERE age >= 18 ORDER BY name DESC LIMIT 10", TargetSupabase)
+		require.NoError(t, err)
+		assert.Equal(t, "supabase.from('users').select('id,name').gte('age', 18).order('name', {ascending: false}).limit(10)", result.Supabase)
+	})
+
+	t.Run("postgrest to supabase", func(t *testing.T) {
+		result, err := conv.Convert("GET /users?age=gte.18&order=name.desc", TargetSupabase)
+		require.NoError(t, err)
+		assert.Equal(t, "supabase.from('users').select('*').gte('age', 18).order('name', {ascending: false})", result.Supabase)
+	})
+
+	t.Run("supabase url prefix is stripped", func(t *testing.T) {
+		result, err := conv.Convert("https://abc.supabase.co/rest/v1/users?id=eq.1", TargetSQL)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users WHERE id = 1", result.SQL)
+	})
+
+	t.Run("request line with body", func(t *testing.T) {
+		result, err := conv.Convert(`PATCH /users?id=eq.1 {"status":"active"}`, TargetSQL)
+		require.NoError(t, err)
+		assert.Equal(t, "UPDATE users SET status = 'active' WHERE id = 1", result.SQL)
+	})
+
+	t.Run("supabase to sql and postgrest by default", func(t *testing.T) {
+		result, err := conv.Convert("supabase.from('users').select('*').eq('id', 1)", TargetDefault)
+		require.NoError(t, err)
+		assert.Equal(t, KindSupabase, result.From)
+		require.NotNil(t, result.Request)
+		assert.Equal(t, "/users", result.Request.Path)
+		assert.Equal(t, "SELECT * FROM users WHERE id = 1", result.SQL)
+	})
+
+	t.Run("supabase to curl", func(t *testing.T) {
+		result, err := conv.Convert("supabase.from('users').insert({name: 'O'})", TargetCurl)
+		require.NoError(t, err)
+		assert.Equal(t, `curl -X POST 'http://localhost:3000/users' -H 'Content-Type: application/json' -d '{"name":"O"}'`, result.Curl)
+	})
+
+	t.Run("supabase upsert keeps its resolution in sql", func(t *testing.T) {
+		result, err := conv.Convert("supabase.from('users').upsert({id: 1})", TargetSQL)
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO users (id) VALUES (1) ON CONFLICT (id) DO NOTHING", result.SQL)
+	})
+
+	t.Run("supabase rpc to sql", func(t *testing.T) {
+		result, err := conv.Convert("supabase.rpc('add_numbers', {a: 5, b: 3})", TargetSQL)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM add_numbers(a := 5, b := 3)", result.SQL)
+	})
+
+	t.Run("supabase-py to sql", func(t *testing.T) {
+		result, err := conv.Convert(`supabase.table("users").select("*").eq("age", 18).execute()`, TargetSQL)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users WHERE age = 18", result.SQL)
+	})
+
+	t.Run("http only supabase op to sql fails", func(t *testing.T) {
+		_, err := conv.Convert("supabase.auth.getUser()", TargetSQL)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown target", func(t *testing.T) {
+		_, err := conv.Convert("SELECT 1 FROM t", Target("xml"))
+		require.Error(t, err)
+	})
+
+	t.Run("undetectable input", func(t *testing.T) {
+		_, err := conv.Convert("hello world", TargetDefault)
+		require.Error(t, err)
+	})
+}
+
+func TestCurlCommand(t *testing.T) {
+	req := &Request{
+		Method:  "POST",
+		URL:     "http://localhost:3000/users",
+		Headers: map[string]string{"Prefer": "return=representation", "Content-Type": "application/json"},
+		Body:    `{"name":"O'Brien"}`,
+	}
+
+	assert.Equal(t,
+		`curl -X POST 'http://localhost:3000/users' -H 'Content-Type: application/json' -H 'Prefer: return=representation' -d '{"name":"O'\''Brien"}'`,
+		CurlCommand(req),
+	)
+
+	t.Run("body without content type", func(t *testing.T) {
+		req := &Request{Method: "PATCH", URL: "http://localhost:3000/users?id=eq.1", Body: `{"active":false}`}
+		assert.Equal(t,
+			`curl -X PATCH 'http://localhost:3000/users?id=eq.1' -H 'Content-Type: application/json' -d '{"active":false}'`,
+			CurlCommand(req),
+		)
+	})
+
+	t.Run("head", func(t *testing.T) {
+		req := &Request{Method: "HEAD", URL: "http://localhost:3000/users", Headers: map[string]string{"Prefer": "count=exact"}}
+		assert.Equal(t,
+			`curl -I 'http://localhost:3000/users' -H 'Prefer: count=exact'`,
+			CurlCommand(req),
+		)
+	})
+}
+
+func TestExecute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/users", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "return=representation", r.Header.Get("Prefer"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "anon", r.Header.Get("apikey"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"name":"Alice"}`, string(body))
+
+		w.Header().Set("Content-Range", "*/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`[{"id":1,"name":"Alice"}]`))
+	}))
+	defer server.Close()
+
+	req := &Request{
+		Method:  "POST",
+		URL:     server.URL + "/users",
+		Headers: map[string]string{"Prefer": "return=representation"},
+		Body:    `{"name":"Alice"}`,
+	}
+	resp, err := Execute(server.Client(), req, map[string]string{"Authorization": "Bearer secret", "apikey": "anon"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "*/1", resp.ContentRange)
+	assert.Equal(t, "HTTP 201 Created\nContent-Range: */1\n\n[{\"id\":1,\"name\":\"Alice\"}]", resp.Format(false))
+	assert.Equal(t, "HTTP 201 Created\nContent-Range: */1\n\n[\n  {\n    \"id\": 1,\n    \"name\": \"Alice\"\n  }\n]", resp.Format(true))
+
+	_, err = Execute(server.Client(), &Request{URL: server.URL}, nil)
+	assert.Error(t, err)
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	input := "SELECT * FROM users\n\n  GET /users?id=eq.1  \nnot a query\n"
+	var out strings.Builder
+	err := StreamNDJSON(strings.NewReader(input), &out, func(line string) (interface{}, error) {
+		if line == "not a query" {
+			return nil, errors.New("unrecognized input")
+		}
+		return map[string]string{"echo": line}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"echo":"SELECT * FROM users"}
+{"echo":"GET /users?id=eq.1"}
+{"input":"not a query","error":"unrecognized input","code":"ERR_SEMANTIC","type":"semantic"}
+`, out.String())
+
+	long := strings.Repeat("x", MaxNDJSONLine+1)
+	err = StreamNDJSON(strings.NewReader(long), io.Discard, func(line string) (interface{}, error) {
+		return line, nil
+	})
+	assert.Error(t, err)
+}
+
+func TestClassify(t *testing.T) {
+	conv := NewConverter("")
+	tests := []struct {
+		name     string
+		input    string
+		code     string
+		typ      string
+		exitCode int
+	}{
+		{name: "SQL syntax", input: "SELECT * FROM users WHERE", code: "ERR_SYNTAX_SQL", typ: "syntax", exitCode: ExitSyntax},
+		{name: "unsupported function", input: "SELECT * FROM users WHERE stddev(age) > 1", code: "ERR_UNSUPPORTED_FUNCTION", typ: "unsupported", exitCode: ExitUnsupported},
+		{name: "unsupported clause", input: "SELECT status FROM users GROUP BY status", code: "ERR_UNSUPPORTED", typ: "unsupported", exitCode: ExitUnsupported},
+		{name: "postgrest unknown operator", input: "GET /users?age=between.1", code: "ERR_UNSUPPORTED_OPERATOR", typ: "unsupported", exitCode: ExitUnsupported},
+		{name: "supabase syntax", input: "supabase.rpc('add', {a: 1}", code: "ERR_SYNTAX_UNBALANCED", typ: "syntax", exitCode: ExitSyntax},
+		{name: "supabase unknown method", input: "supabase.from('users').select('*').frobnicate()", code: "ERR_UNSUPPORTED_METHOD", typ: "unsupported", exitCode: ExitUnsupported},
+		{name: "postgrest semantic", input: "DELETE /users", code: "ERR_SEMANTIC_DELETE_NO_WHERE", typ: "semantic", exitCode: ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := conv.Convert(tt.input, TargetDefault)
+			require.Error(t, err)
+
+			e := Classify(err, tt.input)
+			assert.Equal(t, tt.code, e.Code)
+			assert.Equal(t, tt.typ, e.Type)
+			assert.Equal(t, tt.exitCode, e.ExitCode())
+			assert.Equal(t, err.Error(), e.Message)
+		})
+	}
+
+	e := Classify(&IOError{errors.New("connection refused")}, "")
+	assert.Equal(t, "io", e.Type)
+	assert.Equal(t, ExitIO, e.ExitCode())
+
+	e = Classify(&NoRequestError{Code: "session_set", Reasons: []string{"SET x only affects the database session"}}, "SET x = 1")
+	assert.Equal(t, "ERR_UNSUPPORTED_SESSION_SET", e.Code)
+	assert.Equal(t, ExitUnsupported, e.ExitCode())
+}
+
+func TestWriteError(t *testing.T) {
+	err := error(&converter.UnsupportedError{Feature: "COPY FROM", Hint: "use INSERT"})
+
+	var b strings.Builder
+	assert.Equal(t, ExitUnsupported, WriteError(&b, err, "COPY users FROM STDIN", true))
+	assert.Equal(t, `{"code":"ERR_UNSUPPORTED","type":"unsupported","message":"COPY FROM not supported - use INSERT","hint":"use INSERT","input":"COPY users FROM STDIN"}`+"\n", b.String())
+
+	// Only the error's type counts, not its wording
+	b.Reset()
+	assert.Equal(t, ExitError, WriteError(&b, errors.New("unsupported function: stddev"), "", true))
+	assert.Equal(t, `{"code":"ERR_SEMANTIC","type":"semantic","message":"unsupported function: stddev"}`+"\n", b.String())
+
+	b.Reset()
+	err = &Error{Code: "ERR_SEMANTIC", Type: "semantic", Message: "no table", Hint: "name a table"}
+	assert.Equal(t, ExitError, WriteError(&b, err, "", false))
+	assert.Equal(t, "Error: no table\nHint: name a table\n", b.String())
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+	}{
+		{name: "simple filter", sql: "SELECT * FROM users WHERE age > 18"},
+		{name: "lower-case keywords", sql: "select name, email from users where status = 'active' order by name limit 10"},
+		{name: "between", sql: "SELECT * FROM users WHERE age BETWEEN 18 AND 65"},
+		{name: "not equal spelling", sql: "SELECT * FROM users WHERE status != 'banned'"},
+		{name: "quoted identifiers", sql: `SELECT "firstName" FROM "Users" WHERE "lastName" = 'Smith' ORDER BY "firstName"`},
+		{name: "cast in select", sql: "SELECT name, age::text FROM users"},
+		{name: "or inside and", sql: "SELECT * FROM users WHERE age > 18 AND (status = 'active' OR role = 'admin')"},
+		{name: "and inside or", sql: "SELECT * FROM users WHERE (age > 18 AND status = 'active') OR role = 'admin'"},
+		{name: "nested or", sql: "SELECT * FROM users WHERE a = 1 OR (b = 2 OR c = 3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Verify(tt.sql)
+			require.NoError(t, err)
+			assert.True(t, report.Lossless, "differences: %v", report.Differences)
+			assert.Equal(t, tt.sql, report.SQL)
+			assert.NotEmpty(t, report.Request)
+			assert.NotEmpty(t, report.RoundTrip)
+		})
+	}
+}
+
+func TestVerifyDifferences(t *testing.T) {
+	report, err := Verify("SELECT u.name, p.title FROM users u JOIN posts p ON p.user_id = u.id WHERE u.age > 18")
+	require.NoError(t, err)
+	assert.False(t, report.Lossless)
+	assert.Equal(t, "GET /users?age=gt.18&select=name%2Cposts%28title%29", report.Request)
+	assert.Contains(t, report.Differences, Difference{"changed", "JOIN", "posts: INNER JOIN -> LEFT JOIN"})
+
+	report, err = Verify("DELETE FROM users WHERE id = 1")
+	require.NoError(t, err)
+	assert.False(t, report.Lossless)
+	assert.Equal(t, []Difference{{"added", "RETURNING", "*"}}, report.Differences)
+	assert.Equal(t, "added RETURNING: *", report.Differences[0].String())
+}
+
+func TestVerifyInsertColumnOrder(t *testing.T) {
+	report, err := Verify("INSERT INTO users (name, age) VALUES ('Alice', 30), ('Bob', 25)")
+	require.NoError(t, err)
+	for _, difference := range report.Differences {
+		assert.NotEqual(t, "VALUES", difference.Clause, "unexpected difference: %v", difference)
+	}
+}
+
+func TestVerifyReorderedFilters(t *testing.T) {
+	original, err := parseShape("SELECT * FROM users WHERE age > 18 AND status = 'active'")
+	require.NoError(t, err)
+	roundTrip, err := parseShape("SELECT * FROM users WHERE status = 'active' AND age > 18")
+	require.NoError(t, err)
+	assert.Equal(t, []Difference{{"reordered", "WHERE", "age > 18, status = 'active' -> status = 'active', age > 18"}}, compareShapes(original, roundTrip))
+}
+
+func TestCompareShapes(t *testing.T) {
+	tests := []struct {
+		name      string
+		original  string
+		roundTrip string
+		want      []Difference
+	}{
+		{
+			name:      "keyword case and spacing",
+			original:  "select name from users where age>18 order by name",
+			roundTrip: "SELECT name FROM users WHERE age > 18 ORDER BY name ASC",
+		},
+		{
+			name:      "cast spellings",
+			original:  "SELECT age::text FROM users WHERE age::int > 18",
+			roundTrip: "SELECT CAST(age AS text) FROM users WHERE CAST(age AS int) > 18",
+		},
+		{
+			name:      "quoted identifiers keep their case",
+			original:  `SELECT * FROM "Users" WHERE "Age" > 18`,
+			roundTrip: `SELECT * FROM users WHERE age > 18`,
+			want: []Difference{
+				{"changed", "FROM", "Users -> users"},
+				{"lost", "WHERE", `"Age" > 18`},
+				{"added", "WHERE", "age > 18"},
+			},
+		},
+		{
+			name:      "quoted identifier with a dot",
+			original:  `SELECT * FROM users WHERE "a.b" = 1`,
+			roundTrip: `SELECT * FROM users WHERE a.b = 1`,
+			want: []Difference{
+				{"lost", "WHERE", `"a.b" = 1`},
+				{"added", "WHERE", "a.b = 1"},
+			},
+		},
+		{
+			name:      "aliases and qualifiers",
+			original:  "SELECT u.name FROM users u JOIN posts p ON p.user_id = u.id WHERE u.age > 18",
+			roundTrip: "SELECT users.name FROM users INNER JOIN posts ON posts.user_id = users.id WHERE age > 18",
+		},
+		{
+			name:      "nested ands",
+			original:  "SELECT * FROM users WHERE a = 1 AND (b = 2 AND c = 3)",
+			roundTrip: "SELECT * FROM users WHERE a = 1 AND b = 2 AND c = 3",
+		},
+		{
+			name:      "nested ors",
+			original:  "SELECT * FROM users WHERE a = 1 OR (b = 2 OR c = 3)",
+			roundTrip: "SELECT * FROM users WHERE ((a = 1 OR b = 2) OR c = 3)",
+		},
+		{
+			name:      "or and and do not regroup",
+			original:  "SELECT * FROM users WHERE a = 1 OR (b = 2 AND c = 3)",
+			roundTrip: "SELECT * FROM users WHERE (a = 1 OR b = 2) AND c = 3",
+			want: []Difference{
+				{"lost", "WHERE", "a = 1 OR (b = 2 AND c = 3)"},
+				{"added", "WHERE", "a = 1 OR b = 2"},
+				{"added", "WHERE", "c = 3"},
+			},
+		},
+		{
+			name:      "not keeps its parentheses",
+			original:  "SELECT * FROM users WHERE NOT (a = 1 OR b = 2)",
+			roundTrip: "SELECT * FROM users WHERE NOT a = 1 OR b = 2",
+			want: []Difference{
+				{"lost", "WHERE", "NOT (a = 1 OR b = 2)"},
+				{"added", "WHERE", "(NOT a = 1) OR b = 2"},
+			},
+		},
+		{
+			name:      "between",
+			original:  "SELECT * FROM users WHERE age BETWEEN 18 AND 65",
+			roundTrip: "SELECT * FROM users WHERE age >= 18 AND age <= 65",
+		},
+		{
+			name:      "limited delete",
+			original:  "DELETE FROM users WHERE id > 1 ORDER BY id LIMIT 5",
+			roundTrip: "DELETE FROM users WHERE id > 1 ORDER BY id ASC LIMIT 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, err := parseShape(tt.original)
+			require.NoError(t, err)
+			roundTrip, err := parseShape(tt.roundTrip)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, compareShapes(original, roundTrip))
+		})
+	}
+}
+
+func TestRoundTripReportWriteText(t *testing.T) {
+	report, err := Verify("DELETE FROM users WHERE id = 1")
+	require.NoError(t, err)
+
+	var b strings.Builder
+	report.WriteText(&b)
+	assert.Equal(t, `SQL:        DELETE FROM users WHERE id = 1
+PostgREST:  DELETE /users?id=eq.1
+Round trip: `+report.RoundTrip+`
+added RETURNING: *
+LOSSY: 1 clause(s) did not survive the round trip
+`, b.String())
+}
+
+func TestVerifyUnsupported(t *testing.T) {
+	_, err := Verify("SELECT FROM")
+	require.Error(t, err)
+
+	_, err = Verify("SET search_path = app")
+	var noRequest *NoRequestError
+	require.ErrorAs(t, err, &noRequest)
+}
@@ -0,0 +1,46 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kind identifies the notation of a conversion input
+type Kind string
+
+const (
+	KindUnknown   Kind = "unknown"
+	KindSQL       Kind = "sql"
+	KindPostgREST Kind = "postgrest"
+	KindSupabase  Kind = "supabase"
+)
+
+var (
+	supabasePattern    = regexp.MustCompile(`(?:supabase|client)\s*\.\s*(?:from|table|rpc|auth|storage|schema)\b`)
+	requestLinePattern = regexp.MustCompile(`^(?i:GET|POST|PATCH|PUT|DELETE|HEAD)\s+(?:/|https?://)`)
+	sqlPattern         = regexp.MustCompile(`^(?i:SELECT|INSERT|UPDATE|DELETE|WITH|VALUES|TABLE|SET|SHOW|RESET|GRANT|REVOKE|COPY)\b`)
+)
+
+// Detect sniffs the input and reports whether it looks like SQL, a PostgREST
+// request (URL, path or "METHOD /path" line) or a Supabase JS or Python
+// query chain
+func Detect(input string) Kind {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return KindUnknown
+	}
+
+	switch {
+	case supabasePattern.MatchString(input):
+		return KindSupabase
+	case requestLinePattern.MatchString(input),
+		strings.HasPrefix(input, "http://"),
+		strings.HasPrefix(input, "https://"),
+		strings.HasPrefix(input, "/"):
+		return KindPostgREST
+	case sqlPattern.MatchString(input):
+		return KindSQL
+	default:
+		return KindUnknown
+	}
+}
@@ -0,0 +1,162 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"strings"
+
+	"sql2postgrest/pkg/codegen"
+	"sql2postgrest/pkg/converter"
+	"sql2postgrest/pkg/reverse"
+	"sql2postgrest/pkg/supabase"
+)
+
+// Exit codes of the command-line tools, so scripts can branch on the
+// class of a failure
+const (
+	ExitError       = 1 // A conversion failed for another reason
+	ExitUsage       = 2 // Invalid flags or no input, as the flag package exits
+	ExitSyntax      = 3 // The input could not be parsed
+	ExitUnsupported = 4 // The input uses a feature with no equivalent
+	ExitIO          = 5 // Reading input, writing output or reaching a server failed
+	ExitHTTPError   = 6 // --exec got an HTTP error response (status 300 or above)
+	ExitLossy       = 7 // verify found clauses lost in the round trip
+)
+
+// Error is the machine-readable form of a failure, as printed by
+// --json-errors
+type Error struct {
+	Code    string `json:"code"`           // e.g. ERR_SYNTAX_SQL
+	Type    string `json:"type"`           // "syntax", "semantic", "unsupported", "io" or "usage"
+	Message string `json:"message"`        // Human-readable error message
+	Hint    string `json:"hint,omitempty"` // Suggestion for a fix
+	Input   string `json:"input,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ExitCode returns the exit code for the error's type
+func (e *Error) ExitCode() int {
+	switch e.Type {
+	case "syntax":
+		return ExitSyntax
+	case "unsupported":
+		return ExitUnsupported
+	case "io":
+		return ExitIO
+	case "usage":
+		return ExitUsage
+	}
+	return ExitError
+}
+
+// NewUsageError creates the error for flags that cannot be combined or
+// take an unknown value
+func NewUsageError(message string) *Error {
+	return &Error{Code: "ERR_USAGE", Type: "usage", Message: message}
+}
+
+// IOError marks a failure to read input, write output or reach a server,
+// as opposed to a failed conversion
+type IOError struct {
+	Err error
+}
+
+func (e *IOError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *IOError) Unwrap() error {
+	return e.Err
+}
+
+// NoRequestError reports a statement, such as SET or GRANT, that converts
+// to no PostgREST request
+type NoRequestError struct {
+	Code    converter.NoOpCode
+	Reasons []string // The converter's warnings, which say why
+}
+
+func (e *NoRequestError) Error() string {
+	return "statement has no PostgREST request: " + strings.Join(e.Reasons, "; ")
+}
+
+// Classify builds the Error for err from the structured error types of
+// the converters. Errors with no type of their own are semantic errors.
+func Classify(err error, input string) *Error {
+	var (
+		classified  *Error
+		ioErr       *IOError
+		pathErr     *fs.PathError
+		netErr      net.Error
+		urlErr      *url.Error
+		noRequest   *NoRequestError
+		parseErr    *converter.ParseError
+		unsupported *converter.UnsupportedError
+		codeFeature *codegen.UnsupportedError
+		reverseErr  *reverse.ConversionError
+		supabaseErr *supabase.ConversionError
+	)
+
+	e := &Error{Message: err.Error(), Input: input}
+	switch {
+	case errors.As(err, &classified):
+		copied := *classified
+		if copied.Input == "" {
+			copied.Input = input
+		}
+		return &copied
+	case errors.As(err, &ioErr), errors.As(err, &pathErr), errors.As(err, &netErr), errors.As(err, &urlErr):
+		e.Code, e.Type = "ERR_IO", "io"
+	case errors.As(err, &noRequest):
+		e.Code, e.Type = "ERR_UNSUPPORTED_"+strings.ToUpper(string(noRequest.Code)), "unsupported"
+	case errors.As(err, &parseErr):
+		e.Code, e.Type = "ERR_SYNTAX_SQL", "syntax"
+	case errors.As(err, &unsupported):
+		e.Code, e.Type = "ERR_UNSUPPORTED", "unsupported"
+		if unsupported.Kind != "" {
+			e.Code += "_" + strings.ToUpper(strings.ReplaceAll(unsupported.Kind, " ", "_"))
+		}
+		e.Hint = unsupported.Hint
+		if e.Hint == "" && len(unsupported.Suggestions) > 0 {
+			e.Hint = "did you mean " + strings.Join(unsupported.Suggestions, ", ") + "?"
+		}
+	case errors.As(err, &codeFeature):
+		e.Code, e.Type, e.Hint = "ERR_UNSUPPORTED", "unsupported", codeFeature.Hint
+	case errors.As(err, &reverseErr):
+		e.Code, e.Type, e.Hint = reverseErr.Code, reverseErr.Type, reverseErr.Hint
+		if reverseErr.Input != "" {
+			e.Input = reverseErr.Input
+		}
+	case errors.As(err, &supabaseErr):
+		e.Code, e.Type, e.Hint = supabaseErr.Code, supabaseErr.Type, supabaseErr.Hint
+	default:
+		e.Code, e.Type = "ERR_SEMANTIC", "semantic"
+	}
+	return e
+}
+
+// WriteError writes err to w and returns the exit code for its class: as
+// a JSON Error with jsonErrors set, and otherwise as "Error: message"
+// followed by the hint
+func WriteError(w io.Writer, err error, input string, jsonErrors bool) int {
+	e := Classify(err, input)
+	if jsonErrors {
+		line, _ := json.Marshal(e)
+		fmt.Fprintln(w, string(line))
+		return e.ExitCode()
+	}
+
+	fmt.Fprintf(w, "Error: %s\n", e.Message)
+	if e.Hint != "" && !strings.Contains(e.Message, e.Hint) {
+		fmt.Fprintf(w, "Hint: %s\n", e.Hint)
+	}
+	return e.ExitCode()
+}
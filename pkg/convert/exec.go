@@ -0,0 +1,81 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"sql2postgrest/pkg/converter"
+)
+
+// Response is what the server answered to an executed Request
+type Response struct {
+	Status       string // Status line, e.g. "200 OK"
+	StatusCode   int
+	ContentRange string // Content-Range header, set for reads and counts
+	Body         []byte
+}
+
+// Execute sends req with client and reads the whole response. The extra
+// headers, such as Authorization or a Supabase apikey, are added to the
+// request's own; a body without a Content-Type is sent as JSON, as with
+// CurlCommand. A non-2xx status is not an error: the response carries
+// PostgREST's error body. Network failures are returned as an *IOError.
+func Execute(client *http.Client, req *Request, extra map[string]string) (*Response, error) {
+	if req.Method == "" {
+		return nil, fmt.Errorf("request has no method")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	httpReq, err := converter.NewHTTPRequest(context.Background(), req.Method, req.URL, requestHeaders(req), req.Body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, &IOError{err}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &IOError{fmt.Errorf("reading response: %w", err)}
+	}
+	return &Response{
+		Status:       resp.Status,
+		StatusCode:   resp.StatusCode,
+		ContentRange: resp.Header.Get("Content-Range"),
+		Body:         data,
+	}, nil
+}
+
+// Format renders the response for a terminal: the status line, the
+// Content-Range when there is one, and the body after a blank line. With
+// pretty set a JSON body is indented.
+func (r *Response) Format(pretty bool) string {
+	var b strings.Builder
+	b.WriteString("HTTP " + r.Status + "\n")
+	if r.ContentRange != "" {
+		b.WriteString("Content-Range: " + r.ContentRange + "\n")
+	}
+
+	body := r.Body
+	var indented bytes.Buffer
+	if pretty && json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	if len(body) > 0 {
+		b.WriteString("\n" + strings.TrimRight(string(body), "\n"))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,63 @@
+package convert
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxNDJSONLine is the longest input line StreamNDJSON accepts
+const MaxNDJSONLine = 16 << 20
+
+// ndjsonError is the line written for an input that failed to convert:
+// the input, the message and its classification
+type ndjsonError struct {
+	Input string `json:"input"`
+	Error string `json:"error"`
+	Code  string `json:"code"`
+	Type  string `json:"type"`
+	Hint  string `json:"hint,omitempty"`
+}
+
+// StreamNDJSON reads one input per line from r and writes one JSON value
+// per line to w: whatever convert returns for the line, or
+// {"input", "error", "code", "type", "hint"} when it fails. Lines are read and written one at a
+// time, so memory stays bounded by the longest line, and blank lines are
+// skipped. A failed conversion does not stop the stream; only reading,
+// writing and lines over MaxNDJSONLine are returned, as an *IOError.
+func StreamNDJSON(r io.Reader, w io.Writer, convert func(input string) (interface{}, error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxNDJSONLine)
+	out := bufio.NewWriter(w)
+
+	for scanner.Scan() {
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" {
+			continue
+		}
+
+		var line []byte
+		result, err := convert(input)
+		if err == nil {
+			line, err = json.Marshal(result)
+		}
+		if err != nil {
+			e := Classify(err, input)
+			line, _ = json.Marshal(ndjsonError{Input: input, Error: e.Message, Code: e.Code, Type: e.Type, Hint: e.Hint})
+		}
+
+		out.Write(line)
+		out.WriteByte('\n')
+		// Flush per line so results reach the next stage of a pipeline as
+		// they are produced
+		if err := out.Flush(); err != nil {
+			return &IOError{fmt.Errorf("writing output: %w", err)}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &IOError{fmt.Errorf("reading input: %w", err)}
+	}
+	return nil
+}
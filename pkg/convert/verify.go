@@ -0,0 +1,545 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"sql2postgrest/pkg/converter"
+	"sql2postgrest/pkg/reverse"
+
+	"github.com/multigres/multigres/go/parser"
+	"github.com/multigres/multigres/go/parser/ast"
+)
+
+// RoundTripReport is the result of Verify
+type RoundTripReport struct {
+	SQL         string       `json:"sql"`                   // The SQL given to Verify
+	Request     string       `json:"request"`               // The PostgREST request it converts to, e.g. GET /users?age=gt.18
+	RoundTrip   string       `json:"round_trip"`            // The SQL converted back from that request
+	Lossless    bool         `json:"lossless"`              // Nothing was lost, added or changed; reordering is allowed
+	Differences []Difference `json:"differences,omitempty"` // Clause-level differences between SQL and RoundTrip
+	Warnings    []string     `json:"warnings,omitempty"`    // Warnings from both conversions
+}
+
+// Difference is one clause-level difference found by Verify
+type Difference struct {
+	Kind   string `json:"kind"`   // "lost", "added", "changed" or "reordered"
+	Clause string `json:"clause"` // SELECT, FROM, JOIN, WHERE, ORDER BY, ...
+	Detail string `json:"detail"` // The item or change, e.g. "age > 18" or "INNER JOIN -> LEFT JOIN"
+}
+
+// String renders the difference as "lost WHERE: age > 18"
+func (d Difference) String() string {
+	return d.Kind + " " + d.Clause + ": " + d.Detail
+}
+
+// WriteText writes the report for a terminal: the three forms of the
+// statement, each difference and whether the round trip is lossless
+func (r *RoundTripReport) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "SQL:        %s\n", r.SQL)
+	fmt.Fprintf(w, "PostgREST:  %s\n", r.Request)
+	fmt.Fprintf(w, "Round trip: %s\n", r.RoundTrip)
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(w, "warning: %s\n", warning)
+	}
+
+	lossy := 0
+	for _, difference := range r.Differences {
+		fmt.Fprintln(w, difference)
+		if difference.Kind != "reordered" {
+			lossy++
+		}
+	}
+	if r.Lossless {
+		fmt.Fprintln(w, "LOSSLESS")
+	} else {
+		fmt.Fprintf(w, "LOSSY: %d clause(s) did not survive the round trip\n", lossy)
+	}
+}
+
+// Verify converts SQL to a PostgREST request and back, parses both
+// statements, normalizes the parse trees (aliases, BETWEEN, default ASC)
+// and reports the clauses that did not survive the round trip
+func Verify(sql string) (*RoundTripReport, error) {
+	forward, err := converter.NewConverter("").Convert(sql)
+	if err != nil {
+		return nil, err
+	}
+	if forward.NoOp != "" {
+		return nil, &NoRequestError{Code: forward.NoOp, Reasons: forward.Warnings}
+	}
+
+	query := forward.QueryParams.Encode()
+	result, err := reverse.NewConverter().ConvertWithHeaders(forward.Method, forward.Path, query, forward.Body, forward.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	request := forward.Method + " " + forward.Path
+	if query != "" {
+		request += "?" + query
+	}
+	if forward.Body != "" {
+		request += " " + forward.Body
+	}
+
+	report := &RoundTripReport{
+		SQL:       sql,
+		Request:   request,
+		RoundTrip: result.SQL,
+		Warnings:  append(append([]string{}, forward.Warnings...), result.Warnings...),
+	}
+	original, err := parseShape(sql)
+	if err != nil {
+		return nil, err
+	}
+	roundTrip, err := parseShape(result.SQL)
+	if err != nil {
+		return nil, err
+	}
+	report.Differences = compareShapes(original, roundTrip)
+
+	report.Lossless = true
+	for _, difference := range report.Differences {
+		if difference.Kind != "reordered" {
+			report.Lossless = false
+		}
+	}
+	return report, nil
+}
+
+// roundTripError reports a statement Verify cannot compare
+func roundTripError(message, sql string) *Error {
+	return &Error{Code: "ERR_UNSUPPORTED_ROUNDTRIP", Type: "unsupported", Message: message, Input: sql}
+}
+
+// sqlJoin is one JOIN of a FROM clause
+type sqlJoin struct {
+	joinType   string
+	table      string
+	on         ast.Node
+	conditions []string
+}
+
+// sqlShape is a statement broken into the clauses Verify compares. Items
+// are deparsed from the parse tree, so keyword case, spacing, casts and
+// operator spellings never show up as differences.
+type sqlShape struct {
+	table   string              // Main table, without alias
+	aliases map[string]string   // Table of each alias in FROM
+	lists   map[string][]string // SELECT, VALUES, SET, GROUP BY, ORDER BY, RETURNING items and WHERE, HAVING conditions
+	scalars map[string]string   // Every other clause as text
+	joins   []sqlJoin
+}
+
+// joinTypes names the join types of a JoinExpr
+var joinTypes = map[ast.JoinType]string{
+	ast.JOIN_INNER: "INNER", ast.JOIN_LEFT: "LEFT", ast.JOIN_RIGHT: "RIGHT", ast.JOIN_FULL: "FULL",
+}
+
+// parseShape parses a statement and splits it into its clauses. The
+// ORDER BY/LIMIT of an UPDATE or DELETE is split off first, since
+// PostgreSQL does not accept it there.
+func parseShape(sql string) (*sqlShape, error) {
+	stmtSQL, orderBy, limit := converter.SplitMutationLimit(sql)
+	stmts, err := parser.ParseSQL(stmtSQL)
+	if err != nil {
+		return nil, &converter.ParseError{SQL: sql, Err: err}
+	}
+	if len(stmts) != 1 {
+		return nil, roundTripError("expected a single statement to round-trip", sql)
+	}
+
+	shape := &sqlShape{aliases: map[string]string{}, lists: map[string][]string{}, scalars: map[string]string{}}
+	switch stmt := stmts[0].(type) {
+	case *ast.SelectStmt:
+		shape.addFrom(stmt.FromClause)
+		if shape.table != "" {
+			shape.scalars["FROM"] = shape.table
+		}
+		shape.addList("SELECT", stmt.TargetList)
+		shape.addConditions("WHERE", stmt.WhereClause)
+		shape.addList("GROUP BY", stmt.GroupClause)
+		shape.addConditions("HAVING", stmt.HavingClause)
+		shape.addSort(stmt.SortClause)
+		if stmt.LimitCount != nil {
+			shape.scalars["LIMIT"] = stmt.LimitCount.SqlString()
+		}
+		if stmt.LimitOffset != nil {
+			shape.scalars["OFFSET"] = stmt.LimitOffset.SqlString()
+		}
+	case *ast.InsertStmt:
+		shape.addFrom(ast.NewNodeList(stmt.Relation))
+		shape.scalars["INSERT INTO"] = shape.table
+		shape.lists["VALUES"] = insertedValues(stmt)
+		if stmt.OnConflictClause != nil {
+			shape.scalars["ON CONFLICT"] = strings.TrimPrefix(stmt.OnConflictClause.SqlString(), "ON CONFLICT ")
+		}
+		shape.addList("RETURNING", stmt.ReturningList)
+	case *ast.UpdateStmt:
+		shape.addFrom(ast.NewNodeList(stmt.Relation))
+		shape.addFrom(stmt.FromClause)
+		shape.scalars["UPDATE"] = shape.table
+		if stmt.TargetList != nil {
+			for _, item := range stmt.TargetList.Items {
+				if target, ok := item.(*ast.ResTarget); ok {
+					shape.qualify(target.Val)
+					shape.lists["SET"] = append(shape.lists["SET"], target.SetClauseString())
+				}
+			}
+		}
+		shape.addConditions("WHERE", stmt.WhereClause)
+		shape.addList("RETURNING", stmt.ReturningList)
+	case *ast.DeleteStmt:
+		shape.addFrom(ast.NewNodeList(stmt.Relation))
+		shape.addFrom(stmt.UsingClause)
+		shape.scalars["DELETE FROM"] = shape.table
+		shape.addConditions("WHERE", stmt.WhereClause)
+		shape.addList("RETURNING", stmt.ReturningList)
+	default:
+		return nil, roundTripError(fmt.Sprintf("cannot round-trip %s statements", stmts[0].StatementType()), sql)
+	}
+
+	if orderBy != "" {
+		stmts, err := parser.ParseSQL("SELECT * FROM t ORDER BY " + orderBy)
+		if err != nil {
+			return nil, &converter.ParseError{SQL: orderBy, Err: err}
+		}
+		shape.addSort(stmts[0].(*ast.SelectStmt).SortClause)
+	}
+	if limit != "" {
+		shape.scalars["LIMIT"] = limit
+	}
+
+	for i := range shape.joins {
+		shape.joins[i].conditions = shape.conditions(shape.joins[i].on)
+	}
+	return shape, nil
+}
+
+// addFrom records the tables of a FROM list: the first is the main table,
+// and every other one a join. Tables listed after the first are cross
+// joins, as are the FROM of an UPDATE and the USING of a DELETE.
+func (s *sqlShape) addFrom(from *ast.NodeList) {
+	if from == nil {
+		return
+	}
+	for _, item := range from.Items {
+		s.addFromItem(item, "CROSS JOIN")
+	}
+}
+
+// addFromItem records a table or the tables of a join tree
+func (s *sqlShape) addFromItem(node ast.Node, joinType string) {
+	join, ok := node.(*ast.JoinExpr)
+	if !ok {
+		table, alias := relationName(node)
+		if alias != "" {
+			s.aliases[alias] = table
+		}
+		if s.table == "" {
+			s.table = table
+			return
+		}
+		s.joins = append(s.joins, sqlJoin{joinType: joinType, table: table})
+		return
+	}
+
+	s.addFromItem(join.Larg, joinType)
+	kind := joinTypes[join.Jointype]
+	if join.Jointype == ast.JOIN_INNER && join.Quals == nil && join.UsingClause == nil && !join.IsNatural {
+		kind = "CROSS"
+	}
+	if join.IsNatural {
+		kind = "NATURAL " + kind
+	}
+	s.addFromItem(join.Rarg, kind+" JOIN")
+	if _, nested := join.Rarg.(*ast.JoinExpr); !nested && join.Quals != nil {
+		s.joins[len(s.joins)-1].on = join.Quals
+	}
+}
+
+// relationName returns the table of a FROM item and its alias
+func relationName(node ast.Node) (table, alias string) {
+	rv, ok := node.(*ast.RangeVar)
+	if !ok {
+		return node.SqlString(), ""
+	}
+	table = rv.RelName
+	if rv.SchemaName != "" {
+		table = rv.SchemaName + "." + table
+	}
+	if rv.Alias != nil {
+		alias = rv.Alias.AliasName
+	}
+	return table, alias
+}
+
+// addList records the deparsed items of a clause
+func (s *sqlShape) addList(clause string, list *ast.NodeList) {
+	if list == nil {
+		return
+	}
+	for _, item := range list.Items {
+		s.qualify(item)
+		s.lists[clause] = append(s.lists[clause], item.SqlString())
+	}
+}
+
+// addSort records ORDER BY items, spelling out the ASC they default to
+func (s *sqlShape) addSort(list *ast.NodeList) {
+	if list == nil {
+		return
+	}
+	for _, item := range list.Items {
+		if sortBy, ok := item.(*ast.SortBy); ok && sortBy.SortbyDir == ast.SORTBY_DEFAULT {
+			sortBy.SortbyDir = ast.SORTBY_ASC
+		}
+	}
+	s.addList("ORDER BY", list)
+}
+
+// addConditions records the conditions of a WHERE or HAVING clause
+func (s *sqlShape) addConditions(clause string, node ast.Node) {
+	if conditions := s.conditions(node); len(conditions) > 0 {
+		s.lists[clause] = conditions
+	}
+}
+
+// conditions splits a condition on its ANDs, at any nesting, and expands
+// x BETWEEN a AND b into x >= a and x <= b, which is how PostgREST sends it
+func (s *sqlShape) conditions(node ast.Node) []string {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *ast.ParenExpr:
+		return s.conditions(n.Expr)
+	case *ast.BoolExpr:
+		if n.Boolop == ast.AND_EXPR {
+			var conditions []string
+			for _, arg := range n.Args.Items {
+				conditions = append(conditions, s.conditions(arg)...)
+			}
+			return conditions
+		}
+	case *ast.A_Expr:
+		if bounds, ok := n.Rexpr.(*ast.NodeList); ok && n.Kind == ast.AEXPR_BETWEEN && len(bounds.Items) == 2 {
+			lower := ast.NewA_Expr(ast.AEXPR_OP, ast.NewNodeList(ast.NewString(">=")), n.Lexpr, bounds.Items[0], 0)
+			upper := ast.NewA_Expr(ast.AEXPR_OP, ast.NewNodeList(ast.NewString("<=")), n.Lexpr, bounds.Items[1], 0)
+			return append(s.conditions(lower), s.conditions(upper)...)
+		}
+	}
+	s.qualify(node)
+	return []string{flattenBool(node).SqlString()}
+}
+
+// flattenBool merges nested ANDs and ORs of the same kind, so
+// a OR (b OR c) and (a OR b) OR c deparse alike, and puts parentheses
+// around every other nested AND, OR and NOT
+func flattenBool(node ast.Node) ast.Node {
+	switch n := node.(type) {
+	case *ast.ParenExpr:
+		if inner, ok := n.Expr.(*ast.BoolExpr); ok {
+			return flattenBool(inner)
+		}
+	case *ast.BoolExpr:
+		args := ast.NewNodeList()
+		for _, arg := range n.Args.Items {
+			arg = flattenBool(arg)
+			if nested, ok := arg.(*ast.BoolExpr); ok && nested.Boolop == n.Boolop && n.Boolop != ast.NOT_EXPR {
+				args.Items = append(args.Items, nested.Args.Items...)
+				continue
+			}
+			if _, ok := arg.(*ast.BoolExpr); ok {
+				arg = ast.NewParenExpr(arg, 0)
+			}
+			args.Items = append(args.Items, arg)
+		}
+		return ast.NewBoolExpr(n.Boolop, args)
+	}
+	return node
+}
+
+// qualify rewrites the column references in node so u.name, users.name and
+// name compare equal: an alias becomes its table, and the main table's
+// qualifier is dropped. Subqueries keep their own names.
+func (s *sqlShape) qualify(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.ColumnRef:
+		if n.Fields == nil || len(n.Fields.Items) < 2 {
+			return
+		}
+		first, ok := n.Fields.Items[0].(*ast.String)
+		if !ok {
+			return
+		}
+		name := first.SVal
+		if table, ok := s.aliases[name]; ok {
+			name = table
+		}
+		if name == s.table {
+			n.Fields.Items = n.Fields.Items[1:]
+		} else {
+			n.Fields.Items[0] = ast.NewString(name)
+		}
+	case *ast.NodeList:
+		if n != nil {
+			for _, item := range n.Items {
+				s.qualify(item)
+			}
+		}
+	case *ast.ResTarget:
+		s.qualify(n.Val)
+	case *ast.SortBy:
+		s.qualify(n.Node)
+	case *ast.A_Expr:
+		s.qualify(n.Lexpr)
+		s.qualify(n.Rexpr)
+	case *ast.BoolExpr:
+		s.qualify(n.Args)
+	case *ast.ParenExpr:
+		s.qualify(n.Expr)
+	case *ast.TypeCast:
+		s.qualify(n.Arg)
+	case *ast.FuncCall:
+		s.qualify(n.Args)
+	case *ast.NullTest:
+		s.qualify(n.Arg)
+	case *ast.BooleanTest:
+		s.qualify(n.Arg)
+	case *ast.A_ArrayExpr:
+		s.qualify(n.Elements)
+	case *ast.SubLink:
+		s.qualify(n.Testexpr)
+	}
+}
+
+// insertedValues pairs each VALUES row with the INSERT column list, as
+// "row 1: name = 'Alice'", sorted so the column order does not matter
+func insertedValues(stmt *ast.InsertStmt) []string {
+	var columns []string
+	if stmt.Cols != nil {
+		for _, col := range stmt.Cols.Items {
+			if target, ok := col.(*ast.ResTarget); ok {
+				columns = append(columns, ast.QuoteIdentifier(target.Name))
+			}
+		}
+	}
+	values, ok := stmt.SelectStmt.(*ast.SelectStmt)
+	if !ok || values.ValuesLists == nil {
+		return nil
+	}
+
+	var items []string
+	for row, tuple := range values.ValuesLists.Items {
+		list, ok := tuple.(*ast.NodeList)
+		if !ok {
+			continue
+		}
+		for i, value := range list.Items {
+			column := fmt.Sprintf("$%d", i+1)
+			if i < len(columns) {
+				column = columns[i]
+			}
+			items = append(items, fmt.Sprintf("row %d: %s = %s", row+1, column, value.SqlString()))
+		}
+	}
+	slices.Sort(items)
+	return items
+}
+
+// compareShapes lists what changed from the original to the round trip
+func compareShapes(original, roundTrip *sqlShape) []Difference {
+	var differences []Difference
+
+	for _, clause := range []string{"INSERT INTO", "UPDATE", "DELETE FROM", "FROM", "ON CONFLICT", "LIMIT", "OFFSET"} {
+		before, hadBefore := original.scalars[clause]
+		after, hasAfter := roundTrip.scalars[clause]
+		switch {
+		case hadBefore && !hasAfter:
+			differences = append(differences, Difference{"lost", clause, before})
+		case !hadBefore && hasAfter:
+			differences = append(differences, Difference{"added", clause, after})
+		case before != after:
+			differences = append(differences, Difference{"changed", clause, before + " -> " + after})
+		}
+	}
+
+	differences = append(differences, compareJoins(original.joins, roundTrip.joins)...)
+
+	for _, clause := range []string{"SELECT", "VALUES", "SET", "WHERE", "GROUP BY", "HAVING", "ORDER BY", "RETURNING"} {
+		differences = append(differences, compareItems(clause, original.lists[clause], roundTrip.lists[clause])...)
+	}
+	return differences
+}
+
+// compareJoins pairs joins by table and compares their type and condition
+func compareJoins(original, roundTrip []sqlJoin) []Difference {
+	var differences []Difference
+	matched := map[int]bool{}
+	for _, before := range original {
+		found := false
+		for i, after := range roundTrip {
+			if matched[i] || after.table != before.table {
+				continue
+			}
+			matched[i], found = true, true
+			if before.joinType != after.joinType {
+				differences = append(differences, Difference{"changed", "JOIN", before.table + ": " + before.joinType + " -> " + after.joinType})
+			}
+			beforeOn, afterOn := strings.Join(before.conditions, " AND "), strings.Join(after.conditions, " AND ")
+			if !sameItems(before.conditions, after.conditions) {
+				differences = append(differences, Difference{"changed", "JOIN", before.table + " ON " + beforeOn + " -> " + afterOn})
+			}
+			break
+		}
+		if !found {
+			differences = append(differences, Difference{"lost", "JOIN", before.joinType + " " + before.table})
+		}
+	}
+	for i, after := range roundTrip {
+		if !matched[i] {
+			differences = append(differences, Difference{"added", "JOIN", after.joinType + " " + after.table})
+		}
+	}
+	return differences
+}
+
+// compareItems reports items lost or added, and a changed order; only
+// ORDER BY depends on the order of its items
+func compareItems(clause string, original, roundTrip []string) []Difference {
+	var differences []Difference
+	remaining := append([]string{}, roundTrip...)
+	for _, item := range original {
+		if i := slices.Index(remaining, item); i >= 0 {
+			remaining = slices.Delete(remaining, i, i+1)
+			continue
+		}
+		differences = append(differences, Difference{"lost", clause, item})
+	}
+	for _, item := range remaining {
+		differences = append(differences, Difference{"added", clause, item})
+	}
+
+	if len(differences) == 0 && !slices.Equal(original, roundTrip) {
+		kind := "reordered"
+		if clause == "ORDER BY" {
+			kind = "changed"
+		}
+		differences = append(differences, Difference{kind, clause, fmt.Sprintf("%s -> %s", strings.Join(original, ", "), strings.Join(roundTrip, ", "))})
+	}
+	return differences
+}
+
+// sameItems reports whether two lists hold the same items in any order
+func sameItems(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA, sortedB := slices.Clone(a), slices.Clone(b)
+	slices.Sort(sortedA)
+	slices.Sort(sortedB)
+	return slices.Equal(sortedA, sortedB)
+}
@@ -77,6 +77,94 @@ func TestDISTINCT(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "/products", result.Path)
 	})
+
+	t.Run("DISTINCT ON is rejected with view DDL", func(t *testing.T) {
+		_, err := conv.Convert("SELECT DISTINCT ON (user_id) * FROM events WHERE kind = 'click' ORDER BY user_id, created_at DESC LIMIT 10")
+		require.Error(t, err)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "clause", unsupported.Kind)
+		assert.Equal(t, "DISTINCT ON (user_id)", unsupported.Name)
+		assert.Contains(t, unsupported.Hint,
+			"CREATE VIEW events_distinct_user_id AS SELECT DISTINCT ON (user_id) * FROM events WHERE kind = 'click' ORDER BY user_id, created_at DESC;")
+		assert.Contains(t, unsupported.Hint, "GET /events_distinct_user_id")
+		assert.NotContains(t, unsupported.Hint, "LIMIT")
+	})
+
+	t.Run("DISTINCT ON multiple expressions", func(t *testing.T) {
+		_, err := conv.Convert("SELECT DISTINCT ON (e.user_id, e.kind) e.* FROM app.events e ORDER BY e.user_id, e.kind")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "CREATE VIEW app_events_distinct_user_id_kind AS")
+	})
+}
+
+func TestScalarSubqueryInSELECT(t *testing.T) {
+	conv := NewConverter("https://api.example.com")
+
+	t.Run("correlated COUNT becomes embedded count", func(t *testing.T) {
+		result, err := conv.Convert("SELECT u.name, (SELECT COUNT(*) FROM posts p WHERE p.user_id = u.id) AS post_count FROM users u")
+		require.NoError(t, err)
+		assert.Equal(t, "/users", result.Path)
+		assert.Equal(t, "name,posts(count())", result.QueryParams.Get("select"))
+		require.Len(t, result.Warnings, 1)
+		assert.Contains(t, result.Warnings[0], `"posts": [{"count": ...}]`)
+	})
+
+	t.Run("unaliased tables and reversed correlation", func(t *testing.T) {
+		result, err := conv.Convert("SELECT name, (SELECT count(*) FROM posts WHERE users.id = posts.user_id) FROM users")
+		require.NoError(t, err)
+		assert.Equal(t, "name,posts(count())", result.QueryParams.Get("select"))
+		assert.Empty(t, result.Warnings)
+	})
+
+	t.Run("column aggregate with extra conditions", func(t *testing.T) {
+		result, err := conv.Convert("SELECT u.name, (SELECT SUM(p.views) FROM posts p WHERE p.user_id = u.id AND p.published = true) FROM users u WHERE u.age > 18")
+		require.NoError(t, err)
+		assert.Equal(t, "name,posts(views.sum())", result.QueryParams.Get("select"))
+		assert.Equal(t, "eq.true", result.QueryParams.Get("posts.published"))
+		assert.Equal(t, "gt.18", result.QueryParams.Get("age"))
+	})
+
+	errorTests := []struct {
+		name        string
+		sql         string
+		wantErrText string
+	}{
+		{
+			name:        "not correlated",
+			sql:         "SELECT u.name, (SELECT count(*) FROM posts p) FROM users u",
+			wantErrText: "not correlated",
+		},
+		{
+			name:        "non-equality correlation",
+			sql:         "SELECT u.name, (SELECT count(*) FROM posts p WHERE p.user_id > u.id) FROM users u",
+			wantErrText: "must be correlated",
+		},
+		{
+			name:        "non-aggregate value",
+			sql:         "SELECT u.name, (SELECT p.title FROM posts p WHERE p.user_id = u.id) FROM users u",
+			wantErrText: "must return an aggregate",
+		},
+		{
+			name:        "limit inside subquery",
+			sql:         "SELECT u.name, (SELECT count(*) FROM posts p WHERE p.user_id = u.id LIMIT 1) FROM users u",
+			wantErrText: "create a VIEW",
+		},
+		{
+			name:        "OR beside correlation",
+			sql:         "SELECT u.name, (SELECT count(*) FROM posts p WHERE p.user_id = u.id AND (p.a = 1 OR p.b = 2)) FROM users u",
+			wantErrText: "OR/NOT",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := conv.Convert(tt.sql)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErrText)
+		})
+	}
 }
 
 func TestColumnCastingInSELECT(t *testing.T) {
@@ -172,6 +260,18 @@ func TestMultipleORDERBY(t *testing.T) {
 		assert.Contains(t, order, "last_login.desc.nullslast")
 		assert.Contains(t, order, "created_at.asc")
 	})
+
+	t.Run("order by using comparison operators", func(t *testing.T) {
+		result, err := conv.Convert("SELECT * FROM users ORDER BY age USING >, name USING < NULLS FIRST")
+		require.NoError(t, err)
+		assert.Equal(t, "age.desc,name.asc.nullsfirst", result.QueryParams.Get("order"))
+	})
+
+	t.Run("order by using custom operator", func(t *testing.T) {
+		_, err := conv.Convert("SELECT * FROM users ORDER BY name USING ~<~")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "USING ~<~")
+	})
 }
 
 func TestJSONPathOperations(t *testing.T) {
@@ -340,6 +440,28 @@ func TestFullTextSearch(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "wfts(french).amusant", result.QueryParams.Get("content"))
 	})
+
+	t.Run("to_tsvector on left side", func(t *testing.T) {
+		result, err := conv.Convert("SELECT * FROM articles WHERE to_tsvector(content) @@ websearch_to_tsquery('fat cats')")
+		require.NoError(t, err)
+		assert.Equal(t, "wfts.fat cats", result.QueryParams.Get("content"))
+	})
+
+	t.Run("to_tsvector language carries over", func(t *testing.T) {
+		result, err := conv.Convert("SELECT * FROM articles WHERE to_tsvector('english', a.content) @@ plainto_tsquery('fat cats')")
+		require.NoError(t, err)
+		assert.Equal(t, "plfts(english).fat cats", result.QueryParams.Get("content"))
+	})
+
+	t.Run("to_tsvector language conflict", func(t *testing.T) {
+		_, err := conv.Convert("SELECT * FROM articles WHERE to_tsvector('english', content) @@ to_tsquery('french', 'chat')")
+		assert.Error(t, err)
+	})
+
+	t.Run("to_tsvector over an expression", func(t *testing.T) {
+		_, err := conv.Convert("SELECT * FROM articles WHERE to_tsvector(title || ' ' || body) @@ to_tsquery('cat')")
+		assert.Error(t, err)
+	})
 }
 
 func TestComplexCombinations(t *testing.T) {
@@ -506,7 +628,7 @@ func TestTypeCastSupport(t *testing.T) {
 		{
 			name:     "INSERT with ::jsonb cast",
 			sql:      "INSERT INTO profiles (user_id, settings) VALUES (1, '{\"theme\": \"dark\"}'::jsonb)",
-			wantBody: `[{"user_id":1,"settings":"{\"theme\": \"dark\"}"}]`,
+			wantBody: `[{"user_id":1,"settings":{"theme":"dark"}}]`,
 		},
 		{
 			name:     "INSERT with ::text cast",
@@ -521,23 +643,28 @@ func TestTypeCastSupport(t *testing.T) {
 		{
 			name:     "UPDATE with ::jsonb cast",
 			sql:      "UPDATE profiles SET settings = '{\"theme\": \"dark\"}'::jsonb WHERE user_id = 1",
-			wantBody: `{"settings":"{\"theme\": \"dark\"}"}`,
+			wantBody: `{"settings":{"theme":"dark"}}`,
 		},
 		{
 			name:     "UPDATE with multiple casts",
 			sql:      "UPDATE data SET config = '{}'::jsonb, status = 'active'::text WHERE id = 5",
-			wantBody: `{"config":"{}","status":"active"}`,
+			wantBody: `{"config":{},"status":"active"}`,
 		},
 		{
 			name:     "INSERT multiple rows with cast",
 			sql:      "INSERT INTO settings (data) VALUES ('{\"a\":1}'::jsonb), ('{\"b\":2}'::jsonb)",
-			wantBody: `[{"data":"{\"a\":1}"},{"data":"{\"b\":2}"}]`,
+			wantBody: `[{"data":{"a":1}},{"data":{"b":2}}]`,
 		},
 		{
 			name:     "INSERT with array and cast",
 			sql:      "INSERT INTO items (tags) VALUES (ARRAY['a', 'b']::text[])",
 			wantBody: `[{"tags":["a","b"]}]`,
 		},
+		{
+			name:     "INSERT with CAST AS json array",
+			sql:      "INSERT INTO items (tags) VALUES (CAST('[\"a\", \"b\"]' AS json))",
+			wantBody: `[{"tags":["a","b"]}]`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -639,7 +766,7 @@ func TestNewFeaturesIntegration(t *testing.T) {
 			wantMethod: "PATCH",
 			checkFunc: func(t *testing.T, r *ConversionResult) {
 				assert.Equal(t, "/profiles", r.Path)
-				assert.JSONEq(t, `{"settings":"{\"theme\":\"dark\"}"}`, r.Body)
+				assert.JSONEq(t, `{"settings":{"theme":"dark"}}`, r.Body)
 				assert.Equal(t, "in.(1,2,3)", r.QueryParams.Get("user_id"))
 			},
 		},
@@ -268,6 +268,9 @@ func TestAggregatesNotSupported(t *testing.T) {
 		_, err := conv.Convert("SELECT status, COUNT(*) FROM orders GROUP BY status")
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "GROUP BY not supported for simple queries")
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "GROUP BY", unsupported.Feature)
 	})
 
 	t.Run("unsupported aggregate function", func(t *testing.T) {
@@ -498,6 +498,16 @@ func TestErrorCases(t *testing.T) {
 			sql:         "SELECT status, COUNT(*) FROM orders GROUP BY status",
 			wantErrText: "GROUP BY not supported",
 		},
+		{
+			name:        "IN with subquery",
+			sql:         "SELECT * FROM users WHERE id IN (SELECT user_id FROM admins)",
+			wantErrText: "only IN (VALUES ...) lists can be converted",
+		},
+		{
+			name:        "IN VALUES with multi-column rows",
+			sql:         "SELECT * FROM users WHERE id IN (VALUES (1, 2))",
+			wantErrText: "exactly one value",
+		},
 	}
 
 	for _, tt := range tests {
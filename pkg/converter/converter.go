@@ -3,6 +3,7 @@ package converter
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/multigres/multigres/go/parser"
@@ -15,34 +16,163 @@ type ConversionResult struct {
 	QueryParams url.Values
 	Body        string
 	Headers     map[string]string
+	Warnings    []string
+
+	// NoOp is set for session and privilege statements (SET, SHOW, GRANT)
+	// that have no PostgREST request; Warnings explains why.
+	NoOp NoOpCode
+
+	// Trace is only set by ConvertWithTrace
+	Trace *Trace
 }
 
 type Converter struct {
-	baseURL string
+	baseURL      string
+	rangeHeaders bool
+	profile      string
+	readableURLs bool
+	csv          bool
+	headers      map[string]string
+	trace        *Trace
+}
+
+// Option configures optional Converter behavior
+type Option func(*Converter)
+
+// WithRangeHeaders makes LIMIT/OFFSET convert to a Range header
+// (with Range-Unit: items) instead of limit/offset query params.
+func WithRangeHeaders() Option {
+	return func(c *Converter) {
+		c.rangeHeaders = true
+	}
+}
+
+// WithProfile selects a schema via Accept-Profile (reads) or
+// Content-Profile (writes), like a session-wide search_path.
+func WithProfile(schema string) Option {
+	return func(c *Converter) {
+		c.profile = schema
+	}
 }
 
-func NewConverter(baseURL string) *Converter {
-	return &Converter{
+// WithReadableURLs makes URL return RawURL's readable form, leaving
+// PostgREST syntax characters unescaped.
+func WithReadableURLs() Option {
+	return func(c *Converter) {
+		c.readableURLs = true
+	}
+}
+
+// WithHeaders adds headers, such as apikey or Authorization, to every
+// converted request. They replace generated headers of the same name.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Converter) {
+		c.headers = headers
+	}
+}
+
+// ParseError reports SQL the parser could not read
+type ParseError struct {
+	SQL string
+	Err error // The parser's error, e.g. "parse error at position 5: syntax error"
+}
+
+func (e *ParseError) Error() string {
+	return "failed to parse SQL: " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func NewConverter(baseURL string, opts ...Option) *Converter {
+	c := &Converter{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *Converter) Convert(sql string) (*ConversionResult, error) {
+	sql, limit := splitMutationLimit(sql)
+
 	stmts, err := parser.ParseSQL(sql)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse SQL: %w", err)
+		return nil, &ParseError{SQL: sql, Err: err}
 	}
 
 	if len(stmts) == 0 {
 		return nil, fmt.Errorf("no statements found in SQL")
 	}
 
+	// Leading SET statements configure the session the final statement runs
+	// in, which PostgREST expresses as request headers.
+	session := &sessionSettings{profile: c.profile}
+	var sessionSQL []string
+	for len(stmts) > 1 {
+		setStmt, ok := stmts[0].(*ast.VariableSetStmt)
+		if !ok {
+			break
+		}
+		if err := c.applySessionSet(session, setStmt); err != nil {
+			return nil, err
+		}
+		sessionSQL = append(sessionSQL, setStmt.SqlString())
+		stmts = stmts[1:]
+	}
+
 	if len(stmts) > 1 {
-		return nil, fmt.Errorf("multiple statements not supported (found %d)", len(stmts))
+		return nil, &UnsupportedError{Feature: "multiple statements", Hint: fmt.Sprintf("found %d; convert one statement at a time", len(stmts))}
+	}
+
+	result, err := c.convertStatement(stmts[0])
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(session.warnings, result.Warnings...)
+
+	if limit != nil {
+		err := c.traceStep(result, "LIMIT", limit.String(), func() error {
+			return c.applyMutationLimit(result, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.csv && result.NoOp == "" {
+		applyCSV(result)
+	}
+
+	if result.NoOp == "" {
+		err := c.traceStep(result, "SET", strings.Join(sessionSQL, "; "), func() error {
+			return c.applySession(result, session)
+		})
+		if err != nil {
+			return nil, err
+		}
+		applyHeaders(result, c.headers)
 	}
 
-	stmt := stmts[0]
+	return result, nil
+}
+
+// applyHeaders sets the extra headers on result, replacing generated
+// headers whose names differ only in case
+func applyHeaders(result *ConversionResult, headers map[string]string) {
+	for name, value := range headers {
+		for existing := range result.Headers {
+			if strings.EqualFold(existing, name) {
+				delete(result.Headers, existing)
+			}
+		}
+		result.Headers[name] = value
+	}
+}
 
+func (c *Converter) convertStatement(stmt ast.Stmt) (*ConversionResult, error) {
 	switch s := stmt.(type) {
 	case *ast.SelectStmt:
 		return c.convertSelect(s)
@@ -52,15 +182,86 @@ func (c *Converter) Convert(sql string) (*ConversionResult, error) {
 		return c.convertUpdate(s)
 	case *ast.DeleteStmt:
 		return c.convertDelete(s)
+	case *ast.CopyStmt:
+		return c.convertCopy(s)
+	case *ast.VariableSetStmt:
+		return c.convertSet(s)
+	case *ast.VariableShowStmt:
+		return noOpResult(NoOpShow, fmt.Sprintf("SHOW %s reads a server setting and has no PostgREST equivalent", s.Name)), nil
+	case *ast.GrantStmt:
+		if s.IsGrant {
+			return noOpResult(NoOpGrant, "GRANT changes privileges and has no PostgREST equivalent; run it as a migration"), nil
+		}
+		return noOpResult(NoOpGrant, "REVOKE changes privileges and has no PostgREST equivalent; run it as a migration"), nil
+	case *ast.GrantRoleStmt:
+		return noOpResult(NoOpGrant, "GRANT/REVOKE role changes membership and has no PostgREST equivalent; run it as a migration"), nil
 	default:
-		return nil, fmt.Errorf("unsupported statement type: %T", stmt)
+		return nil, &UnsupportedError{Feature: fmt.Sprintf("statement type %T", stmt)}
+	}
+}
+
+// addPrefer appends a preference to the comma-separated Prefer header
+func addPrefer(result *ConversionResult, preference string) {
+	if existing := result.Headers["Prefer"]; existing != "" {
+		result.Headers["Prefer"] = existing + "," + preference
+	} else {
+		result.Headers["Prefer"] = preference
 	}
 }
 
+// preferValue returns the value of a key=value preference in the Prefer header
+func preferValue(result *ConversionResult, key string) (string, bool) {
+	for _, pref := range strings.Split(result.Headers["Prefer"], ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(pref), "=")
+		if found && name == key {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 func (c *Converter) URL(result *ConversionResult) string {
+	if c.readableURLs {
+		return c.RawURL(result)
+	}
+	return result.requestURL(c.baseURL)
+}
+
+// RawURL is like URL but leaves the characters PostgREST uses for its own
+// syntax (,().*:) unescaped, so the URL is easy to read and paste. Characters
+// that are unsafe in a query string are still percent-encoded.
+func (c *Converter) RawURL(result *ConversionResult) string {
 	urlStr := c.baseURL + result.Path
 	if len(result.QueryParams) > 0 {
-		urlStr += "?" + result.QueryParams.Encode()
+		urlStr += "?" + readableQuery(result.QueryParams)
 	}
 	return urlStr
 }
+
+// readableUnescaper restores PostgREST syntax characters after query escaping
+var readableUnescaper = strings.NewReplacer(
+	"%2C", ",",
+	"%28", "(",
+	"%29", ")",
+	"%2A", "*",
+	"%3A", ":",
+)
+
+// readableQuery encodes params like url.Values.Encode (sorted by key) but
+// keeps PostgREST syntax characters readable
+func readableQuery(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		escapedKey := readableUnescaper.Replace(url.QueryEscape(key))
+		for _, value := range params[key] {
+			parts = append(parts, escapedKey+"="+readableUnescaper.Replace(url.QueryEscape(value)))
+		}
+	}
+	return strings.Join(parts, "&")
+}
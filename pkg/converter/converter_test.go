@@ -15,6 +15,9 @@
 package converter
 
 import (
+	"context"
+	"io"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -371,6 +374,642 @@ func TestURL(t *testing.T) {
 	assert.Contains(t, url, "limit=10")
 }
 
+func TestRangeHeaders(t *testing.T) {
+	conv := NewConverter("https://api.example.com", WithRangeHeaders())
+
+	tests := []struct {
+		name        string
+		sql         string
+		wantRange   string
+		wantParams  map[string]string
+		wantNoRange bool
+	}{
+		{
+			name:      "limit and offset",
+			sql:       "SELECT * FROM users LIMIT 10 OFFSET 20",
+			wantRange: "20-29",
+		},
+		{
+			name:      "limit only",
+			sql:       "SELECT * FROM users LIMIT 5",
+			wantRange: "0-4",
+		},
+		{
+			name:      "offset only",
+			sql:       "SELECT * FROM users OFFSET 15",
+			wantRange: "15-",
+		},
+		{
+			name:        "limit zero stays a param",
+			sql:         "SELECT * FROM users LIMIT 0",
+			wantParams:  map[string]string{"limit": "0"},
+			wantNoRange: true,
+		},
+		{
+			name:        "no pagination",
+			sql:         "SELECT * FROM users",
+			wantNoRange: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.sql)
+			require.NoError(t, err)
+
+			if tt.wantNoRange {
+				assert.NotContains(t, result.Headers, "Range")
+			} else {
+				assert.Equal(t, tt.wantRange, result.Headers["Range"])
+				assert.Equal(t, "items", result.Headers["Range-Unit"])
+				assert.Empty(t, result.QueryParams.Get("limit"))
+				assert.Empty(t, result.QueryParams.Get("offset"))
+			}
+
+			for key, val := range tt.wantParams {
+				assert.Equal(t, val, result.QueryParams.Get(key))
+			}
+		})
+	}
+
+	t.Run("default keeps query params", func(t *testing.T) {
+		result, err := NewConverter("https://api.example.com").Convert("SELECT * FROM users LIMIT 10 OFFSET 20")
+		require.NoError(t, err)
+		assert.Equal(t, "10", result.QueryParams.Get("limit"))
+		assert.Equal(t, "20", result.QueryParams.Get("offset"))
+		assert.NotContains(t, result.Headers, "Range")
+	})
+}
+
+func TestTimezonePreference(t *testing.T) {
+	conv := NewConverter("https://api.example.com")
+
+	tests := []struct {
+		name         string
+		sql          string
+		wantPrefer   string
+		wantSelect   string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{
+			name:       "SET TIME ZONE before select",
+			sql:        "SET TIME ZONE 'America/New_York'; SELECT * FROM events",
+			wantPrefer: "timezone=America/New_York",
+		},
+		{
+			name:       "SET timezone before insert",
+			sql:        "SET timezone = 'UTC'; INSERT INTO events (name) VALUES ('launch')",
+			wantPrefer: "return=representation,timezone=UTC",
+		},
+		{
+			name:         "SET TIME ZONE LOCAL",
+			sql:          "SET TIME ZONE LOCAL; SELECT * FROM events",
+			wantWarnings: 1,
+		},
+		{
+			name:         "AT TIME ZONE in select",
+			sql:          "SELECT id, created_at AT TIME ZONE 'UTC' AS created FROM events",
+			wantPrefer:   "timezone=UTC",
+			wantSelect:   "id,created_at:created",
+			wantWarnings: 1,
+		},
+		{
+			name:         "SET and AT TIME ZONE agree",
+			sql:          "SET TIME ZONE 'UTC'; SELECT created_at AT TIME ZONE 'UTC' FROM events",
+			wantPrefer:   "timezone=UTC",
+			wantSelect:   "created_at",
+			wantWarnings: 1,
+		},
+		{
+			name:    "conflicting zones",
+			sql:     "SELECT created_at AT TIME ZONE 'UTC', updated_at AT TIME ZONE 'Asia/Tokyo' FROM events",
+			wantErr: true,
+		},
+		{
+			name:    "AT TIME ZONE on expression",
+			sql:     "SELECT now() AT TIME ZONE 'UTC' FROM events",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.sql)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantPrefer != "" {
+				assert.Equal(t, tt.wantPrefer, result.Headers["Prefer"])
+			} else {
+				assert.NotContains(t, result.Headers, "Prefer")
+			}
+			if tt.wantSelect != "" {
+				assert.Equal(t, tt.wantSelect, result.QueryParams.Get("select"))
+			}
+			assert.Len(t, result.Warnings, tt.wantWarnings)
+		})
+	}
+}
+
+func TestSessionStatements(t *testing.T) {
+	conv := NewConverter("https://api.example.com")
+
+	tests := []struct {
+		name     string
+		sql      string
+		wantNoOp NoOpCode
+	}{
+		{name: "set search_path", sql: "SET search_path TO api", wantNoOp: NoOpSet},
+		{name: "set time zone", sql: "SET TIME ZONE 'UTC'", wantNoOp: NoOpSet},
+		{name: "set role", sql: "SET ROLE admin", wantNoOp: NoOpSet},
+		{name: "reset all", sql: "RESET ALL", wantNoOp: NoOpReset},
+		{name: "show", sql: "SHOW server_version", wantNoOp: NoOpShow},
+		{name: "grant", sql: "GRANT SELECT ON users TO anon", wantNoOp: NoOpGrant},
+		{name: "revoke", sql: "REVOKE ALL ON users FROM anon", wantNoOp: NoOpGrant},
+		{name: "grant role", sql: "GRANT admin TO bob", wantNoOp: NoOpGrant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.sql)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantNoOp, result.NoOp)
+			assert.Empty(t, result.Method)
+			assert.NotEmpty(t, result.Warnings)
+		})
+	}
+
+	t.Run("search_path before select sets Accept-Profile", func(t *testing.T) {
+		result, err := conv.Convert("SET search_path TO api; SELECT * FROM users")
+		require.NoError(t, err)
+		assert.Equal(t, "api", result.Headers["Accept-Profile"])
+		assert.Empty(t, result.NoOp)
+	})
+
+	t.Run("search_path before insert sets Content-Profile", func(t *testing.T) {
+		result, err := conv.Convert("SET search_path TO api, public; INSERT INTO users (name) VALUES ('Alice')")
+		require.NoError(t, err)
+		assert.Equal(t, "api", result.Headers["Content-Profile"])
+		assert.Len(t, result.Warnings, 1)
+	})
+
+	t.Run("unrelated SET before select is ignored with a warning", func(t *testing.T) {
+		result, err := conv.Convert("SET statement_timeout = 5000; SELECT * FROM users")
+		require.NoError(t, err)
+		assert.Equal(t, "/users", result.Path)
+		assert.Len(t, result.Warnings, 1)
+	})
+
+	t.Run("WithProfile option", func(t *testing.T) {
+		result, err := NewConverter("https://api.example.com", WithProfile("tenant")).Convert("DELETE FROM users WHERE id = 1")
+		require.NoError(t, err)
+		assert.Equal(t, "tenant", result.Headers["Content-Profile"])
+	})
+
+	t.Run("WithHeaders option", func(t *testing.T) {
+		withHeaders := NewConverter("https://api.example.com", WithHeaders(map[string]string{
+			"apikey": "anon-key",
+			"prefer": "return=minimal",
+		}))
+
+		result, err := withHeaders.Convert("INSERT INTO users (name) VALUES ('Alice')")
+		require.NoError(t, err)
+		assert.Equal(t, "anon-key", result.Headers["apikey"])
+		assert.Equal(t, "return=minimal", result.Headers["prefer"])
+		assert.NotContains(t, result.Headers, "Prefer")
+
+		result, err = withHeaders.Convert("SHOW server_version")
+		require.NoError(t, err)
+		assert.Empty(t, result.Headers)
+	})
+
+	t.Run("no-op JSON output", func(t *testing.T) {
+		output, err := conv.ConvertToJSON("SHOW server_version")
+		require.NoError(t, err)
+		assert.Contains(t, output, `"noop":"session_show"`)
+	})
+}
+
+func TestLimitedMutations(t *testing.T) {
+	conv := NewConverter("https://api.example.com")
+
+	tests := []struct {
+		name         string
+		sql          string
+		wantMethod   string
+		wantParams   map[string]string
+		wantPrefer   string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{
+			name:    "limit without order by is rejected",
+			sql:     "DELETE FROM logs WHERE level='debug' LIMIT 1000",
+			wantErr: true,
+		},
+		{
+			name:       "delete with order by and limit",
+			sql:        "DELETE FROM logs WHERE level = 'debug' ORDER BY created_at ASC, id LIMIT 500;",
+			wantMethod: "DELETE",
+			wantParams: map[string]string{"order": "created_at.asc,id.asc", "limit": "500"},
+			wantPrefer: "return=representation,handling=strict,max-affected=500",
+		},
+		{
+			name:       "update with order by and limit",
+			sql:        "UPDATE jobs SET status = 'queued' WHERE status = 'stale' ORDER BY id DESC LIMIT 10",
+			wantMethod: "PATCH",
+			wantParams: map[string]string{"status": "eq.stale", "order": "id.desc", "limit": "10"},
+			wantPrefer: "return=representation,handling=strict,max-affected=10",
+		},
+		{
+			name:       "order by and limit inside a string literal",
+			sql:        "UPDATE t SET a = 'x ORDER BY b LIMIT 2' WHERE id = 1 ORDER BY id LIMIT 5",
+			wantMethod: "PATCH",
+			wantParams: map[string]string{"id": "eq.1", "order": "id.asc", "limit": "5"},
+			wantPrefer: "return=representation,handling=strict,max-affected=5",
+		},
+		{
+			name:    "string literal containing order by is not split",
+			sql:     "UPDATE t SET a = 'x ORDER BY b' WHERE id = 1 LIMIT 5",
+			wantErr: true,
+		},
+		{
+			name:    "order by inside a subquery is not the mutation's",
+			sql:     "DELETE FROM logs WHERE id = (SELECT max(id) FROM logs ORDER BY id) ORDER BY id LIMIT 1",
+			wantErr: true,
+		},
+		{
+			name:    "limit inside subquery is untouched",
+			sql:     "DELETE FROM logs WHERE id IN (SELECT id FROM logs LIMIT 5)",
+			wantErr: true,
+		},
+		{
+			name:    "delete without where is still rejected",
+			sql:     "DELETE FROM logs LIMIT 10",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.sql)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.NotContains(t, err.Error(), "failed to parse SQL")
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantMethod, result.Method)
+			for key, val := range tt.wantParams {
+				assert.Equal(t, val, result.QueryParams.Get(key), "param %s mismatch", key)
+			}
+			assert.Equal(t, tt.wantPrefer, result.Headers["Prefer"])
+			assert.Len(t, result.Warnings, tt.wantWarnings)
+		})
+	}
+}
+
+func TestConvertWithTrace(t *testing.T) {
+	conv := NewConverter("https://api.example.com")
+
+	t.Run("select clauses map to params", func(t *testing.T) {
+		sql := "SELECT name, age FROM users WHERE age >= 18 AND status = 'active' ORDER BY age DESC LIMIT 10"
+		result, err := conv.ConvertWithTrace(sql)
+		require.NoError(t, err)
+		require.NotNil(t, result.Trace)
+
+		assert.Equal(t, sql, result.Trace.SQL)
+		assert.Equal(t, []Mapping{
+			{Clause: "FROM", SQL: "users", Target: TargetPath, Value: "/users"},
+			{Clause: "SELECT", SQL: "name, age", Target: TargetQuery, Key: "select", Value: "name,age"},
+			{Clause: "WHERE", SQL: "age >= 18", Target: TargetQuery, Key: "age", Value: "gte.18"},
+			{Clause: "WHERE", SQL: "status = 'active'", Target: TargetQuery, Key: "status", Value: "eq.active"},
+			{Clause: "ORDER BY", SQL: "age DESC", Target: TargetQuery, Key: "order", Value: "age.desc"},
+			{Clause: "LIMIT", SQL: "LIMIT 10", Target: TargetQuery, Key: "limit", Value: "10"},
+		}, result.Trace.Mappings)
+	})
+
+	t.Run("appended Prefer keeps each clause's part", func(t *testing.T) {
+		result, err := conv.ConvertWithTrace("DELETE FROM users WHERE id = 1")
+		require.NoError(t, err)
+		assert.Contains(t, result.Trace.Mappings,
+			Mapping{Clause: "DELETE FROM", SQL: "users", Target: TargetHeader, Key: "Prefer", Value: "return=representation"})
+
+		result, err = conv.ConvertWithTrace("SET TIME ZONE 'UTC'; SELECT * FROM users")
+		require.NoError(t, err)
+		assert.Contains(t, result.Trace.Mappings,
+			Mapping{Clause: "SET", SQL: "SET TIME ZONE 'UTC'", Target: TargetHeader, Key: "Prefer", Value: "timezone=UTC"})
+	})
+
+	t.Run("update body comes from SET", func(t *testing.T) {
+		result, err := conv.ConvertWithTrace("UPDATE users SET name = 'x' WHERE id = 1")
+		require.NoError(t, err)
+		assert.Contains(t, result.Trace.Mappings,
+			Mapping{Clause: "SET", SQL: "name = 'x'", Target: TargetBody, Value: `{"name":"x"}`})
+	})
+
+	t.Run("range headers replace limit and offset mappings", func(t *testing.T) {
+		result, err := NewConverter("https://api.example.com", WithRangeHeaders()).ConvertWithTrace("SELECT * FROM users LIMIT 10 OFFSET 5")
+		require.NoError(t, err)
+		assert.Equal(t, []Mapping{
+			{Clause: "FROM", SQL: "users", Target: TargetPath, Value: "/users"},
+			{Clause: "LIMIT", SQL: "LIMIT 10 OFFSET 5", Target: TargetHeader, Key: "Range", Value: "5-14"},
+			{Clause: "LIMIT", SQL: "LIMIT 10 OFFSET 5", Target: TargetHeader, Key: "Range-Unit", Value: "items"},
+		}, result.Trace.Mappings)
+	})
+
+	t.Run("plain Convert has no trace", func(t *testing.T) {
+		result, err := conv.Convert("SELECT * FROM users")
+		require.NoError(t, err)
+		assert.Nil(t, result.Trace)
+	})
+
+	t.Run("JSON output includes trace", func(t *testing.T) {
+		output, err := conv.ConvertWithTraceToJSON("SELECT * FROM users WHERE id = 1")
+		require.NoError(t, err)
+		assert.Contains(t, output, `"trace":{"sql":"SELECT * FROM users WHERE id = 1","mappings":[`)
+	})
+}
+
+func TestRawURL(t *testing.T) {
+	conv := NewConverter("https://api.example.com")
+
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "syntax characters stay readable",
+			sql:  "SELECT id, name FROM users WHERE id IN (1, 2, 3) ORDER BY name DESC",
+			want: "https://api.example.com/users?id=in.(1,2,3)&order=name.desc&select=id,name",
+		},
+		{
+			name: "or groups and wildcards",
+			sql:  "SELECT * FROM users WHERE age < 18 OR name LIKE 'A%'",
+			want: "https://api.example.com/users?or=(age.lt.18,name.like.A*)",
+		},
+		{
+			name: "aliases and casts",
+			sql:  "SELECT price::text AS price_str FROM products",
+			want: "https://api.example.com/products?select=price::text:price_str",
+		},
+		{
+			name: "unsafe characters are still escaped",
+			sql:  "SELECT * FROM notes WHERE body = 'a&b c#?=%'",
+			want: "https://api.example.com/notes?body=eq.a%26b+c%23%3F%3D%25",
+		},
+		{
+			name: "no params",
+			sql:  "SELECT * FROM users",
+			want: "https://api.example.com/users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.sql)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, conv.RawURL(result))
+
+			// Readable and encoded forms carry the same params
+			parsed, err := url.Parse(conv.RawURL(result))
+			require.NoError(t, err)
+			assert.Equal(t, result.QueryParams, parsed.Query())
+		})
+	}
+
+	t.Run("WithReadableURLs switches URL", func(t *testing.T) {
+		readable := NewConverter("https://api.example.com", WithReadableURLs())
+		result, err := readable.Convert("SELECT id, name FROM users WHERE id IN (1, 2)")
+		require.NoError(t, err)
+		assert.Equal(t, "https://api.example.com/users?id=in.(1,2)&select=id,name", readable.URL(result))
+	})
+}
+
+func TestCSVExport(t *testing.T) {
+	conv := NewConverter("https://api.example.com")
+
+	tests := []struct {
+		name     string
+		sql      string
+		path     string
+		params   map[string]string
+		warnings []string
+	}{
+		{
+			name:   "copy query with csv header",
+			sql:    "COPY (SELECT id, name FROM users WHERE age > 18 ORDER BY name) TO STDOUT WITH CSV HEADER",
+			path:   "/users",
+			params: map[string]string{"select": "id,name", "age": "gt.18", "order": "name.asc"},
+		},
+		{
+			name:   "copy table with columns",
+			sql:    "COPY public.users (id, email) TO STDOUT WITH (FORMAT csv, HEADER true)",
+			path:   "/public.users",
+			params: map[string]string{"select": "id,email"},
+		},
+		{
+			name:     "copy without header",
+			sql:      "COPY users TO STDOUT WITH (FORMAT csv)",
+			path:     "/users",
+			params:   map[string]string{},
+			warnings: []string{"PostgREST CSV output always starts with a header row"},
+		},
+		{
+			name:   "custom delimiter is ignored",
+			sql:    "COPY users TO STDOUT WITH (FORMAT csv, HEADER, DELIMITER ';')",
+			path:   "/users",
+			params: map[string]string{},
+			warnings: []string{
+				"COPY option DELIMITER ignored - PostgREST CSV output uses fixed formatting",
+			},
+		},
+		{
+			name:   "text format falls back to csv",
+			sql:    "COPY users TO STDOUT",
+			path:   "/users",
+			params: map[string]string{},
+			warnings: []string{
+				"PostgREST has no tab-separated output; requesting CSV instead (add WITH CSV to make this explicit)",
+				"PostgREST CSV output always starts with a header row",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.sql)
+			require.NoError(t, err)
+			assert.Equal(t, "GET", result.Method)
+			assert.Equal(t, tt.path, result.Path)
+			assert.Equal(t, "text/csv", result.Headers["Accept"])
+			assert.Len(t, result.QueryParams, len(tt.params))
+			for key, value := range tt.params {
+				assert.Equal(t, value, result.QueryParams.Get(key), "param %s", key)
+			}
+			assert.Equal(t, tt.warnings, result.Warnings)
+		})
+	}
+
+	errorTests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"copy from", "COPY users FROM STDIN WITH CSV", "COPY FROM not supported"},
+		{"copy to file", "COPY users TO '/tmp/users.csv' WITH CSV", "server file or program"},
+		{"copy to program", "COPY users TO PROGRAM 'gzip > users.gz' WITH CSV", "server file or program"},
+		{"binary format", "COPY users TO STDOUT WITH (FORMAT binary)", "COPY format binary not supported"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := conv.Convert(tt.sql)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	t.Run("WithCSV sets Accept on reads only", func(t *testing.T) {
+		csv := NewConverter("https://api.example.com", WithCSV())
+
+		result, err := csv.Convert("SELECT id, name FROM users")
+		require.NoError(t, err)
+		assert.Equal(t, "text/csv", result.Headers["Accept"])
+
+		result, err = csv.Convert("DELETE FROM users WHERE id = 1")
+		require.NoError(t, err)
+		assert.NotContains(t, result.Headers, "Accept")
+	})
+}
+
+func TestResultRequestHelpers(t *testing.T) {
+	conv := NewConverter("https://api.example.com")
+
+	t.Run("ToHTTPRequest", func(t *testing.T) {
+		result, err := conv.Convert("UPDATE users SET status = 'active' WHERE id = 5")
+		require.NoError(t, err)
+
+		req, err := result.ToHTTPRequest(context.Background(), "https://db.example.com/rest/v1/")
+		require.NoError(t, err)
+		assert.Equal(t, "PATCH", req.Method)
+		assert.Equal(t, "https://db.example.com/rest/v1/users?id=eq.5", req.URL.String())
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, "return=representation", req.Header.Get("Prefer"))
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"status":"active"}`, string(body))
+	})
+
+	t.Run("ToHTTPRequest without body", func(t *testing.T) {
+		result, err := conv.Convert("SELECT * FROM users")
+		require.NoError(t, err)
+
+		req, err := result.ToHTTPRequest(context.Background(), "https://api.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "GET", req.Method)
+		assert.Nil(t, req.Body)
+	})
+
+	t.Run("ToHTTPRequest rejects no-op statements", func(t *testing.T) {
+		result, err := conv.Convert("SHOW search_path")
+		require.NoError(t, err)
+
+		_, err = result.ToHTTPRequest(context.Background(), "https://api.example.com")
+		assert.Error(t, err)
+		assert.Empty(t, result.CurlCommand("https://api.example.com"))
+	})
+
+	t.Run("CurlCommand", func(t *testing.T) {
+		result, err := conv.Convert("INSERT INTO users (name) VALUES ('O''Brien')")
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			`curl -X POST 'https://api.example.com/users' -H 'Content-Type: application/json' -H 'Prefer: return=representation' -d '[{"name":"O'\''Brien"}]'`,
+			result.CurlCommand("https://api.example.com"))
+	})
+
+	t.Run("CurlCommand for GET omits -X", func(t *testing.T) {
+		result, err := conv.Convert("SELECT id FROM users WHERE id = 1")
+		require.NoError(t, err)
+		assert.Equal(t, `curl 'https://api.example.com/users?id=eq.1&select=id'`, result.CurlCommand("https://api.example.com"))
+	})
+}
+
+func TestUnsupportedSuggestions(t *testing.T) {
+	conv := NewConverter("https://api.example.com")
+
+	tests := []struct {
+		name            string
+		sql             string
+		wantKind        string
+		wantName        string
+		wantSuggestions []string
+		wantHint        string
+	}{
+		{
+			name:            "statistics aggregate",
+			sql:             "SELECT STDDEV(age) FROM users",
+			wantKind:        "function",
+			wantName:        "stddev",
+			wantSuggestions: []string{"avg"},
+			wantHint:        "VIEW",
+		},
+		{
+			name:            "misspelled aggregate",
+			sql:             "SELECT cout(id) FROM users",
+			wantKind:        "function",
+			wantName:        "cout",
+			wantSuggestions: []string{"count"},
+			wantHint:        "RPC",
+		},
+		{
+			name:            "case folding in WHERE",
+			sql:             "SELECT * FROM users WHERE lower(name) = 'bob'",
+			wantKind:        "function",
+			wantName:        "lower",
+			wantSuggestions: []string{"ilike"},
+			wantHint:        "ilike",
+		},
+		{
+			name:            "jsonb key existence",
+			sql:             "SELECT * FROM users WHERE data ? 'key'",
+			wantKind:        "operator",
+			wantName:        "?",
+			wantSuggestions: []string{"@>"},
+			wantHint:        "cs",
+		},
+		{
+			name:     "json_agg in JOIN",
+			sql:      "SELECT a.name, json_agg(b.title) FROM authors a JOIN books b ON b.author_id = a.id GROUP BY a.name",
+			wantKind: "aggregate function",
+			wantName: "json_agg",
+			wantHint: "embedded resources",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := conv.Convert(tt.sql)
+			require.Error(t, err)
+
+			var unsupported *UnsupportedError
+			require.ErrorAs(t, err, &unsupported)
+			assert.Equal(t, tt.wantKind, unsupported.Kind)
+			assert.Equal(t, tt.wantName, unsupported.Name)
+			assert.Equal(t, tt.wantSuggestions, unsupported.Suggestions)
+			assert.Contains(t, unsupported.Hint, tt.wantHint)
+		})
+	}
+}
+
 func TestEdgeCases(t *testing.T) {
 	conv := NewConverter("https://api.example.com")
 
@@ -472,6 +1111,11 @@ func TestInsertEdgeCases(t *testing.T) {
 			sql:     "INSERT INTO products (id, price) VALUES (1, 99.99)",
 			wantErr: false,
 		},
+		{
+			name:    "insert invalid jsonb literal",
+			sql:     "INSERT INTO profiles (settings) VALUES ('{not json'::jsonb)",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -564,6 +1208,18 @@ func TestInOperator(t *testing.T) {
 			wantCol: "id",
 			wantVal: "in.(42)",
 		},
+		{
+			name:    "IN with VALUES list",
+			sql:     "SELECT * FROM users WHERE id IN (VALUES (1), (2))",
+			wantCol: "id",
+			wantVal: "in.(1,2)",
+		},
+		{
+			name:    "NOT IN with VALUES list",
+			sql:     "SELECT * FROM users WHERE status NOT IN (VALUES ('banned'), ('deleted'))",
+			wantCol: "status",
+			wantVal: "not.in.(banned,deleted)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1062,17 +1718,17 @@ func TestJoinsNotSupported(t *testing.T) {
 		{
 			name:        "json_agg not supported",
 			sql:         "SELECT a.name, json_agg(b.title) AS books FROM authors a LEFT JOIN books b ON b.author_id = a.id GROUP BY a.id",
-			wantErrText: "json_agg/json_build_object not supported",
+			wantErrText: "embedded resources",
 		},
 		{
 			name:        "json_build_object not supported",
 			sql:         "SELECT a.name, json_build_object('title', b.title) AS book FROM authors a LEFT JOIN books b ON b.author_id = a.id GROUP BY a.id",
-			wantErrText: "json_agg/json_build_object not supported",
+			wantErrText: "embedded resources",
 		},
 		{
 			name:        "complex nested json aggregation not supported",
 			sql:         "SELECT o.id, json_build_object('name', c.name) AS customer, json_agg(json_build_object('quantity', oi.quantity, 'product', json_build_object('name', p.name))) AS items FROM orders o LEFT JOIN customers c ON c.id = o.customer_id LEFT JOIN order_items oi ON oi.order_id = o.id LEFT JOIN products p ON p.id = oi.product_id GROUP BY o.id, c.name",
-			wantErrText: "json_agg/json_build_object not supported",
+			wantErrText: "embedded resources",
 		},
 	}
 
@@ -1084,3 +1740,63 @@ func TestJoinsNotSupported(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "one statement per semicolon",
+			sql:  "SELECT * FROM users;\nDELETE FROM users WHERE id = 1;",
+			want: []string{"SELECT * FROM users", "DELETE FROM users WHERE id = 1"},
+		},
+		{
+			name: "semicolons in literals and quoted identifiers",
+			sql:  `SELECT * FROM users WHERE name = 'a;b' AND "odd;col" = 1; SELECT 2`,
+			want: []string{`SELECT * FROM users WHERE name = 'a;b' AND "odd;col" = 1`, "SELECT 2"},
+		},
+		{
+			name: "semicolons in dollar quotes",
+			sql:  "SELECT $$a;b$$, $tag$c;d$tag$; SELECT 2",
+			want: []string{"SELECT $$a;b$$, $tag$c;d$tag$", "SELECT 2"},
+		},
+		{
+			name: "comments are dropped between statements",
+			sql:  "-- first; query\nSELECT 1 /* ; */;\n/* nested /* ; */ */ SELECT 2 -- trailing;",
+			want: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name: "leading SET stays with the next statement",
+			sql:  "SET search_path TO api;\nSELECT * FROM users; SELECT 2",
+			want: []string{"SET search_path TO api;\nSELECT * FROM users", "SELECT 2"},
+		},
+		{
+			name: "trailing SET is its own statement",
+			sql:  "SELECT 1; SET search_path TO api",
+			want: []string{"SELECT 1", "SET search_path TO api"},
+		},
+		{
+			name: "limited mutation keeps its tail",
+			sql:  "DELETE FROM logs WHERE note = 'x' ORDER BY id LIMIT 5; SELECT 1",
+			want: []string{"DELETE FROM logs WHERE note = 'x' ORDER BY id LIMIT 5", "SELECT 1"},
+		},
+		{
+			name: "input the lexer rejects is returned whole",
+			sql:  "SELECT 1; SELECT 'unterminated",
+			want: []string{"SELECT 1; SELECT 'unterminated"},
+		},
+		{
+			name: "empty script",
+			sql:  " ;\n-- nothing\n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SplitStatements(tt.sql))
+		})
+	}
+}
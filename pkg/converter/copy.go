@@ -0,0 +1,151 @@
+// Copyright 2025 Supabase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/multigres/multigres/go/parser/ast"
+)
+
+const csvMediaType = "text/csv"
+
+// WithCSV makes converted reads request CSV output (Accept: text/csv)
+// instead of JSON, for data-export queries.
+func WithCSV() Option {
+	return func(c *Converter) {
+		c.csv = true
+	}
+}
+
+// applyCSV sets Accept: text/csv on reads
+func applyCSV(result *ConversionResult) {
+	if result.Method == "GET" || result.Method == "HEAD" {
+		result.Headers["Accept"] = csvMediaType
+	}
+}
+
+// convertCopy maps COPY (SELECT ...) TO STDOUT and COPY table TO STDOUT to a
+// GET that asks PostgREST for CSV
+func (c *Converter) convertCopy(stmt *ast.CopyStmt) (*ConversionResult, error) {
+	if stmt.IsFrom {
+		return nil, &UnsupportedError{Feature: "COPY FROM", Hint: "use INSERT (a POST with a JSON array body) to load rows"}
+	}
+	if stmt.IsProgram || stmt.Filename != "" {
+		return nil, &UnsupportedError{Feature: "COPY TO a server file or program", Hint: "use COPY ... TO STDOUT to export through PostgREST"}
+	}
+
+	var result *ConversionResult
+	var err error
+	switch {
+	case stmt.Query != nil:
+		selectStmt, ok := stmt.Query.(*ast.SelectStmt)
+		if !ok {
+			return nil, &UnsupportedError{Feature: fmt.Sprintf("COPY of %T", stmt.Query), Hint: "only COPY (SELECT ...) TO STDOUT can be exported"}
+		}
+		result, err = c.convertSelect(selectStmt)
+	case stmt.Relation != nil:
+		result, err = c.convertCopyTable(stmt)
+	default:
+		return nil, fmt.Errorf("COPY statement missing table or query")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := copyFormatWarnings(stmt.Options)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+
+	applyCSV(result)
+	return result, nil
+}
+
+// convertCopyTable handles COPY table [(columns)] TO STDOUT
+func (c *Converter) convertCopyTable(stmt *ast.CopyStmt) (*ConversionResult, error) {
+	tableName := stmt.Relation.RelName
+	if stmt.Relation.SchemaName != "" {
+		tableName = stmt.Relation.SchemaName + "." + tableName
+	}
+
+	result := &ConversionResult{
+		Method:      "GET",
+		Path:        "/" + tableName,
+		QueryParams: url.Values{},
+		Headers:     make(map[string]string),
+	}
+
+	if stmt.Attlist != nil && len(stmt.Attlist.Items) > 0 {
+		var columns []string
+		for _, item := range stmt.Attlist.Items {
+			col, ok := item.(*ast.String)
+			if !ok {
+				return nil, fmt.Errorf("unexpected COPY column type: %T", item)
+			}
+			columns = append(columns, col.SVal)
+		}
+		result.QueryParams.Set("select", strings.Join(columns, ","))
+	}
+
+	return result, nil
+}
+
+// copyFormatWarnings checks COPY options against what PostgREST's CSV output
+// can produce: comma-separated, double-quoted, always with a header row
+func copyFormatWarnings(options *ast.NodeList) ([]string, error) {
+	format := "text"
+	header := false
+	var warnings []string
+
+	if options != nil {
+		for _, item := range options.Items {
+			opt, ok := item.(*ast.DefElem)
+			if !ok {
+				continue
+			}
+			value := ""
+			if s, ok := opt.Arg.(*ast.String); ok {
+				value = strings.ToLower(s.SVal)
+			}
+
+			switch strings.ToLower(opt.Defname) {
+			case "format":
+				format = value
+			case "header":
+				header = value == "" || value == "true" || value == "on" || value == "1" || value == "match"
+			default:
+				warnings = append(warnings, fmt.Sprintf("COPY option %s ignored - PostgREST CSV output uses fixed formatting", strings.ToUpper(opt.Defname)))
+			}
+		}
+	}
+
+	switch format {
+	case "csv":
+	case "text":
+		warnings = append(warnings, "PostgREST has no tab-separated output; requesting CSV instead (add WITH CSV to make this explicit)")
+	default:
+		return nil, &UnsupportedError{Feature: "COPY format " + format, Hint: "PostgREST can only export CSV"}
+	}
+
+	if !header {
+		warnings = append(warnings, "PostgREST CSV output always starts with a header row")
+	}
+
+	return warnings, nil
+}
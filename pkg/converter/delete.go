@@ -36,24 +36,27 @@ func (c *Converter) convertDelete(stmt *ast.DeleteStmt) (*ConversionResult, erro
 	if stmt.Relation.SchemaName != "" {
 		tableName = stmt.Relation.SchemaName + "." + tableName
 	}
-	result.Path = "/" + tableName
-
-	result.Headers["Prefer"] = "return=representation"
+	c.traceSet(result, "DELETE FROM", stmt.Relation.SqlString(), func() {
+		result.Path = "/" + tableName
+		result.Headers["Prefer"] = "return=representation"
+	})
 
 	if stmt.WhereClause != nil {
-		if err := c.addWhereClause(result, stmt.WhereClause); err != nil {
+		if err := c.traceWhere(result, stmt.WhereClause, func(cond ast.Node) error {
+			return c.addWhereClause(result, cond)
+		}); err != nil {
 			return nil, fmt.Errorf("failed to process WHERE clause: %w", err)
 		}
 	} else {
-		return nil, fmt.Errorf("DELETE without WHERE clause is dangerous and not supported")
+		return nil, &UnsupportedError{Feature: "DELETE without WHERE clause", Hint: "a DELETE without a WHERE clause is dangerous; add a filter"}
 	}
 
 	if stmt.UsingClause != nil {
-		return nil, fmt.Errorf("DELETE with USING clause not supported")
+		return nil, &UnsupportedError{Feature: "DELETE with USING clause"}
 	}
 
 	if stmt.ReturningList != nil {
-		return nil, fmt.Errorf("RETURNING clause not yet supported")
+		return nil, &UnsupportedError{Feature: "RETURNING clause"}
 	}
 
 	return result, nil
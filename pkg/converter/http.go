@@ -0,0 +1,104 @@
+// Copyright 2025 Supabase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// requestURL joins baseURL with the result's path and encoded query params
+func (r *ConversionResult) requestURL(baseURL string) string {
+	urlStr := strings.TrimSuffix(baseURL, "/") + r.Path
+	if len(r.QueryParams) > 0 {
+		urlStr += "?" + r.QueryParams.Encode()
+	}
+	return urlStr
+}
+
+// ToHTTPRequest builds a request for the converted statement against the
+// PostgREST server at baseURL, with method, headers and body set.
+func (r *ConversionResult) ToHTTPRequest(ctx context.Context, baseURL string) (*http.Request, error) {
+	if r.NoOp != "" {
+		return nil, fmt.Errorf("statement has no PostgREST request (%s)", r.NoOp)
+	}
+	return NewHTTPRequest(ctx, r.Method, r.requestURL(baseURL), r.Headers, r.Body)
+}
+
+// NewHTTPRequest builds a request from its parts, for callers that hold a
+// request other than a ConversionResult
+func NewHTTPRequest(ctx context.Context, method, url string, headers map[string]string, body string) (*http.Request, error) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return req, nil
+}
+
+// CurlCommand renders the converted statement as a copy-pasteable curl
+// command against baseURL. No-op results render as an empty string.
+func (r *ConversionResult) CurlCommand(baseURL string) string {
+	if r.NoOp != "" {
+		return ""
+	}
+	return Curl(r.Method, r.requestURL(baseURL), r.Headers, r.Body)
+}
+
+// Curl renders a request as a curl command, with the headers sorted by
+// name and every argument shell-quoted. HEAD uses -I, since with -X HEAD
+// curl waits for a body that never comes.
+func Curl(method, url string, headers map[string]string, body string) string {
+	parts := []string{"curl"}
+	switch method {
+	case "", "GET":
+	case "HEAD":
+		parts = append(parts, "-I")
+	default:
+		parts = append(parts, "-X", method)
+	}
+	parts = append(parts, ShellQuote(url))
+
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, "-H", ShellQuote(key+": "+headers[key]))
+	}
+
+	if body != "" {
+		parts = append(parts, "-d", ShellQuote(body))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ShellQuote wraps a string in single quotes for POSIX shells and fish
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -17,6 +17,7 @@ package converter
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/multigres/multigres/go/parser/ast"
 )
@@ -36,10 +37,11 @@ func (c *Converter) convertInsert(stmt *ast.InsertStmt) (*ConversionResult, erro
 	if stmt.Relation.SchemaName != "" {
 		tableName = stmt.Relation.SchemaName + "." + tableName
 	}
-	result.Path = "/" + tableName
-
-	result.Headers["Content-Type"] = "application/json"
-	result.Headers["Prefer"] = "return=representation"
+	c.traceSet(result, "INSERT INTO", stmt.Relation.SqlString(), func() {
+		result.Path = "/" + tableName
+		result.Headers["Content-Type"] = "application/json"
+		result.Headers["Prefer"] = "return=representation"
+	})
 
 	if stmt.SelectStmt == nil {
 		return nil, fmt.Errorf("INSERT statement missing values")
@@ -47,7 +49,7 @@ func (c *Converter) convertInsert(stmt *ast.InsertStmt) (*ConversionResult, erro
 
 	selectStmt, ok := stmt.SelectStmt.(*ast.SelectStmt)
 	if !ok {
-		return nil, fmt.Errorf("unsupported INSERT SELECT type: %T", stmt.SelectStmt)
+		return nil, &UnsupportedError{Feature: fmt.Sprintf("INSERT SELECT type %T", stmt.SelectStmt)}
 	}
 
 	if selectStmt.ValuesLists == nil || len(selectStmt.ValuesLists.Items) == 0 {
@@ -97,10 +99,15 @@ func (c *Converter) convertInsert(stmt *ast.InsertStmt) (*ConversionResult, erro
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal body: %w", err)
 	}
-	result.Body = string(bodyBytes)
+	c.traceSet(result, "VALUES", selectStmt.SqlString(), func() {
+		result.Body = string(bodyBytes)
+	})
 
 	if stmt.OnConflictClause != nil {
-		if err := c.addOnConflict(result, stmt.OnConflictClause); err != nil {
+		err := c.traceStep(result, "ON CONFLICT", stmt.OnConflictClause.SqlString(), func() error {
+			return c.addOnConflict(result, stmt.OnConflictClause)
+		})
+		if err != nil {
 			return nil, err
 		}
 	}
@@ -115,13 +122,16 @@ func (c *Converter) extractInsertValue(node ast.Node) (interface{}, error) {
 	case *ast.ColumnRef:
 		return c.extractColumnName(val), nil
 	case *ast.TypeCast:
+		if c.isJSONTypeCast(val) {
+			return c.extractJSONLiteral(val)
+		}
 		return c.extractInsertValue(val.Arg)
 	case *ast.A_Expr:
 		return c.extractExprValue(val)
 	case *ast.ArrayExpr:
 		return c.extractArrayValueInterface(val)
 	default:
-		return nil, fmt.Errorf("unsupported value type: %T", node)
+		return nil, &UnsupportedError{Feature: fmt.Sprintf("value type %T", node)}
 	}
 }
 
@@ -144,12 +154,45 @@ func (c *Converter) extractConstValueInterface(aConst *ast.A_Const) (interface{}
 	case *ast.Null:
 		return nil, nil
 	default:
-		return nil, fmt.Errorf("unsupported const type: %T", aConst.Val)
+		return nil, &UnsupportedError{Feature: fmt.Sprintf("const type %T", aConst.Val)}
+	}
+}
+
+// isJSONTypeCast reports whether a cast targets json or jsonb.
+func (c *Converter) isJSONTypeCast(tc *ast.TypeCast) bool {
+	if tc.TypeName == nil || tc.TypeName.Names == nil || len(tc.TypeName.Names.Items) == 0 {
+		return false
+	}
+	nameNode, ok := tc.TypeName.Names.Items[len(tc.TypeName.Names.Items)-1].(*ast.String)
+	if !ok {
+		return false
+	}
+	typeName := strings.ToLower(nameNode.SVal)
+	return typeName == "json" || typeName == "jsonb"
+}
+
+// extractJSONLiteral embeds a '...'::jsonb string literal as raw JSON so
+// PostgREST receives an object/array instead of a quoted string.
+func (c *Converter) extractJSONLiteral(tc *ast.TypeCast) (interface{}, error) {
+	aConst, ok := tc.Arg.(*ast.A_Const)
+	if !ok {
+		return c.extractInsertValue(tc.Arg)
 	}
+
+	strVal, ok := aConst.Val.(*ast.String)
+	if !ok {
+		return c.extractConstValueInterface(aConst)
+	}
+
+	if !json.Valid([]byte(strVal.SVal)) {
+		return nil, fmt.Errorf("invalid JSON literal: %s", strVal.SVal)
+	}
+
+	return json.RawMessage(strVal.SVal), nil
 }
 
 func (c *Converter) extractExprValue(expr *ast.A_Expr) (interface{}, error) {
-	return nil, fmt.Errorf("expressions in INSERT/UPDATE values not yet supported")
+	return nil, &UnsupportedError{Feature: "expressions in INSERT/UPDATE values"}
 }
 
 func (c *Converter) extractArrayValueInterface(arr *ast.ArrayExpr) (interface{}, error) {
@@ -177,7 +220,7 @@ func (c *Converter) addOnConflict(result *ConversionResult, onConflict *ast.OnCo
 	for _, elem := range onConflict.Infer.IndexElems.Items {
 		indexElem, ok := elem.(*ast.IndexElem)
 		if !ok {
-			return fmt.Errorf("unsupported index element type: %T", elem)
+			return &UnsupportedError{Feature: fmt.Sprintf("index element type %T", elem)}
 		}
 		if indexElem.Name != "" {
 			conflictColumns = append(conflictColumns, indexElem.Name)
@@ -33,7 +33,7 @@ func (c *Converter) extractFromClause(fromClause *ast.NodeList) (string, map[str
 	}
 
 	if len(fromClause.Items) > 1 {
-		return "", nil, fmt.Errorf("multiple FROM items not yet supported (use JOINs)")
+		return "", nil, &UnsupportedError{Feature: "multiple FROM items", Hint: "use JOINs"}
 	}
 
 	item := fromClause.Items[0]
@@ -58,7 +58,7 @@ func (c *Converter) extractFromClause(fromClause *ast.NodeList) (string, map[str
 		return c.extractJoinExpr(v)
 
 	default:
-		return "", nil, fmt.Errorf("unsupported FROM item type: %T", item)
+		return "", nil, &UnsupportedError{Feature: fmt.Sprintf("FROM item type %T", item)}
 	}
 }
 
@@ -119,14 +119,14 @@ func (c *Converter) extractJoinSide(node ast.Node, joins map[string]joinInfo) (s
 		return leftTable, nil
 
 	default:
-		return "", fmt.Errorf("unsupported join side type: %T", node)
+		return "", &UnsupportedError{Feature: fmt.Sprintf("join side type %T", node)}
 	}
 }
 
 func (c *Converter) extractJoinTable(node ast.Node) (string, string, error) {
 	rangeVar, ok := node.(*ast.RangeVar)
 	if !ok {
-		return "", "", fmt.Errorf("unsupported join table type: %T", node)
+		return "", "", &UnsupportedError{Feature: fmt.Sprintf("join table type %T", node)}
 	}
 
 	tableName := rangeVar.RelName
@@ -142,7 +142,7 @@ func (c *Converter) extractJoinTable(node ast.Node) (string, string, error) {
 	return tableName, alias, nil
 }
 
-func (c *Converter) buildEmbeddedSelect(targetList *ast.NodeList, joins map[string]joinInfo) (string, error) {
+func (c *Converter) buildEmbeddedSelect(result *ConversionResult, targetList *ast.NodeList, joins map[string]joinInfo) (string, error) {
 	if targetList == nil || len(targetList.Items) == 0 {
 		return "", nil
 	}
@@ -153,11 +153,12 @@ func (c *Converter) buildEmbeddedSelect(targetList *ast.NodeList, joins map[stri
 
 	baseColumns := []string{}
 	embeds := make(map[string]*embedInfo)
+	var embedOrder []string // embeds in SELECT-list order
 
 	for _, item := range targetList.Items {
 		resTarget, ok := item.(*ast.ResTarget)
 		if !ok {
-			return "", fmt.Errorf("unsupported target list item: %T", item)
+			return "", &UnsupportedError{Feature: fmt.Sprintf("target list item %T", item)}
 		}
 
 		if resTarget.Val == nil {
@@ -188,6 +189,7 @@ func (c *Converter) buildEmbeddedSelect(targetList *ast.NodeList, joins map[stri
 					} else {
 						if embeds[joinInfo.tableName] == nil {
 							embeds[joinInfo.tableName] = &embedInfo{columns: []string{}}
+							embedOrder = append(embedOrder, joinInfo.tableName)
 						}
 						if resTarget.Name != "" {
 							embeds[joinInfo.tableName].columns = append(embeds[joinInfo.tableName].columns, column+":"+resTarget.Name)
@@ -224,6 +226,7 @@ func (c *Converter) buildEmbeddedSelect(targetList *ast.NodeList, joins map[stri
 			} else {
 				if embeds[tableName] == nil {
 					embeds[tableName] = &embedInfo{columns: []string{}}
+					embedOrder = append(embedOrder, tableName)
 				}
 				embeds[tableName].columns = append(embeds[tableName].columns, funcStr)
 			}
@@ -235,8 +238,15 @@ func (c *Converter) buildEmbeddedSelect(targetList *ast.NodeList, joins map[stri
 			}
 			baseColumns = append(baseColumns, castStr)
 
+		case *ast.SubLink:
+			embedStr, err := c.convertScalarSubquery(result, val, resTarget.Name, outerNames(result, joins))
+			if err != nil {
+				return "", err
+			}
+			baseColumns = append(baseColumns, embedStr)
+
 		default:
-			return "", fmt.Errorf("unsupported SELECT expression type in JOIN: %T", val)
+			return "", &UnsupportedError{Feature: fmt.Sprintf("SELECT expression type %T in JOIN", val)}
 		}
 	}
 
@@ -245,8 +255,8 @@ func (c *Converter) buildEmbeddedSelect(targetList *ast.NodeList, joins map[stri
 		selectParts = append(selectParts, strings.Join(baseColumns, ","))
 	}
 
-	for tableName, embed := range embeds {
-		embedStr := tableName + "(" + strings.Join(embed.columns, ",") + ")"
+	for _, tableName := range embedOrder {
+		embedStr := tableName + "(" + strings.Join(embeds[tableName].columns, ",") + ")"
 		selectParts = append(selectParts, embedStr)
 	}
 
@@ -282,10 +292,7 @@ func (c *Converter) convertFunctionCallForJoin(fn *ast.FuncCall, alias string, j
 	}
 
 	if !supportedAggregates[funcName] {
-		if funcName == "json_agg" || funcName == "json_build_object" {
-			return "", "", fmt.Errorf("json_agg/json_build_object not supported - PostgREST handles JSON automatically via embedded resources. Use: GET /authors?select=name,books(title,published_date) instead")
-		}
-		return "", "", fmt.Errorf("unsupported aggregate function in JOIN: %s (only count, sum, avg, max, min are supported)", funcName)
+		return "", "", unsupportedAggregate(funcName, "JOIN")
 	}
 
 	var result string
@@ -316,7 +323,7 @@ func (c *Converter) convertFunctionCallForJoin(fn *ast.FuncCall, alias string, j
 					result = colName + ".count()"
 				}
 			} else {
-				return "", "", fmt.Errorf("unsupported COUNT argument type: %T", arg)
+				return "", "", &UnsupportedError{Feature: fmt.Sprintf("COUNT argument type %T", arg)}
 			}
 		} else {
 			return "", "", fmt.Errorf("COUNT accepts at most one argument")
@@ -364,7 +371,7 @@ func (c *Converter) convertTypeCastForJoin(tc *ast.TypeCast, alias string, joins
 
 	colRef, ok := tc.Arg.(*ast.ColumnRef)
 	if !ok {
-		return "", fmt.Errorf("unsupported typecast argument type in JOIN: %T", tc.Arg)
+		return "", &UnsupportedError{Feature: fmt.Sprintf("typecast argument type %T in JOIN", tc.Arg)}
 	}
 
 	colName := c.extractColumnName(colRef)
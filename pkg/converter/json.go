@@ -5,10 +5,13 @@ import (
 )
 
 type JSONOutput struct {
-	Method  string            `json:"method"`
-	URL     string            `json:"url"`
-	Headers map[string]string `json:"headers,omitempty"`
-	Body    interface{}       `json:"body,omitempty"`
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     interface{}       `json:"body,omitempty"`
+	Warnings []string          `json:"warnings,omitempty"`
+	NoOp     NoOpCode          `json:"noop,omitempty"`
+	Trace    *Trace            `json:"trace,omitempty"`
 }
 
 func (c *Converter) ConvertToJSON(sql string) (string, error) {
@@ -17,22 +20,7 @@ func (c *Converter) ConvertToJSON(sql string) (string, error) {
 		return "", err
 	}
 
-	output := JSONOutput{
-		Method:  result.Method,
-		URL:     c.URL(result),
-		Headers: result.Headers,
-	}
-
-	if result.Body != "" {
-		var bodyJSON interface{}
-		if err := json.Unmarshal([]byte(result.Body), &bodyJSON); err == nil {
-			output.Body = bodyJSON
-		} else {
-			output.Body = result.Body
-		}
-	}
-
-	jsonBytes, err := json.Marshal(output)
+	jsonBytes, err := json.Marshal(c.jsonOutput(result))
 	if err != nil {
 		return "", err
 	}
@@ -46,10 +34,24 @@ func (c *Converter) ConvertToJSONPretty(sql string) (string, error) {
 		return "", err
 	}
 
+	jsonBytes, err := json.MarshalIndent(c.jsonOutput(result), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonBytes), nil
+}
+
+func (c *Converter) jsonOutput(result *ConversionResult) JSONOutput {
 	output := JSONOutput{
-		Method:  result.Method,
-		URL:     c.URL(result),
-		Headers: result.Headers,
+		Method:   result.Method,
+		Headers:  result.Headers,
+		Warnings: result.Warnings,
+		NoOp:     result.NoOp,
+		Trace:    result.Trace,
+	}
+	if result.NoOp == "" {
+		output.URL = c.URL(result)
 	}
 
 	if result.Body != "" {
@@ -61,10 +63,5 @@ func (c *Converter) ConvertToJSONPretty(sql string) (string, error) {
 		}
 	}
 
-	jsonBytes, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		return "", err
-	}
-
-	return string(jsonBytes), nil
+	return output
 }
@@ -0,0 +1,111 @@
+// Copyright 2025 Supabase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/multigres/multigres/go/parser"
+	"github.com/multigres/multigres/go/parser/ast"
+)
+
+// mutationLimit holds the ORDER BY/LIMIT stripped from an UPDATE/DELETE
+type mutationLimit struct {
+	orderBy string
+	limit   string
+}
+
+func (ml *mutationLimit) String() string {
+	if ml.orderBy == "" {
+		return "LIMIT " + ml.limit
+	}
+	return "ORDER BY " + ml.orderBy + " LIMIT " + ml.limit
+}
+
+// splitMutationLimit removes a trailing ORDER BY/LIMIT from UPDATE/DELETE so
+// the rest can be parsed as PostgreSQL. Only the statement's own tokens
+// count: text in literals, comments and subqueries never matches.
+func splitMutationLimit(sql string) (string, *mutationLimit) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return sql, nil
+	}
+	for len(tokens) > 0 && tokens[len(tokens)-1].is(";") {
+		tokens = tokens[:len(tokens)-1]
+	}
+	n := len(tokens)
+	if n < 3 || !(tokens[0].is("UPDATE") || tokens[0].is("DELETE")) || !tokens[n-2].is("LIMIT") {
+		return sql, nil
+	}
+	count := tokens[n-1]
+	if _, err := strconv.ParseUint(count.text, 10, 64); err != nil {
+		return sql, nil
+	}
+
+	rest := tokens[n-2].start
+	ml := &mutationLimit{limit: count.text}
+	for i := n - 3; i > 0; i-- {
+		if tokens[i].depth == 0 && tokens[i].is("BY") && tokens[i-1].is("ORDER") && tokens[i-1].depth == 0 {
+			ml.orderBy = strings.TrimSpace(sql[tokens[i].end:tokens[n-2].start])
+			rest = tokens[i-1].start
+			break
+		}
+	}
+	return strings.TrimSpace(sql[:rest]), ml
+}
+
+// SplitMutationLimit returns an UPDATE or DELETE without the trailing
+// ORDER BY/LIMIT PostgreSQL does not accept there, with the ORDER BY items
+// and the limit; both are empty when the statement has no LIMIT.
+func SplitMutationLimit(sql string) (stmt, orderBy, limit string) {
+	stmt, ml := splitMutationLimit(sql)
+	if ml == nil {
+		return stmt, "", ""
+	}
+	return stmt, ml.orderBy, ml.limit
+}
+
+// applyMutationLimit maps LIMIT on UPDATE/DELETE to PostgREST's limited
+// mutations: limit and order params, with Prefer: handling=strict and
+// max-affected so the server refuses to touch more rows than requested.
+func (c *Converter) applyMutationLimit(result *ConversionResult, ml *mutationLimit) error {
+	if ml.orderBy != "" {
+		// Parse the ORDER BY through a SELECT to reuse the regular conversion
+		stmts, err := parser.ParseSQL("SELECT * FROM t ORDER BY " + ml.orderBy)
+		if err != nil {
+			return fmt.Errorf("failed to parse ORDER BY: %w", err)
+		}
+		selectStmt, ok := stmts[0].(*ast.SelectStmt)
+		if !ok || selectStmt.SortClause == nil {
+			return fmt.Errorf("failed to parse ORDER BY: %s", ml.orderBy)
+		}
+		if err := c.addOrderBy(result, selectStmt.SortClause); err != nil {
+			return err
+		}
+	} else {
+		return &UnsupportedError{
+			Kind: "clause",
+			Name: "LIMIT without ORDER BY",
+			Hint: "PostgREST requires an order param for limited updates/deletes; add ORDER BY on a unique column (e.g. the primary key)",
+		}
+	}
+
+	result.QueryParams.Set("limit", ml.limit)
+	addPrefer(result, "handling=strict")
+	addPrefer(result, "max-affected="+ml.limit)
+	return nil
+}
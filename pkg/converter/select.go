@@ -34,46 +34,80 @@ func (c *Converter) convertSelect(stmt *ast.SelectStmt) (*ConversionResult, erro
 	if err != nil {
 		return nil, err
 	}
-	result.Path = "/" + tableName
 
-	if len(joins) > 0 {
-		selectStr, err := c.buildEmbeddedSelect(stmt.TargetList, joins)
-		if err != nil {
-			return nil, err
-		}
-		if selectStr != "" {
-			result.QueryParams.Set("select", selectStr)
-		}
-	} else {
-		if err := c.addSelectColumns(result, stmt.TargetList); err != nil {
-			return nil, err
+	if stmt.DistinctClause != nil && len(stmt.DistinctClause.Items) > 0 {
+		return nil, distinctOnError(stmt, tableName)
+	}
+	c.traceSet(result, "FROM", listSQL(stmt.FromClause), func() {
+		result.Path = "/" + tableName
+	})
+
+	err = c.traceStep(result, "SELECT", listSQL(stmt.TargetList), func() error {
+		if len(joins) > 0 {
+			selectStr, err := c.buildEmbeddedSelect(result, stmt.TargetList, joins)
+			if err != nil {
+				return err
+			}
+			if selectStr != "" {
+				result.QueryParams.Set("select", selectStr)
+			}
+			return nil
 		}
+		return c.addSelectColumns(result, stmt.TargetList)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if stmt.WhereClause != nil {
-		if err := c.addWhereClauseWithJoins(result, stmt.WhereClause, joins); err != nil {
+		err := c.traceWhere(result, stmt.WhereClause, func(cond ast.Node) error {
+			return c.addWhereClauseWithJoins(result, cond, joins)
+		})
+		if err != nil {
 			return nil, err
 		}
 	}
 
 	if stmt.SortClause != nil && len(stmt.SortClause.Items) > 0 {
-		if err := c.addOrderByWithJoins(result, stmt.SortClause, joins); err != nil {
+		err := c.traceStep(result, "ORDER BY", listSQL(stmt.SortClause), func() error {
+			return c.addOrderByWithJoins(result, stmt.SortClause, joins)
+		})
+		if err != nil {
 			return nil, err
 		}
 	}
 
 	if stmt.LimitCount != nil {
-		if err := c.addLimit(result, stmt.LimitCount); err != nil {
+		err := c.traceStep(result, "LIMIT", "LIMIT "+nodeSQL(stmt.LimitCount), func() error {
+			return c.addLimit(result, stmt.LimitCount)
+		})
+		if err != nil {
 			return nil, err
 		}
 	}
 
 	if stmt.LimitOffset != nil {
-		if err := c.addOffset(result, stmt.LimitOffset); err != nil {
+		err := c.traceStep(result, "OFFSET", "OFFSET "+nodeSQL(stmt.LimitOffset), func() error {
+			return c.addOffset(result, stmt.LimitOffset)
+		})
+		if err != nil {
 			return nil, err
 		}
 	}
 
+	if c.rangeHeaders {
+		var fragment []string
+		if stmt.LimitCount != nil {
+			fragment = append(fragment, "LIMIT "+nodeSQL(stmt.LimitCount))
+		}
+		if stmt.LimitOffset != nil {
+			fragment = append(fragment, "OFFSET "+nodeSQL(stmt.LimitOffset))
+		}
+		c.traceSet(result, "LIMIT", strings.Join(fragment, " "), func() {
+			c.moveLimitOffsetToRange(result)
+		})
+	}
+
 	if stmt.DistinctClause != nil {
 		// PostgREST doesn't have direct DISTINCT support
 		// We'll process the query normally - the user can handle deduplication client-side
@@ -81,33 +115,62 @@ func (c *Converter) convertSelect(stmt *ast.SelectStmt) (*ConversionResult, erro
 	}
 
 	if stmt.GroupClause != nil && len(joins) == 0 {
-		return nil, fmt.Errorf("GROUP BY not supported for simple queries (use aggregate functions with JOINs or PostgREST's native aggregation)")
+		return nil, &UnsupportedError{Feature: "GROUP BY", Context: "simple queries", Hint: "use aggregate functions with JOINs or PostgREST's native aggregation"}
 	}
 
 	if stmt.HavingClause != nil {
-		return nil, fmt.Errorf("HAVING not supported - PostgREST has no HAVING equivalent. Create a database VIEW with the aggregation and HAVING clause, then query the view")
+		return nil, &UnsupportedError{Feature: "HAVING", Hint: "PostgREST has no HAVING equivalent. Create a database VIEW with the aggregation and HAVING clause, then query the view"}
 	}
 
 	if stmt.WithClause != nil {
-		return nil, fmt.Errorf("WITH (CTE) not yet supported")
+		return nil, &UnsupportedError{Feature: "WITH (CTE)"}
 	}
 
 	return result, nil
 }
 
+// distinctOnError rejects DISTINCT ON, which picks one row per group and would
+// return every row if dropped. The hint carries view DDL that keeps the
+// DISTINCT ON, WHERE and ORDER BY so the request can query the view instead.
+func distinctOnError(stmt *ast.SelectStmt, tableName string) error {
+	nameParts := []string{strings.ReplaceAll(tableName, ".", "_"), "distinct"}
+	for _, item := range stmt.DistinctClause.Items {
+		if col, ok := item.(*ast.ColumnRef); ok && col.Fields != nil && len(col.Fields.Items) > 0 {
+			if field, ok := col.Fields.Items[len(col.Fields.Items)-1].(*ast.String); ok {
+				nameParts = append(nameParts, field.SVal)
+				continue
+			}
+		}
+		nameParts = append(nameParts, "on")
+	}
+	viewName := strings.Join(nameParts, "_")
+
+	// LIMIT/OFFSET stay on the request rather than in the view
+	view := *stmt
+	view.LimitCount = nil
+	view.LimitOffset = nil
+
+	return &UnsupportedError{
+		Kind: "clause",
+		Name: "DISTINCT ON (" + listSQL(stmt.DistinctClause) + ")",
+		Hint: fmt.Sprintf("PostgREST cannot pick one row per group; create a VIEW and query it instead: CREATE VIEW %s AS %s; then GET /%s",
+			viewName, view.SqlString(), viewName),
+	}
+}
+
 func (c *Converter) extractTableName(fromClause *ast.NodeList) (string, error) {
 	if fromClause == nil || len(fromClause.Items) == 0 {
 		return "", fmt.Errorf("no FROM clause found")
 	}
 
 	if len(fromClause.Items) > 1 {
-		return "", fmt.Errorf("multiple FROM items not yet supported (use JOINs)")
+		return "", &UnsupportedError{Feature: "multiple FROM items", Hint: "use JOINs"}
 	}
 
 	item := fromClause.Items[0]
 	rangeVar, ok := item.(*ast.RangeVar)
 	if !ok {
-		return "", fmt.Errorf("unsupported FROM item type: %T", item)
+		return "", &UnsupportedError{Feature: fmt.Sprintf("FROM item type %T", item)}
 	}
 
 	if rangeVar.SchemaName != "" {
@@ -127,7 +190,7 @@ func (c *Converter) addSelectColumns(result *ConversionResult, targetList *ast.N
 	for _, item := range targetList.Items {
 		resTarget, ok := item.(*ast.ResTarget)
 		if !ok {
-			return fmt.Errorf("unsupported target list item: %T", item)
+			return &UnsupportedError{Feature: fmt.Sprintf("target list item %T", item)}
 		}
 
 		if resTarget.Val == nil {
@@ -151,6 +214,15 @@ func (c *Converter) addSelectColumns(result *ConversionResult, targetList *ast.N
 			continue
 
 		case *ast.FuncCall:
+			if c.isAtTimeZone(val) {
+				colStr, err := c.convertAtTimeZone(result, val, resTarget.Name)
+				if err != nil {
+					return err
+				}
+				columns = append(columns, colStr)
+				continue
+			}
+
 			funcStr, err := c.convertFunctionCall(val, resTarget.Name)
 			if err != nil {
 				return err
@@ -171,8 +243,15 @@ func (c *Converter) addSelectColumns(result *ConversionResult, targetList *ast.N
 			}
 			columns = append(columns, exprStr)
 
+		case *ast.SubLink:
+			embedStr, err := c.convertScalarSubquery(result, val, resTarget.Name, outerNames(result, nil))
+			if err != nil {
+				return err
+			}
+			columns = append(columns, embedStr)
+
 		default:
-			return fmt.Errorf("unsupported SELECT expression type: %T", val)
+			return &UnsupportedError{Feature: fmt.Sprintf("SELECT expression type %T", val)}
 		}
 	}
 
@@ -219,7 +298,7 @@ func (c *Converter) convertFunctionCall(fn *ast.FuncCall, alias string) (string,
 			if colRef, ok := arg.(*ast.ColumnRef); ok {
 				args = append(args, c.extractColumnName(colRef))
 			} else {
-				return "", fmt.Errorf("unsupported function argument type: %T", arg)
+				return "", &UnsupportedError{Feature: fmt.Sprintf("function argument type %T", arg)}
 			}
 		}
 	}
@@ -238,7 +317,7 @@ func (c *Converter) convertFunctionCall(fn *ast.FuncCall, alias string) (string,
 		}
 		result = args[0] + "." + funcName
 	default:
-		return "", fmt.Errorf("unsupported function: %s", funcName)
+		return "", unsupportedFunction(funcName, "")
 	}
 
 	if alias != "" {
@@ -258,20 +337,27 @@ func (c *Converter) addOrderByWithJoins(result *ConversionResult, sortClause *as
 	for _, item := range sortClause.Items {
 		sortBy, ok := item.(*ast.SortBy)
 		if !ok {
-			return fmt.Errorf("unsupported sort clause item: %T", item)
+			return &UnsupportedError{Feature: fmt.Sprintf("sort clause item %T", item)}
 		}
 
 		colRef, ok := sortBy.Node.(*ast.ColumnRef)
 		if !ok {
-			return fmt.Errorf("unsupported sort expression type: %T", sortBy.Node)
+			return &UnsupportedError{Feature: fmt.Sprintf("sort expression type %T", sortBy.Node)}
 		}
 
 		colName := c.extractColumnName(colRef)
 		colName = c.stripTablePrefix(colName)
 
 		direction := "asc"
-		if sortBy.SortbyDir == ast.SORTBY_DESC {
+		switch sortBy.SortbyDir {
+		case ast.SORTBY_DESC:
 			direction = "desc"
+		case ast.SORTBY_USING:
+			usingDir, err := c.sortUsingDirection(sortBy)
+			if err != nil {
+				return err
+			}
+			direction = usingDir
 		}
 
 		nullsHandling := ""
@@ -291,6 +377,26 @@ func (c *Converter) addOrderByWithJoins(result *ConversionResult, sortClause *as
 	return nil
 }
 
+// sortUsingDirection maps ORDER BY ... USING <op> to asc/desc when the
+// operator is a standard comparison; other operators have no PostgREST form.
+func (c *Converter) sortUsingDirection(sortBy *ast.SortBy) (string, error) {
+	var op string
+	if sortBy.UseOp != nil && len(sortBy.UseOp.Items) > 0 {
+		if opNode, ok := sortBy.UseOp.Items[len(sortBy.UseOp.Items)-1].(*ast.String); ok {
+			op = opNode.SVal
+		}
+	}
+
+	switch op {
+	case "<", "<=":
+		return "asc", nil
+	case ">", ">=":
+		return "desc", nil
+	default:
+		return "", &UnsupportedError{Feature: "ORDER BY ... USING " + op, Hint: "PostgREST can only sort ascending or descending with the column's default ordering"}
+	}
+}
+
 func (c *Converter) addLimit(result *ConversionResult, limitNode ast.Node) error {
 	limitVal, err := c.extractIntValue(limitNode)
 	if err != nil {
@@ -311,6 +417,35 @@ func (c *Converter) addOffset(result *ConversionResult, offsetNode ast.Node) err
 	return nil
 }
 
+// moveLimitOffsetToRange replaces limit/offset params with the equivalent
+// Range header. LIMIT 0 has no Range equivalent and stays a query param.
+func (c *Converter) moveLimitOffsetToRange(result *ConversionResult) {
+	limitStr := result.QueryParams.Get("limit")
+	offsetStr := result.QueryParams.Get("offset")
+	if limitStr == "" && offsetStr == "" {
+		return
+	}
+
+	offset := 0
+	if offsetStr != "" {
+		offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	rangeValue := strconv.Itoa(offset) + "-"
+	if limitStr != "" {
+		limit, _ := strconv.Atoi(limitStr)
+		if limit <= 0 {
+			return
+		}
+		rangeValue += strconv.Itoa(offset + limit - 1)
+	}
+
+	result.QueryParams.Del("limit")
+	result.QueryParams.Del("offset")
+	result.Headers["Range-Unit"] = "items"
+	result.Headers["Range"] = rangeValue
+}
+
 func (c *Converter) extractIntValue(node ast.Node) (int, error) {
 	switch n := node.(type) {
 	case *ast.A_Const:
@@ -322,7 +457,7 @@ func (c *Converter) extractIntValue(node ast.Node) (int, error) {
 		}
 		return 0, fmt.Errorf("not an integer: %T", n.Val)
 	default:
-		return 0, fmt.Errorf("unsupported value type: %T", node)
+		return 0, &UnsupportedError{Feature: fmt.Sprintf("value type %T", node)}
 	}
 }
 
@@ -333,7 +468,7 @@ func (c *Converter) convertTypeCast(tc *ast.TypeCast, alias string) (string, err
 
 	colRef, ok := tc.Arg.(*ast.ColumnRef)
 	if !ok {
-		return "", fmt.Errorf("unsupported typecast argument type: %T", tc.Arg)
+		return "", &UnsupportedError{Feature: fmt.Sprintf("typecast argument type %T", tc.Arg)}
 	}
 
 	colName := c.extractColumnName(colRef)
@@ -387,7 +522,7 @@ func (c *Converter) convertAExpr(expr *ast.A_Expr, alias string) (string, error)
 		return c.convertJSONPath(expr, alias)
 	}
 
-	return "", fmt.Errorf("unsupported A_Expr operator in SELECT: %s", operator)
+	return "", unsupportedOperator(operator, "SELECT")
 }
 
 func (c *Converter) convertJSONPath(expr *ast.A_Expr, alias string) (string, error) {
@@ -413,7 +548,7 @@ func (c *Converter) convertJSONPath(expr *ast.A_Expr, alias string) (string, err
 		}
 		leftPart = nestedPath
 	default:
-		return "", fmt.Errorf("unsupported JSON path left expression type: %T", expr.Lexpr)
+		return "", &UnsupportedError{Feature: fmt.Sprintf("JSON path left expression type %T", expr.Lexpr)}
 	}
 
 	if expr.Rexpr == nil {
@@ -422,7 +557,7 @@ func (c *Converter) convertJSONPath(expr *ast.A_Expr, alias string) (string, err
 
 	aConst, ok := expr.Rexpr.(*ast.A_Const)
 	if !ok {
-		return "", fmt.Errorf("unsupported JSON path right expression type: %T", expr.Rexpr)
+		return "", &UnsupportedError{Feature: fmt.Sprintf("JSON path right expression type %T", expr.Rexpr)}
 	}
 
 	strVal, ok := aConst.Val.(*ast.String)
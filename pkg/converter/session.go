@@ -0,0 +1,178 @@
+// Copyright 2025 Supabase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/multigres/multigres/go/parser/ast"
+)
+
+// NoOpCode classifies statements that convert to no PostgREST request
+type NoOpCode string
+
+const (
+	NoOpSet   NoOpCode = "session_set"
+	NoOpReset NoOpCode = "session_reset"
+	NoOpShow  NoOpCode = "session_show"
+	NoOpGrant NoOpCode = "privilege"
+)
+
+// sessionSettings collects leading SET statements that apply to the
+// statement following them
+type sessionSettings struct {
+	timezone string
+	profile  string
+	warnings []string
+}
+
+func noOpResult(code NoOpCode, warning string) *ConversionResult {
+	return &ConversionResult{
+		QueryParams: url.Values{},
+		Headers:     make(map[string]string),
+		Warnings:    []string{warning},
+		NoOp:        code,
+	}
+}
+
+// setArgString returns the string value of a SET argument
+func setArgString(node ast.Node) (string, bool) {
+	switch arg := node.(type) {
+	case *ast.String:
+		return arg.SVal, true
+	case *ast.A_Const:
+		if str, ok := arg.Val.(*ast.String); ok {
+			return str.SVal, true
+		}
+	}
+	return "", false
+}
+
+// applySessionSet records a leading SET statement on the session
+func (c *Converter) applySessionSet(session *sessionSettings, stmt *ast.VariableSetStmt) error {
+	switch stmt.Name {
+	case "timezone":
+		tz, warning, err := c.extractSetTimezone(stmt)
+		if err != nil {
+			return err
+		}
+		session.timezone = tz
+		if warning != "" {
+			session.warnings = append(session.warnings, warning)
+		}
+	case "search_path":
+		schema, warning, err := c.extractSearchPath(stmt)
+		if err != nil {
+			return err
+		}
+		session.profile = schema
+		if warning != "" {
+			session.warnings = append(session.warnings, warning)
+		}
+	default:
+		session.warnings = append(session.warnings, c.setStatementName(stmt)+" has no PostgREST equivalent and was ignored")
+	}
+	return nil
+}
+
+// applySession turns session settings into request headers
+func (c *Converter) applySession(result *ConversionResult, session *sessionSettings) error {
+	if session.timezone != "" {
+		if err := c.setTimezone(result, session.timezone); err != nil {
+			return err
+		}
+	}
+
+	if session.profile != "" {
+		if result.Method == "GET" || result.Method == "HEAD" {
+			result.Headers["Accept-Profile"] = session.profile
+		} else {
+			result.Headers["Content-Profile"] = session.profile
+		}
+	}
+
+	return nil
+}
+
+// extractSearchPath maps SET search_path to a single PostgREST profile.
+// PostgREST exposes one schema per request, so only the first is kept.
+func (c *Converter) extractSearchPath(stmt *ast.VariableSetStmt) (string, string, error) {
+	if stmt.Kind != ast.VAR_SET_VALUE || stmt.Args == nil {
+		return "", "SET search_path DEFAULT uses the default schema; no profile header needed", nil
+	}
+
+	var schemas []string
+	for _, item := range stmt.Args.Items {
+		schema, ok := setArgString(item)
+		if !ok {
+			return "", "", fmt.Errorf("SET search_path requires schema names, got %T", item)
+		}
+		if schema == "$user" {
+			continue
+		}
+		schemas = append(schemas, schema)
+	}
+
+	if len(schemas) == 0 {
+		return "", "", nil
+	}
+	if len(schemas) > 1 {
+		return schemas[0], fmt.Sprintf("PostgREST selects a single schema per request; using %s from search_path %s", schemas[0], strings.Join(schemas, ", ")), nil
+	}
+	return schemas[0], "", nil
+}
+
+// convertSet classifies a standalone SET/RESET statement
+func (c *Converter) convertSet(stmt *ast.VariableSetStmt) (*ConversionResult, error) {
+	if stmt.Kind == ast.VAR_RESET || stmt.Kind == ast.VAR_RESET_ALL {
+		return noOpResult(NoOpReset, c.setStatementName(stmt)+" only affects the database session and has no PostgREST equivalent"), nil
+	}
+
+	switch stmt.Name {
+	case "timezone":
+		tz, warning, err := c.extractSetTimezone(stmt)
+		if err != nil {
+			return nil, err
+		}
+		if tz == "" {
+			return noOpResult(NoOpSet, warning), nil
+		}
+		return noOpResult(NoOpSet, fmt.Sprintf("SET TIME ZONE has no request of its own; send Prefer: timezone=%s with each request", tz)), nil
+	case "search_path":
+		schema, warning, err := c.extractSearchPath(stmt)
+		if err != nil {
+			return nil, err
+		}
+		if schema == "" {
+			return noOpResult(NoOpSet, warning), nil
+		}
+		return noOpResult(NoOpSet, fmt.Sprintf("SET search_path has no request of its own; send Accept-Profile/Content-Profile: %s with each request", schema)), nil
+	}
+
+	return noOpResult(NoOpSet, c.setStatementName(stmt)+" only affects the database session and has no PostgREST equivalent"), nil
+}
+
+func (c *Converter) setStatementName(stmt *ast.VariableSetStmt) string {
+	switch stmt.Kind {
+	case ast.VAR_RESET_ALL:
+		return "RESET ALL"
+	case ast.VAR_RESET:
+		return "RESET " + stmt.Name
+	default:
+		return "SET " + stmt.Name
+	}
+}
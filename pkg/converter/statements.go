@@ -0,0 +1,156 @@
+// Copyright 2025 Supabase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"strings"
+
+	"github.com/multigres/multigres/go/parser"
+)
+
+// sqlToken is a token of the input with its byte range and parenthesis
+// depth; a parenthesis itself is at the depth outside it
+type sqlToken struct {
+	text       string
+	start, end int
+	depth      int
+}
+
+// is reports whether the token is the keyword or symbol s
+func (t sqlToken) is(s string) bool {
+	return strings.EqualFold(t.text, s)
+}
+
+// tokenize lexes sql with the parser's lexer: quoted strings and
+// identifiers and dollar quotes are single tokens, and comments are skipped
+func tokenize(sql string) ([]sqlToken, error) {
+	lexer := parser.NewLexer(sql)
+	var tokens []sqlToken
+	depth, end := 0, 0
+	for {
+		tok := lexer.NextToken()
+		if tok == nil || tok.Type == parser.EOF {
+			break
+		}
+		// The lexer's positions run ahead after string literals, so find the
+		// token after the whitespace and comments that follow the last one
+		start := skipSpace(sql, end)
+		if !strings.HasPrefix(sql[start:], tok.Text) {
+			start = tok.Position
+		}
+		end = start + len(tok.Text)
+		t := sqlToken{text: tok.Text, start: start, end: end, depth: depth}
+		switch tok.Type {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+			t.depth = depth
+		}
+		tokens = append(tokens, t)
+	}
+	if lexer.HasErrors() {
+		return nil, lexer.GetErrors()[0]
+	}
+	return tokens, nil
+}
+
+// skipSpace returns the offset of the first byte at or after i that is not
+// whitespace or part of a comment
+func skipSpace(sql string, i int) int {
+	for i < len(sql) {
+		switch {
+		case parser.IsWhitespace(sql[i]):
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return len(sql)
+			}
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			// Block comments nest
+			depth := 0
+			for ; i < len(sql); i++ {
+				if strings.HasPrefix(sql[i:], "/*") {
+					depth++
+					i++
+				} else if strings.HasPrefix(sql[i:], "*/") {
+					depth--
+					i++
+					if depth == 0 {
+						i++
+						break
+					}
+				}
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// SplitStatements splits a script into the statements Convert takes, at
+// the semicolons outside literals, comments and parentheses. A leading SET
+// stays with the statement after it, since it configures the session that
+// statement runs in. Input the lexer rejects is returned whole, so Convert
+// reports the error.
+func SplitStatements(sql string) []string {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		if sql = strings.TrimSpace(sql); sql != "" {
+			return []string{sql}
+		}
+		return nil
+	}
+
+	var stmts []string
+	pending := ""
+	first := -1
+	flush := func(last int) {
+		if first < 0 {
+			return
+		}
+		stmt := sql[tokens[first].start:tokens[last].end]
+		set := tokens[first].is("SET")
+		first = -1
+		if pending != "" {
+			stmt = pending + ";\n" + stmt
+		}
+		if set {
+			pending = stmt
+			return
+		}
+		pending = ""
+		stmts = append(stmts, stmt)
+	}
+
+	for i, tok := range tokens {
+		switch {
+		case tok.is(";") && tok.depth == 0:
+			flush(i - 1)
+		case first < 0:
+			first = i
+		}
+	}
+	flush(len(tokens) - 1)
+	if pending != "" {
+		stmts = append(stmts, pending)
+	}
+	return stmts
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 Supabase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/multigres/multigres/go/parser/ast"
+)
+
+// convertScalarSubquery maps a correlated aggregate subquery in the SELECT
+// list to an embedded aggregate:
+//
+//	(SELECT COUNT(*) FROM posts p WHERE p.user_id = u.id) → posts(count())
+//
+// PostgREST finds the relationship from the foreign key, so the correlation
+// condition is dropped; any other conditions become embedded filters.
+func (c *Converter) convertScalarSubquery(result *ConversionResult, sublink *ast.SubLink, alias string, outer map[string]bool) (string, error) {
+	if sublink.SubLinkType != ast.EXPR_SUBLINK {
+		return "", &UnsupportedError{Feature: "this subquery in SELECT", Hint: "only a scalar subquery can be embedded"}
+	}
+
+	sub, ok := sublink.Subselect.(*ast.SelectStmt)
+	if !ok || sub.FromClause == nil || len(sub.FromClause.Items) != 1 {
+		return "", fmt.Errorf("scalar subquery in SELECT must select from a single table")
+	}
+	rangeVar, ok := sub.FromClause.Items[0].(*ast.RangeVar)
+	if !ok {
+		return "", fmt.Errorf("scalar subquery in SELECT must select from a single table")
+	}
+
+	table := rangeVar.RelName
+	inner := map[string]bool{table: true}
+	if rangeVar.Alias != nil && rangeVar.Alias.AliasName != "" {
+		inner[rangeVar.Alias.AliasName] = true
+	}
+
+	if sub.GroupClause != nil || sub.HavingClause != nil || sub.SortClause != nil || sub.LimitCount != nil || sub.LimitOffset != nil {
+		return "", &UnsupportedError{Feature: "scalar subquery in SELECT with GROUP BY, HAVING, ORDER BY, LIMIT or OFFSET", Hint: "only an aggregate with WHERE can be embedded; create a VIEW for anything more complex"}
+	}
+
+	aggregate, err := c.subqueryAggregate(sub.TargetList, inner)
+	if err != nil {
+		return "", err
+	}
+
+	if sub.WhereClause == nil {
+		return "", fmt.Errorf("scalar subquery on %s is not correlated with the outer query - PostgREST can only embed related tables", table)
+	}
+
+	var correlated bool
+	var filters []ast.Node
+	for _, cond := range andConditions(sub.WhereClause) {
+		if isCorrelation(cond, inner, outer) {
+			if correlated {
+				return "", fmt.Errorf("scalar subquery on %s has more than one correlation condition - PostgREST embeds follow a single foreign key", table)
+			}
+			correlated = true
+			continue
+		}
+		filters = append(filters, cond)
+	}
+	if !correlated {
+		return "", fmt.Errorf("scalar subquery on %s must be correlated with an equality like %s.<fk> = <outer>.<pk>", table, table)
+	}
+
+	if err := c.addEmbeddedFilters(result, table, filters, inner); err != nil {
+		return "", err
+	}
+
+	if alias != "" {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"subquery %s is returned as the embedded resource %q (e.g. \"%s\": [{\"%s\": ...}]) rather than a scalar column",
+			alias, table, table, aggregateKey(aggregate)))
+	}
+
+	return table + "(" + aggregate + ")", nil
+}
+
+// subqueryAggregate converts the subquery's single aggregate target, e.g.
+// COUNT(*) → count() or SUM(p.views) → views.sum()
+func (c *Converter) subqueryAggregate(targetList *ast.NodeList, inner map[string]bool) (string, error) {
+	if targetList == nil || len(targetList.Items) != 1 {
+		return "", fmt.Errorf("scalar subquery in SELECT must return exactly one aggregate")
+	}
+	resTarget, ok := targetList.Items[0].(*ast.ResTarget)
+	if !ok {
+		return "", fmt.Errorf("scalar subquery in SELECT must return exactly one aggregate")
+	}
+	fn, ok := resTarget.Val.(*ast.FuncCall)
+	if !ok || fn.Funcname == nil || len(fn.Funcname.Items) == 0 {
+		return "", fmt.Errorf("scalar subquery in SELECT must return an aggregate (count, sum, avg, max, min) - PostgREST embeds rows, not arbitrary values")
+	}
+
+	nameNode, ok := fn.Funcname.Items[len(fn.Funcname.Items)-1].(*ast.String)
+	if !ok {
+		return "", fmt.Errorf("invalid function name type")
+	}
+	funcName := strings.ToLower(nameNode.SVal)
+
+	switch funcName {
+	case "count", "sum", "avg", "max", "min":
+	default:
+		return "", unsupportedAggregate(funcName, "subquery")
+	}
+
+	var column string
+	if fn.Args != nil && len(fn.Args.Items) > 0 {
+		if len(fn.Args.Items) != 1 {
+			return "", fmt.Errorf("%s requires exactly one argument", strings.ToUpper(funcName))
+		}
+		switch arg := fn.Args.Items[0].(type) {
+		case *ast.A_Star:
+		case *ast.ColumnRef:
+			column = c.innerColumn(c.extractColumnName(arg), inner)
+		default:
+			return "", fmt.Errorf("%s argument must be a column reference", strings.ToUpper(funcName))
+		}
+	}
+
+	if column == "" {
+		if funcName != "count" {
+			return "", fmt.Errorf("%s requires exactly one argument", strings.ToUpper(funcName))
+		}
+		return "count()", nil
+	}
+	return column + "." + funcName + "()", nil
+}
+
+// isCorrelation reports whether cond is inner.col = outer.col (either order)
+func isCorrelation(cond ast.Node, inner, outer map[string]bool) bool {
+	expr, ok := cond.(*ast.A_Expr)
+	if !ok || expr.Kind != ast.AEXPR_OP || expr.Name == nil || len(expr.Name.Items) != 1 {
+		return false
+	}
+	if op, ok := expr.Name.Items[0].(*ast.String); !ok || op.SVal != "=" {
+		return false
+	}
+
+	left, leftOK := columnQualifier(expr.Lexpr)
+	right, rightOK := columnQualifier(expr.Rexpr)
+	if !leftOK || !rightOK {
+		return false
+	}
+	return (inner[left] && !inner[right] && outer[right]) || (inner[right] && !inner[left] && outer[left])
+}
+
+// columnQualifier returns the table qualifier of a qualified column reference
+func columnQualifier(node ast.Node) (string, bool) {
+	col, ok := node.(*ast.ColumnRef)
+	if !ok || col.Fields == nil || len(col.Fields.Items) != 2 {
+		return "", false
+	}
+	qualifier, ok := col.Fields.Items[0].(*ast.String)
+	if !ok {
+		return "", false
+	}
+	return qualifier.SVal, true
+}
+
+// addEmbeddedFilters converts the subquery's non-correlation conditions into
+// filters on the embedded resource, e.g. posts.published=eq.true
+func (c *Converter) addEmbeddedFilters(result *ConversionResult, table string, filters []ast.Node, inner map[string]bool) error {
+	scratch := &ConversionResult{QueryParams: url.Values{}, Headers: map[string]string{}}
+	for _, cond := range filters {
+		if err := c.addWhereClause(scratch, cond); err != nil {
+			return fmt.Errorf("subquery on %s: %w", table, err)
+		}
+	}
+
+	for key, values := range scratch.QueryParams {
+		if key == "or" || key == "and" || strings.HasPrefix(key, "not.") {
+			return &UnsupportedError{Feature: "OR/NOT logic in the subquery on " + table, Hint: "only simple conditions are supported besides the correlation; create a VIEW for OR/NOT logic"}
+		}
+		column := c.innerColumn(key, inner)
+		if strings.Contains(column, ".") {
+			return fmt.Errorf("subquery on %s: condition on %s references another table", table, key)
+		}
+		for _, value := range values {
+			result.QueryParams.Add(table+"."+column, value)
+		}
+	}
+	return nil
+}
+
+// innerColumn strips the subquery table's name or alias from a column
+func (c *Converter) innerColumn(colName string, inner map[string]bool) string {
+	if qualifier, column, found := strings.Cut(colName, "."); found && inner[qualifier] {
+		return column
+	}
+	return colName
+}
+
+// aggregateKey is the JSON key PostgREST uses for an embedded aggregate
+func aggregateKey(aggregate string) string {
+	name := strings.TrimSuffix(aggregate, "()")
+	if i := strings.LastIndex(name, "."); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// outerNames returns the names the outer query's table can be referenced by
+func outerNames(result *ConversionResult, joins map[string]joinInfo) map[string]bool {
+	table := strings.TrimPrefix(result.Path, "/")
+	names := map[string]bool{table: true}
+	if _, name, found := strings.Cut(table, "."); found {
+		names[name] = true
+	}
+	for alias, info := range joins {
+		if info.isBase {
+			names[alias] = true
+		}
+	}
+	return names
+}
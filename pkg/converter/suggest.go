@@ -0,0 +1,256 @@
+// Copyright 2025 Supabase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"sort"
+	"strings"
+)
+
+// UnsupportedError reports SQL that parses but has no PostgREST
+// equivalent: a function or operator, along with the nearest supported
+// alternatives, or a whole Feature such as COPY FROM.
+type UnsupportedError struct {
+	Kind        string   // "function", "aggregate function", "operator" or "clause"
+	Name        string   // Function name or operator symbol
+	Feature     string   // Set instead of Kind and Name for anything else, e.g. "COPY FROM"
+	Context     string   // Clause it appeared in (WHERE, JOIN), or the queries a Feature is not supported for
+	Suggestions []string // Nearest supported functions/operators
+	Hint        string   // PostgREST-native way to get the same result
+}
+
+func (e *UnsupportedError) Error() string {
+	var msg string
+	if e.Feature != "" {
+		msg = e.Feature + " not supported"
+		if e.Context != "" {
+			msg += " for " + e.Context
+		}
+	} else {
+		msg = "unsupported " + e.Kind
+		if e.Context != "" {
+			msg += " in " + e.Context
+		}
+		msg += ": " + e.Name
+	}
+
+	if len(e.Suggestions) > 0 {
+		msg += " (did you mean " + strings.Join(e.Suggestions, ", ") + "?)"
+	}
+	if e.Hint != "" {
+		msg += " - " + e.Hint
+	}
+	return msg
+}
+
+// alternative is a knowledge-table entry for an unsupported feature
+type alternative struct {
+	suggestions []string
+	hint        string
+}
+
+const (
+	hintView         = "create a VIEW that computes it and query the view"
+	hintComputed     = "add a computed column (a function taking the table's row type) or a generated column and filter on it"
+	hintRPC          = "wrap the query in a function and call it via POST /rpc/<function>"
+	hintEmbed        = "PostgREST builds JSON automatically via embedded resources, e.g. GET /authors?select=name,books(title)"
+	hintClientSide   = "compute the value client-side and send it as a literal"
+	hintCaseFold     = "use ilike for case-insensitive matching (e.g. name=ilike.alice)"
+	hintStatistics   = "PostgREST aggregates only support count, sum, avg, max and min; " + hintView
+	hintWindow       = "window functions are not available in PostgREST queries; " + hintView
+	hintArithmetic   = "PostgREST filters compare a column against a literal; " + hintClientSide + " or " + hintComputed
+	hintJSONPath     = "use arrow chains instead (e.g. data->a->>b)"
+	hintJSONExists   = "use cs (@>) with a JSON object, or " + hintComputed
+	hintTextMatching = "use an RPC function for similarity and distance search: " + hintRPC
+)
+
+// functionAlternatives maps unsupported SQL functions to what to use instead
+var functionAlternatives = map[string]alternative{
+	"stddev":             {suggestions: []string{"avg"}, hint: hintStatistics},
+	"stddev_pop":         {suggestions: []string{"avg"}, hint: hintStatistics},
+	"stddev_samp":        {suggestions: []string{"avg"}, hint: hintStatistics},
+	"variance":           {suggestions: []string{"avg"}, hint: hintStatistics},
+	"var_pop":            {suggestions: []string{"avg"}, hint: hintStatistics},
+	"var_samp":           {suggestions: []string{"avg"}, hint: hintStatistics},
+	"percentile_cont":    {hint: hintStatistics},
+	"percentile_disc":    {hint: hintStatistics},
+	"mode":               {hint: hintStatistics},
+	"bool_and":           {suggestions: []string{"min"}, hint: hintStatistics},
+	"bool_or":            {suggestions: []string{"max"}, hint: hintStatistics},
+	"string_agg":         {hint: hintView},
+	"array_agg":          {hint: hintEmbed},
+	"json_agg":           {hint: hintEmbed},
+	"jsonb_agg":          {hint: hintEmbed},
+	"json_build_object":  {hint: hintEmbed},
+	"jsonb_build_object": {hint: hintEmbed},
+	"json_object_agg":    {hint: hintEmbed},
+	"row_to_json":        {hint: hintEmbed},
+	"to_json":            {hint: hintEmbed},
+	"to_jsonb":           {hint: hintEmbed},
+	"lower":              {suggestions: []string{"ilike"}, hint: hintCaseFold},
+	"upper":              {suggestions: []string{"ilike"}, hint: hintCaseFold},
+	"initcap":            {suggestions: []string{"ilike"}, hint: hintCaseFold},
+	"trim":               {hint: hintComputed},
+	"btrim":              {hint: hintComputed},
+	"length":             {hint: hintComputed},
+	"char_length":        {hint: hintComputed},
+	"substring":          {suggestions: []string{"like"}, hint: "use like with wildcards (e.g. code=like.ABC*), or " + hintComputed},
+	"substr":             {suggestions: []string{"like"}, hint: "use like with wildcards (e.g. code=like.ABC*), or " + hintComputed},
+	"left":               {suggestions: []string{"like"}, hint: "use like with a trailing wildcard (e.g. code=like.ABC*)"},
+	"starts_with":        {suggestions: []string{"like"}, hint: "use like with a trailing wildcard (e.g. code=like.ABC*)"},
+	"concat":             {hint: hintComputed},
+	"coalesce":           {suggestions: []string{"or"}, hint: "filter with or=(col.is.null,col.eq.value), or " + hintComputed},
+	"nullif":             {hint: hintComputed},
+	"greatest":           {hint: hintComputed},
+	"least":              {hint: hintComputed},
+	"abs":                {hint: hintComputed},
+	"round":              {hint: hintComputed},
+	"date_trunc":         {hint: hintComputed},
+	"date_part":          {hint: hintComputed},
+	"extract":            {hint: hintComputed},
+	"to_char":            {hint: hintComputed},
+	"age":                {hint: hintComputed},
+	"now":                {hint: hintClientSide},
+	"current_date":       {hint: hintClientSide},
+	"current_timestamp":  {hint: hintClientSide},
+	"clock_timestamp":    {hint: hintClientSide},
+	"random":             {hint: hintRPC},
+	"row_number":         {hint: hintWindow},
+	"rank":               {hint: hintWindow},
+	"dense_rank":         {hint: hintWindow},
+	"lag":                {hint: hintWindow},
+	"lead":               {hint: hintWindow},
+	"similarity":         {hint: hintTextMatching},
+	"levenshtein":        {hint: hintTextMatching},
+	"jsonb_path_exists":  {hint: hintJSONExists},
+}
+
+// operatorAlternatives maps unsupported SQL operators to what to use instead
+var operatorAlternatives = map[string]alternative{
+	"||":  {hint: hintComputed},
+	"+":   {hint: hintArithmetic},
+	"-":   {hint: hintArithmetic},
+	"*":   {hint: hintArithmetic},
+	"/":   {hint: hintArithmetic},
+	"%":   {hint: hintTextMatching},
+	"<->": {hint: hintTextMatching},
+	"<%":  {hint: hintTextMatching},
+	"#>":  {suggestions: []string{"->"}, hint: hintJSONPath},
+	"#>>": {suggestions: []string{"->>"}, hint: hintJSONPath},
+	"?":   {suggestions: []string{"@>"}, hint: hintJSONExists},
+	"?|":  {suggestions: []string{"@>"}, hint: hintJSONExists},
+	"?&":  {suggestions: []string{"@>"}, hint: hintJSONExists},
+	"@?":  {hint: hintJSONExists},
+	"^@":  {suggestions: []string{"LIKE"}, hint: "use like with a trailing wildcard (e.g. code=like.ABC*)"},
+}
+
+// supportedFunctions are the functions the converter understands somewhere
+var supportedFunctions = []string{
+	"count", "sum", "avg", "max", "min",
+	"to_tsvector", "to_tsquery", "plainto_tsquery", "phraseto_tsquery", "websearch_to_tsquery",
+	"int4range", "int8range", "numrange", "tsrange", "tstzrange", "daterange",
+}
+
+// supportedOperators are the operators with a PostgREST filter equivalent
+var supportedOperators = []string{
+	"=", "<>", "!=", "<", "<=", ">", ">=",
+	"~~", "~~*", "!~~", "!~~*", "~", "~*", "!~", "!~*",
+	"@>", "<@", "&&", "<<", ">>", "&<", "&>", "-|-", "@@", "->", "->>",
+}
+
+func unsupportedFunction(name, context string) *UnsupportedError {
+	return newUnsupportedError("function", strings.ToLower(name), context, functionAlternatives, supportedFunctions,
+		"PostgREST cannot call arbitrary functions in a query; expose the logic as a view, a computed column, or an RPC function")
+}
+
+func unsupportedAggregate(name, context string) *UnsupportedError {
+	return newUnsupportedError("aggregate function", strings.ToLower(name), context, functionAlternatives, []string{"count", "sum", "avg", "max", "min"},
+		"only count, sum, avg, max and min are supported")
+}
+
+func unsupportedOperator(op, context string) *UnsupportedError {
+	return newUnsupportedError("operator", op, context, operatorAlternatives, supportedOperators,
+		"PostgREST filters only support comparison, pattern, array/range, JSON arrow and full-text operators")
+}
+
+func newUnsupportedError(kind, name, context string, table map[string]alternative, supported []string, fallbackHint string) *UnsupportedError {
+	err := &UnsupportedError{Kind: kind, Name: name, Context: context}
+
+	if alt, ok := table[name]; ok {
+		err.Suggestions = alt.suggestions
+		err.Hint = alt.hint
+		return err
+	}
+
+	err.Suggestions = nearestNames(name, supported)
+	err.Hint = fallbackHint
+	return err
+}
+
+// nearestNames returns the supported names within a small edit distance,
+// closest first, to catch typos like "cout" or "=<"
+func nearestNames(name string, supported []string) []string {
+	maxDistance := 2
+	if len(name) <= 3 {
+		maxDistance = 1
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	for _, s := range supported {
+		if d := editDistance(name, s); d > 0 && d <= maxDistance {
+			candidates = append(candidates, candidate{s, d})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var names []string
+	for i, cand := range candidates {
+		if i == 3 {
+			break
+		}
+		names = append(names, cand.name)
+	}
+	return names
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Supabase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/multigres/multigres/go/parser/ast"
+)
+
+// extractSetTimezone reads the zone from SET TIME ZONE / SET timezone.
+// SET TIME ZONE LOCAL/DEFAULT returns an empty zone and a warning, since the
+// server default already applies when no Prefer: timezone is sent.
+func (c *Converter) extractSetTimezone(stmt *ast.VariableSetStmt) (string, string, error) {
+	if stmt.Kind == ast.VAR_SET_DEFAULT || stmt.Kind == ast.VAR_RESET {
+		return "", "SET TIME ZONE DEFAULT uses the server time zone; no Prefer header needed", nil
+	}
+	if stmt.Kind != ast.VAR_SET_VALUE || stmt.Args == nil || len(stmt.Args.Items) != 1 {
+		return "", "", &UnsupportedError{Feature: "this SET TIME ZONE form", Hint: "use SET TIME ZONE 'zone name'"}
+	}
+
+	zone, ok := setArgString(stmt.Args.Items[0])
+	if !ok {
+		return "", "", fmt.Errorf("SET TIME ZONE requires a time zone name (numeric offsets cannot be sent as Prefer: timezone)")
+	}
+
+	switch strings.ToLower(zone) {
+	case "local", "default":
+		return "", "SET TIME ZONE " + strings.ToUpper(zone) + " uses the server time zone; no Prefer header needed", nil
+	}
+
+	return zone, "", nil
+}
+
+// setTimezone adds Prefer: timezone=<zone>, rejecting conflicting zones
+func (c *Converter) setTimezone(result *ConversionResult, zone string) error {
+	if existing, ok := preferValue(result, "timezone"); ok {
+		if existing != zone {
+			return fmt.Errorf("conflicting time zones %q and %q: PostgREST applies a single time zone per request", existing, zone)
+		}
+		return nil
+	}
+	addPrefer(result, "timezone="+zone)
+	return nil
+}
+
+// isAtTimeZone matches `col AT TIME ZONE 'zone'`, which the parser produces as
+// pg_catalog.timezone('zone', col)
+func (c *Converter) isAtTimeZone(fn *ast.FuncCall) bool {
+	if fn.Funcname == nil || len(fn.Funcname.Items) == 0 || fn.Args == nil || len(fn.Args.Items) != 2 {
+		return false
+	}
+	nameNode, ok := fn.Funcname.Items[len(fn.Funcname.Items)-1].(*ast.String)
+	return ok && strings.ToLower(nameNode.SVal) == "timezone"
+}
+
+// convertAtTimeZone maps `col AT TIME ZONE 'zone'` in a select list to the
+// plain column plus Prefer: timezone=<zone>. PostgREST has no per-column zone
+// conversion, so the zone applies to every timestamptz value in the response.
+func (c *Converter) convertAtTimeZone(result *ConversionResult, fn *ast.FuncCall, alias string) (string, error) {
+	zoneConst, ok := fn.Args.Items[0].(*ast.A_Const)
+	if !ok {
+		return "", fmt.Errorf("AT TIME ZONE requires a constant time zone name, got %T", fn.Args.Items[0])
+	}
+	zoneStr, ok := zoneConst.Val.(*ast.String)
+	if !ok {
+		return "", fmt.Errorf("AT TIME ZONE requires a time zone name (numeric offsets cannot be sent as Prefer: timezone)")
+	}
+
+	col, ok := fn.Args.Items[1].(*ast.ColumnRef)
+	if !ok {
+		return "", &UnsupportedError{Feature: fmt.Sprintf("AT TIME ZONE on %T", fn.Args.Items[1]), Hint: "AT TIME ZONE can only be applied to a column"}
+	}
+	colName := c.extractColumnName(col)
+
+	if err := c.setTimezone(result, zoneStr.SVal); err != nil {
+		return "", err
+	}
+	result.Warnings = append(result.Warnings, fmt.Sprintf(
+		"%s AT TIME ZONE '%s' mapped to Prefer: timezone=%s; PostgREST applies it to every timestamptz column in the response and returns timestamptz (not timestamp without time zone)",
+		colName, zoneStr.SVal, zoneStr.SVal))
+
+	if alias != "" {
+		return colName + ":" + alias, nil
+	}
+	return colName, nil
+}
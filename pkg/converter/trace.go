@@ -0,0 +1,283 @@
+// Copyright 2025 Supabase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/multigres/multigres/go/parser/ast"
+)
+
+// Trace records which SQL clause produced which part of the request, so
+// tools like the playground can highlight the mapping.
+type Trace struct {
+	SQL      string    `json:"sql"`
+	Mappings []Mapping `json:"mappings"`
+}
+
+// Mapping ties one request fragment to the clause that produced it
+type Mapping struct {
+	Clause string `json:"clause"`        // SQL clause, e.g. "WHERE" or "ORDER BY"
+	SQL    string `json:"sql,omitempty"` // The clause fragment, deparsed
+	Target string `json:"target"`        // "path", "query", "header" or "body"
+	Key    string `json:"key,omitempty"` // Query param or header name
+	Value  string `json:"value"`
+}
+
+// Mapping targets
+const (
+	TargetPath   = "path"
+	TargetQuery  = "query"
+	TargetHeader = "header"
+	TargetBody   = "body"
+)
+
+// ConvertWithTrace converts like Convert and also records a Trace in the
+// result. Tracing is opt-in because it snapshots the request after each clause.
+func (c *Converter) ConvertWithTrace(sql string) (*ConversionResult, error) {
+	traced := *c
+	traced.trace = &Trace{SQL: sql}
+
+	result, err := traced.Convert(sql)
+	if err != nil {
+		return nil, err
+	}
+	result.Trace = traced.trace
+	return result, nil
+}
+
+// ConvertWithTraceToJSON is ConvertToJSON with the trace included
+func (c *Converter) ConvertWithTraceToJSON(sql string) (string, error) {
+	result, err := c.ConvertWithTrace(sql)
+	if err != nil {
+		return "", err
+	}
+
+	jsonBytes, err := json.Marshal(c.jsonOutput(result))
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonBytes), nil
+}
+
+// requestSnapshot is a copy of the request parts a clause can change
+type requestSnapshot struct {
+	path    string
+	params  url.Values
+	headers map[string]string
+	body    string
+}
+
+func snapshotRequest(result *ConversionResult) requestSnapshot {
+	snap := requestSnapshot{
+		path:    result.Path,
+		params:  url.Values{},
+		headers: map[string]string{},
+		body:    result.Body,
+	}
+	for key, values := range result.QueryParams {
+		snap.params[key] = append([]string(nil), values...)
+	}
+	for key, value := range result.Headers {
+		snap.headers[key] = value
+	}
+	return snap
+}
+
+// traceStep runs one clause conversion step and, when tracing, attributes
+// whatever it added to the request to that clause
+func (c *Converter) traceStep(result *ConversionResult, clause, fragment string, step func() error) error {
+	if c.trace == nil {
+		return step()
+	}
+
+	before := snapshotRequest(result)
+	if err := step(); err != nil {
+		return err
+	}
+	c.trace.record(clause, fragment, before, result)
+	return nil
+}
+
+// traceSet is traceStep for a step that cannot fail, such as setting the
+// path or body
+func (c *Converter) traceSet(result *ConversionResult, clause, fragment string, set func()) {
+	if c.trace == nil {
+		set()
+		return
+	}
+
+	before := snapshotRequest(result)
+	set()
+	c.trace.record(clause, fragment, before, result)
+}
+
+// traceWhere converts a WHERE clause one top-level AND condition at a time,
+// so each condition maps to its own filters
+func (c *Converter) traceWhere(result *ConversionResult, whereClause ast.Node, add func(ast.Node) error) error {
+	if c.trace == nil {
+		return add(whereClause)
+	}
+
+	for _, cond := range andConditions(whereClause) {
+		if err := c.traceStep(result, "WHERE", cond.SqlString(), func() error { return add(cond) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// andConditions flattens top-level ANDs into their conditions
+func andConditions(node ast.Node) []ast.Node {
+	switch expr := node.(type) {
+	case *ast.ParenExpr:
+		if inner, ok := expr.Expr.(*ast.BoolExpr); ok && inner.Boolop == ast.AND_EXPR {
+			return andConditions(inner)
+		}
+	case *ast.BoolExpr:
+		if expr.Boolop == ast.AND_EXPR {
+			var conds []ast.Node
+			for _, arg := range expr.Args.Items {
+				conds = append(conds, andConditions(arg)...)
+			}
+			return conds
+		}
+	}
+	return []ast.Node{node}
+}
+
+// listSQL deparses a clause list such as a target list or ORDER BY items
+func listSQL(list *ast.NodeList) string {
+	if list == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		parts = append(parts, item.SqlString())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// setListSQL deparses UPDATE SET assignments, which ResTarget renders as
+// "value AS column"
+func setListSQL(list *ast.NodeList) string {
+	parts := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		if target, ok := item.(*ast.ResTarget); ok && target.Val != nil {
+			parts = append(parts, target.Name+" = "+target.Val.SqlString())
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// nodeSQL deparses a single clause node, tolerating nil
+func nodeSQL(node ast.Node) string {
+	if node == nil {
+		return ""
+	}
+	return node.SqlString()
+}
+
+// record diffs the request against the snapshot taken before a step
+func (t *Trace) record(clause, fragment string, before requestSnapshot, result *ConversionResult) {
+	add := func(target, key, value string) {
+		t.Mappings = append(t.Mappings, Mapping{Clause: clause, SQL: fragment, Target: target, Key: key, Value: value})
+	}
+
+	if result.Path != before.path {
+		t.forget(TargetPath, "")
+		add(TargetPath, "", result.Path)
+	}
+
+	keys := make([]string, 0, len(result.QueryParams))
+	for key := range result.QueryParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		old, values := before.params[key], result.QueryParams[key]
+		if isPrefix(old, values) {
+			for _, value := range values[len(old):] {
+				add(TargetQuery, key, value)
+			}
+			continue
+		}
+		// The step replaced earlier values, so they no longer map anywhere
+		t.forget(TargetQuery, key)
+		for _, value := range values {
+			add(TargetQuery, key, value)
+		}
+	}
+	for key := range before.params {
+		if _, ok := result.QueryParams[key]; !ok {
+			t.forget(TargetQuery, key)
+		}
+	}
+
+	keys = keys[:0]
+	for key := range result.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		old, value := before.headers[key], result.Headers[key]
+		switch {
+		case value == old:
+		case old != "" && strings.HasPrefix(value, old+","):
+			// Appended preference, e.g. Prefer: return=representation,timezone=UTC
+			add(TargetHeader, key, strings.TrimPrefix(value, old+","))
+		default:
+			t.forget(TargetHeader, key)
+			add(TargetHeader, key, value)
+		}
+	}
+	for key := range before.headers {
+		if _, ok := result.Headers[key]; !ok {
+			t.forget(TargetHeader, key)
+		}
+	}
+
+	if result.Body != before.body {
+		t.forget(TargetBody, "")
+		add(TargetBody, "", result.Body)
+	}
+}
+
+// forget drops mappings for a fragment that a later step replaced or removed
+func (t *Trace) forget(target, key string) {
+	kept := t.Mappings[:0]
+	for _, m := range t.Mappings {
+		if m.Target != target || m.Key != key {
+			kept = append(kept, m)
+		}
+	}
+	t.Mappings = kept
+}
+
+func isPrefix(prefix, values []string) bool {
+	if len(prefix) > len(values) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != values[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -37,10 +37,11 @@ func (c *Converter) convertUpdate(stmt *ast.UpdateStmt) (*ConversionResult, erro
 	if stmt.Relation.SchemaName != "" {
 		tableName = stmt.Relation.SchemaName + "." + tableName
 	}
-	result.Path = "/" + tableName
-
-	result.Headers["Content-Type"] = "application/json"
-	result.Headers["Prefer"] = "return=representation"
+	c.traceSet(result, "UPDATE", stmt.Relation.SqlString(), func() {
+		result.Path = "/" + tableName
+		result.Headers["Content-Type"] = "application/json"
+		result.Headers["Prefer"] = "return=representation"
+	})
 
 	if stmt.TargetList == nil || len(stmt.TargetList.Items) == 0 {
 		return nil, fmt.Errorf("UPDATE statement missing SET clause")
@@ -73,20 +74,24 @@ func (c *Converter) convertUpdate(stmt *ast.UpdateStmt) (*ConversionResult, erro
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal body: %w", err)
 	}
-	result.Body = string(bodyBytes)
+	c.traceSet(result, "SET", setListSQL(stmt.TargetList), func() {
+		result.Body = string(bodyBytes)
+	})
 
 	if stmt.WhereClause != nil {
-		if err := c.addWhereClause(result, stmt.WhereClause); err != nil {
+		if err := c.traceWhere(result, stmt.WhereClause, func(cond ast.Node) error {
+			return c.addWhereClause(result, cond)
+		}); err != nil {
 			return nil, fmt.Errorf("failed to process WHERE clause: %w", err)
 		}
 	}
 
 	if stmt.FromClause != nil {
-		return nil, fmt.Errorf("UPDATE with FROM clause not supported")
+		return nil, &UnsupportedError{Feature: "UPDATE with FROM clause"}
 	}
 
 	if stmt.ReturningList != nil {
-		return nil, fmt.Errorf("RETURNING clause not yet supported")
+		return nil, &UnsupportedError{Feature: "RETURNING clause"}
 	}
 
 	return result, nil
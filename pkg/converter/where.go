@@ -31,8 +31,10 @@ func (c *Converter) addWhereClause(result *ConversionResult, whereClause ast.Nod
 		return c.addBoolExpr(result, expr)
 	case *ast.NullTest:
 		return c.addNullTest(result, expr)
+	case *ast.SubLink:
+		return c.addInValuesCondition(result, expr, false)
 	default:
-		return fmt.Errorf("unsupported WHERE clause type: %T", whereClause)
+		return &UnsupportedError{Feature: fmt.Sprintf("WHERE clause type %T", whereClause)}
 	}
 }
 
@@ -81,7 +83,7 @@ func (c *Converter) addSimpleCondition(result *ConversionResult, expr *ast.A_Exp
 	case ast.AEXPR_OP:
 		return c.addOperatorCondition(result, expr)
 	default:
-		return fmt.Errorf("unsupported A_Expr kind: %d", expr.Kind)
+		return &UnsupportedError{Feature: fmt.Sprintf("A_Expr kind %d", expr.Kind)}
 	}
 }
 
@@ -175,6 +177,60 @@ func (c *Converter) addInCondition(result *ConversionResult, expr *ast.A_Expr, n
 	return nil
 }
 
+// addInValuesCondition handles the ORM-style `col IN (VALUES (1), (2))` form,
+// which PostgREST expresses the same way as a plain IN list.
+func (c *Converter) addInValuesCondition(result *ConversionResult, sublink *ast.SubLink, negate bool) error {
+	colName, values, err := c.extractInValuesList(sublink)
+	if err != nil {
+		return err
+	}
+
+	op := "in.(" + strings.Join(values, ",") + ")"
+	if negate {
+		op = "not." + op
+	}
+	result.QueryParams.Add(colName, op)
+	return nil
+}
+
+func (c *Converter) extractInValuesList(sublink *ast.SubLink) (string, []string, error) {
+	if sublink.SubLinkType != ast.ANY_SUBLINK || sublink.Testexpr == nil {
+		return "", nil, &UnsupportedError{Feature: "subqueries in WHERE", Hint: "use embedded resource filters or a view"}
+	}
+
+	if sublink.OperName != nil && len(sublink.OperName.Items) > 0 {
+		if opNode, ok := sublink.OperName.Items[0].(*ast.String); ok && opNode.SVal != "=" {
+			return "", nil, &UnsupportedError{Feature: opNode.SVal + " ANY (subquery)"}
+		}
+	}
+
+	colRef, ok := sublink.Testexpr.(*ast.ColumnRef)
+	if !ok {
+		return "", nil, fmt.Errorf("IN: left side must be a column reference, got: %T", sublink.Testexpr)
+	}
+	colName := c.stripTablePrefix(c.extractColumnName(colRef))
+
+	selectStmt, ok := sublink.Subselect.(*ast.SelectStmt)
+	if !ok || selectStmt.ValuesLists == nil || len(selectStmt.ValuesLists.Items) == 0 {
+		return "", nil, &UnsupportedError{Feature: "IN (subquery)", Hint: "only IN (VALUES ...) lists can be converted"}
+	}
+
+	var values []string
+	for _, row := range selectStmt.ValuesLists.Items {
+		rowList, ok := row.(*ast.NodeList)
+		if !ok || len(rowList.Items) != 1 {
+			return "", nil, fmt.Errorf("IN (VALUES ...): each row must contain exactly one value")
+		}
+		val, err := c.extractWhereValue(rowList.Items[0])
+		if err != nil {
+			return "", nil, fmt.Errorf("IN (VALUES ...): failed to extract value: %w", err)
+		}
+		values = append(values, val)
+	}
+
+	return colName, values, nil
+}
+
 func (c *Converter) addBetweenCondition(result *ConversionResult, expr *ast.A_Expr, negate bool) error {
 	colRef, ok := expr.Lexpr.(*ast.ColumnRef)
 	if !ok {
@@ -275,14 +331,11 @@ func (c *Converter) addDistinctCondition(result *ConversionResult, expr *ast.A_E
 }
 
 func (c *Converter) addFullTextSearch(result *ConversionResult, expr *ast.A_Expr) error {
-	colRef, ok := expr.Lexpr.(*ast.ColumnRef)
-	if !ok {
-		return fmt.Errorf("FTS: left side must be a column reference")
+	colName, vectorLanguage, err := c.extractTsvectorColumn(expr.Lexpr)
+	if err != nil {
+		return err
 	}
 
-	colName := c.extractColumnName(colRef)
-	colName = c.stripTablePrefix(colName)
-
 	fn, ok := expr.Rexpr.(*ast.FuncCall)
 	if !ok {
 		return fmt.Errorf("FTS: right side must be a function call (to_tsquery, plainto_tsquery, etc.)")
@@ -313,7 +366,7 @@ func (c *Converter) addFullTextSearch(result *ConversionResult, expr *ast.A_Expr
 	case "websearch_to_tsquery":
 		ftsOp = "wfts"
 	default:
-		return fmt.Errorf("FTS: unsupported function: %s (use to_tsquery, plainto_tsquery, phraseto_tsquery, or websearch_to_tsquery)", funcName)
+		return &UnsupportedError{Kind: "function", Name: funcName, Context: "full-text search", Hint: "use to_tsquery, plainto_tsquery, phraseto_tsquery, or websearch_to_tsquery"}
 	}
 
 	if fn.Args == nil || len(fn.Args.Items) == 0 {
@@ -342,6 +395,13 @@ func (c *Converter) addFullTextSearch(result *ConversionResult, expr *ast.A_Expr
 		return fmt.Errorf("FTS: %s accepts 1 or 2 arguments", funcName)
 	}
 
+	if vectorLanguage != "" {
+		if language != "" && language != vectorLanguage {
+			return fmt.Errorf("FTS: to_tsvector uses %s but %s uses %s - PostgREST applies one text search configuration to both sides", vectorLanguage, funcName, language)
+		}
+		language = vectorLanguage
+	}
+
 	var value string
 	if language != "" {
 		value = ftsOp + "(" + language + ")." + searchTerm
@@ -353,6 +413,46 @@ func (c *Converter) addFullTextSearch(result *ConversionResult, expr *ast.A_Expr
 	return nil
 }
 
+// extractTsvectorColumn returns the column searched by @@, accepting either a
+// bare column or to_tsvector([config,] column). PostgREST wraps text columns
+// in to_tsvector itself, so the wrapper maps to the same filter.
+func (c *Converter) extractTsvectorColumn(node ast.Node) (string, string, error) {
+	switch left := node.(type) {
+	case *ast.ColumnRef:
+		return c.stripTablePrefix(c.extractColumnName(left)), "", nil
+
+	case *ast.FuncCall:
+		if left.Funcname == nil || len(left.Funcname.Items) == 0 {
+			return "", "", fmt.Errorf("FTS: function name is empty")
+		}
+		nameNode, ok := left.Funcname.Items[len(left.Funcname.Items)-1].(*ast.String)
+		if !ok || strings.ToLower(nameNode.SVal) != "to_tsvector" {
+			return "", "", fmt.Errorf("FTS: left side must be a column or to_tsvector(column)")
+		}
+		if left.Args == nil || len(left.Args.Items) == 0 || len(left.Args.Items) > 2 {
+			return "", "", fmt.Errorf("FTS: to_tsvector accepts 1 or 2 arguments")
+		}
+
+		var language string
+		if len(left.Args.Items) == 2 {
+			lang, err := c.extractWhereValue(left.Args.Items[0])
+			if err != nil {
+				return "", "", fmt.Errorf("FTS: failed to extract to_tsvector language: %w", err)
+			}
+			language = lang
+		}
+
+		colRef, ok := left.Args.Items[len(left.Args.Items)-1].(*ast.ColumnRef)
+		if !ok {
+			return "", "", fmt.Errorf("FTS: to_tsvector must wrap a single column - for expressions over several columns, create a generated tsvector column and search that")
+		}
+		return c.stripTablePrefix(c.extractColumnName(colRef)), language, nil
+
+	default:
+		return "", "", fmt.Errorf("FTS: left side must be a column or to_tsvector(column)")
+	}
+}
+
 func (c *Converter) addBoolExpr(result *ConversionResult, expr *ast.BoolExpr) error {
 	switch expr.Boolop {
 	case ast.AND_EXPR:
@@ -382,7 +482,7 @@ func (c *Converter) addBoolExpr(result *ConversionResult, expr *ast.BoolExpr) er
 		return c.addNotExpr(result, expr.Args.Items[0])
 
 	default:
-		return fmt.Errorf("unsupported boolean operation: %v", expr.Boolop)
+		return &UnsupportedError{Feature: fmt.Sprintf("boolean operation %v", expr.Boolop)}
 	}
 }
 
@@ -426,7 +526,7 @@ func (c *Converter) extractOrCondition(node ast.Node) (string, error) {
 			return "not." + part, nil
 
 		default:
-			return "", fmt.Errorf("unsupported boolean operation in OR: %v", expr.Boolop)
+			return "", &UnsupportedError{Feature: fmt.Sprintf("boolean operation %v in OR", expr.Boolop)}
 		}
 
 	case *ast.A_Expr:
@@ -617,7 +717,7 @@ func (c *Converter) extractOrCondition(node ast.Node) (string, error) {
 					return "", fmt.Errorf("failed to extract JSON path: %w", err)
 				}
 			} else if _, ok := expr.Lexpr.(*ast.FuncCall); ok {
-				return "", fmt.Errorf("function calls on left side in OR conditions not yet supported")
+				return "", &UnsupportedError{Feature: "function calls on left side in OR conditions"}
 			} else {
 				return "", fmt.Errorf("left side must be a column reference or JSON path, got: %T", expr.Lexpr)
 			}
@@ -635,7 +735,7 @@ func (c *Converter) extractOrCondition(node ast.Node) (string, error) {
 			return colName + "." + postgrestOp, nil
 
 		default:
-			return "", fmt.Errorf("unsupported A_Expr kind in OR: %d", expr.Kind)
+			return "", &UnsupportedError{Feature: fmt.Sprintf("A_Expr kind %d in OR", expr.Kind)}
 		}
 
 	case *ast.NullTest:
@@ -652,10 +752,17 @@ func (c *Converter) extractOrCondition(node ast.Node) (string, error) {
 		} else if expr.Nulltesttype == ast.IS_NOT_NULL {
 			return colName + ".not.is.null", nil
 		}
-		return "", fmt.Errorf("unsupported NULL test type")
+		return "", &UnsupportedError{Feature: "NULL test type"}
+
+	case *ast.SubLink:
+		colName, values, err := c.extractInValuesList(expr)
+		if err != nil {
+			return "", err
+		}
+		return colName + ".in.(" + strings.Join(values, ",") + ")", nil
 
 	default:
-		return "", fmt.Errorf("unsupported OR condition type: %T", node)
+		return "", &UnsupportedError{Feature: fmt.Sprintf("OR condition type %T", node)}
 	}
 }
 
@@ -680,7 +787,7 @@ func (c *Converter) addNullTest(result *ConversionResult, expr *ast.NullTest) er
 	} else if expr.Nulltesttype == ast.IS_NOT_NULL {
 		result.QueryParams.Add(colName, "not.is.null")
 	} else {
-		return fmt.Errorf("unsupported NULL test type")
+		return &UnsupportedError{Feature: "NULL test type"}
 	}
 
 	return nil
@@ -710,10 +817,12 @@ func (c *Converter) addNotExpr(result *ConversionResult, node ast.Node) error {
 		case ast.AEXPR_OP:
 			return c.addOperatorConditionNegated(result, expr)
 		default:
-			return fmt.Errorf("unsupported NOT expression kind: %d", expr.Kind)
+			return &UnsupportedError{Feature: fmt.Sprintf("NOT expression kind %d", expr.Kind)}
 		}
+	case *ast.SubLink:
+		return c.addInValuesCondition(result, expr, true)
 	default:
-		return fmt.Errorf("unsupported NOT expression type: %T", node)
+		return &UnsupportedError{Feature: fmt.Sprintf("NOT expression type %T", node)}
 	}
 }
 
@@ -798,7 +907,7 @@ func (c *Converter) mapOperator(sqlOp string, value string) (string, error) {
 	case "-|-":
 		return "adj." + value, nil
 	default:
-		return "", fmt.Errorf("unsupported operator: %s", sqlOp)
+		return "", unsupportedOperator(sqlOp, "WHERE")
 	}
 }
 
@@ -818,11 +927,11 @@ func (c *Converter) extractWhereValue(node ast.Node) (string, error) {
 				}
 			}
 		}
-		return "", fmt.Errorf("complex expressions in WHERE not supported")
+		return "", &UnsupportedError{Feature: "complex expressions in WHERE"}
 	case *ast.FuncCall:
 		return c.extractFunctionValue(val)
 	default:
-		return "", fmt.Errorf("unsupported value type in WHERE: %T", node)
+		return "", &UnsupportedError{Feature: fmt.Sprintf("value type %T in WHERE", node)}
 	}
 }
 
@@ -848,7 +957,7 @@ func (c *Converter) extractConstValue(aConst *ast.A_Const) (string, error) {
 	case *ast.Null:
 		return "null", nil
 	default:
-		return "", fmt.Errorf("unsupported const type: %T", aConst.Val)
+		return "", &UnsupportedError{Feature: fmt.Sprintf("const type %T", aConst.Val)}
 	}
 }
 
@@ -896,7 +1005,7 @@ func (c *Converter) extractFunctionValue(fn *ast.FuncCall) (string, error) {
 		}
 		return "(" + arg1 + "," + arg2 + ")", nil
 	default:
-		return "", fmt.Errorf("unsupported function in WHERE: %s", funcName)
+		return "", unsupportedFunction(funcName, "WHERE")
 	}
 }
 
@@ -984,5 +1093,5 @@ func (c *Converter) addFunctionOperatorCondition(result *ConversionResult, expr
 		return nil
 	}
 
-	return fmt.Errorf("unsupported function on left side of operator: %s", funcName)
+	return unsupportedFunction(funcName, "WHERE")
 }
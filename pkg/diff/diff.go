@@ -0,0 +1,71 @@
+// Package diff compares rendered output line by line, for watch modes that
+// print what changed between two conversions of the same file.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Statements compares the rendered statements of two versions of a file,
+// statement by statement, and returns the changed ones as Lines under a
+// "@@ statement N @@" header. It returns "" when nothing changed.
+func Statements(before, after [][]string) string {
+	var b strings.Builder
+	for i := 0; i < len(before) || i < len(after); i++ {
+		var old, cur []string
+		if i < len(before) {
+			old = before[i]
+		}
+		if i < len(after) {
+			cur = after[i]
+		}
+		if strings.Join(old, "\n") == strings.Join(cur, "\n") {
+			continue
+		}
+
+		fmt.Fprintf(&b, "@@ statement %d @@\n", i+1)
+		b.WriteString(Lines(old, cur))
+	}
+	return b.String()
+}
+
+// Lines returns the line diff of old and cur: lines only in old with "- ",
+// lines only in cur with "+ " and common lines indented, following their
+// longest common subsequence. Removed lines come before the lines that
+// replace them.
+func Lines(old, cur []string) string {
+	// lcs[i][j] is the length of the longest common subsequence of old[i:]
+	// and cur[j:]
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(cur)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(cur) - 1; j >= 0; j-- {
+			if old[i] == cur[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var b strings.Builder
+	i, j := 0, 0
+	for i < len(old) || j < len(cur) {
+		switch {
+		case i < len(old) && j < len(cur) && old[i] == cur[j]:
+			b.WriteString("  " + old[i] + "\n")
+			i++
+			j++
+		case i < len(old) && (j == len(cur) || lcs[i+1][j] >= lcs[i][j+1]):
+			b.WriteString("- " + old[i] + "\n")
+			i++
+		default:
+			b.WriteString("+ " + cur[j] + "\n")
+			j++
+		}
+	}
+	return b.String()
+}
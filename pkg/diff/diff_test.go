@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		old  []string
+		cur  []string
+		want string
+	}{
+		{
+			name: "unchanged",
+			old:  []string{"GET /users", "Accept: text/csv"},
+			cur:  []string{"GET /users", "Accept: text/csv"},
+			want: "  GET /users\n  Accept: text/csv\n",
+		},
+		{
+			name: "changed line is removed then added",
+			old:  []string{"GET /users?age=gt.18", "Accept: text/csv"},
+			cur:  []string{"GET /users?age=gt.21", "Accept: text/csv"},
+			want: "- GET /users?age=gt.18\n+ GET /users?age=gt.21\n  Accept: text/csv\n",
+		},
+		{
+			name: "added lines",
+			old:  []string{"POST /users"},
+			cur:  []string{"POST /users", "Prefer: return=representation", "", `{"name":"Alice"}`},
+			want: "  POST /users\n+ Prefer: return=representation\n+ \n+ {\"name\":\"Alice\"}\n",
+		},
+		{
+			name: "removed lines",
+			old:  []string{"GET /users", "warning: ignored SET"},
+			cur:  []string{"GET /users"},
+			want: "  GET /users\n- warning: ignored SET\n",
+		},
+		{
+			name: "common lines kept around an insertion",
+			old:  []string{"a", "b", "c"},
+			cur:  []string{"a", "x", "b", "c"},
+			want: "  a\n+ x\n  b\n  c\n",
+		},
+		{
+			name: "everything replaced",
+			old:  []string{"a", "b"},
+			cur:  []string{"c"},
+			want: "- a\n- b\n+ c\n",
+		},
+		{
+			name: "from nothing",
+			cur:  []string{"GET /users"},
+			want: "+ GET /users\n",
+		},
+		{
+			name: "both empty",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Lines(tt.old, tt.cur))
+		})
+	}
+}
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		before [][]string
+		after  [][]string
+		want   string
+	}{
+		{
+			name:   "unchanged",
+			before: [][]string{{"GET /users"}, {"DELETE /users?id=eq.1"}},
+			after:  [][]string{{"GET /users"}, {"DELETE /users?id=eq.1"}},
+			want:   "",
+		},
+		{
+			name:   "only changed statements are listed",
+			before: [][]string{{"GET /users"}, {"DELETE /users?id=eq.1"}},
+			after:  [][]string{{"GET /users"}, {"DELETE /users?id=eq.2"}},
+			want:   "@@ statement 2 @@\n- DELETE /users?id=eq.1\n+ DELETE /users?id=eq.2\n",
+		},
+		{
+			name:   "statement added",
+			before: [][]string{{"GET /users"}},
+			after:  [][]string{{"GET /users"}, {"GET /posts"}},
+			want:   "@@ statement 2 @@\n+ GET /posts\n",
+		},
+		{
+			name:   "statement removed",
+			before: [][]string{{"GET /users"}, {"GET /posts"}},
+			after:  [][]string{{"GET /users"}},
+			want:   "@@ statement 2 @@\n- GET /posts\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Statements(tt.before, tt.after))
+		})
+	}
+}
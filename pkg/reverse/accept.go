@@ -0,0 +1,87 @@
+package reverse
+
+import (
+	"mime"
+	"slices"
+	"strings"
+)
+
+const (
+	objectMediaType = "application/vnd.pgrst.object+json"
+	planMediaType   = "application/vnd.pgrst.plan"
+)
+
+// planOptions are the options=... PostgREST accepts on the plan media type,
+// in the order EXPLAIN takes them
+var planOptions = []string{"analyze", "verbose", "settings", "buffers", "wal"}
+
+// singleObject reports whether the Accept header asks for a single JSON
+// object instead of an array
+func singleObject(req *PostgRESTRequest) bool {
+	for _, mediaType := range strings.Split(req.Headers["Accept"], ",") {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case objectMediaType, "application/vnd.pgrst.object":
+			return true
+		}
+	}
+	return false
+}
+
+// limitSingleRow caps a read at one row, keeping a smaller explicit limit
+func limitSingleRow(req *PostgRESTRequest) {
+	if req.Limit == nil || *req.Limit > 1 {
+		limit := 1
+		req.Limit = &limit
+	}
+}
+
+// applyPlanAccept prefixes the statement with EXPLAIN for
+// Accept: application/vnd.pgrst.plan+text|json, which makes PostgREST
+// return the execution plan instead of the rows. options=analyze|verbose
+// become EXPLAIN options.
+func applyPlanAccept(req *PostgRESTRequest, result *SQLResult) {
+	for _, part := range strings.Split(req.Headers["Accept"], ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		format := ""
+		switch mediaType {
+		case planMediaType, planMediaType + "+text":
+			format = "TEXT"
+		case planMediaType + "+json":
+			format = "JSON"
+		default:
+			continue
+		}
+
+		requested := map[string]bool{}
+		if options := params["options"]; options != "" {
+			for _, option := range strings.Split(options, "|") {
+				option = strings.ToLower(strings.TrimSpace(option))
+				if !slices.Contains(planOptions, option) {
+					result.Warnings = append(result.Warnings, "unknown plan option "+option+" ignored")
+				}
+				requested[option] = true
+			}
+		}
+
+		var explain []string
+		for _, option := range planOptions {
+			if requested[option] {
+				explain = append(explain, strings.ToUpper(option))
+			}
+		}
+		explain = append(explain, "FORMAT "+format)
+
+		result.SQL = "EXPLAIN (" + strings.Join(explain, ", ") + ") " + result.SQL
+		result.Metadata["plan"] = strings.ToLower(format)
+		if req.Method != "GET" && req.Method != "HEAD" && requested["analyze"] {
+			result.Warnings = append(result.Warnings, "EXPLAIN ANALYZE runs the statement, so the mutation takes effect unless it is rolled back (Prefer: tx=rollback)")
+		}
+		result.Warnings = append(result.Warnings, "PostgREST returns the plan only when db-plan-enabled is on")
+		return
+	}
+}
@@ -0,0 +1,66 @@
+package reverse
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// limitedMutation reports whether limit= or offset= restricts a PATCH or
+// DELETE to part of the filtered rows
+func limitedMutation(req *PostgRESTRequest) bool {
+	return req.Limit != nil || req.Offset != nil
+}
+
+// limitedWhereClause restricts a mutation to the first rows of the filtered
+// set, in order, through a ctid subquery, since UPDATE and DELETE take no
+// ORDER BY or LIMIT of their own:
+//
+//	WHERE ctid IN (SELECT ctid FROM tasks WHERE done IS FALSE ORDER BY id ASC LIMIT 5)
+func limitedWhereClause(req *PostgRESTRequest, whereClause string) string {
+	subquery := "SELECT ctid FROM " + qualifiedName(req, req.Table)
+	if whereClause != "" {
+		subquery += " " + whereClause
+	}
+	if orderByClause := buildOrderByClause(req.Order); orderByClause != "" {
+		subquery += " " + orderByClause
+	}
+	if limitOffsetClause := buildLimitOffsetClause(req.Limit, req.Offset); limitOffsetClause != "" {
+		subquery += " " + limitOffsetClause
+	}
+	return "WHERE ctid IN (" + subquery + ")"
+}
+
+// mutationLimitNotes explains limit/order on a mutation and records
+// Prefer: max-affected in metadata (max_affected)
+func mutationLimitNotes(req *PostgRESTRequest, metadata map[string]string) []string {
+	var notes []string
+
+	switch {
+	case !limitedMutation(req):
+		if len(req.Order) > 0 {
+			notes = append(notes, "order has no effect on a mutation without limit")
+		}
+	case req.Method == "PATCH" || req.Method == "DELETE":
+		notes = append(notes, "limit on a mutation selects the affected rows by ctid in a subquery; ctid is only stable within the statement")
+		if len(req.Order) == 0 {
+			notes = append(notes, "limit without order affects arbitrary matching rows; add order= on a unique column")
+		}
+	default:
+		notes = append(notes, "limit and offset have no effect on "+req.Method)
+	}
+
+	prefs := preferences(req)
+	value, ok := prefs["max-affected"]
+	if !ok {
+		return notes
+	}
+	maxAffected, err := strconv.Atoi(value)
+	if err != nil || maxAffected < 0 {
+		return append(notes, "invalid Prefer max-affected "+value+" ignored")
+	}
+	if prefs["handling"] != "strict" {
+		return append(notes, "Prefer: max-affected only applies with handling=strict")
+	}
+	metadata["max_affected"] = value
+	return append(notes, fmt.Sprintf("PostgREST fails the request and rolls it back when more than %d rows are affected; the SQL does not check this", maxAffected))
+}
@@ -0,0 +1,59 @@
+package reverse
+
+import (
+	"strings"
+)
+
+// aggregateFunctions are the aggregates PostgREST accepts in select
+var aggregateFunctions = map[string]bool{
+	"count": true,
+	"sum":   true,
+	"avg":   true,
+	"max":   true,
+	"min":   true,
+}
+
+// parseAggregate recognizes PostgREST aggregate columns
+// Examples: "count()" -> count of rows, "total.sum()" -> sum of total
+func parseAggregate(column string) (function, arg string, ok bool) {
+	call, found := strings.CutSuffix(column, "()")
+	if !found {
+		return "", "", false
+	}
+
+	if call == "count" {
+		return call, "", true
+	}
+
+	i := strings.LastIndex(call, ".")
+	if i == -1 || !aggregateFunctions[call[i+1:]] || strings.ContainsAny(call[:i], "()") {
+		return "", "", false
+	}
+	return call[i+1:], call[:i], true
+}
+
+// isAggregateItem reports whether a select item, with any alias or cast, is
+// an aggregate rather than an embedded resource
+func isAggregateItem(item string) bool {
+	column, _, _ := parseSelectItem(item)
+	_, _, ok := parseAggregate(column)
+	return ok
+}
+
+// aggregateSQL renders an aggregate call, qualifying its argument by table
+func aggregateSQL(function, arg, table string) string {
+	name := strings.ToUpper(function)
+	if arg == "" {
+		if table == "" {
+			return name + "(*)"
+		}
+		// table.* is NULL for unmatched LEFT JOIN rows, so they count as 0
+		return name + "(" + identifierSQL(table) + ".*)"
+	}
+
+	expr := arg
+	if table != "" {
+		expr = table + "." + arg
+	}
+	return name + "(" + columnSQL(expr) + ")"
+}
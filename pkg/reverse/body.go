@@ -0,0 +1,84 @@
+package reverse
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// decodeBody decodes a request body by its Content-Type: JSON by default,
+// CSV (text/csv) for bulk inserts or form fields for a single row
+func decodeBody(contentType string, body []byte) (interface{}, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "text/csv":
+		return decodeCSVBody(body)
+	case "application/x-www-form-urlencoded":
+		return decodeFormBody(body)
+	default:
+		var bodyData interface{}
+		if err := json.Unmarshal(body, &bodyData); err != nil {
+			return nil, NewSyntaxError("invalid JSON body", string(body), "ensure body is valid JSON")
+		}
+		return bodyData, nil
+	}
+}
+
+// decodeCSVBody turns a CSV payload into rows keyed by the header row.
+// As in PostgREST, values are text and the word NULL is the SQL NULL.
+func decodeCSVBody(body []byte) (interface{}, error) {
+	reader := csv.NewReader(strings.NewReader(string(body)))
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, NewSyntaxError(
+			fmt.Sprintf("invalid CSV body: %v", err),
+			string(body),
+			"quote fields containing commas, quotes or line breaks",
+		)
+	}
+	if len(records) < 2 {
+		return nil, NewSyntaxError(
+			"CSV body needs a header row and at least one data row",
+			string(body),
+			"start the body with the column names, e.g. name,age",
+		)
+	}
+
+	header := records[0]
+	rows := make([]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if record[i] == "NULL" {
+				row[column] = nil
+			} else {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// decodeFormBody turns name=Alice&age=30 into a single row of text values
+func decodeFormBody(body []byte) (interface{}, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, NewSyntaxError(
+			fmt.Sprintf("invalid form body: %v", err),
+			string(body),
+			"URL-encode the fields, e.g. name=Alice&age=30",
+		)
+	}
+
+	row := make(map[string]interface{}, len(values))
+	for column := range values {
+		row[column] = values.Get(column)
+	}
+	return row, nil
+}
@@ -2,49 +2,125 @@ package reverse
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 )
 
 // Converter converts PostgREST requests to SQL
 type Converter struct {
-	baseURL string
+	baseURL    string
+	parameters bool
+	pretty     bool
+}
+
+// Option configures optional Converter behavior
+type Option func(*Converter)
+
+// WithBaseURL sets the PostgREST URL that SQLResult.HTTPRequest.URL starts
+// with, e.g. http://localhost:3000 or https://xyz.supabase.co/rest/v1
+func WithBaseURL(baseURL string) Option {
+	return func(c *Converter) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithParameters makes the converter emit $1, $2, ... placeholders with the
+// values in SQLResult.Args instead of inlined literals, ready for pgx or
+// lib/pq
+func WithParameters() Option {
+	return func(c *Converter) {
+		c.parameters = true
+	}
+}
+
+// WithPrettySQL lays the generated SQL out over multiple indented lines
+// (see FormatSQL) instead of a single line
+func WithPrettySQL() Option {
+	return func(c *Converter) {
+		c.pretty = true
+	}
 }
 
 // NewConverter creates a new reverse converter
-func NewConverter() *Converter {
-	return &Converter{}
+func NewConverter(opts ...Option) *Converter {
+	c := &Converter{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Convert converts a PostgREST request to SQL
 func (c *Converter) Convert(method, path, query, body string) (*SQLResult, error) {
+	return c.ConvertWithHeaders(method, path, query, body, nil)
+}
+
+// ConvertWithHeaders converts a PostgREST request to SQL, honoring request
+// headers such as Prefer that change the generated statement
+func (c *Converter) ConvertWithHeaders(method, path, query, body string, headers map[string]string) (*SQLResult, error) {
+	return c.convert(c.baseURL, method, path, query, body, headers)
+}
+
+// ConvertURL converts a request given as a URL rather than a split path and
+// query: https://host/users?age=gte.18, /rest/v1/users?age=gte.18 or
+// /users?age=gte.18
+func (c *Converter) ConvertURL(method, rawURL, body string, headers map[string]string) (*SQLResult, error) {
+	baseURL, path, query, err := splitURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if baseURL == "" {
+		baseURL = c.baseURL
+	}
+	return c.convert(baseURL, method, path, query, body, headers)
+}
+
+// convert parses and converts a request, and records its canonical form in
+// HTTPRequest against baseURL
+func (c *Converter) convert(baseURL, method, path, query, body string, headers map[string]string) (*SQLResult, error) {
 	// Parse the PostgREST request
-	req, err := ParsePostgRESTRequest(method, path, query, []byte(body))
+	req, err := ParsePostgRESTRequestWithHeaders(method, path, query, []byte(body), headers)
 	if err != nil {
 		return nil, err
 	}
 
-	// Validate the request
-	if err := ValidateRequest(req); err != nil {
+	result, err := c.ConvertRequest(req)
+	if err != nil {
 		return nil, err
 	}
 
-	// Convert based on HTTP method
-	switch req.Method {
-	case "GET":
-		return c.convertSelect(req)
-	case "POST":
-		return c.convertInsert(req)
-	case "PATCH":
-		return c.convertUpdate(req)
-	case "DELETE":
-		return c.convertDelete(req)
-	default:
-		return nil, NewSemanticError(
-			"ERR_SEMANTIC_INVALID_METHOD",
-			fmt.Sprintf("unsupported HTTP method: %s", req.Method),
-			method,
-			"supported methods: GET, POST, PATCH, DELETE",
-		)
+	result.HTTPRequest, err = canonicalRequest(baseURL, req, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// splitURL extracts the base URL (scheme, host and Supabase's /rest/v1
+// prefix), the PostgREST path and the raw query from a URL
+func splitURL(rawURL string) (string, string, string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", "", "", NewSyntaxError("invalid URL", rawURL, "use a URL like https://host/users?age=gte.18")
 	}
+
+	baseURL := ""
+	if parsed.Host != "" {
+		baseURL = parsed.Scheme + "://" + parsed.Host
+	}
+
+	path := parsed.Path
+	if parsed.Host == "" && parsed.Scheme == "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if rest, found := strings.CutPrefix(path, "/rest/v1"); found {
+		path = rest
+		if baseURL != "" {
+			baseURL += "/rest/v1"
+		}
+	}
+
+	return baseURL, path, parsed.RawQuery, nil
 }
 
 // ConvertRequest converts a structured PostgRESTRequest to SQL
@@ -54,14 +130,47 @@ func (c *Converter) ConvertRequest(req *PostgRESTRequest) (*SQLResult, error) {
 		return nil, err
 	}
 
-	// Convert based on HTTP method
+	result, err := c.convertMethod(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accept: application/vnd.pgrst.object+json expects exactly one row
+	if singleObject(req) {
+		result.Metadata["single_object"] = "true"
+		result.Warnings = append(result.Warnings, "PostgREST responds 406 unless exactly one row is returned for Accept: "+objectMediaType)
+	}
+
+	// Accept: application/vnd.pgrst.plan asks for the EXPLAIN output
+	applyPlanAccept(req, result)
+
+	// Prefer: tx=rollback makes the mutation a dry run
+	applyTxPreference(req, result)
+
+	if c.pretty {
+		result.SQL = FormatSQL(result.SQL)
+	}
+
+	return result, nil
+}
+
+// convertMethod dispatches on the path and HTTP method
+func (c *Converter) convertMethod(req *PostgRESTRequest) (*SQLResult, error) {
+	if req.Function != "" {
+		return c.convertRPC(req)
+	}
+
 	switch req.Method {
 	case "GET":
 		return c.convertSelect(req)
+	case "HEAD":
+		return c.convertHead(req)
 	case "POST":
 		return c.convertInsert(req)
 	case "PATCH":
 		return c.convertUpdate(req)
+	case "PUT":
+		return c.convertPut(req)
 	case "DELETE":
 		return c.convertDelete(req)
 	default:
@@ -69,7 +178,7 @@ func (c *Converter) ConvertRequest(req *PostgRESTRequest) (*SQLResult, error) {
 			"ERR_SEMANTIC_INVALID_METHOD",
 			fmt.Sprintf("unsupported HTTP method: %s", req.Method),
 			req.Method,
-			"supported methods: GET, POST, PATCH, DELETE",
+			"supported methods: GET, HEAD, POST, PUT, PATCH, DELETE",
 		)
 	}
 }
@@ -81,15 +190,31 @@ func (c *Converter) convertSelect(req *PostgRESTRequest) (*SQLResult, error) {
 		Metadata: make(map[string]string),
 	}
 
+	// Range: 20-29 pages like limit/offset
+	warnings, err := applyRangeHeader(req)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+
 	// Build SELECT clause
-	selectClause := buildSelectClause(req)
+	selectClause, groupBy, warnings := buildSelectClause(req)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	// Move filters on embedded relations (posts.status=eq.x) onto their embeds
+	result.Warnings = append(result.Warnings, attachEmbeddedFilters(req)...)
+	result.Warnings = append(result.Warnings, attachEmbedPages(req)...)
 
 	// Build FROM clause (with JOINs if embedded resources)
-	fromClause, warnings := buildFromClause(req)
+	p := c.newParams()
+	fromClause, warnings, err := buildFromClause(req, p)
+	if err != nil {
+		return nil, err
+	}
 	result.Warnings = append(result.Warnings, warnings...)
 
 	// Build WHERE clause
-	whereClause, err := buildWhereClause(req.Filters)
+	whereClause, err := buildWhereClause(p, req.Filters)
 	if err != nil {
 		return nil, err
 	}
@@ -97,14 +222,23 @@ func (c *Converter) convertSelect(req *PostgRESTRequest) (*SQLResult, error) {
 	// Build ORDER BY clause
 	orderByClause := buildOrderByClause(req.Order)
 
+	// A single object needs only the first row
+	if singleObject(req) {
+		limitSingleRow(req)
+	}
+
 	// Build LIMIT/OFFSET
 	limitOffsetClause := buildLimitOffsetClause(req.Limit, req.Offset)
+	result.Warnings = append(result.Warnings, limitOffsetNotes(req)...)
 
 	// Combine all parts
 	sql := selectClause + " " + fromClause
 	if whereClause != "" {
 		sql += " " + whereClause
 	}
+	if len(groupBy) > 0 {
+		sql += " GROUP BY " + strings.Join(groupBy, ", ")
+	}
 	if orderByClause != "" {
 		sql += " " + orderByClause
 	}
@@ -112,7 +246,42 @@ func (c *Converter) convertSelect(req *PostgRESTRequest) (*SQLResult, error) {
 		sql += " " + limitOffsetClause
 	}
 
+	// Prefer: count=... makes PostgREST also count the matching rows;
+	// count_sql is informational, so it keeps its literals inline
+	countWhere := whereClause
+	if p != nil {
+		countWhere, _ = buildWhereClause(nil, req.Filters)
+	}
+	result.Warnings = append(result.Warnings, addCountQuery(req, countWhere, result.Metadata)...)
+
 	result.SQL = sql
+	result.Args = p.values()
+	return result, nil
+}
+
+// convertHead converts a HEAD request. PostgREST runs the same query as GET
+// but returns only headers, so with Prefer: count the count is the result.
+func (c *Converter) convertHead(req *PostgRESTRequest) (*SQLResult, error) {
+	result, err := c.convertSelect(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if countSQL, ok := result.Metadata["count_sql"]; ok {
+		result.SQL = countSQL
+		if p := c.newParams(); p != nil {
+			whereClause, err := buildWhereClause(p, req.Filters)
+			if err != nil {
+				return nil, err
+			}
+			result.SQL = countQuery(req, whereClause)
+			result.Args = p.values()
+		}
+		result.Warnings = append(result.Warnings, "HEAD returns no body; the count is reported in the Content-Range header")
+		return result, nil
+	}
+
+	result.Warnings = append(result.Warnings, "HEAD returns no body, only headers; add Prefer: count=exact to get the row count in Content-Range")
 	return result, nil
 }
 
@@ -123,12 +292,25 @@ func (c *Converter) convertInsert(req *PostgRESTRequest) (*SQLResult, error) {
 		Metadata: make(map[string]string),
 	}
 
-	sql, err := buildInsertStatement(req)
+	p := c.newParams()
+	sql, err := buildInsertStatement(req, p)
 	if err != nil {
 		return nil, err
 	}
 
-	result.SQL = sql
+	result.Warnings = append(result.Warnings, ignoredColumnWarnings(req)...)
+	result.Warnings = append(result.Warnings, missingKeyWarnings(req)...)
+
+	result.Warnings = append(result.Warnings, mutationLimitNotes(req, result.Metadata)...)
+
+	onConflict, warnings := buildOnConflictClause(req)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	returning, warnings := buildReturningClause(req)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	result.SQL = sql + onConflict + returning
+	result.Args = p.values()
 	return result, nil
 }
 
@@ -140,16 +322,47 @@ func (c *Converter) convertUpdate(req *PostgRESTRequest) (*SQLResult, error) {
 	}
 
 	// Warn if no WHERE clause
-	if len(req.Filters) == 0 {
+	if len(req.Filters) == 0 && !limitedMutation(req) {
 		result.Warnings = append(result.Warnings, "UPDATE without WHERE clause will affect all rows")
 	}
 
-	sql, err := buildUpdateStatement(req)
+	p := c.newParams()
+	sql, err := buildUpdateStatement(req, p)
 	if err != nil {
 		return nil, err
 	}
 
-	result.SQL = sql
+	result.Warnings = append(result.Warnings, mutationLimitNotes(req, result.Metadata)...)
+
+	returning, warnings := buildReturningClause(req)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	result.SQL = sql + returning
+	result.Args = p.values()
+	return result, nil
+}
+
+// convertPut converts a PUT request, PostgREST's single-row upsert, to
+// INSERT ... ON CONFLICT DO UPDATE on the filtered primary key
+func (c *Converter) convertPut(req *PostgRESTRequest) (*SQLResult, error) {
+	result := &SQLResult{
+		Warnings: []string{},
+		Metadata: make(map[string]string),
+	}
+
+	p := c.newParams()
+	sql, err := buildPutStatement(req, p)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Warnings = append(result.Warnings, mutationLimitNotes(req, result.Metadata)...)
+
+	returning, warnings := buildReturningClause(req)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	result.SQL = sql + returning
+	result.Args = p.values()
 	return result, nil
 }
 
@@ -160,11 +373,18 @@ func (c *Converter) convertDelete(req *PostgRESTRequest) (*SQLResult, error) {
 		Metadata: make(map[string]string),
 	}
 
-	sql, err := buildDeleteStatement(req)
+	p := c.newParams()
+	sql, err := buildDeleteStatement(req, p)
 	if err != nil {
 		return nil, err
 	}
 
-	result.SQL = sql
+	result.Warnings = append(result.Warnings, mutationLimitNotes(req, result.Metadata)...)
+
+	returning, warnings := buildReturningClause(req)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	result.SQL = sql + returning
+	result.Args = p.values()
 	return result, nil
 }
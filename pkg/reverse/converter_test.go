@@ -1,6 +1,9 @@
 package reverse
 
 import (
+	"encoding/json"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,8 +40,13 @@ func TestConvertSimpleSelect(t *testing.T) {
 			query:    "age=gte.18",
 			expected: "SELECT * FROM users WHERE age >= 18",
 		},
-		// Note: Removed this test case because URL query param order is non-deterministic
-		// Testing multiple filters is covered in TestConvertOperators and other tests
+		{
+			name:     "select with multiple filters",
+			method:   "GET",
+			path:     "/users",
+			query:    "status=eq.active&age=gte.18",
+			expected: "SELECT * FROM users WHERE status = 'active' AND age >= 18",
+		},
 		{
 			name:     "select with order by",
 			method:   "GET",
@@ -95,11 +103,14 @@ func TestMultipleFilters(t *testing.T) {
 	result, err := conv.Convert("GET", "/users", "age=gte.18&status=eq.active", "")
 	require.NoError(t, err)
 
-	// Map iteration order is non-deterministic, so check both conditions are present
-	assert.Contains(t, result.SQL, "WHERE")
-	assert.Contains(t, result.SQL, "age >= 18")
-	assert.Contains(t, result.SQL, "status = 'active'")
-	assert.Contains(t, result.SQL, "AND")
+	// Filters keep their query-string order
+	assert.Equal(t, "SELECT * FROM users WHERE age >= 18 AND status = 'active'", result.SQL)
+
+	for i := 0; i < 20; i++ {
+		again, err := conv.Convert("GET", "/users", "age=gte.18&status=eq.active", "")
+		require.NoError(t, err)
+		assert.Equal(t, result.SQL, again.SQL)
+	}
 }
 
 func TestConvertOperators(t *testing.T) {
@@ -144,6 +155,66 @@ func TestConvertWithEmbeds(t *testing.T) {
 			expected: "SELECT authors.name, posts.title FROM authors LEFT JOIN posts ON posts.authors_id = authors.id",
 			warnings: 1,
 		},
+		{
+			name:     "embedded filter joins on",
+			query:    "select=name,posts(title)&posts.status=eq.published",
+			expected: "SELECT authors.name, posts.title FROM authors LEFT JOIN posts ON posts.authors_id = authors.id AND posts.status = 'published'",
+			warnings: 1,
+		},
+		{
+			name:     "embedded filter with negation",
+			query:    "select=name,posts(title)&posts.views=not.is.null",
+			expected: "SELECT authors.name, posts.title FROM authors LEFT JOIN posts ON posts.authors_id = authors.id AND posts.views IS NOT NULL",
+			warnings: 1,
+		},
+		{
+			name:     "inner embed",
+			query:    "select=name,posts!inner(title)",
+			expected: "SELECT authors.name, posts.title FROM authors INNER JOIN posts ON posts.authors_id = authors.id",
+			warnings: 1,
+		},
+		{
+			name:     "fk constraint hint",
+			query:    "select=name,posts!posts_author_id_fkey(title)",
+			expected: "SELECT authors.name, posts.title FROM authors LEFT JOIN posts ON posts.author_id = authors.id",
+			warnings: 1,
+		},
+		{
+			name:     "fk column hint with inner and filter",
+			query:    "select=name,posts!author_id!inner(title)&posts.status=eq.published",
+			expected: "SELECT authors.name, posts.title FROM authors INNER JOIN posts ON posts.author_id = authors.id AND posts.status = 'published'",
+			warnings: 1,
+		},
+		{
+			name:     "nested embeds with alias",
+			query:    "select=name,posts(title,comments(body,author:users(name)))",
+			expected: "SELECT authors.name, posts.title, comments.body, author.name FROM authors LEFT JOIN posts ON posts.authors_id = authors.id LEFT JOIN comments ON comments.posts_id = posts.id LEFT JOIN users AS author ON author.comments_id = comments.id",
+			warnings: 3,
+		},
+		{
+			name:     "filter on nested embed",
+			query:    "select=name,posts(title,comments(body))&posts.comments.approved=is.true",
+			expected: "SELECT authors.name, posts.title, comments.body FROM authors LEFT JOIN posts ON posts.authors_id = authors.id LEFT JOIN comments ON comments.posts_id = posts.id AND comments.approved IS TRUE",
+			warnings: 2,
+		},
+		{
+			name:     "spread embed",
+			query:    "select=name,...addresses(city,zip)",
+			expected: "SELECT authors.name, addresses.city, addresses.zip FROM authors LEFT JOIN addresses ON addresses.id = authors.addresses_id",
+			warnings: 1,
+		},
+		{
+			name:     "spread embed with constraint hint",
+			query:    "select=name,...addresses!authors_billing_address_id_fkey(city)",
+			expected: "SELECT authors.name, addresses.city FROM authors LEFT JOIN addresses ON addresses.id = authors.billing_address_id",
+			warnings: 1,
+		},
+		{
+			name:     "dotted filter without embed",
+			query:    "select=name&posts.status=eq.published",
+			expected: "SELECT name FROM authors WHERE posts.status = 'published'",
+			warnings: 1,
+		},
 	}
 
 	conv := NewConverter()
@@ -167,7 +238,7 @@ func TestConvertInsert(t *testing.T) {
 		{
 			name:     "single row insert",
 			body:     `{"name":"Alice","email":"alice@example.com"}`,
-			expected: "INSERT INTO users (name, email) VALUES ('Alice', 'alice@example.com')",
+			expected: "INSERT INTO users (email, name) VALUES ('alice@example.com', 'Alice')",
 		},
 		{
 			name:     "insert with numbers",
@@ -200,9 +271,8 @@ func TestConvertInsert(t *testing.T) {
 				return
 			}
 			require.NoError(t, err)
-			// Note: map iteration order is not guaranteed, so we check both possibilities
-			assert.Contains(t, result.SQL, "INSERT INTO users")
-			assert.Contains(t, result.SQL, "VALUES")
+			// Columns are sorted, so the output is stable
+			assert.Equal(t, tt.expected, result.SQL)
 		})
 	}
 }
@@ -243,7 +313,7 @@ func TestConvertUpdate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			result, err := conv.Convert("PATCH", "/users", tt.query, tt.body)
 			require.NoError(t, err)
-			assert.Contains(t, result.SQL, "UPDATE users SET")
+			assert.Equal(t, tt.expected, result.SQL)
 			assert.Len(t, result.Warnings, tt.warnings)
 		})
 	}
@@ -340,6 +410,64 @@ func TestFormatValue(t *testing.T) {
 	}
 }
 
+func TestLimitOffsetEdgeCases(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		expected     string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{
+			name:         "limit zero",
+			query:        "limit=0",
+			expected:     "SELECT * FROM users LIMIT 0",
+			wantWarnings: 1,
+		},
+		{
+			name:         "offset only",
+			query:        "offset=20",
+			expected:     "SELECT * FROM users OFFSET 20",
+			wantWarnings: 2,
+		},
+		{
+			name:         "offset only with order",
+			query:        "order=id.asc&offset=20",
+			expected:     "SELECT * FROM users ORDER BY id ASC OFFSET 20",
+			wantWarnings: 1,
+		},
+		{
+			name:     "limit offset and order",
+			query:    "order=id.asc&limit=10&offset=20",
+			expected: "SELECT * FROM users ORDER BY id ASC LIMIT 10 OFFSET 20",
+		},
+		{
+			name:    "negative limit",
+			query:   "limit=-1",
+			wantErr: true,
+		},
+		{
+			name:    "negative offset",
+			query:   "offset=-5",
+			wantErr: true,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/users", tt.query, "")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			assert.Len(t, result.Warnings, tt.wantWarnings)
+		})
+	}
+}
+
 func TestOrderByParsing(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -406,3 +534,1946 @@ func TestSelectParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertOrFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "simple or",
+			query:    "or=(age.lt.18,age.gt.65)",
+			expected: "SELECT * FROM users WHERE (age < 18 OR age > 65)",
+		},
+		{
+			name:     "or with nested and",
+			query:    "or=(status.eq.vip,and(age.gte.18,age.lte.25))",
+			expected: "SELECT * FROM users WHERE (status = 'vip' OR (age >= 18 AND age <= 25))",
+		},
+		{
+			name:     "or with in list and negated member",
+			query:    "or=(id.in.(1,2,3),age.not.eq.30)",
+			expected: "SELECT * FROM users WHERE (id IN (1, 2, 3) OR NOT (age = 30))",
+		},
+		{
+			name:     "quoted value with comma",
+			query:    `or=(name.eq."Doe, John",name.is.null)`,
+			expected: "SELECT * FROM users WHERE (name = 'Doe, John' OR name IS NULL)",
+		},
+		{
+			name:    "missing parentheses",
+			query:   "or=age.lt.18",
+			wantErr: true,
+		},
+		{
+			name:    "unbalanced parentheses",
+			query:   "or=(age.lt.18,id.in.(1,2)",
+			wantErr: true,
+		},
+		{
+			name:    "condition without operator",
+			query:   "or=(age,name.eq.x)",
+			wantErr: true,
+		},
+		{
+			name:    "or as a column filter",
+			query:   "age=or(lt.18,gt.65)",
+			wantErr: true,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/users", tt.query, "")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertLogicTrees(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "and group",
+			query:    "and=(age.gte.18,age.lte.65)",
+			expected: "SELECT * FROM users WHERE (age >= 18 AND age <= 65)",
+		},
+		{
+			name:     "and with nested or",
+			query:    "and=(age.gte.18,or(status.eq.active,status.eq.trial))",
+			expected: "SELECT * FROM users WHERE (age >= 18 AND (status = 'active' OR status = 'trial'))",
+		},
+		{
+			name:     "and group first inside or",
+			query:    "or=(and(a.eq.1,b.eq.2),c.is.null)",
+			expected: "SELECT * FROM users WHERE ((a = 1 AND b = 2) OR c IS NULL)",
+		},
+		{
+			name:     "deep nesting",
+			query:    "or=(a.eq.1,and(b.eq.2,or(c.eq.3,and(d.eq.4,e.eq.5))))",
+			expected: "SELECT * FROM users WHERE (a = 1 OR (b = 2 AND (c = 3 OR (d = 4 AND e = 5))))",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/users", tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+
+	t.Run("parses into a tree", func(t *testing.T) {
+		filter, err := parseLogicalFilter("or", "(and(a.eq.1,b.eq.2),c.is.null)")
+		require.NoError(t, err)
+		assert.Equal(t, Filter{
+			Logical: "or",
+			Conditions: []Filter{
+				{
+					Logical: "and",
+					Conditions: []Filter{
+						{Column: "a", Operator: "eq", Value: "1", Logical: "and"},
+						{Column: "b", Operator: "eq", Value: "2", Logical: "and"},
+					},
+				},
+				{Column: "c", Operator: "is", Value: "null", Logical: "and"},
+			},
+		}, filter)
+	})
+}
+
+func TestConvertNegatedLogic(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "not.or",
+			query:    "not.or=(status.eq.banned,status.eq.deleted)",
+			expected: "SELECT * FROM users WHERE NOT (status = 'banned' OR status = 'deleted')",
+		},
+		{
+			name:     "not.and",
+			query:    "not.and=(age.gte.18,age.lte.65)",
+			expected: "SELECT * FROM users WHERE NOT (age >= 18 AND age <= 65)",
+		},
+		{
+			name:     "negated nested group",
+			query:    "or=(role.eq.admin,not.and(status.eq.banned,age.lt.18))",
+			expected: "SELECT * FROM users WHERE (role = 'admin' OR NOT (status = 'banned' AND age < 18))",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/users", tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestAttachEmbeddedFilters(t *testing.T) {
+	req := &PostgRESTRequest{
+		Table: "authors",
+		Filters: []Filter{
+			{Column: "name", Operator: "eq", Value: "Ann", Logical: "and"},
+			{Column: "posts.status", Operator: "eq", Value: "published", Logical: "and"},
+		},
+		Embedded: []EmbeddedResource{{Relation: "posts", Select: []string{"title"}}},
+	}
+
+	warnings := attachEmbeddedFilters(req)
+	assert.Empty(t, warnings)
+	assert.Equal(t, []Filter{{Column: "name", Operator: "eq", Value: "Ann", Logical: "and"}}, req.Filters)
+	assert.Equal(t, []Filter{{Column: "status", Operator: "eq", Value: "published", Logical: "and"}}, req.Embedded[0].Filters)
+}
+
+func TestConvertSelectAliasesAndCasts(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "alias and cast",
+			path:     "/users",
+			query:    "select=full_name:name,price::numeric",
+			expected: "SELECT name AS full_name, price::numeric FROM users",
+		},
+		{
+			name:     "aliased cast",
+			path:     "/products",
+			query:    "select=price_str:price::text",
+			expected: "SELECT price::text AS price_str FROM products",
+		},
+		{
+			name:     "cast before alias",
+			path:     "/products",
+			query:    "select=price::text:price_str",
+			expected: "SELECT price::text AS price_str FROM products",
+		},
+		{
+			name:     "aliases in embeds",
+			path:     "/authors",
+			query:    "select=author:name,posts(headline:title)",
+			expected: "SELECT authors.name AS author, posts.title AS headline FROM authors LEFT JOIN posts ON posts.authors_id = authors.id",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", tt.path, tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestParseSelectItem(t *testing.T) {
+	tests := []struct {
+		input  string
+		column string
+		cast   string
+		alias  string
+	}{
+		{"name", "name", "", ""},
+		{"full_name:name", "name", "", "full_name"},
+		{"price::numeric", "price", "numeric", ""},
+		{"total:price::numeric", "price", "numeric", "total"},
+		{"price::numeric:total", "price", "numeric", "total"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			column, cast, alias := parseSelectItem(tt.input)
+			assert.Equal(t, tt.column, column)
+			assert.Equal(t, tt.cast, cast)
+			assert.Equal(t, tt.alias, alias)
+		})
+	}
+}
+
+func TestConvertJSONPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "select json path",
+			query:    "select=id,data->>name",
+			expected: "SELECT id, data->>'name' AS name FROM users",
+		},
+		{
+			name:     "nested path with alias",
+			query:    "select=city:data->address->>city",
+			expected: "SELECT data->'address'->>'city' AS city FROM users",
+		},
+		{
+			name:     "forward converter alias order",
+			query:    "select=data->address->>city:town",
+			expected: "SELECT data->'address'->>'city' AS town FROM users",
+		},
+		{
+			name:     "cast on json path",
+			query:    "select=data->>age::int",
+			expected: "SELECT (data->>'age')::int AS age FROM users",
+		},
+		{
+			name:     "array index",
+			query:    "select=data->tags->0",
+			expected: `SELECT data->'tags'->0 AS "0" FROM users`,
+		},
+		{
+			name:     "filter on json path",
+			query:    "metadata->>status=eq.shipped",
+			expected: "SELECT * FROM users WHERE metadata->>'status' = 'shipped'",
+		},
+		{
+			name:     "key with quote",
+			query:    "data->>o'k=eq.yes",
+			expected: "SELECT * FROM users WHERE data->>'o''k' = 'yes'",
+		},
+		{
+			name:     "order by json path",
+			query:    "order=data->>name.desc",
+			expected: "SELECT * FROM users ORDER BY data->>'name' DESC",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/users", tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertAggregates(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		query    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "count all",
+			path:     "/users",
+			query:    "select=count()",
+			expected: "SELECT COUNT(*) FROM users",
+		},
+		{
+			name:     "count with grouping",
+			path:     "/users",
+			query:    "select=status,count()",
+			expected: "SELECT status, COUNT(*) FROM users GROUP BY status",
+		},
+		{
+			name:     "aliases in both orders and casts",
+			path:     "/orders",
+			query:    "select=status,total.sum():revenue,avg_total:total.avg(),total.max()::int",
+			expected: "SELECT status, SUM(total) AS revenue, AVG(total) AS avg_total, MAX(total)::int FROM orders GROUP BY status",
+		},
+		{
+			name:     "embedded aggregate",
+			path:     "/users",
+			query:    "select=name,orders(total.sum():revenue)",
+			expected: "SELECT users.name, SUM(orders.total) AS revenue FROM users LEFT JOIN orders ON orders.users_id = users.id GROUP BY users.name",
+			warnings: 2,
+		},
+		{
+			name:     "embedded count with star",
+			path:     "/users",
+			query:    "select=*,posts(count())",
+			expected: "SELECT users.*, COUNT(posts.*) FROM users LEFT JOIN posts ON posts.users_id = users.id",
+			warnings: 3,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", tt.path, tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			assert.Len(t, result.Warnings, tt.warnings)
+		})
+	}
+}
+
+func TestParseAggregate(t *testing.T) {
+	tests := []struct {
+		input    string
+		function string
+		arg      string
+		ok       bool
+	}{
+		{"count()", "count", "", true},
+		{"total.sum()", "sum", "total", true},
+		{"price.avg()", "avg", "price", true},
+		{"sum()", "", "", false},
+		{"posts(title)", "", "", false},
+		{"orders(total.sum()", "", "", false},
+		{"name", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			function, arg, ok := parseAggregate(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.function, function)
+			assert.Equal(t, tt.arg, arg)
+		})
+	}
+}
+
+func TestParseEmbedModifiers(t *testing.T) {
+	tests := []struct {
+		input    string
+		relation string
+		hint     string
+		inner    bool
+	}{
+		{"posts", "posts", "", false},
+		{"posts!inner", "posts", "", true},
+		{"posts!left", "posts", "", false},
+		{"posts!posts_author_id_fkey", "posts", "posts_author_id_fkey", false},
+		{"posts!author_id!inner", "posts", "author_id", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			relation, hint, inner := parseEmbedModifiers(tt.input)
+			assert.Equal(t, tt.relation, relation)
+			assert.Equal(t, tt.hint, hint)
+			assert.Equal(t, tt.inner, inner)
+		})
+	}
+}
+
+func TestParseNestedEmbeds(t *testing.T) {
+	mainCols, embeds, err := ParseEmbeddedResources([]string{"name", "posts(title,comments(body,author:users!inner(name)))"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name"}, mainCols)
+	assert.Equal(t, []EmbeddedResource{
+		{
+			Relation: "posts",
+			Select:   []string{"title"},
+			Embedded: []EmbeddedResource{
+				{
+					Relation: "comments",
+					Select:   []string{"body"},
+					Embedded: []EmbeddedResource{
+						{Relation: "users", Alias: "author", Inner: true, Select: []string{"name"}, Embedded: []EmbeddedResource{}},
+					},
+				},
+			},
+		},
+	}, embeds)
+}
+
+func TestConvertUpsert(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		headers  map[string]string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "merge duplicates",
+			query:    "on_conflict=id",
+			body:     `{"id":1,"name":"Alice"}`,
+			headers:  map[string]string{"Prefer": "resolution=merge-duplicates"},
+			expected: "ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name",
+		},
+		{
+			name:     "ignore duplicates",
+			query:    "on_conflict=email",
+			body:     `[{"email":"a@example.com"},{"email":"b@example.com"}]`,
+			headers:  map[string]string{"Prefer": "resolution=ignore-duplicates"},
+			expected: "INSERT INTO users (email) VALUES ('a@example.com'), ('b@example.com') ON CONFLICT (email) DO NOTHING",
+		},
+		{
+			name:     "composite conflict target and lowercase header",
+			query:    "on_conflict=org_id,email",
+			body:     `{"org_id":1,"email":"a@example.com","name":"A"}`,
+			headers:  map[string]string{"prefer": "return=minimal, resolution=merge-duplicates"},
+			expected: "ON CONFLICT (org_id, email) DO UPDATE SET name = EXCLUDED.name",
+		},
+		{
+			name:     "merge without on_conflict assumes id",
+			body:     `{"id":1,"name":"Alice"}`,
+			headers:  map[string]string{"Prefer": "resolution=merge-duplicates"},
+			expected: "ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name",
+			warnings: 1,
+		},
+		{
+			name:     "ignore without on_conflict",
+			body:     `{"name":"Alice"}`,
+			headers:  map[string]string{"Prefer": "resolution=ignore-duplicates"},
+			expected: "INSERT INTO users (name) VALUES ('Alice') ON CONFLICT DO NOTHING",
+		},
+		{
+			name:     "on_conflict without resolution",
+			query:    "on_conflict=id",
+			body:     `{"name":"Alice"}`,
+			expected: "INSERT INTO users (name) VALUES ('Alice')",
+			warnings: 1,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertWithHeaders("POST", "/users", tt.query, tt.body, tt.headers)
+			require.NoError(t, err)
+			assert.True(t, strings.HasSuffix(result.SQL, tt.expected), "got %s", result.SQL)
+			assert.Len(t, result.Warnings, tt.warnings)
+		})
+	}
+}
+
+func TestConvertPut(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "single row upsert",
+			query:    "id=eq.5",
+			body:     `{"id":5,"name":"Alice","age":30}`,
+			expected: "INSERT INTO users (id, age, name) VALUES (5, 30, 'Alice') ON CONFLICT (id) DO UPDATE SET age = EXCLUDED.age, name = EXCLUDED.name",
+		},
+		{
+			name:     "composite key",
+			query:    "org_id=eq.1&email=eq.a@example.com",
+			body:     `{"org_id":1,"email":"a@example.com","name":"A"}`,
+			expected: "ON CONFLICT (org_id, email) DO UPDATE SET name = EXCLUDED.name",
+		},
+		{
+			name:     "only key columns",
+			query:    "id=eq.5",
+			body:     `{"id":5}`,
+			expected: "INSERT INTO users (id) VALUES (5) ON CONFLICT (id) DO NOTHING",
+		},
+		{
+			name:    "missing filter",
+			body:    `{"id":5}`,
+			wantErr: true,
+		},
+		{
+			name:    "non eq filter",
+			query:   "id=gt.5",
+			body:    `{"id":5}`,
+			wantErr: true,
+		},
+		{
+			name:    "key missing from body",
+			query:   "id=eq.5",
+			body:    `{"name":"Alice"}`,
+			wantErr: true,
+		},
+		{
+			name:    "body and filter disagree",
+			query:   "id=eq.5",
+			body:    `{"id":6,"name":"Alice"}`,
+			wantErr: true,
+		},
+		{
+			name:    "array body",
+			query:   "id=eq.5",
+			body:    `[{"id":5}]`,
+			wantErr: true,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("PUT", "/users", tt.query, tt.body)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, strings.HasSuffix(result.SQL, tt.expected), "got %s", result.SQL)
+		})
+	}
+}
+
+func TestConvertReturning(t *testing.T) {
+	representation := map[string]string{"Prefer": "return=representation"}
+
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		body     string
+		headers  map[string]string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "insert returning all",
+			method:   "POST",
+			body:     `{"name":"Alice"}`,
+			headers:  representation,
+			expected: "INSERT INTO users (name) VALUES ('Alice') RETURNING *",
+		},
+		{
+			name:     "update returning selected columns",
+			method:   "PATCH",
+			query:    "id=eq.1&select=id,full_name:name",
+			body:     `{"name":"Alice"}`,
+			headers:  representation,
+			expected: "UPDATE users SET name = 'Alice' WHERE id = 1 RETURNING id, name AS full_name",
+		},
+		{
+			name:     "delete returning with embed dropped",
+			method:   "DELETE",
+			query:    "id=eq.1&select=id,posts(title)",
+			headers:  representation,
+			expected: "DELETE FROM users WHERE id = 1 RETURNING id",
+			warnings: 1,
+		},
+		{
+			name:     "minimal return",
+			method:   "DELETE",
+			query:    "id=eq.1",
+			headers:  map[string]string{"Prefer": "return=minimal"},
+			expected: "DELETE FROM users WHERE id = 1",
+		},
+		{
+			name:     "select without representation",
+			method:   "DELETE",
+			query:    "id=eq.1&select=id",
+			expected: "DELETE FROM users WHERE id = 1",
+			warnings: 1,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertWithHeaders(tt.method, "/users", tt.query, tt.body, tt.headers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			assert.Len(t, result.Warnings, tt.warnings)
+		})
+	}
+}
+
+func TestConvertCountPreference(t *testing.T) {
+	conv := NewConverter()
+
+	t.Run("exact count", func(t *testing.T) {
+		result, err := conv.ConvertWithHeaders("GET", "/users", "age=gte.18&order=id&limit=10", "", map[string]string{"Prefer": "count=exact"})
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users WHERE age >= 18 ORDER BY id ASC LIMIT 10", result.SQL)
+		assert.Equal(t, "exact", result.Metadata["count"])
+		assert.Equal(t, "SELECT count(*) FROM users WHERE age >= 18", result.Metadata["count_sql"])
+		assert.Empty(t, result.Warnings)
+	})
+
+	t.Run("planned count notes the estimate", func(t *testing.T) {
+		result, err := conv.ConvertWithHeaders("GET", "/users", "", "", map[string]string{"Prefer": "count=planned"})
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT count(*) FROM users", result.Metadata["count_sql"])
+		assert.Len(t, result.Warnings, 1)
+	})
+
+	t.Run("no count preference", func(t *testing.T) {
+		result, err := conv.Convert("GET", "/users", "age=gte.18", "")
+		require.NoError(t, err)
+		assert.NotContains(t, result.Metadata, "count_sql")
+	})
+}
+
+func TestConvertHead(t *testing.T) {
+	conv := NewConverter()
+
+	result, err := conv.Convert("HEAD", "/users", "status=eq.active", "")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE status = 'active'", result.SQL)
+	assert.Len(t, result.Warnings, 1)
+
+	result, err = conv.ConvertWithHeaders("HEAD", "/users", "status=eq.active", "", map[string]string{"Prefer": "count=exact"})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT count(*) FROM users WHERE status = 'active'", result.SQL)
+	assert.Equal(t, "exact", result.Metadata["count"])
+	assert.Len(t, result.Warnings, 1)
+}
+
+func TestConvertRPC(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		query    string
+		body     string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "post with arguments",
+			method:   "POST",
+			path:     "/rpc/add_numbers",
+			body:     `{"a":5,"b":3}`,
+			expected: "SELECT * FROM add_numbers(a := 5, b := 3)",
+		},
+		{
+			name:     "post without arguments",
+			method:   "POST",
+			path:     "/rpc/hello_world",
+			expected: "SELECT * FROM hello_world()",
+		},
+		{
+			name:     "get with arguments and result filters",
+			method:   "GET",
+			path:     "/rpc/search_users",
+			query:    "term=ali&age=gte.18&select=id,name&order=name&limit=10",
+			expected: "SELECT id, name FROM search_users(term := 'ali') WHERE age >= 18 ORDER BY name ASC LIMIT 10",
+		},
+		{
+			name:    "missing function name",
+			method:  "POST",
+			path:    "/rpc/",
+			wantErr: true,
+		},
+		{
+			name:    "array body",
+			method:  "POST",
+			path:    "/rpc/add_numbers",
+			body:    `[{"a":1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "delete",
+			method:  "DELETE",
+			path:    "/rpc/add_numbers",
+			query:   "a=eq.1",
+			wantErr: true,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.method, tt.path, tt.query, tt.body)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertProfile(t *testing.T) {
+	read := map[string]string{"Accept-Profile": "api"}
+	write := map[string]string{"Content-Profile": "api"}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		query    string
+		body     string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "select",
+			method:   "GET",
+			path:     "/users",
+			query:    "id=eq.1",
+			headers:  read,
+			expected: "SELECT * FROM api.users WHERE id = 1",
+		},
+		{
+			name:     "embedded tables share the schema",
+			method:   "GET",
+			path:     "/users",
+			query:    "select=name,author:users(name)",
+			headers:  read,
+			expected: "SELECT users.name, author.name FROM api.users LEFT JOIN api.users AS author",
+		},
+		{
+			name:     "insert",
+			method:   "POST",
+			path:     "/users",
+			body:     `{"name":"Alice"}`,
+			headers:  write,
+			expected: "INSERT INTO api.users (name) VALUES ('Alice')",
+		},
+		{
+			name:     "update",
+			method:   "PATCH",
+			path:     "/users",
+			query:    "id=eq.1",
+			body:     `{"name":"Bob"}`,
+			headers:  write,
+			expected: "UPDATE api.users SET name = 'Bob' WHERE id = 1",
+		},
+		{
+			name:     "delete",
+			method:   "DELETE",
+			path:     "/users",
+			query:    "id=eq.1",
+			headers:  write,
+			expected: "DELETE FROM api.users WHERE id = 1",
+		},
+		{
+			name:     "rpc",
+			method:   "POST",
+			path:     "/rpc/add_numbers",
+			body:     `{"a":1}`,
+			headers:  write,
+			expected: "SELECT * FROM api.add_numbers(a := 1)",
+		},
+		{
+			name:     "read ignores Content-Profile",
+			method:   "GET",
+			path:     "/users",
+			headers:  write,
+			expected: "SELECT * FROM users",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertWithHeaders(tt.method, tt.path, tt.query, tt.body, tt.headers)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(result.SQL, tt.expected), "got %s", result.SQL)
+		})
+	}
+}
+
+func TestConvertSingleObject(t *testing.T) {
+	object := map[string]string{"Accept": "application/vnd.pgrst.object+json"}
+	conv := NewConverter()
+
+	result, err := conv.ConvertWithHeaders("GET", "/users", "id=eq.1", "", object)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = 1 LIMIT 1", result.SQL)
+	assert.Equal(t, "true", result.Metadata["single_object"])
+	assert.Len(t, result.Warnings, 1)
+
+	result, err = conv.ConvertWithHeaders("GET", "/users", "limit=0", "", map[string]string{"accept": "application/vnd.pgrst.object; nulls=stripped"})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users LIMIT 0", result.SQL)
+	assert.Equal(t, "true", result.Metadata["single_object"])
+
+	result, err = conv.ConvertWithHeaders("PATCH", "/users", "id=eq.1", `{"name":"Bob"}`, object)
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = 'Bob' WHERE id = 1", result.SQL)
+	assert.Equal(t, "true", result.Metadata["single_object"])
+
+	result, err = conv.Convert("GET", "/users", "id=eq.1", "")
+	require.NoError(t, err)
+	assert.NotContains(t, result.Metadata, "single_object")
+}
+
+func TestConvertURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		expected string
+	}{
+		{
+			name:     "full url",
+			method:   "GET",
+			url:      "https://api.example.com/users?age=gte.18",
+			expected: "SELECT * FROM users WHERE age >= 18",
+		},
+		{
+			name:     "supabase rest prefix",
+			method:   "GET",
+			url:      "https://abc.supabase.co/rest/v1/users?select=id,name&limit=5",
+			expected: "SELECT id, name FROM users LIMIT 5",
+		},
+		{
+			name:     "relative path",
+			method:   "DELETE",
+			url:      "/users?id=eq.1",
+			expected: "DELETE FROM users WHERE id = 1",
+		},
+		{
+			name:     "no leading slash",
+			method:   "GET",
+			url:      "users",
+			expected: "SELECT * FROM users",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertURL(tt.method, tt.url, "", nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+
+	_, err := conv.ConvertURL("GET", "https://api.example.com/", "", nil)
+	assert.Error(t, err)
+}
+
+func TestConvertQuantifiedOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "eq any",
+			query:    "id=eq(any).{1,2,3}",
+			expected: "SELECT * FROM users WHERE id = ANY (ARRAY[1, 2, 3])",
+		},
+		{
+			name:     "like all with quoted element",
+			query:    `name=like(all).{a%25,"%25b,c"}`,
+			expected: "SELECT * FROM users WHERE name LIKE ALL (ARRAY['a%', '%b,c'])",
+		},
+		{
+			name:     "negated ilike any",
+			query:    "name=not.ilike(any).{a%25}",
+			expected: "SELECT * FROM users WHERE NOT (name ILIKE ANY (ARRAY['a%']))",
+		},
+		{
+			name:     "gt all",
+			query:    "score=gt(all).{10,20}",
+			expected: "SELECT * FROM users WHERE score > ALL (ARRAY[10, 20])",
+		},
+		{
+			name:     "inside or group",
+			query:    "or=(id.eq(any).{1,2},name.eq.x)",
+			expected: "SELECT * FROM users WHERE (id = ANY (ARRAY[1, 2]) OR name = 'x')",
+		},
+		{
+			name:    "unsupported operator",
+			query:   "id=neq(any).{1}",
+			wantErr: true,
+		},
+		{
+			name:    "value without braces",
+			query:   "id=eq(any).1",
+			wantErr: true,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/users", tt.query, "")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertIsDistinct(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"status=isdistinct.active", "SELECT * FROM users WHERE status IS DISTINCT FROM 'active'"},
+		{"status=not.isdistinct.active", "SELECT * FROM users WHERE status IS NOT DISTINCT FROM 'active'"},
+		{"score=isdistinct.null", "SELECT * FROM users WHERE score IS DISTINCT FROM NULL"},
+		{"or=(status.isdistinct.active,id.eq.1)", "SELECT * FROM users WHERE (status IS DISTINCT FROM 'active' OR id = 1)"},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/users", tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertFullTextSearch(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"content=fts.amusant", "SELECT * FROM docs WHERE content @@ to_tsquery('amusant')"},
+		{"content=fts(french).amusant", "SELECT * FROM docs WHERE content @@ to_tsquery('french', 'amusant')"},
+		{"content=plfts.fat%20cats", "SELECT * FROM docs WHERE content @@ plainto_tsquery('fat cats')"},
+		{"content=phfts(english).the%20cat", "SELECT * FROM docs WHERE content @@ phraseto_tsquery('english', 'the cat')"},
+		{"content=wfts(simple).cat%20-dog", "SELECT * FROM docs WHERE content @@ websearch_to_tsquery('simple', 'cat -dog')"},
+		{"content=not.fts(french).amusant", "SELECT * FROM docs WHERE NOT (content @@ to_tsquery('french', 'amusant'))"},
+		{"or=(content.fts(french).a,id.eq.1)", "SELECT * FROM docs WHERE (content @@ to_tsquery('french', 'a') OR id = 1)"},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/docs", tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertArrayAndRangeLiterals(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"tags=cs.{admin,user}", "tags @> '{admin,user}'"},
+		{"ids=cd.{1,2,3}", "ids <@ '{1,2,3}'"},
+		{`tags=ov.{"a b",o'brien}`, `tags && '{"a b",o''brien}'`},
+		{"during=ov.[2024-01-01,2024-06-30]", "during && '[2024-01-01,2024-06-30]'::daterange"},
+		{"during=adj.[2024-01-01T10:00:00Z,2024-01-02T00:00:00Z)", "during -|- '[2024-01-01T10:00:00Z,2024-01-02T00:00:00Z)'::tstzrange"},
+		{"during=sl.[2024-01-01%2010:00,)", "during << '[2024-01-01 10:00,)'::tsrange"},
+		{"slots=sr.(1,10)", "slots >> '(1,10)'"},
+		{`meta=cs.{"role":"admin"}`, `meta @> '{"role":"admin"}'`},
+		{`meta=cs.["a","b"]`, `meta @> '["a","b"]'`},
+		{"during=cs.2024-03-01", "during @> '2024-03-01'"},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/events", tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, "SELECT * FROM events WHERE "+tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertRejectsUnsafeIdentifiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		query   string
+		body    string
+		headers map[string]string
+	}{
+		{name: "table", method: "GET", path: "/users%3Bdrop"},
+		{name: "table with spaces", method: "GET", path: "/users drop table x"},
+		{name: "filter column", method: "GET", path: "/users", query: "id%3Bdrop=eq.1"},
+		{name: "grouped filter column", method: "GET", path: "/users", query: "or=(a%3Bb.eq.1,id.eq.2)"},
+		{name: "select column", method: "GET", path: "/users", query: "select=name,x)%20from%20pg_shadow--"},
+		{name: "select alias", method: "GET", path: "/users", query: "select=x%20y:name"},
+		{name: "cast", method: "GET", path: "/users", query: "select=name::text%3Bdrop"},
+		{name: "embedded relation", method: "GET", path: "/users", query: "select=name,po%22sts(title)"},
+		{name: "order column", method: "GET", path: "/users", query: "order=id%3Bdrop"},
+		{name: "body key", method: "POST", path: "/users", body: `{"a); drop table x; --":1}`},
+		{name: "on_conflict", method: "POST", path: "/users", query: "on_conflict=id)%20do%20nothing--", body: `{"id":1}`},
+		{name: "rpc argument", method: "POST", path: "/rpc/fn", body: `{"a;b":1}`},
+		{name: "profile schema", method: "GET", path: "/users", headers: map[string]string{"Accept-Profile": "api;drop"}},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, _ := url.PathUnescape(tt.path)
+			_, err := conv.ConvertWithHeaders(tt.method, path, tt.query, tt.body, tt.headers)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConvertQuotesIdentifiers(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		query    string
+		body     string
+		expected string
+	}{
+		{
+			name:     "reserved and mixed-case names",
+			method:   "GET",
+			path:     "/user",
+			query:    "select=order,Name,data->>key&group=eq.1&order=desc.desc",
+			expected: `SELECT "order", "Name", data->>'key' AS key FROM "user" WHERE "group" = 1 ORDER BY "desc" DESC`,
+		},
+		{
+			name:     "parameterized cast is not an embed",
+			method:   "GET",
+			path:     "/products",
+			query:    "select=price::numeric(10,2)",
+			expected: "SELECT price::numeric(10,2) FROM products",
+		},
+		{
+			name:     "alias",
+			method:   "GET",
+			path:     "/orders",
+			query:    "select=total.sum():Revenue",
+			expected: `SELECT SUM(total) AS "Revenue" FROM orders`,
+		},
+		{
+			name:     "insert columns",
+			method:   "POST",
+			path:     "/events",
+			body:     `{"user":1,"When":"now"}`,
+			expected: `INSERT INTO events ("When", "user") VALUES ('now', 1)`,
+		},
+		{
+			name:     "update columns",
+			method:   "PATCH",
+			path:     "/events",
+			query:    "id=eq.1",
+			body:     `{"order":2}`,
+			expected: `UPDATE events SET "order" = 2 WHERE id = 1`,
+		},
+		{
+			name:     "unicode names",
+			method:   "GET",
+			path:     "/café",
+			query:    "select=prix",
+			expected: `SELECT prix FROM "café"`,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.method, tt.path, tt.query, tt.body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertParameterized(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		query    string
+		body     string
+		headers  map[string]string
+		expected string
+		args     []interface{}
+	}{
+		{
+			name:     "typed filters",
+			method:   "GET",
+			path:     "/users",
+			query:    "age=gte.18&score=lt.9.5&active=eq.true&name=eq.O'Brien",
+			expected: "SELECT * FROM users WHERE age >= $1 AND score < $2 AND active = $3 AND name = $4",
+			args:     []interface{}{int64(18), 9.5, true, "O'Brien"},
+		},
+		{
+			name:     "null stays inline",
+			method:   "GET",
+			path:     "/users",
+			query:    "manager_id=is.null&status=eq.null",
+			expected: "SELECT * FROM users WHERE manager_id IS NULL AND status = NULL",
+		},
+		{
+			name:     "in list and or group",
+			method:   "GET",
+			path:     "/users",
+			query:    "status=in.(active,pending)&or=(age.lt.18,age.gt.65)",
+			expected: "SELECT * FROM users WHERE status IN ($1, $2) AND (age < $3 OR age > $4)",
+			args:     []interface{}{"active", "pending", int64(18), int64(65)},
+		},
+		{
+			name:     "collections, quantifiers and full-text search",
+			method:   "GET",
+			path:     "/events",
+			query:    "during=ov.[2024-01-01,2024-06-30]&tags=cs.{a,b}&id=eq(any).{1,2}&body=fts(english).cat",
+			expected: "SELECT * FROM events WHERE during && $1::daterange AND tags @> $2 AND id = ANY (ARRAY[$3, $4]) AND body @@ to_tsquery($5, $6)",
+			args:     []interface{}{"[2024-01-01,2024-06-30]", "{a,b}", int64(1), int64(2), "english", "cat"},
+		},
+		{
+			name:     "embed filters number before where",
+			method:   "GET",
+			path:     "/users",
+			query:    "select=name,posts(title)&posts.status=eq.published&age=gt.30",
+			expected: "SELECT users.name, posts.title FROM users LEFT JOIN posts ON posts.users_id = users.id AND posts.status = $1 WHERE age > $2",
+			args:     []interface{}{"published", int64(30)},
+		},
+		{
+			name:     "insert",
+			method:   "POST",
+			path:     "/users",
+			body:     `[{"name":"Alice","age":30,"meta":{"a":1}},{"name":"Bob","age":null}]`,
+			expected: "INSERT INTO users (age, meta, name) VALUES ($1, $2, $3), (NULL, NULL, $4)",
+			args:     []interface{}{int64(30), `{"a":1}`, "Alice", "Bob"},
+		},
+		{
+			name:     "update",
+			method:   "PATCH",
+			path:     "/users",
+			query:    "id=eq.7",
+			body:     `{"status":"active","score":1.5}`,
+			expected: "UPDATE users SET score = $1, status = $2 WHERE id = $3",
+			args:     []interface{}{1.5, "active", int64(7)},
+		},
+		{
+			name:     "delete",
+			method:   "DELETE",
+			path:     "/users",
+			query:    "id=eq.7",
+			expected: "DELETE FROM users WHERE id = $1",
+			args:     []interface{}{int64(7)},
+		},
+		{
+			name:     "rpc",
+			method:   "POST",
+			path:     "/rpc/search",
+			query:    "rank=gt.0.5",
+			body:     `{"term":"go"}`,
+			expected: "SELECT * FROM search(term := $1) WHERE rank > $2",
+			args:     []interface{}{"go", 0.5},
+		},
+		{
+			name:     "head count",
+			method:   "HEAD",
+			path:     "/users",
+			query:    "age=gte.18",
+			headers:  map[string]string{"Prefer": "count=exact"},
+			expected: "SELECT count(*) FROM users WHERE age >= $1",
+			args:     []interface{}{int64(18)},
+		},
+	}
+
+	conv := NewConverter(WithParameters())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertWithHeaders(tt.method, tt.path, tt.query, tt.body, tt.headers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			assert.Equal(t, tt.args, result.Args)
+		})
+	}
+}
+
+func TestConvertParameterizedCountSQL(t *testing.T) {
+	conv := NewConverter(WithParameters())
+	result, err := conv.ConvertWithHeaders("GET", "/users", "age=gte.18", "", map[string]string{"Prefer": "count=exact"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "SELECT * FROM users WHERE age >= $1", result.SQL)
+	assert.Equal(t, []interface{}{int64(18)}, result.Args)
+	assert.Equal(t, "SELECT count(*) FROM users WHERE age >= 18", result.Metadata["count_sql"])
+}
+
+func TestConvertInlineLiteralsByDefault(t *testing.T) {
+	result, err := NewConverter().Convert("GET", "/users", "age=gte.18", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "SELECT * FROM users WHERE age >= 18", result.SQL)
+	assert.Nil(t, result.Args)
+}
+
+func TestFormatSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{
+			name:     "single column select",
+			sql:      "SELECT * FROM users",
+			expected: "SELECT *\nFROM users",
+		},
+		{
+			name: "select list, joins and where",
+			sql:  "SELECT users.name, posts.title FROM users LEFT JOIN posts ON posts.users_id = users.id AND posts.status = 'published' WHERE age > 30 AND (role = 'a' OR role = 'b') ORDER BY name ASC LIMIT 10",
+			expected: `SELECT
+  users.name,
+  posts.title
+FROM users
+  LEFT JOIN posts ON posts.users_id = users.id
+    AND posts.status = 'published'
+WHERE age > 30
+  AND (role = 'a' OR role = 'b')
+ORDER BY name ASC
+LIMIT 10`,
+		},
+		{
+			name:     "quoted text is untouched",
+			sql:      `SELECT * FROM notes WHERE body = 'a, b AND c FROM d' AND "from" = 1`,
+			expected: "SELECT *\nFROM notes\nWHERE body = 'a, b AND c FROM d'\n  AND \"from\" = 1",
+		},
+		{
+			name:     "is distinct from",
+			sql:      "SELECT * FROM users WHERE name IS DISTINCT FROM 'bob'",
+			expected: "SELECT *\nFROM users\nWHERE name IS DISTINCT FROM 'bob'",
+		},
+		{
+			name: "bulk insert",
+			sql:  "INSERT INTO users (age, name) VALUES (30, 'Alice'), (25, 'Bob') RETURNING *",
+			expected: `INSERT INTO users (age, name)
+VALUES
+  (30, 'Alice'),
+  (25, 'Bob')
+RETURNING *`,
+		},
+		{
+			name: "upsert",
+			sql:  "INSERT INTO users (id, name) VALUES (1, 'Alice') ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name",
+			expected: `INSERT INTO users (id, name)
+VALUES (1, 'Alice')
+ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name`,
+		},
+		{
+			name: "update",
+			sql:  "UPDATE users SET age = 31, name = 'Al' WHERE id = 1",
+			expected: `UPDATE users
+SET
+  age = 31,
+  name = 'Al'
+WHERE id = 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatSQL(tt.sql))
+		})
+	}
+}
+
+func TestConvertPrettySQL(t *testing.T) {
+	conv := NewConverter(WithPrettySQL())
+	result, err := conv.Convert("GET", "/users", "select=id,name&age=gte.18&status=eq.active", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "SELECT\n  id,\n  name\nFROM users\nWHERE age >= 18\n  AND status = 'active'", result.SQL)
+}
+
+func TestConvertTxRollback(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		body     string
+		prefer   string
+		expected string
+		rollback bool
+	}{
+		{
+			name:     "update",
+			method:   "PATCH",
+			query:    "id=eq.1",
+			body:     `{"status":"done"}`,
+			prefer:   "tx=rollback",
+			expected: "BEGIN; UPDATE tasks SET status = 'done' WHERE id = 1; ROLLBACK;",
+			rollback: true,
+		},
+		{
+			name:     "insert with return",
+			method:   "POST",
+			body:     `{"title":"a"}`,
+			prefer:   "return=representation, tx=rollback",
+			expected: "BEGIN; INSERT INTO tasks (title) VALUES ('a') RETURNING *; ROLLBACK;",
+			rollback: true,
+		},
+		{
+			name:     "delete",
+			method:   "DELETE",
+			query:    "id=eq.1",
+			prefer:   "tx=rollback",
+			expected: "BEGIN; DELETE FROM tasks WHERE id = 1; ROLLBACK;",
+			rollback: true,
+		},
+		{
+			name:     "read is not wrapped",
+			method:   "GET",
+			prefer:   "tx=rollback",
+			expected: "SELECT * FROM tasks",
+			rollback: true,
+		},
+		{
+			name:     "commit",
+			method:   "DELETE",
+			query:    "id=eq.1",
+			prefer:   "tx=commit",
+			expected: "DELETE FROM tasks WHERE id = 1",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertWithHeaders(tt.method, "/tasks", tt.query, tt.body, map[string]string{"Prefer": tt.prefer})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			if tt.rollback {
+				assert.Equal(t, "rollback", result.Metadata["transaction"])
+			} else {
+				assert.NotContains(t, result.Metadata, "transaction")
+			}
+		})
+	}
+}
+
+func TestConvertTxRollbackPretty(t *testing.T) {
+	conv := NewConverter(WithPrettySQL())
+	result, err := conv.ConvertWithHeaders("PATCH", "/tasks", "id=eq.1", `{"status":"done"}`, map[string]string{"Prefer": "tx=rollback"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "BEGIN;\nUPDATE tasks\nSET status = 'done'\nWHERE id = 1;\nROLLBACK;", result.SQL)
+	assert.NotEmpty(t, result.Warnings)
+}
+
+func TestConvertEmbedPaging(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		warning  string
+	}{
+		{
+			name:     "limit and order",
+			query:    "select=name,posts(title)&posts.order=created_at.desc&posts.limit=5",
+			expected: "SELECT users.name, posts.title FROM users LEFT JOIN LATERAL (SELECT * FROM posts WHERE posts.users_id = users.id ORDER BY created_at DESC LIMIT 5) AS posts ON true",
+		},
+		{
+			name:     "offset with embed filter",
+			query:    "select=name,posts(title)&posts.status=eq.published&posts.offset=10&posts.limit=5",
+			expected: "SELECT users.name, posts.title FROM users LEFT JOIN LATERAL (SELECT * FROM posts WHERE posts.users_id = users.id AND posts.status = 'published' LIMIT 5 OFFSET 10) AS posts ON true",
+		},
+		{
+			name:     "inner join",
+			query:    "select=name,posts!inner(title)&posts.limit=1",
+			expected: "SELECT users.name, posts.title FROM users INNER JOIN LATERAL (SELECT * FROM posts WHERE posts.users_id = users.id LIMIT 1) AS posts ON true",
+		},
+		{
+			name:     "nested embed",
+			query:    "select=name,posts(title,comments(body))&posts.comments.limit=2",
+			expected: "SELECT users.name, posts.title, comments.body FROM users LEFT JOIN posts ON posts.users_id = users.id LEFT JOIN LATERAL (SELECT * FROM comments WHERE comments.posts_id = posts.id LIMIT 2) AS comments ON true",
+		},
+		{
+			name:     "not embedded",
+			query:    "posts.limit=5",
+			expected: "SELECT * FROM users",
+			warning:  "order, limit and offset on posts are ignored because posts is not embedded in select",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/users", tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			if tt.warning != "" {
+				assert.Contains(t, result.Warnings, tt.warning)
+			}
+		})
+	}
+}
+
+func TestConvertEmbedPagingInvalid(t *testing.T) {
+	conv := NewConverter()
+
+	_, err := conv.Convert("GET", "/users", "select=name,posts(title)&posts.limit=abc", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid limit value")
+
+	_, err = conv.Convert("GET", "/users", "select=name,posts(title)&posts.offset=-1", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "offset must not be negative")
+}
+
+func TestConvertRepeatedFilterKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "range on one column",
+			query:    "age=gte.18&age=lte.65",
+			expected: "SELECT * FROM users WHERE age >= 18 AND age <= 65",
+		},
+		{
+			name:     "keeps query order",
+			query:    "age=gte.18&status=eq.active&age=lte.65",
+			expected: "SELECT * FROM users WHERE age >= 18 AND status = 'active' AND age <= 65",
+		},
+		{
+			name:     "repeated or groups",
+			query:    "or=(a.eq.1,b.eq.2)&or=(c.eq.3,d.eq.4)",
+			expected: "SELECT * FROM users WHERE (a = 1 OR b = 2) AND (c = 3 OR d = 4)",
+		},
+		{
+			name:     "limit keeps its first value",
+			query:    "limit=5&limit=10",
+			expected: "SELECT * FROM users LIMIT 5",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("GET", "/users", tt.query, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertCSVBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:        "bulk insert",
+			contentType: "text/csv",
+			body:        "name,age\nAlice,30\nBob,25\n",
+			expected:    "INSERT INTO users (age, name) VALUES ('30', 'Alice'), ('25', 'Bob')",
+		},
+		{
+			name:        "quoted fields and NULL",
+			contentType: "text/csv; charset=utf-8",
+			body:        "name,bio\n\"O'Brien, Jr\",NULL\n",
+			expected:    "INSERT INTO users (bio, name) VALUES (NULL, 'O''Brien, Jr')",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertWithHeaders("POST", "/users", "", tt.body, map[string]string{"Content-Type": tt.contentType})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertCSVBodyInvalid(t *testing.T) {
+	conv := NewConverter()
+	headers := map[string]string{"Content-Type": "text/csv"}
+
+	_, err := conv.ConvertWithHeaders("POST", "/users", "", "name,age\n", headers)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "header row and at least one data row")
+
+	_, err = conv.ConvertWithHeaders("POST", "/users", "", "name,age\nAlice\n", headers)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid CSV body")
+}
+
+func TestConvertFormBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		body     string
+		expected string
+	}{
+		{
+			name:     "insert",
+			method:   "POST",
+			body:     "name=Alice+Smith&age=30",
+			expected: "INSERT INTO users (age, name) VALUES ('30', 'Alice Smith')",
+		},
+		{
+			name:     "update",
+			method:   "PATCH",
+			query:    "id=eq.1",
+			body:     "status=active&note=it%27s+done",
+			expected: "UPDATE users SET note = 'it''s done', status = 'active' WHERE id = 1",
+		},
+	}
+
+	conv := NewConverter()
+	headers := map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertWithHeaders(tt.method, "/users", tt.query, tt.body, headers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertFormBodyInvalid(t *testing.T) {
+	conv := NewConverter()
+	_, err := conv.ConvertWithHeaders("POST", "/users", "", "name=%zz", map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid form body")
+}
+
+func TestConvertPlanAccept(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		body     string
+		headers  map[string]string
+		expected string
+		plan     string
+	}{
+		{
+			name:     "text",
+			method:   "GET",
+			query:    "age=gte.18",
+			headers:  map[string]string{"Accept": "application/vnd.pgrst.plan+text"},
+			expected: "EXPLAIN (FORMAT TEXT) SELECT * FROM users WHERE age >= 18",
+			plan:     "text",
+		},
+		{
+			name:     "bare media type is text",
+			method:   "GET",
+			headers:  map[string]string{"Accept": "application/vnd.pgrst.plan"},
+			expected: "EXPLAIN (FORMAT TEXT) SELECT * FROM users",
+			plan:     "text",
+		},
+		{
+			name:     "json with options",
+			method:   "GET",
+			headers:  map[string]string{"Accept": `application/vnd.pgrst.plan+json; for="application/json"; options=verbose|analyze`},
+			expected: "EXPLAIN (ANALYZE, VERBOSE, FORMAT JSON) SELECT * FROM users",
+			plan:     "json",
+		},
+		{
+			name:     "analyzed mutation in a rolled-back transaction",
+			method:   "DELETE",
+			query:    "id=eq.1",
+			headers:  map[string]string{"Accept": "application/vnd.pgrst.plan+json; options=analyze", "Prefer": "tx=rollback"},
+			expected: "BEGIN; EXPLAIN (ANALYZE, FORMAT JSON) DELETE FROM users WHERE id = 1; ROLLBACK;",
+			plan:     "json",
+		},
+		{
+			name:     "other accept",
+			method:   "GET",
+			headers:  map[string]string{"Accept": "application/json"},
+			expected: "SELECT * FROM users",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertWithHeaders(tt.method, "/users", tt.query, tt.body, tt.headers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			if tt.plan != "" {
+				assert.Equal(t, tt.plan, result.Metadata["plan"])
+			} else {
+				assert.NotContains(t, result.Metadata, "plan")
+			}
+		})
+	}
+}
+
+func TestConvertPlanAcceptUnknownOption(t *testing.T) {
+	result, err := NewConverter().ConvertWithHeaders("GET", "/users", "", "", map[string]string{
+		"Accept": "application/vnd.pgrst.plan+text; options=analyze|fast",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "EXPLAIN (ANALYZE, FORMAT TEXT) SELECT * FROM users", result.SQL)
+	assert.Contains(t, result.Warnings, "unknown plan option fast ignored")
+}
+
+func TestConvertLimitedMutation(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		body     string
+		expected string
+		warning  string
+	}{
+		{
+			name:     "update",
+			method:   "PATCH",
+			query:    "done=is.false&limit=5&order=id",
+			body:     `{"done":true}`,
+			expected: "UPDATE tasks SET done = true WHERE ctid IN (SELECT ctid FROM tasks WHERE done IS FALSE ORDER BY id ASC LIMIT 5)",
+			warning:  "limit on a mutation selects the affected rows by ctid in a subquery; ctid is only stable within the statement",
+		},
+		{
+			name:     "update without filters",
+			method:   "PATCH",
+			query:    "limit=10&offset=20&order=id.desc",
+			body:     `{"done":true}`,
+			expected: "UPDATE tasks SET done = true WHERE ctid IN (SELECT ctid FROM tasks ORDER BY id DESC LIMIT 10 OFFSET 20)",
+		},
+		{
+			name:     "delete without order",
+			method:   "DELETE",
+			query:    "done=is.true&limit=100",
+			expected: "DELETE FROM tasks WHERE ctid IN (SELECT ctid FROM tasks WHERE done IS TRUE LIMIT 100)",
+			warning:  "limit without order affects arbitrary matching rows; add order= on a unique column",
+		},
+		{
+			name:     "order alone",
+			method:   "DELETE",
+			query:    "id=eq.1&order=id",
+			expected: "DELETE FROM tasks WHERE id = 1",
+			warning:  "order has no effect on a mutation without limit",
+		},
+		{
+			name:     "insert ignores limit",
+			method:   "POST",
+			query:    "limit=1",
+			body:     `{"title":"a"}`,
+			expected: "INSERT INTO tasks (title) VALUES ('a')",
+			warning:  "limit and offset have no effect on POST",
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.method, "/tasks", tt.query, tt.body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			if tt.warning != "" {
+				assert.Contains(t, result.Warnings, tt.warning)
+			}
+		})
+	}
+}
+
+func TestConvertMaxAffected(t *testing.T) {
+	conv := NewConverter()
+
+	result, err := conv.ConvertWithHeaders("DELETE", "/tasks", "done=is.true", "", map[string]string{"Prefer": "handling=strict, max-affected=10"})
+	require.NoError(t, err)
+	assert.Equal(t, "DELETE FROM tasks WHERE done IS TRUE", result.SQL)
+	assert.Equal(t, "10", result.Metadata["max_affected"])
+	assert.Contains(t, result.Warnings, "PostgREST fails the request and rolls it back when more than 10 rows are affected; the SQL does not check this")
+
+	result, err = conv.ConvertWithHeaders("DELETE", "/tasks", "done=is.true", "", map[string]string{"Prefer": "max-affected=10"})
+	require.NoError(t, err)
+	assert.NotContains(t, result.Metadata, "max_affected")
+	assert.Contains(t, result.Warnings, "Prefer: max-affected only applies with handling=strict")
+}
+
+func TestConvertHTTPRequest(t *testing.T) {
+	t.Run("canonical request", func(t *testing.T) {
+		conv := NewConverter(WithBaseURL("http://localhost:3000/"))
+		result, err := conv.ConvertWithHeaders("patch", "users", "id=eq.1&select=id,name", `{ "status": "active" }`, map[string]string{"prefer": "return=representation"})
+		require.NoError(t, err)
+		require.NotNil(t, result.HTTPRequest)
+
+		assert.Equal(t, "PATCH", result.HTTPRequest.Method)
+		assert.Equal(t, "http://localhost:3000/users?id=eq.1&select=id%2Cname", result.HTTPRequest.URL)
+		assert.Equal(t, map[string]string{
+			"Prefer":       "return=representation",
+			"Content-Type": "application/json",
+		}, result.HTTPRequest.Headers)
+		assert.Equal(t, `{"status":"active"}`, result.HTTPRequest.Body)
+	})
+
+	t.Run("without a base URL", func(t *testing.T) {
+		result, err := NewConverter().Convert("GET", "/users", "name=eq.John Doe", "")
+		require.NoError(t, err)
+		require.NotNil(t, result.HTTPRequest)
+
+		assert.Equal(t, "/users?name=eq.John+Doe", result.HTTPRequest.URL)
+		assert.Empty(t, result.HTTPRequest.Headers)
+		assert.Empty(t, result.HTTPRequest.Body)
+
+		encoded, err := json.Marshal(result.HTTPRequest)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"method":"GET","url":"/users?name=eq.John+Doe"}`, string(encoded))
+	})
+
+	t.Run("url keeps its host", func(t *testing.T) {
+		result, err := NewConverter().ConvertURL("GET", "https://abc.supabase.co/rest/v1/users?age=gte.18", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "https://abc.supabase.co/rest/v1/users?age=gte.18", result.HTTPRequest.URL)
+	})
+
+	t.Run("csv body is kept as is", func(t *testing.T) {
+		result, err := NewConverter().ConvertWithHeaders("POST", "/users", "", "name\nAlice\n", map[string]string{"Content-Type": "text/csv"})
+		require.NoError(t, err)
+		assert.Equal(t, "text/csv", result.HTTPRequest.Headers["Content-Type"])
+		assert.Equal(t, "name\nAlice\n", result.HTTPRequest.Body)
+	})
+}
+
+func TestConvertRawRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+		url      string
+		headers  map[string]string
+	}{
+		{
+			name:     "get",
+			raw:      "GET /users?age=gte.18&select=id,name HTTP/1.1\r\nHost: localhost:3000\r\nAccept: application/json\r\n\r\n",
+			expected: "SELECT id, name FROM users WHERE age >= 18",
+			url:      "http://localhost:3000/users?age=gte.18&select=id%2Cname",
+			headers:  map[string]string{"Accept": "application/json"},
+		},
+		{
+			name: "post with LF line endings and a stale Content-Length",
+			raw: `POST /rest/v1/users HTTP/1.1
+Host: abc.supabase.co
+Content-Type: application/json
+Content-Length: 2
+Prefer: return=representation
+
+{"name":"Alice"}
+`,
+			expected: "INSERT INTO users (name) VALUES ('Alice') RETURNING *",
+			url:      "https://abc.supabase.co/rest/v1/users",
+			headers:  map[string]string{"Content-Type": "application/json", "Prefer": "return=representation"},
+		},
+		{
+			name:     "http/2 request line without host",
+			raw:      "DELETE /users?id=eq.1 HTTP/2\n\n",
+			expected: "DELETE FROM users WHERE id = 1",
+			url:      "/users?id=eq.1",
+			headers:  map[string]string{},
+		},
+		{
+			name:     "absolute target",
+			raw:      "GET http://db.example.com/users?id=eq.1 HTTP/1.1\r\n\r\n",
+			expected: "SELECT * FROM users WHERE id = 1",
+			url:      "http://db.example.com/users?id=eq.1",
+			headers:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertRawRequest(tt.raw)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			require.NotNil(t, result.HTTPRequest)
+			assert.Equal(t, tt.url, result.HTTPRequest.URL)
+			assert.Equal(t, tt.headers, result.HTTPRequest.Headers)
+		})
+	}
+}
+
+func TestConvertRawRequestInvalid(t *testing.T) {
+	_, err := ConvertRawRequest("not a request")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid HTTP request")
+}
+
+func TestConvertQuotedInList(t *testing.T) {
+	conv := NewConverter()
+	result, err := conv.Convert("GET", "/users", `status=in.("a,b","c (d)",plain,"say \"hi\"")`, "")
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM users WHERE status IN ('a,b', 'c (d)', 'plain', 'say "hi"')`, result.SQL)
+
+	result, err = conv.Convert("GET", "/users", `status=not.in.("banned","deleted")`, "")
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM users WHERE NOT (status IN ('banned', 'deleted'))`, result.SQL)
+}
+
+func TestConvertRangeHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		headers  map[string]string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "closed range",
+			headers:  map[string]string{"Range": "20-29", "Range-Unit": "items"},
+			expected: "SELECT * FROM users LIMIT 10 OFFSET 20",
+		},
+		{
+			name:     "first page",
+			query:    "order=id",
+			headers:  map[string]string{"Range": "0-24"},
+			expected: "SELECT * FROM users ORDER BY id ASC LIMIT 25",
+		},
+		{
+			name:     "open ended",
+			query:    "order=id",
+			headers:  map[string]string{"Range": "20-"},
+			expected: "SELECT * FROM users ORDER BY id ASC OFFSET 20",
+		},
+		{
+			name:     "intersected with limit and offset",
+			query:    "limit=10&offset=5",
+			headers:  map[string]string{"Range": "0-99"},
+			expected: "SELECT * FROM users LIMIT 10 OFFSET 5",
+		},
+		{
+			name:     "range narrower than limit",
+			query:    "limit=100",
+			headers:  map[string]string{"Range": "10-19"},
+			expected: "SELECT * FROM users LIMIT 10 OFFSET 10",
+		},
+		{
+			name:    "end before start",
+			headers: map[string]string{"Range": "9-2"},
+			wantErr: true,
+		},
+		{
+			name:    "not a range",
+			headers: map[string]string{"Range": "bytes"},
+			wantErr: true,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertWithHeaders("GET", "/users", tt.query, "", tt.headers)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+		})
+	}
+}
+
+func TestConvertInsertColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		query    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "single row restricted",
+			query:    "columns=name,age",
+			body:     `{"name":"Alice","age":30,"role":"admin"}`,
+			expected: "INSERT INTO users (name, age) VALUES ('Alice', 30)",
+			warnings: 1,
+		},
+		{
+			name:     "bulk rows with missing key",
+			query:    "columns=name,age",
+			body:     `[{"name":"Alice","age":30},{"name":"Bob"}]`,
+			expected: "INSERT INTO users (name, age) VALUES ('Alice', 30), ('Bob', NULL)",
+			warnings: 1,
+		},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert("POST", "/users", tt.query, tt.body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.SQL)
+			assert.Len(t, result.Warnings, tt.warnings)
+		})
+	}
+}
+
+func TestConvertMissingDefault(t *testing.T) {
+	conv := NewConverter()
+
+	t.Run("missing=default fills absent keys with DEFAULT", func(t *testing.T) {
+		result, err := conv.ConvertWithHeaders("POST", "/users", "columns=name,age",
+			`[{"name":"Alice","age":30},{"name":"Bob"}]`, map[string]string{"Prefer": "missing=default"})
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO users (name, age) VALUES ('Alice', 30), ('Bob', DEFAULT)", result.SQL)
+		assert.Empty(t, result.Warnings)
+	})
+
+	t.Run("later rows' keys are not dropped", func(t *testing.T) {
+		result, err := conv.Convert("POST", "/users", "", `[{"name":"Alice"},{"name":"Bob","age":4}]`)
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO users (age, name) VALUES (NULL, 'Alice'), (4, 'Bob')", result.SQL)
+		assert.Len(t, result.Warnings, 2)
+	})
+}
@@ -5,15 +5,20 @@ import (
 )
 
 // buildDeleteStatement builds a DELETE statement from a DELETE request
-func buildDeleteStatement(req *PostgRESTRequest) (string, error) {
-	sql := fmt.Sprintf("DELETE FROM %s", req.Table)
+func buildDeleteStatement(req *PostgRESTRequest, p *params) (string, error) {
+	sql := fmt.Sprintf("DELETE FROM %s", qualifiedName(req, req.Table))
 
 	// WHERE clause is required (already validated in ValidateRequest)
-	whereClause, err := buildWhereClause(req.Filters)
+	whereClause, err := buildWhereClause(p, req.Filters)
 	if err != nil {
 		return "", err
 	}
 
+	// limit=5&order=id deletes only the first matching rows
+	if limitedMutation(req) {
+		whereClause = limitedWhereClause(req, whereClause)
+	}
+
 	sql += " " + whereClause
 
 	return sql, nil
@@ -0,0 +1,195 @@
+package reverse
+
+import "strings"
+
+// clauseKeywords start a new line in formatted SQL, longest phrases first
+var clauseKeywords = [][]string{
+	{"INSERT", "INTO"},
+	{"DELETE", "FROM"},
+	{"ON", "CONFLICT"},
+	{"GROUP", "BY"},
+	{"ORDER", "BY"},
+	{"SELECT"},
+	{"FROM"},
+	{"WHERE"},
+	{"HAVING"},
+	{"LIMIT"},
+	{"OFFSET"},
+	{"VALUES"},
+	{"UPDATE"},
+	{"SET"},
+	{"RETURNING"},
+}
+
+// listClauses put each item on its own line when there is more than one
+var listClauses = map[string]bool{
+	"SELECT": true,
+	"SET":    true,
+	"VALUES": true,
+}
+
+// FormatSQL lays a generated statement out over multiple indented lines:
+// each clause starts a line, SELECT/SET/VALUES items get a line each, and
+// every JOIN and every top-level AND of a WHERE or ON goes on its own line.
+//
+//	SELECT
+//	  users.name,
+//	  posts.title
+//	FROM users
+//	  LEFT JOIN posts ON posts.users_id = users.id
+//	    AND posts.status = 'published'
+//	WHERE age > 18
+//	  AND active = true
+//
+// Text inside quotes and parentheses is left as is.
+func FormatSQL(sql string) string {
+	tokens := sqlTokens(sql)
+	f := &sqlFormatter{}
+
+	clause := ""
+	listMode := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		if phrase := clauseAt(tokens, i); phrase != nil {
+			f.newline("")
+			for _, word := range phrase {
+				f.word(word)
+			}
+			i += len(phrase) - 1
+			clause = strings.Join(phrase, " ")
+			listMode = listClauses[clause] && hasListItems(tokens, i+1)
+			if listMode {
+				f.newline("  ")
+			}
+			continue
+		}
+
+		switch {
+		case (token == "LEFT" || token == "INNER") && i+1 < len(tokens) && tokens[i+1] == "JOIN":
+			f.newline("  ")
+			clause = "JOIN"
+			listMode = false
+		case token == "AND" && (clause == "WHERE" || clause == "HAVING"):
+			f.newline("  ")
+		case token == "AND" && clause == "JOIN":
+			f.newline("    ")
+		}
+
+		f.word(token)
+		switch {
+		case strings.HasSuffix(token, ";"):
+			// A statement ends; the next one starts a fresh line
+			f.newline("")
+			clause = ""
+			listMode = false
+		case listMode && strings.HasSuffix(token, ","):
+			f.newline("  ")
+		}
+	}
+
+	return f.b.String()
+}
+
+// clauseAt returns the clause keyword phrase starting at tokens[i], if any.
+// DO UPDATE SET (upserts) and IS DISTINCT FROM are not clauses.
+func clauseAt(tokens []string, i int) []string {
+	prev := ""
+	if i > 0 {
+		prev = tokens[i-1]
+	}
+	if prev == "DO" || prev == "DISTINCT" || (tokens[i] == "SET" && i > 1 && tokens[i-2] == "DO") {
+		return nil
+	}
+
+	for _, phrase := range clauseKeywords {
+		if i+len(phrase) > len(tokens) {
+			continue
+		}
+		matches := true
+		for j, word := range phrase {
+			if tokens[i+j] != word {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return phrase
+		}
+	}
+	return nil
+}
+
+// hasListItems reports whether the clause starting at tokens[i] lists more
+// than one item, i.e. has a top-level comma before the next clause
+func hasListItems(tokens []string, i int) bool {
+	for ; i < len(tokens); i++ {
+		if clauseAt(tokens, i) != nil || strings.HasSuffix(tokens[i], ";") {
+			return false
+		}
+		if strings.HasSuffix(tokens[i], ",") {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlTokens splits SQL on the spaces outside quotes and parentheses, so
+// count(*), 'a b' and (age < 18 OR age > 65) each stay one token
+func sqlTokens(sql string) []string {
+	var tokens []string
+	var current strings.Builder
+	depth := 0
+	inSingle, inDouble := false, false
+
+	for _, c := range sql {
+		switch {
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case c == '"' && !inSingle:
+			inDouble = !inDouble
+		case inSingle || inDouble:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case (c == ' ' || c == '\n' || c == '\t') && depth == 0:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(c)
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// sqlFormatter writes tokens separated by spaces, or by a line break and
+// indent when one is pending
+type sqlFormatter struct {
+	b       strings.Builder
+	pending bool
+	indent  string
+}
+
+// newline breaks the line before the next token
+func (f *sqlFormatter) newline(indent string) {
+	f.pending = true
+	f.indent = indent
+}
+
+func (f *sqlFormatter) word(token string) {
+	switch {
+	case f.b.Len() == 0:
+	case f.pending:
+		f.b.WriteString("\n" + f.indent)
+	default:
+		f.b.WriteByte(' ')
+	}
+	f.b.WriteString(token)
+	f.pending = false
+}
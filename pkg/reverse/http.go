@@ -0,0 +1,54 @@
+package reverse
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// canonicalRequest rebuilds the request in a normalized form: the method in
+// upper case, the path with a leading slash, the query re-encoded in its
+// original order, canonical header names, and JSON bodies compacted with a
+// Content-Type
+func canonicalRequest(baseURL string, req *PostgRESTRequest, path, query, body string) (*HTTPRequest, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	params, err := parseQueryString(query)
+	if err != nil {
+		return nil, NewSyntaxError("invalid query string", query, "check URL encoding")
+	}
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		parts = append(parts, url.QueryEscape(param.key)+"="+url.QueryEscape(param.value))
+	}
+
+	requestURL := baseURL + path
+	if len(parts) > 0 {
+		requestURL += "?" + strings.Join(parts, "&")
+	}
+
+	headers := make(map[string]string, len(req.Headers)+1)
+	for key, value := range req.Headers {
+		headers[key] = value
+	}
+
+	if body != "" && headers["Content-Type"] == "" && json.Valid([]byte(body)) {
+		headers["Content-Type"] = "application/json"
+	}
+	if strings.HasPrefix(headers["Content-Type"], "application/json") {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, []byte(body)); err == nil {
+			body = compact.String()
+		}
+	}
+
+	return &HTTPRequest{
+		Method:  req.Method,
+		URL:     requestURL,
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
@@ -0,0 +1,219 @@
+package reverse
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// identifierPattern is what the converter accepts as a table, column,
+	// schema or function name; anything else could inject SQL
+	identifierPattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_$]*$`)
+
+	// typePattern accepts cast targets like int, text[], numeric(10,2) and
+	// timestamp with time zone
+	typePattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_ ]*(\(\d+(,\s*\d+)?\))?(\[\])*$`)
+)
+
+// reservedWords are the PostgreSQL keywords that cannot be used as a table
+// or column name without double quotes
+var reservedWords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true,
+	"as": true, "asc": true, "asymmetric": true, "authorization": true, "binary": true,
+	"both": true, "case": true, "cast": true, "check": true, "collate": true, "collation": true,
+	"column": true, "concurrently": true, "constraint": true, "create": true, "cross": true,
+	"current_catalog": true, "current_date": true, "current_role": true, "current_schema": true,
+	"current_time": true, "current_timestamp": true, "current_user": true, "default": true,
+	"deferrable": true, "desc": true, "distinct": true, "do": true, "else": true, "end": true,
+	"except": true, "false": true, "fetch": true, "for": true, "foreign": true, "freeze": true,
+	"from": true, "full": true, "grant": true, "group": true, "having": true, "ilike": true,
+	"in": true, "initially": true, "inner": true, "intersect": true, "into": true, "is": true,
+	"isnull": true, "join": true, "lateral": true, "leading": true, "left": true, "like": true,
+	"limit": true, "localtime": true, "localtimestamp": true, "natural": true, "not": true,
+	"notnull": true, "null": true, "offset": true, "on": true, "only": true, "or": true,
+	"order": true, "outer": true, "overlaps": true, "placing": true, "primary": true,
+	"references": true, "returning": true, "right": true, "select": true, "session_user": true,
+	"similar": true, "some": true, "symmetric": true, "system_user": true, "table": true,
+	"tablesample": true, "then": true, "to": true, "trailing": true, "true": true, "union": true,
+	"unique": true, "user": true, "using": true, "variadic": true, "verbose": true, "when": true,
+	"where": true, "window": true, "with": true,
+}
+
+// identifierSQL double-quotes a name unless it is a plain lowercase
+// identifier that is not a reserved word
+func identifierSQL(name string) string {
+	plain := name != "" && !isDigit(name[0]) && !reservedWords[name]
+	for _, c := range name {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// qualifiedIdentifierSQL quotes each part of a dotted name like
+// posts.title, leaving a * part as is
+func qualifiedIdentifierSQL(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = identifierSQL(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// identifierListSQL quotes and joins a column list
+func identifierListSQL(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = identifierSQL(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// columnSQL renders a column reference: a possibly qualified name or a JSON
+// path on one
+func columnSQL(column string) string {
+	if isJSONPath(column) {
+		return jsonPathSQL(column)
+	}
+	return qualifiedIdentifierSQL(column)
+}
+
+// validateIdentifier rejects names that are not plain identifiers
+func validateIdentifier(name, kind string) error {
+	if identifierPattern.MatchString(name) {
+		return nil
+	}
+	return NewSyntaxError(
+		"invalid "+kind+" name: "+name,
+		name,
+		"names may contain only letters, digits, _ and $, and must not start with a digit",
+	)
+}
+
+// validateColumn checks a column reference: dotted embed paths like
+// posts.title and the column a JSON path starts from (JSON keys are quoted
+// as string literals, so they may contain anything)
+func validateColumn(column string) error {
+	column, _ = splitJSONPath(column)
+	for _, part := range strings.Split(column, ".") {
+		if err := validateIdentifier(part, "column"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateIdentifiers checks every name the request interpolates into SQL
+func validateIdentifiers(req *PostgRESTRequest) error {
+	if err := validateIdentifier(req.Table, "table"); err != nil {
+		return err
+	}
+	if schema := profileSchema(req); schema != "" {
+		if err := validateIdentifier(schema, "schema"); err != nil {
+			return err
+		}
+	}
+
+	if err := validateFilterColumns(req.Filters); err != nil {
+		return err
+	}
+	for _, order := range req.Order {
+		if err := validateColumn(order.Column); err != nil {
+			return err
+		}
+	}
+	for _, page := range req.EmbedPages {
+		for _, order := range page.Order {
+			if err := validateColumn(order.Column); err != nil {
+				return err
+			}
+		}
+	}
+
+	var names []string
+	names = append(names, req.OnConflict...)
+	names = append(names, req.Columns...)
+	names = append(names, bodyColumns(req.Body)...)
+	for name := range req.Args {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if err := validateIdentifier(name, "column"); err != nil {
+			return err
+		}
+	}
+
+	if len(req.Select) == 0 || (len(req.Select) == 1 && req.Select[0] == "*") {
+		return nil
+	}
+	mainCols, embeds, err := ParseEmbeddedResources(req.Select)
+	if err != nil {
+		return err
+	}
+	return validateSelect(mainCols, embeds)
+}
+
+// validateFilterColumns checks filter columns, including grouped ones
+func validateFilterColumns(filters []Filter) error {
+	for _, filter := range filters {
+		if len(filter.Conditions) > 0 {
+			if err := validateFilterColumns(filter.Conditions); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := validateColumn(filter.Column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSelect checks select items and embeds, recursively
+func validateSelect(columns []string, embeds []EmbeddedResource) error {
+	for _, item := range columns {
+		column, cast, alias := parseSelectItem(item)
+		if function, arg, ok := parseAggregate(column); ok {
+			if function == "count" && arg == "" {
+				column = ""
+			} else {
+				column = arg
+			}
+		}
+		if column != "" && column != "*" {
+			if err := validateColumn(column); err != nil {
+				return err
+			}
+		}
+		if alias != "" {
+			if err := validateIdentifier(alias, "alias"); err != nil {
+				return err
+			}
+		}
+		if cast != "" && !typePattern.MatchString(cast) {
+			return NewSyntaxError("invalid cast type: "+cast, item, "cast to a type name like ::text or ::numeric(10,2)")
+		}
+	}
+
+	for _, embed := range embeds {
+		for _, name := range []string{embed.Relation, embed.Alias, embed.Hint} {
+			if name == "" {
+				continue
+			}
+			if err := validateIdentifier(name, "embedded resource"); err != nil {
+				return err
+			}
+		}
+		if err := validateSelect(embed.Select, embed.Embedded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,11 +3,12 @@ package reverse
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 )
 
 // buildInsertStatement builds an INSERT statement from a POST request
-func buildInsertStatement(req *PostgRESTRequest) (string, error) {
+func buildInsertStatement(req *PostgRESTRequest, p *params) (string, error) {
 	if req.Body == nil {
 		return "", NewSemanticError(
 			"ERR_SEMANTIC_NO_BODY",
@@ -17,14 +18,31 @@ func buildInsertStatement(req *PostgRESTRequest) (string, error) {
 		)
 	}
 
+	// Prefer: missing=default fills keys a row lacks with the column default
+	missing := "NULL"
+	if preferences(req)["missing"] == "default" {
+		missing = "DEFAULT"
+	}
+
+	table := qualifiedName(req, req.Table)
+
+	// columns= fixes the column list, whatever keys the body has
+	if len(req.Columns) > 0 {
+		rows, ok := req.Body.([]interface{})
+		if !ok {
+			rows = []interface{}{req.Body}
+		}
+		return buildInsertRows(p, table, req.Columns, rows, missing)
+	}
+
 	// Check if body is a single object or an array (bulk insert)
 	switch body := req.Body.(type) {
 	case map[string]interface{}:
 		// Single row insert
-		return buildSingleInsert(req.Table, body)
+		return buildSingleInsert(p, table, body)
 	case []interface{}:
 		// Bulk insert
-		return buildBulkInsert(req.Table, body)
+		return buildBulkInsert(p, table, body, missing)
 	default:
 		return "", NewSyntaxError(
 			"invalid body format",
@@ -35,7 +53,7 @@ func buildInsertStatement(req *PostgRESTRequest) (string, error) {
 }
 
 // buildSingleInsert builds an INSERT for a single row
-func buildSingleInsert(table string, data map[string]interface{}) (string, error) {
+func buildSingleInsert(p *params, table string, data map[string]interface{}) (string, error) {
 	if len(data) == 0 {
 		return "", NewSemanticError(
 			"ERR_SEMANTIC_EMPTY_BODY",
@@ -45,26 +63,26 @@ func buildSingleInsert(table string, data map[string]interface{}) (string, error
 		)
 	}
 
-	var columns []string
-	var values []string
-
-	for col, val := range data {
-		columns = append(columns, col)
-		values = append(values, formatJSONValue(val))
+	// Columns in sorted order so the output is stable
+	columns := bodyColumns(data)
+	values := make([]string, 0, len(columns))
+	for _, col := range columns {
+		values = append(values, p.json(data[col]))
 	}
 
 	sql := fmt.Sprintf(
 		"INSERT INTO %s (%s) VALUES (%s)",
 		table,
-		strings.Join(columns, ", "),
+		identifierListSQL(columns),
 		strings.Join(values, ", "),
 	)
 
 	return sql, nil
 }
 
-// buildBulkInsert builds an INSERT for multiple rows
-func buildBulkInsert(table string, rows []interface{}) (string, error) {
+// buildBulkInsert builds an INSERT for multiple rows over the union of their
+// keys; keys a row lacks get the missing value (NULL or DEFAULT)
+func buildBulkInsert(p *params, table string, rows []interface{}, missing string) (string, error) {
 	if len(rows) == 0 {
 		return "", NewSemanticError(
 			"ERR_SEMANTIC_EMPTY_BODY",
@@ -74,9 +92,7 @@ func buildBulkInsert(table string, rows []interface{}) (string, error) {
 		)
 	}
 
-	// Get columns from first row
-	firstRow, ok := rows[0].(map[string]interface{})
-	if !ok {
+	if _, ok := rows[0].(map[string]interface{}); !ok {
 		return "", NewSyntaxError(
 			"invalid row format",
 			fmt.Sprintf("%v", rows[0]),
@@ -84,9 +100,19 @@ func buildBulkInsert(table string, rows []interface{}) (string, error) {
 		)
 	}
 
-	var columns []string
-	for col := range firstRow {
-		columns = append(columns, col)
+	// The sorted union of every row's keys
+	return buildInsertRows(p, table, bodyColumns(rows), rows, missing)
+}
+
+// buildInsertRows builds a multi-row INSERT over a fixed column list
+func buildInsertRows(p *params, table string, columns []string, rows []interface{}, missing string) (string, error) {
+	if len(rows) == 0 {
+		return "", NewSemanticError(
+			"ERR_SEMANTIC_EMPTY_BODY",
+			"INSERT requires at least one row",
+			"",
+			"provide array of objects in body",
+		)
 	}
 
 	// Build values for each row
@@ -106,9 +132,9 @@ func buildBulkInsert(table string, rows []interface{}) (string, error) {
 			val, ok := rowMap[col]
 			if !ok {
 				// Column missing in this row
-				values = append(values, "NULL")
+				values = append(values, missing)
 			} else {
-				values = append(values, formatJSONValue(val))
+				values = append(values, p.json(val))
 			}
 		}
 
@@ -118,7 +144,7 @@ func buildBulkInsert(table string, rows []interface{}) (string, error) {
 	sql := fmt.Sprintf(
 		"INSERT INTO %s (%s) VALUES %s",
 		table,
-		strings.Join(columns, ", "),
+		identifierListSQL(columns),
 		strings.Join(allValues, ", "),
 	)
 
@@ -160,3 +186,133 @@ func formatJSONValue(val interface{}) string {
 		return fmt.Sprintf("'%v'", v)
 	}
 }
+
+// missingKeyWarnings notes bulk rows whose key sets differ, which PostgREST
+// only accepts with columns= and fills with NULL unless Prefer: missing=default
+func missingKeyWarnings(req *PostgRESTRequest) []string {
+	rows, ok := req.Body.([]interface{})
+	if !ok || len(rows) < 2 {
+		return nil
+	}
+
+	columns := bodyColumns(rows)
+	heterogeneous := false
+	for _, row := range rows {
+		if rowMap, ok := row.(map[string]interface{}); ok && len(rowMap) != len(columns) {
+			heterogeneous = true
+			break
+		}
+	}
+	if !heterogeneous {
+		return nil
+	}
+
+	var warnings []string
+	if len(req.Columns) == 0 {
+		warnings = append(warnings, "bulk rows have different keys; PostgREST rejects this unless columns= lists them")
+	}
+	if preferences(req)["missing"] != "default" {
+		warnings = append(warnings, "keys missing from a row are inserted as NULL; send Prefer: missing=default to use column defaults")
+	}
+	return warnings
+}
+
+// ignoredColumnWarnings lists body keys that columns= leaves out
+func ignoredColumnWarnings(req *PostgRESTRequest) []string {
+	if len(req.Columns) == 0 {
+		return nil
+	}
+
+	listed := map[string]bool{}
+	for _, column := range req.Columns {
+		listed[column] = true
+	}
+
+	var warnings []string
+	for _, column := range bodyColumns(req.Body) {
+		if !listed[column] {
+			warnings = append(warnings, fmt.Sprintf("body key %s is not in columns= and is ignored", column))
+		}
+	}
+	return warnings
+}
+
+// buildOnConflictClause renders the upsert clause requested by
+// Prefer: resolution=merge-duplicates|ignore-duplicates and on_conflict=cols
+func buildOnConflictClause(req *PostgRESTRequest) (string, []string) {
+	var warnings []string
+	resolution := preferences(req)["resolution"]
+
+	switch resolution {
+	case "":
+		if len(req.OnConflict) > 0 {
+			warnings = append(warnings, "on_conflict has no effect without Prefer: resolution=merge-duplicates or resolution=ignore-duplicates")
+		}
+		return "", warnings
+	case "ignore-duplicates":
+		if len(req.OnConflict) == 0 {
+			return " ON CONFLICT DO NOTHING", warnings
+		}
+		return " ON CONFLICT (" + identifierListSQL(req.OnConflict) + ") DO NOTHING", warnings
+	case "merge-duplicates":
+	default:
+		return "", append(warnings, "unknown Prefer resolution "+resolution+" ignored")
+	}
+
+	target := req.OnConflict
+	if len(target) == 0 {
+		// PostgREST uses the primary key, which we can't see without schema
+		target = []string{"id"}
+		warnings = append(warnings, "Assuming primary key id as the conflict target; pass on_conflict to name it")
+	}
+
+	conflict := map[string]bool{}
+	for _, column := range target {
+		conflict[column] = true
+	}
+
+	columns := req.Columns
+	if len(columns) == 0 {
+		columns = bodyColumns(req.Body)
+	}
+
+	var assignments []string
+	for _, column := range columns {
+		if !conflict[column] {
+			assignments = append(assignments, identifierSQL(column)+" = EXCLUDED."+identifierSQL(column))
+		}
+	}
+	if len(assignments) == 0 {
+		return " ON CONFLICT (" + identifierListSQL(target) + ") DO NOTHING", warnings
+	}
+
+	return " ON CONFLICT (" + identifierListSQL(target) + ") DO UPDATE SET " + strings.Join(assignments, ", "), warnings
+}
+
+// bodyColumns returns the sorted set of keys across the body's rows
+func bodyColumns(body interface{}) []string {
+	seen := map[string]bool{}
+	var rows []interface{}
+	switch b := body.(type) {
+	case map[string]interface{}:
+		rows = []interface{}{b}
+	case []interface{}:
+		rows = b
+	}
+
+	var columns []string
+	for _, row := range rows {
+		rowMap, ok := row.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for column := range rowMap {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
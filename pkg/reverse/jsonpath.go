@@ -0,0 +1,84 @@
+package reverse
+
+import (
+	"strings"
+)
+
+// isJSONPath reports whether a column uses PostgREST's JSON path syntax
+// Examples: data->>name, data->address->>city
+func isJSONPath(column string) bool {
+	return strings.Contains(column, "->")
+}
+
+// jsonPathSQL renders a PostgREST JSON path with quoted keys
+// Examples: data->>name -> data->>'name', data->tags->0 -> data->'tags'->0
+func jsonPathSQL(path string) string {
+	column, steps := splitJSONPath(path)
+
+	var sb strings.Builder
+	sb.WriteString(qualifiedIdentifierSQL(column))
+	for _, step := range steps {
+		sb.WriteString(step.operator)
+		sb.WriteString(jsonKeySQL(step.key))
+	}
+	return sb.String()
+}
+
+// jsonPathKey returns the last key of a JSON path, which PostgREST uses as
+// the name of the selected value
+func jsonPathKey(path string) string {
+	_, steps := splitJSONPath(path)
+	if len(steps) == 0 {
+		return path
+	}
+	return steps[len(steps)-1].key
+}
+
+type jsonPathStep struct {
+	operator string // "->" or "->>"
+	key      string
+}
+
+// splitJSONPath splits data->address->>city into data and its path steps
+func splitJSONPath(path string) (string, []jsonPathStep) {
+	i := strings.Index(path, "->")
+	if i == -1 {
+		return path, nil
+	}
+
+	column, rest := path[:i], path[i:]
+	var steps []jsonPathStep
+	for rest != "" {
+		operator := "->"
+		if strings.HasPrefix(rest, "->>") {
+			operator = "->>"
+		}
+		rest = rest[len(operator):]
+
+		key := rest
+		if next := strings.Index(rest, "->"); next != -1 {
+			key, rest = rest[:next], rest[next:]
+		} else {
+			rest = ""
+		}
+		steps = append(steps, jsonPathStep{operator: operator, key: key})
+	}
+	return column, steps
+}
+
+// jsonKeySQL quotes an object key; integer keys stay bare as array indexes
+func jsonKeySQL(key string) string {
+	if key != "" {
+		isIndex := true
+		for i := 0; i < len(key); i++ {
+			if !isDigit(key[i]) && !(i == 0 && key[i] == '-' && len(key) > 1) {
+				isIndex = false
+				break
+			}
+		}
+		if isIndex {
+			return key
+		}
+	}
+	return "'" + strings.ReplaceAll(key, "'", "''") + "'"
+}
@@ -0,0 +1,86 @@
+package reverse
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+var (
+	dateBoundPattern        = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	timestampBoundPattern   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?$`)
+	timestamptzBoundPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}(:?\d{2})?)$`)
+)
+
+// formatCollectionValue renders the operand of the array and range
+// operators (cs, cd, ov, sl, sr, nxr, nxl, adj). Arrays and JSON become
+// quoted literals, which take on the column's type, or bind as text
+// parameters when p is set:
+//
+//	{admin,user}            -> '{admin,user}'
+//	[2024-01-01,2024-06-30] -> '[2024-01-01,2024-06-30]'::daterange
+func formatCollectionValue(p *params, value string) string {
+	if lower, upper, ok := rangeBounds(value); ok {
+		literal := p.text(value)
+		if rangeType := inferRangeType(lower, upper); rangeType != "" {
+			return literal + "::" + rangeType
+		}
+		return literal
+	}
+	if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+		return p.text(value)
+	}
+	return p.value(value)
+}
+
+// rangeBounds splits a range literal like [a,b) into its bounds
+func rangeBounds(value string) (string, string, bool) {
+	if len(value) < 3 || !strings.ContainsAny(value[:1], "[(") || !strings.ContainsAny(value[len(value)-1:], "])") {
+		return "", "", false
+	}
+	// A JSON array such as ["a","b"] is not a range
+	if json.Valid([]byte(value)) {
+		return "", "", false
+	}
+	bounds := strings.Split(value[1:len(value)-1], ",")
+	if len(bounds) != 2 {
+		return "", "", false
+	}
+	return strings.Trim(strings.TrimSpace(bounds[0]), `"`), strings.Trim(strings.TrimSpace(bounds[1]), `"`), true
+}
+
+// inferRangeType picks the range type when the bounds make it unambiguous.
+// Numeric ranges stay untyped: int4range, int8range and numrange all fit,
+// and the untyped literal takes on the column's type.
+func inferRangeType(lower, upper string) string {
+	var bounds []string
+	for _, bound := range []string{lower, upper} {
+		if bound != "" && !strings.EqualFold(bound, "infinity") && !strings.EqualFold(bound, "-infinity") {
+			bounds = append(bounds, bound)
+		}
+	}
+	if len(bounds) == 0 {
+		return ""
+	}
+
+	for _, candidate := range []struct {
+		pattern   *regexp.Regexp
+		rangeType string
+	}{
+		{dateBoundPattern, "daterange"},
+		{timestampBoundPattern, "tsrange"},
+		{timestamptzBoundPattern, "tstzrange"},
+	} {
+		matches := true
+		for _, bound := range bounds {
+			if !candidate.pattern.MatchString(bound) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return candidate.rangeType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package reverse
+
+import (
+	"strings"
+)
+
+// parseLogicalFilter parses a logical group such as or=(...) or and=(...) into
+// a filter whose Conditions are joined by the logical operator. Groups nest to
+// any depth, so the result is a logic tree.
+// Examples: or=(age.lt.18,age.gt.65), or=(and(a.eq.1,b.eq.2),c.is.null)
+func parseLogicalFilter(logical, value string) (Filter, error) {
+	if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+		return Filter{}, NewSyntaxError("invalid "+logical+" format", value, "expected format: "+logical+"=(column.operator.value,...)")
+	}
+
+	items, err := splitLogicItems(value[1 : len(value)-1])
+	if err != nil {
+		return Filter{}, err
+	}
+	if len(items) == 0 {
+		return Filter{}, NewSyntaxError("empty "+logical+" group", value, "add at least one condition inside the parentheses")
+	}
+
+	group := Filter{Logical: logical}
+	for _, item := range items {
+		condition, err := parseLogicItem(item)
+		if err != nil {
+			return Filter{}, err
+		}
+		group.Conditions = append(group.Conditions, condition)
+	}
+
+	return group, nil
+}
+
+// parseLogicItem parses one member of a logical group: either a nested
+// and(...) / or(...) group, possibly negated with not., or a
+// column.operator.value condition
+func parseLogicItem(item string) (Filter, error) {
+	if head, _, found := strings.Cut(item, "("); found {
+		if logical, negated, ok := logicalKey(head); ok {
+			group, err := parseLogicalFilter(logical, item[len(head):])
+			if err != nil {
+				return Filter{}, err
+			}
+			group.Negated = negated
+			return group, nil
+		}
+	}
+
+	column, filterValue, found := strings.Cut(item, ".")
+	if !found || column == "" {
+		return Filter{}, NewSyntaxError("invalid condition in logical group", item, "expected format: column.operator.value")
+	}
+
+	filter, err := parseFilter(column, filterValue)
+	if err != nil {
+		return Filter{}, err
+	}
+	filter.Value = unquoteLogicValue(filter.Value.(string))
+	return filter, nil
+}
+
+// logicalKey recognizes or, and, not.or and not.and
+func logicalKey(key string) (logical string, negated bool, ok bool) {
+	if rest, found := strings.CutPrefix(key, "not."); found {
+		key = rest
+		negated = true
+	}
+	if key == "or" || key == "and" {
+		return key, negated, true
+	}
+	return "", false, false
+}
+
+// splitLogicItems splits a logical group's contents on top-level commas,
+// leaving commas inside parentheses (in lists, nested groups) and double
+// quoted values alone
+func splitLogicItems(s string) ([]string, error) {
+	var items []string
+	var current strings.Builder
+	depth := 0
+	inQuotes := false
+	escaped := false
+
+	for _, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inQuotes:
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == '(' || c == '{' || c == '[':
+			depth++
+		case c == ')' || c == '}' || c == ']':
+			depth--
+			if depth < 0 {
+				return nil, NewSyntaxError("unbalanced parentheses in logical group", s, "check that every ( has a matching )")
+			}
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteRune(c)
+	}
+
+	if depth != 0 || inQuotes {
+		return nil, NewSyntaxError("unbalanced parentheses or quotes in logical group", s, "check that every ( has a matching ) and quotes are closed")
+	}
+	if current.Len() > 0 {
+		items = append(items, strings.TrimSpace(current.String()))
+	}
+
+	return items, nil
+}
+
+// unquoteLogicValue strips the double quotes PostgREST uses to protect
+// reserved characters in logical group values, e.g. name.eq."Doe, John"
+func unquoteLogicValue(value string) string {
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return value
+	}
+	value = value[1 : len(value)-1]
+	value = strings.ReplaceAll(value, `\"`, `"`)
+	return strings.ReplaceAll(value, `\\`, `\`)
+}
+
+// buildGroupCondition renders a logical group as a parenthesized condition
+func buildGroupCondition(p *params, filter Filter) (string, error) {
+	joiner := " AND "
+	if filter.Logical == "or" {
+		joiner = " OR "
+	}
+
+	var conditions []string
+	for _, member := range filter.Conditions {
+		condition, err := buildCondition(p, member)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	condition := "(" + strings.Join(conditions, joiner) + ")"
+	if filter.Negated {
+		return "NOT " + condition, nil
+	}
+	return condition, nil
+}
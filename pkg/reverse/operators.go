@@ -18,8 +18,8 @@ var ReverseOperatorMap = map[string]string{
 	// Pattern matching operators
 	"like":   "LIKE",
 	"ilike":  "ILIKE",
-	"match":  "~",    // POSIX regex match
-	"imatch": "~*",   // Case-insensitive POSIX regex
+	"match":  "~",  // POSIX regex match
+	"imatch": "~*", // Case-insensitive POSIX regex
 
 	// Array operators
 	"cs": "@>", // Contains (e.g., array @> value)
@@ -40,15 +40,16 @@ var ReverseOperatorMap = map[string]string{
 	"wfts":  "@@", // Full-text search using websearch_to_tsquery
 
 	// Special operators
-	"is": "IS", // IS NULL / IS NOT NULL
-	"in": "IN", // IN (list)
+	"is":         "IS",               // IS NULL / IS NOT NULL
+	"in":         "IN",               // IN (list)
+	"isdistinct": "IS DISTINCT FROM", // Null-safe inequality
 }
 
 // MapOperator converts a PostgREST operator to SQL operator
 func MapOperator(postgrestOp string) (string, error) {
 	sqlOp, ok := ReverseOperatorMap[postgrestOp]
 	if !ok {
-		return "", fmt.Errorf("unsupported operator: %s", postgrestOp)
+		return "", NewUnsupportedError("ERR_UNSUPPORTED_OPERATOR", "unsupported operator: "+postgrestOp, postgrestOp, "")
 	}
 	return sqlOp, nil
 }
@@ -57,83 +58,55 @@ func MapOperator(postgrestOp string) (string, error) {
 func ParseOperatorValue(filterValue string) (operator string, value string, err error) {
 	parts := strings.SplitN(filterValue, ".", 2)
 	if len(parts) != 2 {
-		return "", "", fmt.Errorf("invalid filter format: %s (expected format: operator.value)", filterValue)
+		return "", "", NewSyntaxError("invalid filter format: "+filterValue, filterValue, "use operator.value, e.g. eq.1")
 	}
 	return parts[0], parts[1], nil
 }
 
 // FormatValue formats a value for SQL based on its type and operator
 func FormatValue(value string, operator string) string {
-	// Handle NULL values
-	if strings.ToLower(value) == "null" {
-		return "NULL"
-	}
-
-	// Handle boolean values
-	if value == "true" || value == "false" {
-		return value
-	}
+	return formatValue(nil, value, operator)
+}
 
-	// Handle numeric values (simple check - starts with digit or negative sign)
-	if len(value) > 0 && (isDigit(value[0]) || value[0] == '-') {
-		// Check if it's a valid number
-		isNumber := true
-		hasDecimal := false
-		for i, c := range value {
-			if i == 0 && c == '-' {
-				continue
-			}
-			if c == '.' {
-				if hasDecimal {
-					isNumber = false
-					break
-				}
-				hasDecimal = true
-				continue
-			}
-			if !isDigit(byte(c)) {
-				isNumber = false
-				break
-			}
-		}
-		if isNumber {
-			return value
-		}
+// formatValue formats a filter value, binding it as parameters when p is set
+func formatValue(p *params, value string, operator string) string {
+	// NULL, booleans and numbers format the same whatever the operator
+	literal := formatSingleValue(value)
+	if literal == "NULL" || literal == "true" || literal == "false" || literal == value {
+		return p.value(value)
 	}
 
 	// Handle IN operator - format as (val1,val2,val3)
 	if operator == "in" {
 		// Value format: (val1,val2,val3) or val1,val2,val3
 		if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
-			// Already formatted
-			inner := value[1 : len(value)-1]
-			values := strings.Split(inner, ",")
-			var formatted []string
-			for _, v := range values {
-				formatted = append(formatted, formatSingleValue(strings.TrimSpace(v)))
-			}
-			return "(" + strings.Join(formatted, ", ") + ")"
+			value = value[1 : len(value)-1]
+		}
+		// Items may be double quoted, as in ("a,b","c"), and are then text
+		values, err := splitLogicItems(value)
+		if err != nil {
+			values = strings.Split(value, ",")
 		}
-		// Format individual values
-		values := strings.Split(value, ",")
 		var formatted []string
 		for _, v := range values {
-			formatted = append(formatted, formatSingleValue(strings.TrimSpace(v)))
+			v = strings.TrimSpace(v)
+			if unquoted := unquoteLogicValue(v); unquoted != v {
+				formatted = append(formatted, p.text(unquoted))
+				continue
+			}
+			formatted = append(formatted, p.value(v))
 		}
 		return "(" + strings.Join(formatted, ", ") + ")"
 	}
 
-	// Handle array/range operators - these might have special formatting
-	if operator == "cs" || operator == "cd" || operator == "ov" {
-		// These expect array or range literals
-		// If value looks like an array literal, keep it as-is
-		if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
-			return value
-		}
+	// Array and range operators take array, range or JSON literals
+	switch operator {
+	case "cs", "cd", "ov", "sl", "sr", "nxr", "nxl", "adj":
+		return formatCollectionValue(p, value)
 	}
 
 	// Default: treat as string and escape
-	return formatSingleValue(value)
+	return p.value(value)
 }
 
 func formatSingleValue(value string) string {
@@ -190,8 +163,15 @@ func HandleNegation(condition string, negated bool) string {
 	return condition
 }
 
-// HandleFullTextSearch formats full-text search operators
-func HandleFullTextSearch(column, operator, value string) (string, error) {
+// HandleFullTextSearch formats full-text search operators, passing the text
+// search configuration when one is given (fts(french).amusant)
+func HandleFullTextSearch(column, operator, language, value string) (string, error) {
+	return fullTextSearchSQL(nil, column, operator, language, value)
+}
+
+// fullTextSearchSQL renders a full-text search condition, binding the
+// configuration and query as parameters when p is set
+func fullTextSearchSQL(p *params, column, operator, language, value string) (string, error) {
 	var tsFunc string
 	switch operator {
 	case "fts":
@@ -203,12 +183,29 @@ func HandleFullTextSearch(column, operator, value string) (string, error) {
 	case "wfts":
 		tsFunc = "websearch_to_tsquery"
 	default:
-		return "", fmt.Errorf("invalid full-text search operator: %s", operator)
+		return "", NewSemanticError("ERR_SEMANTIC_FTS_OPERATOR", "invalid full-text search operator: "+operator, operator, "use fts, plfts, phfts or wfts")
 	}
 
-	// Format: column @@ to_tsquery('english', 'search terms')
-	// Assuming English language by default
-	return fmt.Sprintf("%s @@ %s(%s)", column, tsFunc, formatSingleValue(value)), nil
+	// Format: column @@ to_tsquery('english', 'search terms'); without a
+	// language the server's default_text_search_config applies
+	if language != "" {
+		return fmt.Sprintf("%s @@ %s(%s, %s)", column, tsFunc, p.text(language), p.text(value)), nil
+	}
+	return fmt.Sprintf("%s @@ %s(%s)", column, tsFunc, p.text(value)), nil
+}
+
+// splitLanguage splits fts(french) into fts and french
+func splitLanguage(operator string) (string, string) {
+	base, rest, found := strings.Cut(operator, "(")
+	if !found || !IsFullTextSearchOperator(base) || !strings.HasSuffix(rest, ")") {
+		return operator, ""
+	}
+	return base, strings.TrimSuffix(rest, ")")
+}
+
+// quoteLiteral quotes a value as a SQL string literal
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
 }
 
 // IsFullTextSearchOperator checks if an operator is a full-text search operator
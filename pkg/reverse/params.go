@@ -0,0 +1,95 @@
+package reverse
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// params collects bind parameters for WithParameters. Values render as $1,
+// $2, ... in the order they appear in the statement; a nil *params renders
+// them as inline literals instead.
+type params struct {
+	args []interface{}
+}
+
+// newParams returns a collector when the converter emits placeholders
+func (c *Converter) newParams() *params {
+	if !c.parameters {
+		return nil
+	}
+	return &params{}
+}
+
+// values returns the collected arguments, nil when literals are inlined
+func (p *params) values() []interface{} {
+	if p == nil {
+		return nil
+	}
+	return p.args
+}
+
+// bind appends an argument and returns its placeholder
+func (p *params) bind(value interface{}) string {
+	p.args = append(p.args, value)
+	return fmt.Sprintf("$%d", len(p.args))
+}
+
+// value renders a query string value, binding numbers as int64 or float64,
+// booleans as bool and anything else as a string. NULL stays inline.
+func (p *params) value(value string) string {
+	literal := formatSingleValue(value)
+	if p == nil || literal == "NULL" {
+		return literal
+	}
+
+	switch {
+	case literal == "true" || literal == "false":
+		return p.bind(literal == "true")
+	case literal == value:
+		// formatSingleValue leaves only numbers unquoted
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return p.bind(n)
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return p.bind(f)
+		}
+	}
+	return p.bind(value)
+}
+
+// text renders a value that is always a string literal
+func (p *params) text(value string) string {
+	if p == nil {
+		return quoteLiteral(value)
+	}
+	return p.bind(value)
+}
+
+// json renders a JSON body value. Whole numbers bind as int64, and arrays
+// and objects as their JSON text.
+func (p *params) json(value interface{}) string {
+	literal := formatJSONValue(value)
+	if p == nil || value == nil {
+		return literal
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return p.bind(int64(v))
+		}
+		return p.bind(v)
+	case string, bool, int, int64:
+		return p.bind(v)
+	case []interface{}, map[string]interface{}:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			return literal
+		}
+		return p.bind(string(jsonBytes))
+	default:
+		return p.bind(fmt.Sprintf("%v", v))
+	}
+}
@@ -1,7 +1,7 @@
 package reverse
 
 import (
-	"encoding/json"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -9,12 +9,21 @@ import (
 
 // ParsePostgRESTRequest parses a PostgREST HTTP request into a structured representation
 func ParsePostgRESTRequest(method, path, query string, body []byte) (*PostgRESTRequest, error) {
+	return ParsePostgRESTRequestWithHeaders(method, path, query, body, nil)
+}
+
+// ParsePostgRESTRequestWithHeaders parses a PostgREST HTTP request along with
+// its headers; Content-Type decides how the body is decoded
+func ParsePostgRESTRequestWithHeaders(method, path, query string, body []byte, headers map[string]string) (*PostgRESTRequest, error) {
 	req := &PostgRESTRequest{
 		Method:  strings.ToUpper(method),
 		Filters: []Filter{},
 		Order:   []OrderBy{},
 		Headers: make(map[string]string),
 	}
+	for key, value := range headers {
+		req.Headers[http.CanonicalHeaderKey(key)] = value
+	}
 
 	// Extract table name from path
 	tableName, err := extractTableName(path)
@@ -23,9 +32,20 @@ func ParsePostgRESTRequest(method, path, query string, body []byte) (*PostgRESTR
 	}
 	req.Table = tableName
 
+	// /rpc/fn calls a function instead of reading a table
+	if tableName == "rpc" {
+		function, err := extractFunctionName(path)
+		if err != nil {
+			return nil, err
+		}
+		req.Function = function
+		req.Table = function
+		req.Args = make(map[string]string)
+	}
+
 	// Parse query parameters
 	if query != "" {
-		params, err := url.ParseQuery(query)
+		params, err := parseQueryString(query)
 		if err != nil {
 			return nil, NewSyntaxError("invalid query string", query, "check URL encoding")
 		}
@@ -36,12 +56,12 @@ func ParsePostgRESTRequest(method, path, query string, body []byte) (*PostgRESTR
 		}
 	}
 
-	// Parse body for POST/PATCH requests
-	if method == "POST" || method == "PATCH" {
+	// Parse body for POST/PUT/PATCH requests
+	if req.Method == "POST" || req.Method == "PUT" || req.Method == "PATCH" {
 		if len(body) > 0 {
-			var bodyData interface{}
-			if err := json.Unmarshal(body, &bodyData); err != nil {
-				return nil, NewSyntaxError("invalid JSON body", string(body), "ensure body is valid JSON")
+			bodyData, err := decodeBody(req.Headers["Content-Type"], body)
+			if err != nil {
+				return nil, err
 			}
 			req.Body = bodyData
 		}
@@ -64,13 +84,69 @@ func extractTableName(path string) (string, error) {
 	return parts[0], nil
 }
 
-// parseQueryParams parses URL query parameters into the request structure
-func parseQueryParams(req *PostgRESTRequest, params url.Values) error {
-	for key, values := range params {
-		if len(values) == 0 {
+// extractFunctionName extracts the function name from an /rpc/ path
+func extractFunctionName(path string) (string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || parts[1] == "" {
+		return "", NewSemanticError("ERR_SEMANTIC_NO_FUNCTION", "function name is required", path, "path should be /rpc/function_name")
+	}
+	return parts[1], nil
+}
+
+// queryParam is one key=value pair of the query string
+type queryParam struct {
+	key   string
+	value string
+}
+
+// singleValueParams are the query parameters that take one value
+var singleValueParams = map[string]bool{
+	"select": true, "order": true, "limit": true, "offset": true, "columns": true, "on_conflict": true,
+}
+
+// parseQueryString splits a query string into its params in the order they
+// appear, so filters render in the order the client wrote them. A repeated
+// filter key keeps every value (age=gte.18&age=lte.65 ANDs both); a
+// repeated select, order, limit, offset, columns or on_conflict keeps its
+// first value, as url.Values.Get does.
+func parseQueryString(query string) ([]queryParam, error) {
+	var params []queryParam
+	seen := map[string]bool{}
+
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
 			continue
 		}
-		value := values[0]
+		if strings.Contains(pair, ";") {
+			return nil, NewSyntaxError("invalid semicolon separator in query", pair, "separate query parameters with &")
+		}
+
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return nil, err
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, err
+		}
+
+		if singleValueParams[key] {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		params = append(params, queryParam{key: key, value: value})
+	}
+
+	return params, nil
+}
+
+// parseQueryParams parses URL query parameters into the request structure
+func parseQueryParams(req *PostgRESTRequest, params []queryParam) error {
+	for _, param := range params {
+		key, value := param.key, param.value
 
 		// Skip empty values (can happen with empty query strings)
 		if value == "" && key != "select" && key != "order" && key != "limit" && key != "offset" {
@@ -87,18 +163,48 @@ func parseQueryParams(req *PostgRESTRequest, params url.Values) error {
 			}
 			req.Order = orderBy
 		case "limit":
-			limit, err := strconv.Atoi(value)
+			limit, err := parseLimitParam(value)
 			if err != nil {
-				return NewSyntaxError("invalid limit value", value, "limit must be an integer")
+				return err
 			}
 			req.Limit = &limit
 		case "offset":
-			offset, err := strconv.Atoi(value)
+			offset, err := parseOffsetParam(value)
 			if err != nil {
-				return NewSyntaxError("invalid offset value", value, "offset must be an integer")
+				return err
 			}
 			req.Offset = &offset
+		case "columns":
+			for _, column := range strings.Split(value, ",") {
+				req.Columns = append(req.Columns, strings.TrimSpace(column))
+			}
+		case "on_conflict":
+			for _, column := range strings.Split(value, ",") {
+				req.OnConflict = append(req.OnConflict, strings.TrimSpace(column))
+			}
+		case "or", "and", "not.or", "not.and":
+			logical, negated, _ := logicalKey(key)
+			filter, err := parseLogicalFilter(logical, value)
+			if err != nil {
+				return err
+			}
+			filter.Negated = negated
+			req.Filters = append(req.Filters, filter)
 		default:
+			// posts.limit=5 pages an embed rather than filtering a column
+			if path, modifier, found := cutEmbedModifier(key); found {
+				if err := parseEmbedPage(req, path, modifier, value); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// GET /rpc/fn?x=1 passes x as a function argument
+			if req.Function != "" && req.Method != "POST" && !isFilterValue(value) {
+				req.Args[key] = value
+				continue
+			}
+
 			// It's a filter
 			filter, err := parseFilter(key, value)
 			if err != nil {
@@ -111,6 +217,79 @@ func parseQueryParams(req *PostgRESTRequest, params url.Values) error {
 	return nil
 }
 
+// parseLimitParam parses a limit value
+func parseLimitParam(value string) (int, error) {
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, NewSyntaxError("invalid limit value", value, "limit must be an integer")
+	}
+	if limit < 0 {
+		return 0, NewSemanticError("ERR_SEMANTIC_NEGATIVE_LIMIT", "limit must not be negative", value, "use limit=0 to fetch no rows")
+	}
+	return limit, nil
+}
+
+// parseOffsetParam parses an offset value
+func parseOffsetParam(value string) (int, error) {
+	offset, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, NewSyntaxError("invalid offset value", value, "offset must be an integer")
+	}
+	if offset < 0 {
+		return 0, NewSemanticError("ERR_SEMANTIC_NEGATIVE_OFFSET", "offset must not be negative", value, "use offset=0 or omit offset")
+	}
+	return offset, nil
+}
+
+// cutEmbedModifier splits posts.comments.limit into posts.comments and limit
+func cutEmbedModifier(key string) (string, string, bool) {
+	i := strings.LastIndex(key, ".")
+	if i <= 0 {
+		return "", "", false
+	}
+	switch modifier := key[i+1:]; modifier {
+	case "order", "limit", "offset":
+		return key[:i], modifier, true
+	}
+	return "", "", false
+}
+
+// parseEmbedPage records an embed's order, limit or offset
+func parseEmbedPage(req *PostgRESTRequest, path, modifier, value string) error {
+	var page *EmbedPage
+	for i := range req.EmbedPages {
+		if req.EmbedPages[i].Path == path {
+			page = &req.EmbedPages[i]
+		}
+	}
+	if page == nil {
+		req.EmbedPages = append(req.EmbedPages, EmbedPage{Path: path})
+		page = &req.EmbedPages[len(req.EmbedPages)-1]
+	}
+
+	switch modifier {
+	case "order":
+		order, err := parseOrderParam(value)
+		if err != nil {
+			return err
+		}
+		page.Order = order
+	case "limit":
+		limit, err := parseLimitParam(value)
+		if err != nil {
+			return err
+		}
+		page.Limit = &limit
+	case "offset":
+		offset, err := parseOffsetParam(value)
+		if err != nil {
+			return err
+		}
+		page.Offset = &offset
+	}
+	return nil
+}
+
 // parseSelectParam parses the select parameter
 // Examples: "*", "name,email", "name,posts(title,created_at)"
 func parseSelectParam(selectValue string) []string {
@@ -216,10 +395,10 @@ func parseFilter(column, filterValue string) (Filter, error) {
 		return Filter{}, NewSyntaxError("empty filter value", column, "provide a filter value like: column=eq.value")
 	}
 
-	// Check for OR conditions
+	// or(...) is a parameter of its own, not a column's filter
 	if strings.HasPrefix(filterValue, "or(") && strings.HasSuffix(filterValue, ")") {
-		// TODO: Handle OR conditions - for now, return error
-		return Filter{}, NewUnsupportedError("ERR_UNSUPPORTED_OR", "OR conditions not yet supported", filterValue, "use simple filters for now")
+		return Filter{}, NewSyntaxError("or conditions cannot be a filter on "+column, column+"="+filterValue,
+			"write them as or=(a.eq.1,b.eq.2), e.g. or=(age.lt.18,age.gt.65)")
 	}
 
 	// Check for NOT prefix
@@ -235,16 +414,45 @@ func parseFilter(column, filterValue string) (Filter, error) {
 		return Filter{}, err
 	}
 
+	// fts(french).amusant names the text search configuration
+	operator, language := splitLanguage(operator)
+
+	// eq(any).{1,2} compares against each listed value
+	operator, quantifier, err := splitQuantifier(operator)
+	if err != nil {
+		return Filter{}, err
+	}
+
 	return Filter{
-		Column:   column,
-		Operator: operator,
-		Value:    value,
-		Negated:  negated,
-		Logical:  "and", // Default to AND
+		Column:     column,
+		Operator:   operator,
+		Value:      value,
+		Negated:    negated,
+		Quantifier: quantifier,
+		Language:   language,
+		Logical:    "and", // Default to AND
 	}, nil
 }
 
-// ParseEmbeddedResources parses embedded resources from select columns
+// isFilterValue reports whether a query param value starts with a known
+// operator (eq.5, not.in.(1,2)) rather than being a plain value
+func isFilterValue(value string) bool {
+	value = strings.TrimPrefix(value, "not.")
+	operator, _, found := strings.Cut(value, ".")
+	if !found {
+		return false
+	}
+	operator, _ = splitLanguage(operator)
+	operator, _, err := splitQuantifier(operator)
+	if err != nil {
+		return true
+	}
+	_, ok := ReverseOperatorMap[operator]
+	return ok
+}
+
+// ParseEmbeddedResources parses embedded resources from select columns,
+// recursing into nested embeds
 // Example: "name,posts(title,created_at)" -> main cols: [name], embeds: [{posts, [title, created_at]}]
 func ParseEmbeddedResources(selectCols []string) (mainCols []string, embeds []EmbeddedResource, err error) {
 	mainCols = []string{}
@@ -253,22 +461,37 @@ func ParseEmbeddedResources(selectCols []string) (mainCols []string, embeds []Em
 	for _, col := range selectCols {
 		col = strings.TrimSpace(col)
 
-		// Check if it's an embedded resource
-		if strings.Contains(col, "(") {
+		// Check if it's an embedded resource (aggregates like count() and casts
+		// like ::numeric(10,2) also use parentheses)
+		openIdx := strings.Index(col, "(")
+		if openIdx != -1 && !strings.Contains(col[:openIdx], "::") && !isAggregateItem(col) {
 			// Parse embedded resource
-			openIdx := strings.Index(col, "(")
 			closeIdx := strings.LastIndex(col, ")")
 
 			if closeIdx == -1 || closeIdx < openIdx {
 				return nil, nil, NewSyntaxError("invalid embedded resource format", col, "expected format: relation(columns)")
 			}
 
-			relation := col[:openIdx]
-			innerCols := col[openIdx+1 : closeIdx]
+			head, spread := strings.CutPrefix(col[:openIdx], "...")
+			alias := ""
+			if i := aliasSeparator(head); i != -1 {
+				alias, head = head[:i], head[i+1:]
+			}
+			relation, hint, inner := parseEmbedModifiers(head)
+
+			innerCols, nested, err := ParseEmbeddedResources(parseSelectParam(col[openIdx+1 : closeIdx]))
+			if err != nil {
+				return nil, nil, err
+			}
 
 			embed := EmbeddedResource{
 				Relation: relation,
-				Select:   parseSelectParam(innerCols),
+				Alias:    alias,
+				Hint:     hint,
+				Inner:    inner,
+				Spread:   spread,
+				Select:   innerCols,
+				Embedded: nested,
 			}
 
 			embeds = append(embeds, embed)
@@ -280,8 +503,39 @@ func ParseEmbeddedResources(selectCols []string) (mainCols []string, embeds []Em
 	return mainCols, embeds, nil
 }
 
+// parseEmbedModifiers splits the ! modifiers off an embedded relation
+// Examples: "posts!inner" -> posts, inner join; "posts!posts_author_id_fkey" -> posts, FK hint
+func parseEmbedModifiers(head string) (relation, hint string, inner bool) {
+	parts := strings.Split(head, "!")
+	relation = parts[0]
+	for _, modifier := range parts[1:] {
+		switch modifier {
+		case "inner":
+			inner = true
+		case "left":
+			inner = false
+		default:
+			hint = modifier
+		}
+	}
+	return relation, hint, inner
+}
+
+// Name returns how the embed is referenced in SQL: its alias, else its relation
+func (e EmbeddedResource) Name() string {
+	if e.Alias != "" {
+		return e.Alias
+	}
+	return e.Relation
+}
+
 // ValidateRequest validates a PostgREST request for semantic correctness
 func ValidateRequest(req *PostgRESTRequest) error {
+	// Names are interpolated into SQL, so they must be plain identifiers
+	if err := validateIdentifiers(req); err != nil {
+		return err
+	}
+
 	// DELETE must have WHERE clause
 	if req.Method == "DELETE" && len(req.Filters) == 0 {
 		return NewSemanticError(
@@ -292,6 +546,16 @@ func ValidateRequest(req *PostgRESTRequest) error {
 		)
 	}
 
+	// PUT upserts the single row its filters identify
+	if req.Method == "PUT" && len(req.Filters) == 0 {
+		return NewSemanticError(
+			"ERR_SEMANTIC_PUT_NO_FILTER",
+			"PUT requires eq filters on the primary key",
+			"PUT /"+req.Table,
+			"identify the row, e.g. PUT /"+req.Table+"?id=eq.1",
+		)
+	}
+
 	// UPDATE should have WHERE clause (warning, not error)
 	// We'll add this as a warning in the result instead of blocking
 
@@ -0,0 +1,119 @@
+package reverse
+
+import (
+	"strings"
+)
+
+// preferences parses the Prefer header into its key=value preferences
+// Example: "resolution=merge-duplicates, return=representation"
+func preferences(req *PostgRESTRequest) map[string]string {
+	prefs := map[string]string{}
+	for _, part := range strings.Split(req.Headers["Prefer"], ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if key != "" {
+			prefs[strings.ToLower(key)] = strings.TrimSpace(value)
+		}
+	}
+	return prefs
+}
+
+// buildReturningClause renders RETURNING for mutations with
+// Prefer: return=representation, limited to the select columns if given
+func buildReturningClause(req *PostgRESTRequest) (string, []string) {
+	var warnings []string
+	hasSelect := len(req.Select) > 0 && !(len(req.Select) == 1 && req.Select[0] == "*")
+
+	if preferences(req)["return"] != "representation" {
+		if hasSelect {
+			warnings = append(warnings, "select has no effect on a mutation without Prefer: return=representation")
+		}
+		return "", warnings
+	}
+
+	if !hasSelect {
+		return " RETURNING *", warnings
+	}
+
+	mainCols, embeds, err := ParseEmbeddedResources(req.Select)
+	if err != nil {
+		return " RETURNING *", warnings
+	}
+	for _, embed := range embeds {
+		warnings = append(warnings, "embedded resource "+embed.Relation+" cannot be returned by RETURNING; it is omitted")
+	}
+	if len(mainCols) == 0 {
+		return " RETURNING *", warnings
+	}
+
+	var columns []string
+	for _, col := range mainCols {
+		sql, _ := selectItemSQL(col, "")
+		columns = append(columns, sql)
+	}
+	return " RETURNING " + strings.Join(columns, ", "), warnings
+}
+
+// addCountQuery records the companion query PostgREST runs for
+// Prefer: count=exact|planned|estimated in metadata (count, count_sql), so
+// users can see how the total in Content-Range is computed
+func addCountQuery(req *PostgRESTRequest, whereClause string, metadata map[string]string) []string {
+	var warnings []string
+	count := preferences(req)["count"]
+
+	switch count {
+	case "":
+		return nil
+	case "exact":
+	case "planned":
+		warnings = append(warnings, "count=planned reads the row estimate from the query planner (EXPLAIN); count_sql is the exact equivalent")
+	case "estimated":
+		warnings = append(warnings, "count=estimated is exact up to db-max-rows and a planner estimate beyond it; count_sql is the exact equivalent")
+	default:
+		return []string{"unknown Prefer count " + count + " ignored"}
+	}
+
+	for _, embed := range req.Embedded {
+		if embed.Inner {
+			warnings = append(warnings, "count_sql does not apply the !inner embed on "+embed.Relation+", which also limits the counted rows")
+		}
+	}
+
+	metadata["count"] = count
+	metadata["count_sql"] = countQuery(req, whereClause)
+	return warnings
+}
+
+// countQuery counts the rows matching the request's filters
+func countQuery(req *PostgRESTRequest, whereClause string) string {
+	sql := "SELECT count(*) FROM " + qualifiedName(req, req.Table)
+	if whereClause != "" {
+		sql += " " + whereClause
+	}
+	return sql
+}
+
+// applyTxPreference wraps a mutation in BEGIN; ...; ROLLBACK; for
+// Prefer: tx=rollback, the dry run PostgREST performs when the server
+// allows it (db-tx-end = commit-allow-override or rollback-allow-override)
+func applyTxPreference(req *PostgRESTRequest, result *SQLResult) {
+	tx := preferences(req)["tx"]
+	switch tx {
+	case "", "commit":
+		return
+	case "rollback":
+	default:
+		result.Warnings = append(result.Warnings, "unknown Prefer tx "+tx+" ignored")
+		return
+	}
+
+	result.Metadata["transaction"] = "rollback"
+	switch req.Method {
+	case "POST", "PATCH", "PUT", "DELETE":
+	default:
+		// Reads change nothing, so there is nothing to roll back
+		return
+	}
+
+	result.SQL = "BEGIN; " + result.SQL + "; ROLLBACK;"
+	result.Warnings = append(result.Warnings, "Prefer: tx=rollback runs the statement and rolls it back, so PostgREST does not persist the change; it is honored only when db-tx-end allows overrides")
+}
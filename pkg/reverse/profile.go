@@ -0,0 +1,19 @@
+package reverse
+
+// profileSchema returns the schema PostgREST selects for the request: the
+// Accept-Profile header for reads, Content-Profile for writes
+func profileSchema(req *PostgRESTRequest) string {
+	if req.Method == "GET" || req.Method == "HEAD" {
+		return req.Headers["Accept-Profile"]
+	}
+	return req.Headers["Content-Profile"]
+}
+
+// qualifiedName quotes a table or function name and prefixes it with the
+// profile schema
+func qualifiedName(req *PostgRESTRequest, name string) string {
+	if schema := profileSchema(req); schema != "" {
+		return identifierSQL(schema) + "." + identifierSQL(name)
+	}
+	return identifierSQL(name)
+}
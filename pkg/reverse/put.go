@@ -0,0 +1,77 @@
+package reverse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// buildPutStatement builds the upsert for a PUT request. PostgREST requires
+// eq filters on every primary key column and a body repeating those values,
+// so the filter columns become the ON CONFLICT target.
+func buildPutStatement(req *PostgRESTRequest, p *params) (string, error) {
+	data, ok := req.Body.(map[string]interface{})
+	if !ok {
+		return "", NewSyntaxError(
+			"invalid body format",
+			fmt.Sprintf("%v", req.Body),
+			"PUT body should be a single JSON object",
+		)
+	}
+
+	var keys []string
+	for _, filter := range req.Filters {
+		if filter.Operator != "eq" || filter.Negated || len(filter.Conditions) > 0 {
+			return "", NewSemanticError(
+				"ERR_SEMANTIC_PUT_FILTER",
+				"PUT only accepts eq filters on the primary key",
+				filter.Column,
+				"use PATCH to update rows matching other conditions",
+			)
+		}
+
+		value, ok := data[filter.Column]
+		if !ok {
+			return "", NewSemanticError(
+				"ERR_SEMANTIC_PUT_BODY",
+				fmt.Sprintf("PUT body must include the primary key column %s", filter.Column),
+				filter.Column,
+				fmt.Sprintf("add %q: %s to the body", filter.Column, filter.Value),
+			)
+		}
+		if fmt.Sprint(value) != fmt.Sprint(filter.Value) {
+			return "", NewSemanticError(
+				"ERR_SEMANTIC_PUT_BODY",
+				fmt.Sprintf("PUT body value for %s does not match the filter", filter.Column),
+				fmt.Sprintf("%v", value),
+				"the body and the eq filter must name the same row",
+			)
+		}
+		keys = append(keys, filter.Column)
+	}
+
+	// Key columns first, then the rest in a stable order
+	isKey := map[string]bool{}
+	for _, key := range keys {
+		isKey[key] = true
+	}
+	columns := append([]string{}, keys...)
+	var assignments []string
+	for _, column := range bodyColumns(data) {
+		if isKey[column] {
+			continue
+		}
+		columns = append(columns, column)
+		assignments = append(assignments, identifierSQL(column)+" = EXCLUDED."+identifierSQL(column))
+	}
+
+	sql, err := buildInsertRows(p, qualifiedName(req, req.Table), columns, []interface{}{data}, "NULL")
+	if err != nil {
+		return "", err
+	}
+
+	target := identifierListSQL(keys)
+	if len(assignments) == 0 {
+		return sql + " ON CONFLICT (" + target + ") DO NOTHING", nil
+	}
+	return sql + " ON CONFLICT (" + target + ") DO UPDATE SET " + strings.Join(assignments, ", "), nil
+}
@@ -0,0 +1,83 @@
+package reverse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// quantifiedOperators are the operators PostgREST accepts with (any)/(all)
+var quantifiedOperators = map[string]bool{
+	"eq": true, "gt": true, "gte": true, "lt": true, "lte": true,
+	"like": true, "ilike": true, "match": true, "imatch": true,
+}
+
+// splitQuantifier splits eq(any) into eq and any
+func splitQuantifier(operator string) (string, string, error) {
+	for _, quantifier := range []string{"any", "all"} {
+		base, found := strings.CutSuffix(operator, "("+quantifier+")")
+		if !found {
+			continue
+		}
+		if !quantifiedOperators[base] {
+			return "", "", NewUnsupportedError(
+				"ERR_UNSUPPORTED_QUANTIFIER",
+				fmt.Sprintf("operator %s does not support (%s)", base, quantifier),
+				operator,
+				"use (any)/(all) with eq, gt, gte, lt, lte, like, ilike, match or imatch",
+			)
+		}
+		return base, quantifier, nil
+	}
+	return operator, "", nil
+}
+
+// buildQuantifiedCondition renders col = ANY (ARRAY[...]) for eq(any).{a,b}
+func buildQuantifiedCondition(p *params, column, sqlOp, quantifier, value string) (string, error) {
+	elements, ok := arrayElements(value)
+	if !ok {
+		return "", NewSyntaxError(
+			fmt.Sprintf("(%s) expects a list of values", quantifier),
+			value,
+			"use braces, e.g. eq(any).{1,2,3}",
+		)
+	}
+
+	formatted := make([]string, 0, len(elements))
+	for _, element := range elements {
+		formatted = append(formatted, p.value(element))
+	}
+	return fmt.Sprintf("%s %s %s (ARRAY[%s])", column, sqlOp, strings.ToUpper(quantifier), strings.Join(formatted, ", ")), nil
+}
+
+// arrayElements splits a {a,"b,c",d} list into its unquoted elements
+func arrayElements(value string) ([]string, bool) {
+	if !strings.HasPrefix(value, "{") || !strings.HasSuffix(value, "}") {
+		return nil, false
+	}
+	inner := value[1 : len(value)-1]
+	if strings.TrimSpace(inner) == "" {
+		return []string{}, true
+	}
+
+	var elements []string
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+	for _, c := range inner {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inQuotes:
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ',' && !inQuotes:
+			elements = append(elements, unquoteLogicValue(strings.TrimSpace(current.String())))
+			current.Reset()
+			continue
+		}
+		current.WriteRune(c)
+	}
+	elements = append(elements, unquoteLogicValue(strings.TrimSpace(current.String())))
+	return elements, true
+}
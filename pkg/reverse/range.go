@@ -0,0 +1,80 @@
+package reverse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// applyRangeHeader folds a Range header (e.g. "Range: 20-29" with
+// Range-Unit: items) into the request's limit and offset. PostgREST
+// intersects the header range with limit/offset params, so both are merged.
+func applyRangeHeader(req *PostgRESTRequest) ([]string, error) {
+	header := strings.TrimSpace(req.Headers["Range"])
+	if header == "" {
+		return nil, nil
+	}
+
+	var warnings []string
+	if unit := req.Headers["Range-Unit"]; unit != "" && unit != "items" {
+		warnings = append(warnings, fmt.Sprintf("Range-Unit %s ignored; PostgREST only supports items", unit))
+	}
+
+	first, last, bounded, err := parseRange(strings.TrimPrefix(header, "items="))
+	if err != nil {
+		return nil, err
+	}
+
+	// Intersect with the range of the limit/offset params
+	if req.Limit != nil || req.Offset != nil {
+		warnings = append(warnings, "Range header and limit/offset params are both set; using their intersection")
+	}
+	paramFirst := 0
+	if req.Offset != nil {
+		paramFirst = *req.Offset
+	}
+	if req.Limit != nil {
+		paramLast := paramFirst + *req.Limit - 1
+		if !bounded || paramLast < last {
+			last = paramLast
+		}
+		bounded = true
+	}
+	if paramFirst > first {
+		first = paramFirst
+	}
+
+	req.Offset = nil
+	if first > 0 {
+		req.Offset = &first
+	}
+	if bounded {
+		limit := max(last-first+1, 0)
+		req.Limit = &limit
+	}
+
+	return warnings, nil
+}
+
+// parseRange parses "first-last" or the open-ended "first-"
+func parseRange(value string) (first, last int, bounded bool, err error) {
+	firstStr, lastStr, found := strings.Cut(value, "-")
+	if !found {
+		return 0, 0, false, NewSyntaxError("invalid Range header", value, "expected format: Range: 0-9 or Range: 10-")
+	}
+
+	first, err = strconv.Atoi(strings.TrimSpace(firstStr))
+	if err != nil || first < 0 {
+		return 0, 0, false, NewSyntaxError("invalid Range header", value, "range start must be a non-negative integer")
+	}
+
+	lastStr = strings.TrimSpace(lastStr)
+	if lastStr == "" {
+		return first, 0, false, nil
+	}
+	last, err = strconv.Atoi(lastStr)
+	if err != nil || last < first {
+		return 0, 0, false, NewSyntaxError("invalid Range header", value, "range end must be an integer no smaller than the start")
+	}
+	return first, last, true, nil
+}
@@ -0,0 +1,82 @@
+package reverse
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ConvertRawRequest converts a raw HTTP/1.1 request, as pasted from a proxy
+// or browser devtools, with a default converter
+func ConvertRawRequest(raw string) (*SQLResult, error) {
+	return NewConverter().ConvertRawRequest(raw)
+}
+
+// ConvertRawRequest converts a raw HTTP/1.1 request: the request line,
+// headers, a blank line and the body. The body is everything after the
+// blank line, whatever Content-Length says, since pasted requests are often
+// edited.
+func (c *Converter) ConvertRawRequest(raw string) (*SQLResult, error) {
+	raw = strings.TrimLeft(raw, "\r\n\t ")
+	head, body := splitRawRequest(raw)
+
+	// Devtools show HTTP/2 and HTTP/3 requests with their version, which
+	// http.ReadRequest rejects; the version does not change the conversion
+	requestLine, rest, _ := strings.Cut(head, "\n")
+	requestLine = strings.TrimRight(requestLine, "\r")
+	for _, version := range []string{" HTTP/2", " HTTP/2.0", " HTTP/3", " HTTP/3.0"} {
+		if line, found := strings.CutSuffix(requestLine, version); found {
+			requestLine = line + " HTTP/1.1"
+		}
+	}
+	head = requestLine + "\r\n" + rest
+
+	parsed, err := http.ReadRequest(bufio.NewReader(strings.NewReader(head + "\r\n\r\n")))
+	if err != nil {
+		return nil, NewSyntaxError(
+			"invalid HTTP request: "+err.Error(),
+			raw,
+			"start with a request line such as GET /users?age=gte.18 HTTP/1.1, then headers and a blank line",
+		)
+	}
+
+	headers := map[string]string{}
+	for key, values := range parsed.Header {
+		if key == "Content-Length" || len(values) == 0 {
+			continue
+		}
+		headers[key] = strings.Join(values, ", ")
+	}
+
+	rawURL := parsed.RequestURI
+	if !parsed.URL.IsAbs() && parsed.Host != "" {
+		rawURL = rawRequestScheme(parsed.Host) + "://" + parsed.Host + parsed.RequestURI
+	}
+
+	return c.ConvertURL(parsed.Method, rawURL, body, headers)
+}
+
+// splitRawRequest splits a raw request at the first blank line
+func splitRawRequest(raw string) (string, string) {
+	for _, separator := range []string{"\r\n\r\n", "\n\n"} {
+		if head, body, found := strings.Cut(raw, separator); found {
+			return head, strings.TrimRight(body, "\r\n")
+		}
+	}
+	return strings.TrimRight(raw, "\r\n"), ""
+}
+
+// rawRequestScheme guesses the scheme for a Host header: plain HTTP for
+// local servers, HTTPS otherwise
+func rawRequestScheme(host string) string {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	switch hostname {
+	case "localhost", "127.0.0.1", "::1":
+		return "http"
+	}
+	return "https"
+}
@@ -0,0 +1,124 @@
+package reverse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// convertRPC converts a /rpc/ call to SELECT * FROM fn(arg := value, ...).
+// Filters, order and limit apply to the function's result, as in PostgREST.
+func (c *Converter) convertRPC(req *PostgRESTRequest) (*SQLResult, error) {
+	result := &SQLResult{
+		Warnings: []string{},
+		Metadata: make(map[string]string),
+	}
+
+	p := c.newParams()
+	var args []string
+	switch req.Method {
+	case "GET", "HEAD":
+		args = rpcQueryArgs(p, req.Args)
+	case "POST":
+		var err error
+		args, err = rpcBodyArgs(p, req.Body)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, NewSemanticError(
+			"ERR_SEMANTIC_INVALID_METHOD",
+			fmt.Sprintf("unsupported HTTP method for /rpc/%s: %s", req.Function, req.Method),
+			req.Method,
+			"call functions with GET or POST",
+		)
+	}
+
+	_, embeds, err := ParseEmbeddedResources(req.Select)
+	if err == nil && len(embeds) > 0 {
+		return nil, NewUnsupportedError(
+			"ERR_UNSUPPORTED_RPC_EMBED",
+			"embedding resources on function results is not supported",
+			strings.Join(req.Select, ","),
+			"select only the function's own columns",
+		)
+	}
+
+	if req.Method != "POST" && singleObject(req) {
+		limitSingleRow(req)
+	}
+
+	selectClause, groupBy, warnings := buildSelectClause(req)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	whereClause, err := buildWhereClause(p, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := selectClause + " FROM " + qualifiedName(req, req.Function) + "(" + strings.Join(args, ", ") + ")"
+	if whereClause != "" {
+		sql += " " + whereClause
+	}
+	if len(groupBy) > 0 {
+		sql += " GROUP BY " + strings.Join(groupBy, ", ")
+	}
+	if orderByClause := buildOrderByClause(req.Order); orderByClause != "" {
+		sql += " " + orderByClause
+	}
+	if limitOffsetClause := buildLimitOffsetClause(req.Limit, req.Offset); limitOffsetClause != "" {
+		sql += " " + limitOffsetClause
+	}
+
+	if req.Method == "POST" && len(req.Filters) > 0 {
+		result.Warnings = append(result.Warnings, "filters on a POST /rpc/ call apply to the function's result, after it has run")
+	}
+
+	result.Metadata["function"] = req.Function
+	result.SQL = sql
+	result.Args = p.values()
+	return result, nil
+}
+
+// rpcQueryArgs renders GET query arguments as named arguments, sorted by name
+func rpcQueryArgs(p *params, query map[string]string) []string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := make([]string, 0, len(names))
+	for _, name := range names {
+		args = append(args, identifierSQL(name)+" := "+p.value(query[name]))
+	}
+	return args
+}
+
+// rpcBodyArgs renders a POST body object as named arguments, sorted by name
+func rpcBodyArgs(p *params, body interface{}) ([]string, error) {
+	if body == nil {
+		return nil, nil
+	}
+	data, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, NewUnsupportedError(
+			"ERR_UNSUPPORTED_RPC_BODY",
+			"function arguments must be a single JSON object",
+			fmt.Sprintf("%v", body),
+			"pass arguments as {\"name\": value}",
+		)
+	}
+
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := make([]string, 0, len(names))
+	for _, name := range names {
+		args = append(args, identifierSQL(name)+" := "+p.json(data[name]))
+	}
+	return args, nil
+}
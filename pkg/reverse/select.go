@@ -5,79 +5,372 @@ import (
 	"strings"
 )
 
-// buildSelectClause builds the SELECT clause
-func buildSelectClause(req *PostgRESTRequest) string {
+// buildSelectClause builds the SELECT clause. When the select list contains
+// aggregates it also returns the GROUP BY expressions PostgREST implies: every
+// selected column that is not aggregated.
+func buildSelectClause(req *PostgRESTRequest) (string, []string, []string) {
 	if len(req.Select) == 0 || (len(req.Select) == 1 && req.Select[0] == "*") {
-		return "SELECT *"
+		return "SELECT *", nil, nil
 	}
 
 	// Parse embedded resources
 	mainCols, embeds, err := ParseEmbeddedResources(req.Select)
 	if err != nil {
 		// Fallback to simple select
-		return "SELECT " + strings.Join(req.Select, ", ")
+		return "SELECT " + strings.Join(req.Select, ", "), nil, nil
+	}
+
+	var allColumns, groupBy []string
+	hasAggregate := false
+	add := func(col, table string) {
+		sql, groupExpr := selectItemSQL(col, table)
+		allColumns = append(allColumns, sql)
+		if groupExpr == "" {
+			hasAggregate = true
+		} else {
+			groupBy = append(groupBy, groupExpr)
+		}
 	}
 
 	// If no embeds, simple select
 	if len(embeds) == 0 {
-		return "SELECT " + strings.Join(mainCols, ", ")
+		for _, col := range mainCols {
+			add(col, "")
+		}
+	} else {
+		// With embeds, we need to qualify columns and include embedded columns
+
+		// Add main table columns (qualified)
+		for _, col := range mainCols {
+			add(col, req.Table)
+		}
+
+		// Add embedded resource columns (qualified), depth first
+		var addEmbeds func([]EmbeddedResource)
+		addEmbeds = func(embeds []EmbeddedResource) {
+			for _, embed := range embeds {
+				for _, col := range embed.Select {
+					add(col, embed.Name())
+				}
+				addEmbeds(embed.Embedded)
+			}
+		}
+		addEmbeds(embeds)
+
+		// Store embeds in request for FROM clause builder
+		req.Embedded = embeds
 	}
 
-	// With embeds, we need to qualify columns and include embedded columns
-	var allColumns []string
+	if !hasAggregate {
+		return "SELECT " + strings.Join(allColumns, ", "), nil, nil
+	}
 
-	// Add main table columns (qualified)
-	for _, col := range mainCols {
-		if col != "*" {
-			allColumns = append(allColumns, req.Table+"."+col)
-		} else {
-			allColumns = append(allColumns, req.Table+".*")
+	var warnings, grouped []string
+	for _, expr := range groupBy {
+		if expr == "*" || strings.HasSuffix(expr, ".*") {
+			warnings = append(warnings, fmt.Sprintf(
+				"cannot GROUP BY %s alongside aggregates; list the columns (or the primary key) explicitly", expr))
+			continue
 		}
+		grouped = append(grouped, expr)
+	}
+	if len(embeds) > 0 {
+		warnings = append(warnings, "PostgREST aggregates embedded rows per parent row; the SQL groups by the selected parent columns instead")
 	}
 
-	// Add embedded resource columns (qualified)
-	for _, embed := range embeds {
-		for _, col := range embed.Select {
-			if col != "*" {
-				allColumns = append(allColumns, embed.Relation+"."+col)
-			} else {
-				allColumns = append(allColumns, embed.Relation+".*")
-			}
+	return "SELECT " + strings.Join(allColumns, ", "), grouped, warnings
+}
+
+// selectItemSQL renders one select item, optionally qualified by table, and
+// the expression to group it by (empty for aggregates)
+// Examples: "full_name:name" -> "name AS full_name", "total.sum():revenue" -> "SUM(total) AS revenue"
+func selectItemSQL(item, table string) (string, string) {
+	column, cast, alias := parseSelectItem(item)
+
+	if function, arg, ok := parseAggregate(column); ok {
+		sql := aggregateSQL(function, arg, table)
+		if cast != "" {
+			sql += "::" + cast
+		}
+		if alias != "" {
+			sql += " AS " + identifierSQL(alias)
 		}
+		return sql, ""
 	}
 
-	// Store embeds in request for FROM clause builder
-	req.Embedded = embeds
+	sql := column
+	if table != "" {
+		sql = table + "." + column
+	}
+	sql = columnSQL(sql)
+	if isJSONPath(column) {
+		if cast != "" {
+			// :: binds tighter than ->>, so the path needs parentheses
+			sql = "(" + sql + ")"
+		}
+		if alias == "" {
+			// PostgREST names the value after the last key
+			alias = jsonPathKey(column)
+		}
+	}
+	if cast != "" {
+		sql += "::" + cast
+	}
 
-	return "SELECT " + strings.Join(allColumns, ", ")
+	groupExpr := sql
+	if alias != "" {
+		sql += " AS " + identifierSQL(alias)
+	}
+	return sql, groupExpr
+}
+
+// parseSelectItem splits a select item into column, cast and alias.
+// PostgREST writes aliases first (alias:column::type); a cast, aggregate or
+// JSON path before the alias separator (column::type:alias) is read as column
+// first, which is how the forward converter renders aliased expressions.
+func parseSelectItem(item string) (column, cast, alias string) {
+	column = item
+	if i := aliasSeparator(item); i != -1 {
+		left, right := item[:i], item[i+1:]
+		if strings.Contains(left, "::") || strings.Contains(left, "(") || isJSONPath(left) {
+			column, alias = left, right
+		} else {
+			alias, column = left, right
+		}
+	}
+
+	if col, typ, found := strings.Cut(column, "::"); found {
+		column, cast = col, typ
+	}
+	return column, cast, alias
+}
+
+// aliasSeparator returns the index of the single ':' separating an alias,
+// skipping '::' casts and anything inside parentheses, or -1
+func aliasSeparator(item string) int {
+	depth := 0
+	for i := 0; i < len(item); i++ {
+		switch item[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if item[i] != ':' || depth > 0 {
+			continue
+		}
+		if i+1 < len(item) && item[i+1] == ':' {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
 }
 
 // buildFromClause builds the FROM clause with JOINs for embedded resources
-func buildFromClause(req *PostgRESTRequest) (string, []string) {
+func buildFromClause(req *PostgRESTRequest, p *params) (string, []string, error) {
 	warnings := []string{}
 
 	// Start with main table
-	fromClause := "FROM " + req.Table
+	fromClause := "FROM " + qualifiedName(req, req.Table)
+
+	// Add JOINs for embedded resources, which live in the same schema
+	joins, warnings, err := buildJoins(req, p, req.Table, req.Table, req.Embedded)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fromClause + joins, warnings, nil
+}
+
+// buildJoins renders the JOINs for embeds of a parent, then their own embeds,
+// so nested embeds chain through each level
+func buildJoins(req *PostgRESTRequest, p *params, parentTable, parentName string, embeds []EmbeddedResource) (string, []string, error) {
+	var joins strings.Builder
+	warnings := []string{}
+
+	for _, embed := range embeds {
+		name := embed.Name()
+		var joinCondition string
+		if embed.Spread {
+			fkColumn, warning := spreadForeignKey(parentTable, embed)
+			warnings = append(warnings, warning)
+			joinCondition = fmt.Sprintf("%s.id = %s.%s", identifierSQL(name), identifierSQL(parentName), identifierSQL(fkColumn))
+		} else {
+			fkColumn, warning := embedForeignKey(parentTable, embed)
+			warnings = append(warnings, warning)
+			joinCondition = fmt.Sprintf("%s.%s = %s.id", identifierSQL(name), identifierSQL(fkColumn), identifierSQL(parentName))
+		}
+
+		// Embedded filters restrict the joined rows, not the parent rows,
+		// so they belong in ON (for !inner this also drops unmatched parents)
+		for _, filter := range embed.Filters {
+			filter.Column = name + "." + filter.Column
+			condition, err := buildCondition(p, filter)
+			if err != nil {
+				return "", nil, err
+			}
+			joinCondition += " AND " + condition
+		}
+
+		joinType := "LEFT JOIN"
+		if embed.Inner {
+			joinType = "INNER JOIN"
+		}
+		target := qualifiedName(req, embed.Relation)
+		if embed.Alias != "" {
+			target += " AS " + identifierSQL(embed.Alias)
+		}
+
+		if len(embed.Order) > 0 || embed.Limit != nil || embed.Offset != nil {
+			// Order and paging apply per parent row, so the embed becomes a
+			// LATERAL subquery correlated on the join condition
+			subquery := "SELECT * FROM " + target + " WHERE " + joinCondition
+			if orderByClause := buildOrderByClause(embed.Order); orderByClause != "" {
+				subquery += " " + orderByClause
+			}
+			if limitOffsetClause := buildLimitOffsetClause(embed.Limit, embed.Offset); limitOffsetClause != "" {
+				subquery += " " + limitOffsetClause
+			}
+			fmt.Fprintf(&joins, " %s LATERAL (%s) AS %s ON true", joinType, subquery, identifierSQL(name))
+		} else {
+			fmt.Fprintf(&joins, " %s %s ON %s", joinType, target, joinCondition)
+		}
 
-	// Add JOINs for embedded resources
-	if len(req.Embedded) > 0 {
-		for _, embed := range req.Embedded {
-			// Assume foreign key convention: {table}_id
-			// This is a limitation - we can't know the actual FK without schema
-			joinCondition := fmt.Sprintf("%s.%s = %s.id", embed.Relation, req.Table+"_id", req.Table)
+		nested, nestedWarnings, err := buildJoins(req, p, embed.Relation, name, embed.Embedded)
+		if err != nil {
+			return "", nil, err
+		}
+		joins.WriteString(nested)
+		warnings = append(warnings, nestedWarnings...)
+	}
 
-			fromClause += fmt.Sprintf(" LEFT JOIN %s ON %s", embed.Relation, joinCondition)
+	return joins.String(), warnings, nil
+}
+
+// embedForeignKey picks the embedded table's column referencing the parent.
+// Without a schema this comes from the FK hint when it names a column or a
+// default-named constraint ({table}_{column}_fkey), else the {parent}_id
+// convention; the warning says which assumption was made.
+func embedForeignKey(parent string, embed EmbeddedResource) (string, string) {
+	if embed.Hint == "" {
+		// This is a limitation - we can't know the actual FK without schema
+		column := parent + "_id"
+		return column, fmt.Sprintf("Assuming FK convention: %s.%s references %s.id", embed.Relation, column, parent)
+	}
 
+	if constraint, found := strings.CutSuffix(embed.Hint, "_fkey"); found {
+		if column, found := strings.CutPrefix(constraint, embed.Relation+"_"); found && column != "" {
+			return column, fmt.Sprintf("Using FK %s: assuming %s.%s references %s.id", embed.Hint, embed.Relation, column, parent)
+		}
+		column := parent + "_id"
+		return column, fmt.Sprintf("FK %s does not follow {table}_{column}_fkey naming; assuming %s.%s references %s.id - check the constraint definition", embed.Hint, embed.Relation, column, parent)
+	}
+
+	return embed.Hint, fmt.Sprintf("Using FK hint %s: assuming %s.%s references %s.id", embed.Hint, embed.Relation, embed.Hint, parent)
+}
+
+// spreadForeignKey picks the parent's column referencing a spread embed.
+// PostgREST only spreads to-one relations, so the FK is on the parent side:
+// the hint when it names a column or a {parent}_{column}_fkey constraint,
+// else the {relation}_id convention.
+func spreadForeignKey(parent string, embed EmbeddedResource) (string, string) {
+	column := embed.Relation + "_id"
+	if constraint, found := strings.CutSuffix(embed.Hint, "_fkey"); found {
+		if fk, found := strings.CutPrefix(constraint, parent+"_"); found && fk != "" {
+			column = fk
+		}
+	} else if embed.Hint != "" {
+		column = embed.Hint
+	}
+	return column, fmt.Sprintf("Assuming FK convention for spread %s: %s.%s references %s.id", embed.Relation, parent, column, embed.Relation)
+}
+
+// attachEmbeddedFilters moves filters whose key names an embedded relation,
+// e.g. posts.status=eq.published or posts.comments.approved=is.true, from the
+// request onto that embed
+func attachEmbeddedFilters(req *PostgRESTRequest) []string {
+	var warnings []string
+	var remaining []Filter
+
+	for _, filter := range req.Filters {
+		relation, _, found := strings.Cut(filter.Column, ".")
+		if !found {
+			remaining = append(remaining, filter)
+			continue
+		}
+
+		embed, column := findEmbed(req.Embedded, filter.Column)
+		if embed == nil {
 			warnings = append(warnings, fmt.Sprintf(
-				"Assuming FK convention: %s.%s references %s.id",
-				embed.Relation,
-				req.Table+"_id",
-				req.Table,
+				"filter on %s refers to %s, which is not embedded in select; treating it as a column",
+				filter.Column, relation,
 			))
+			remaining = append(remaining, filter)
+			continue
 		}
+		filter.Column = column
+		embed.Filters = append(embed.Filters, filter)
 	}
 
-	return fromClause, warnings
+	req.Filters = remaining
+	return warnings
+}
+
+// attachEmbedPages moves posts.order=, posts.limit= and posts.offset= onto
+// the embeds they page
+func attachEmbedPages(req *PostgRESTRequest) []string {
+	var warnings []string
+	for _, page := range req.EmbedPages {
+		embed := embedAt(req.Embedded, page.Path)
+		if embed == nil {
+			warnings = append(warnings, fmt.Sprintf(
+				"order, limit and offset on %s are ignored because %s is not embedded in select",
+				page.Path, page.Path,
+			))
+			continue
+		}
+		embed.Order = page.Order
+		embed.Limit = page.Limit
+		embed.Offset = page.Offset
+	}
+	return warnings
+}
+
+// embedAt returns the embed at a dotted path like posts.comments
+func embedAt(embeds []EmbeddedResource, path string) *EmbeddedResource {
+	name, rest, nested := strings.Cut(path, ".")
+	for i := range embeds {
+		if embeds[i].Name() != name {
+			continue
+		}
+		if !nested {
+			return &embeds[i]
+		}
+		return embedAt(embeds[i].Embedded, rest)
+	}
+	return nil
+}
+
+// findEmbed follows a dotted path like posts.comments.approved through the
+// embeds and returns the deepest matching embed and the remaining column
+func findEmbed(embeds []EmbeddedResource, path string) (*EmbeddedResource, string) {
+	name, rest, found := strings.Cut(path, ".")
+	if !found {
+		return nil, path
+	}
+
+	for i := range embeds {
+		if embeds[i].Name() != name {
+			continue
+		}
+		if nested, column := findEmbed(embeds[i].Embedded, rest); nested != nil {
+			return nested, column
+		}
+		return &embeds[i], rest
+	}
+	return nil, path
 }
 
 // buildOrderByClause builds the ORDER BY clause
@@ -88,7 +381,7 @@ func buildOrderByClause(order []OrderBy) string {
 
 	var parts []string
 	for _, o := range order {
-		part := o.Column
+		part := columnSQL(o.Column)
 		if o.Descending {
 			part += " DESC"
 		} else {
@@ -126,3 +419,22 @@ func buildLimitOffsetClause(limit, offset *int) string {
 
 	return strings.Join(parts, " ")
 }
+
+// limitOffsetNotes explains paging edge cases that are easy to misread
+func limitOffsetNotes(req *PostgRESTRequest) []string {
+	var notes []string
+
+	if req.Limit != nil && *req.Limit == 0 {
+		notes = append(notes, "limit=0 returns no rows; it is typically used to probe columns or read the count")
+	}
+
+	if req.Offset != nil && req.Limit == nil {
+		notes = append(notes, fmt.Sprintf("offset without limit skips %d rows and returns all remaining rows", *req.Offset))
+	}
+
+	if req.Offset != nil && len(req.Order) == 0 {
+		notes = append(notes, "OFFSET without ORDER BY skips rows in an unspecified order; add order= for stable pages")
+	}
+
+	return notes
+}
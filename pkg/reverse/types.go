@@ -2,7 +2,7 @@ package reverse
 
 // PostgRESTRequest represents a structured PostgREST HTTP request
 type PostgRESTRequest struct {
-	Method   string              // GET, POST, PATCH, DELETE
+	Method   string              // GET, HEAD, POST, PUT, PATCH, DELETE
 	Table    string              // Table name from path
 	Select   []string            // Columns to select
 	Filters  []Filter            // WHERE conditions
@@ -11,7 +11,22 @@ type PostgRESTRequest struct {
 	Offset   *int                // OFFSET value
 	Body     interface{}         // Request body for mutations
 	Headers  map[string]string   // HTTP headers
+	OnConflict []string          // on_conflict columns for upserts
+	Columns  []string            // columns= restricting which body keys are inserted
 	Embedded []EmbeddedResource  // Nested resources (JOINs)
+	Function string              // Function name for /rpc/ paths
+	Args     map[string]string   // Function arguments from GET /rpc/ query params
+	EmbedPages []EmbedPage       // posts.order=, posts.limit=, posts.offset= for embeds
+}
+
+// EmbedPage orders and pages the rows of one embedded resource, e.g.
+// posts.order=created_at.desc&posts.limit=5; it is attached to the embed
+// once the select list has been parsed
+type EmbedPage struct {
+	Path   string    // Dotted embed path, e.g. posts or posts.comments
+	Order  []OrderBy // ORDER BY within the embed
+	Limit  *int      // LIMIT per parent row
+	Offset *int      // OFFSET per parent row
 }
 
 // Filter represents a WHERE condition
@@ -20,7 +35,10 @@ type Filter struct {
 	Operator string      // PostgREST operator (eq, gte, like, etc.)
 	Value    interface{} // Filter value
 	Negated  bool        // NOT condition
+	Quantifier string    // "any" or "all" for eq(any).{...}, like(all).{...}
+	Language string      // Text search configuration for fts(french).{...}
 	Logical  string      // Logical operator: "and" or "or"
+	Conditions []Filter  // Grouped conditions joined by Logical (or=, and())
 }
 
 // OrderBy represents an ORDER BY clause
@@ -34,27 +52,34 @@ type OrderBy struct {
 // EmbeddedResource represents a nested resource (JOIN)
 type EmbeddedResource struct {
 	Relation string              // Relation name (table name)
+	Alias    string              // alias:relation(...) renames the embed
+	Hint     string              // FK hint from relation!hint (constraint or column name)
+	Inner    bool                // relation!inner: INNER JOIN instead of LEFT JOIN
+	Spread   bool                // ...relation(...): to-one embed flattened into the parent
 	Select   []string            // Columns to select from embedded resource
 	Filters  []Filter            // Filters on embedded resource
 	Order    []OrderBy           // ORDER BY on embedded resource
 	Limit    *int                // LIMIT on embedded resource
+	Offset   *int                // OFFSET on embedded resource
 	Embedded []EmbeddedResource  // Nested embeds (recursive)
 }
 
 // SQLResult is the result of converting PostgREST to SQL
 type SQLResult struct {
 	SQL         string            // Generated SQL query
-	HTTPRequest *HTTPRequest      // For non-SQL operations
+	Args        []interface{}     // Bind parameters for $1, $2, ... (WithParameters only)
+	HTTPRequest *HTTPRequest      // The request converted, in canonical form
 	Warnings    []string          // Conversion warnings/notes
 	Metadata    map[string]string // Additional context
 }
 
-// HTTPRequest represents an HTTP request (for non-SQL operations)
+// HTTPRequest represents an HTTP request, such as the canonical form of the
+// request a SQLResult was converted from
 type HTTPRequest struct {
-	Method  string            // HTTP method
-	URL     string            // Complete URL
-	Headers map[string]string // HTTP headers
-	Body    string            // Request body
+	Method  string            `json:"method"`            // HTTP method
+	URL     string            `json:"url"`               // Complete URL
+	Headers map[string]string `json:"headers,omitempty"` // HTTP headers
+	Body    string            `json:"body,omitempty"`    // Request body
 }
 
 // ConversionError represents a conversion error with context
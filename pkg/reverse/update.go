@@ -6,7 +6,7 @@ import (
 )
 
 // buildUpdateStatement builds an UPDATE statement from a PATCH request
-func buildUpdateStatement(req *PostgRESTRequest) (string, error) {
+func buildUpdateStatement(req *PostgRESTRequest, p *params) (string, error) {
 	if req.Body == nil {
 		return "", NewSemanticError(
 			"ERR_SEMANTIC_NO_BODY",
@@ -37,18 +37,23 @@ func buildUpdateStatement(req *PostgRESTRequest) (string, error) {
 
 	// Build SET clause
 	var setParts []string
-	for col, val := range data {
-		setParts = append(setParts, fmt.Sprintf("%s = %s", col, formatJSONValue(val)))
+	for _, col := range bodyColumns(data) {
+		setParts = append(setParts, fmt.Sprintf("%s = %s", identifierSQL(col), p.json(data[col])))
 	}
 
-	sql := fmt.Sprintf("UPDATE %s SET %s", req.Table, strings.Join(setParts, ", "))
+	sql := fmt.Sprintf("UPDATE %s SET %s", qualifiedName(req, req.Table), strings.Join(setParts, ", "))
 
 	// Add WHERE clause if filters exist
-	if len(req.Filters) > 0 {
-		whereClause, err := buildWhereClause(req.Filters)
-		if err != nil {
-			return "", err
-		}
+	whereClause, err := buildWhereClause(p, req.Filters)
+	if err != nil {
+		return "", err
+	}
+
+	// limit=5&order=id updates only the first matching rows
+	if limitedMutation(req) {
+		whereClause = limitedWhereClause(req, whereClause)
+	}
+	if whereClause != "" {
 		sql += " " + whereClause
 	}
 
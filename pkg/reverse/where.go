@@ -5,15 +5,16 @@ import (
 	"strings"
 )
 
-// buildWhereClause builds a WHERE clause from filters
-func buildWhereClause(filters []Filter) (string, error) {
+// buildWhereClause builds a WHERE clause from filters, binding values as
+// parameters when p is set
+func buildWhereClause(p *params, filters []Filter) (string, error) {
 	if len(filters) == 0 {
 		return "", nil
 	}
 
 	var conditions []string
 	for _, filter := range filters {
-		condition, err := buildCondition(filter)
+		condition, err := buildCondition(p, filter)
 		if err != nil {
 			return "", err
 		}
@@ -25,10 +26,18 @@ func buildWhereClause(filters []Filter) (string, error) {
 }
 
 // buildCondition builds a single filter condition
-func buildCondition(filter Filter) (string, error) {
+func buildCondition(p *params, filter Filter) (string, error) {
+	// Handle grouped conditions from or=(...) / and(...)
+	if len(filter.Conditions) > 0 {
+		return buildGroupCondition(p, filter)
+	}
+
+	// Quote the column and JSON path keys, e.g. metadata->>status -> metadata->>'status'
+	filter.Column = columnSQL(filter.Column)
+
 	// Handle full-text search operators specially
 	if IsFullTextSearchOperator(filter.Operator) {
-		condition, err := HandleFullTextSearch(filter.Column, filter.Operator, filter.Value.(string))
+		condition, err := fullTextSearchSQL(p, filter.Column, filter.Operator, filter.Language, filter.Value.(string))
 		if err != nil {
 			return "", err
 		}
@@ -51,14 +60,28 @@ func buildCondition(filter Filter) (string, error) {
 		return filter.Column + " IS " + strings.ToUpper(value), nil
 	}
 
+	// not.isdistinct reads better as IS NOT DISTINCT FROM
+	if filter.Operator == "isdistinct" && filter.Negated && filter.Quantifier == "" {
+		return fmt.Sprintf("%s IS NOT DISTINCT FROM %s", filter.Column, formatValue(p, filter.Value.(string), filter.Operator)), nil
+	}
+
 	// Map operator
 	sqlOp, err := MapOperator(filter.Operator)
 	if err != nil {
 		return "", err
 	}
 
+	// eq(any).{1,2} -> col = ANY (ARRAY[1, 2])
+	if filter.Quantifier != "" {
+		condition, err := buildQuantifiedCondition(p, filter.Column, sqlOp, filter.Quantifier, filter.Value.(string))
+		if err != nil {
+			return "", err
+		}
+		return HandleNegation(condition, filter.Negated), nil
+	}
+
 	// Format value
-	value := FormatValue(filter.Value.(string), filter.Operator)
+	value := formatValue(p, filter.Value.(string), filter.Operator)
 
 	// Build condition
 	var condition string
@@ -0,0 +1,171 @@
+package supabase
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// authEndpoint describes the GoTrue request behind a supabase.auth method
+type authEndpoint struct {
+	method string
+	path   string // Under /auth/v1; {id} is the first argument
+	query  string // Fixed query string, e.g. grant_type=password
+	body   string // "object" sends the first argument, "email" wraps it as {email}
+	admin  bool   // Needs the service_role key
+	user   bool   // Needs the signed-in user's access token
+}
+
+// authEndpoints maps supabase.auth methods to GoTrue endpoints
+var authEndpoints = map[string]authEndpoint{
+	"signUp":                {method: "POST", path: "/signup", body: "object"},
+	"signInWithPassword":    {method: "POST", path: "/token", query: "grant_type=password", body: "object"},
+	"signInWithOtp":         {method: "POST", path: "/otp", body: "object"},
+	"signInWithIdToken":     {method: "POST", path: "/token", query: "grant_type=id_token", body: "object"},
+	"signInAnonymously":     {method: "POST", path: "/signup", body: "object"},
+	"verifyOtp":             {method: "POST", path: "/verify", body: "object"},
+	"refreshSession":        {method: "POST", path: "/token", query: "grant_type=refresh_token", body: "object"},
+	"resetPasswordForEmail": {method: "POST", path: "/recover", body: "email"},
+	"resend":                {method: "POST", path: "/resend", body: "object"},
+	"reauthenticate":        {method: "GET", path: "/reauthenticate", user: true},
+	"signOut":               {method: "POST", path: "/logout", user: true},
+	"getUser":               {method: "GET", path: "/user", user: true},
+	"updateUser":            {method: "PUT", path: "/user", body: "object", user: true},
+
+	"admin.listUsers":         {method: "GET", path: "/admin/users", admin: true},
+	"admin.createUser":        {method: "POST", path: "/admin/users", body: "object", admin: true},
+	"admin.getUserById":       {method: "GET", path: "/admin/users/{id}", admin: true},
+	"admin.updateUserById":    {method: "PUT", path: "/admin/users/{id}", body: "object", admin: true},
+	"admin.deleteUser":        {method: "DELETE", path: "/admin/users/{id}", admin: true},
+	"admin.inviteUserByEmail": {method: "POST", path: "/invite", body: "email", admin: true},
+	"admin.generateLink":      {method: "POST", path: "/admin/generate_link", body: "object", admin: true},
+}
+
+// authOptionFields moves supabase-js options into the GoTrue body
+// (options.data -> data) or query string (emailRedirectTo -> redirect_to)
+var authOptionFields = map[string]string{
+	"data":            "data",
+	"captchaToken":    "gotrue_meta_security",
+	"emailRedirectTo": "redirect_to",
+	"redirectTo":      "redirect_to",
+}
+
+// parseAuthCall reads supabase.auth.method(args) or
+// supabase.auth.admin.method(args) into an auth call whose first argument
+// is the qualified method name
+func parseAuthCall(input string) ([]MethodCall, error) {
+	_, chain, _ := strings.Cut(input, ".auth")
+	calls, err := scanMethodCalls(chain)
+	if err != nil {
+		return nil, err
+	}
+	if len(calls) == 0 {
+		return []MethodCall{{Name: "auth", Args: []string{}}}, nil
+	}
+
+	name := calls[0].Name
+	if strings.HasPrefix(strings.TrimSpace(chain), ".admin") {
+		name = "admin." + name
+	}
+	return []MethodCall{{Name: "auth", Args: append([]string{name}, calls[0].Args...), Variables: calls[0].Variables}}, nil
+}
+
+// authRequest fills output with the GoTrue request for a supabase.auth
+// call. Methods with no endpoint, like getSession, only get a warning.
+func (c *Converter) authRequest(query *SupabaseQuery, output *PostgRESTOutput) error {
+	endpoint, ok := authEndpoints[query.SpecialMethod]
+	if !ok {
+		if query.SpecialMethod != "" {
+			output.Warnings = append(output.Warnings, fmt.Sprintf("auth.%s does not map to a single Auth API request", query.SpecialMethod))
+		}
+		return nil
+	}
+
+	args := query.SpecialArgs
+	path := "/auth/v1" + endpoint.path
+	if strings.Contains(path, "{id}") {
+		if len(args) == 0 {
+			return &ArgumentError{Method: query.SpecialMethod, Message: "missing user id argument"}
+		}
+		path = strings.Replace(path, "{id}", url.PathEscape(args[0]), 1)
+		args = args[1:]
+	}
+
+	params := url.Values{}
+	if endpoint.query != "" {
+		params, _ = url.ParseQuery(endpoint.query)
+	}
+
+	var body map[string]interface{}
+	switch endpoint.body {
+	case "email":
+		if len(args) == 0 {
+			return &ArgumentError{Method: query.SpecialMethod, Message: "missing email argument"}
+		}
+		body = map[string]interface{}{"email": args[0]}
+		if len(args) >= 2 {
+			c.moveAuthOptions(parseJSON(args[1]), body, params, output)
+		}
+	case "object":
+		if len(args) > 0 {
+			object, ok := parseJSON(args[0]).(map[string]interface{})
+			if !ok {
+				return &ArgumentError{Method: query.SpecialMethod, Arg: args[0], Message: "argument must be an object"}
+			}
+			body = object
+			if options, found := body["options"]; found {
+				delete(body, "options")
+				c.moveAuthOptions(options, body, params, output)
+			}
+		} else {
+			body = map[string]interface{}{}
+		}
+	}
+
+	output.Method = endpoint.method
+	output.Path = path
+	output.Query = params.Encode()
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		output.Body = string(bodyBytes)
+		output.Headers["Content-Type"] = "application/json"
+	}
+
+	output.Headers["apikey"] = "<SUPABASE_ANON_KEY>"
+	switch {
+	case endpoint.admin:
+		output.Headers["apikey"] = "<SUPABASE_SERVICE_ROLE_KEY>"
+		output.Headers["Authorization"] = "Bearer <SUPABASE_SERVICE_ROLE_KEY>"
+		output.Warnings = append(output.Warnings, "auth.admin methods need the service_role key; never send it from a browser")
+	case endpoint.user:
+		output.Headers["Authorization"] = "Bearer <ACCESS_TOKEN>"
+	}
+	output.Description = fmt.Sprintf("Supabase Auth %s: %s %s", query.SpecialMethod, output.Method, path)
+	return nil
+}
+
+// moveAuthOptions copies supabase-js auth options into the GoTrue body or
+// query string, warning about ones with no request equivalent
+func (c *Converter) moveAuthOptions(options interface{}, body map[string]interface{}, params url.Values, output *PostgRESTOutput) {
+	optsMap, ok := options.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for key, val := range optsMap {
+		field, known := authOptionFields[key]
+		switch {
+		case !known:
+			output.Warnings = append(output.Warnings, fmt.Sprintf("auth option %q has no GoTrue request equivalent and was ignored", key))
+		case field == "redirect_to":
+			params.Set(field, fmt.Sprintf("%v", val))
+		case field == "gotrue_meta_security":
+			body[field] = map[string]interface{}{"captcha_token": val}
+		default:
+			body[field] = val
+		}
+	}
+}
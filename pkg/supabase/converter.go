@@ -9,7 +9,13 @@ import (
 
 // Converter converts Supabase JS queries to PostgREST requests
 type Converter struct {
-	BaseURL string
+	BaseURL     string
+	Dialect     Dialect // Client library syntax of the input; empty means DialectJS
+	RangeParams bool    // Send .range() as limit/offset params instead of a Range header
+
+	// Headers, such as apikey or Authorization, added to every request.
+	// They replace generated headers of the same name.
+	Headers map[string]string
 }
 
 // NewConverter creates a new Supabase converter
@@ -23,20 +29,52 @@ func NewConverter(baseURL string) *Converter {
 // Convert converts a Supabase JS query string to PostgREST
 func (c *Converter) Convert(input string) (*PostgRESTOutput, error) {
 	// Parse the Supabase query
-	query, err := Parse(input)
+	query, err := c.parse(input)
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert to PostgREST
-	return c.toPostgREST(query)
+	output, err := c.toPostgREST(query)
+	if err != nil {
+		return nil, err
+	}
+	c.addHeaders(output)
+	return output, nil
+}
+
+// addHeaders sets the converter's extra headers on output, replacing
+// generated headers whose names differ only in case
+func (c *Converter) addHeaders(output *PostgRESTOutput) {
+	if len(c.Headers) > 0 && output.Headers == nil {
+		output.Headers = make(map[string]string)
+	}
+	for name, value := range c.Headers {
+		for existing := range output.Headers {
+			if strings.EqualFold(existing, name) {
+				delete(output.Headers, existing)
+			}
+		}
+		output.Headers[name] = value
+	}
+}
+
+// parse parses a query written in the converter's dialect
+func (c *Converter) parse(input string) (*SupabaseQuery, error) {
+	switch c.Dialect {
+	case DialectPython:
+		input = pythonToJS(input)
+	case DialectDart:
+		input = dartToJS(input)
+	}
+	return Parse(input)
 }
 
 // toPostgREST converts a SupabaseQuery to PostgRESTOutput
 func (c *Converter) toPostgREST(query *SupabaseQuery) (*PostgRESTOutput, error) {
 	output := &PostgRESTOutput{
 		Headers:  make(map[string]string),
-		Warnings: []string{},
+		Warnings: append([]string{}, query.Warnings...),
 	}
 
 	// Handle special operations
@@ -48,6 +86,9 @@ func (c *Converter) toPostgREST(query *SupabaseQuery) (*PostgRESTOutput, error)
 	switch query.Operation {
 	case "select":
 		output.Method = "GET"
+		if query.Head {
+			output.Method = "HEAD"
+		}
 	case "insert":
 		output.Method = "POST"
 	case "update":
@@ -60,8 +101,61 @@ func (c *Converter) toPostgREST(query *SupabaseQuery) (*PostgRESTOutput, error)
 
 	// Build path
 	output.Path = "/" + query.Table
+	setProfile(output, query.Schema)
 
 	// Build query parameters
+	params := c.readParams(query, output)
+
+	// Prefer header, merged from every option that sets one
+	prefer := &preferences{}
+	if query.Count != "" {
+		prefer.set("count=" + query.Count)
+	}
+
+	// A .select() chained onto a mutation asks for the written rows back
+	if query.Operation != "select" && len(query.Select) > 0 {
+		prefer.set("return=representation")
+	}
+
+	setAccept(query, output, prefer)
+
+	// Upsert handling
+	if query.Upsert {
+		if query.IgnoreDuplicates {
+			prefer.set("resolution=ignore-duplicates")
+		} else {
+			prefer.set("resolution=merge-duplicates")
+		}
+		if query.OnConflict != "" {
+			params.Add("on_conflict", query.OnConflict)
+		}
+	}
+
+	if len(prefer.values) > 0 {
+		output.Headers["Prefer"] = prefer.String()
+	}
+
+	// Build request body for mutations
+	if query.Data != nil {
+		bodyBytes, err := json.Marshal(query.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		output.Body = string(bodyBytes)
+		output.Headers["Content-Type"] = "application/json"
+	}
+
+	// Set query string
+	if len(params) > 0 {
+		output.Query = params.Encode()
+	}
+
+	return output, nil
+}
+
+// readParams builds the select, filter, order and pagination parameters
+// shared by table requests and .rpc() calls
+func (c *Converter) readParams(query *SupabaseQuery, output *PostgRESTOutput) url.Values {
 	params := url.Values{}
 
 	// Add select columns
@@ -69,10 +163,15 @@ func (c *Converter) toPostgREST(query *SupabaseQuery) (*PostgRESTOutput, error)
 		params.Add("select", strings.Join(query.Select, ","))
 	}
 
-	// Add filters
+	// Add filters. A dotted column like posts.status filters the posts
+	// embed, so PostgREST rejects it unless posts is in the select.
+	embeds := embedNames(query.Select)
 	for _, filter := range query.Filters {
-		paramValue := c.formatFilter(filter)
-		params.Add(filter.Column, paramValue)
+		key, value := c.filterParam(filter)
+		if table, _, found := strings.Cut(key, "."); found && !embeds[table] {
+			output.Warnings = append(output.Warnings, fmt.Sprintf("filter on %s refers to %s, which is not embedded in the select; PostgREST will reject it", key, table))
+		}
+		params.Add(key, value)
 	}
 
 	// Add order
@@ -86,58 +185,162 @@ func (c *Converter) toPostgREST(query *SupabaseQuery) (*PostgRESTOutput, error)
 		if order.NullsFirst {
 			orderStr += ".nullsfirst"
 		}
-		params.Add("order", orderStr)
-	}
-
-	// Add limit
-	if query.Limit != nil {
-		params.Add("limit", fmt.Sprintf("%d", *query.Limit))
+		key := "order"
+		if order.Table != "" {
+			key = order.Table + ".order"
+		}
+		params.Add(key, orderStr)
 	}
 
-	// Add range
-	if query.Range != nil {
-		// Range header instead of query param
+	// Add limit and offset, unless .range() goes in a Range header
+	if query.Range != nil && !c.RangeParams {
 		output.Headers["Range"] = fmt.Sprintf("%d-%d", query.Range.From, query.Range.To)
+	} else {
+		if query.Limit != nil {
+			params.Add("limit", fmt.Sprintf("%d", *query.Limit))
+		}
+		if query.Offset != nil {
+			params.Add("offset", fmt.Sprintf("%d", *query.Offset))
+		}
 	}
-
-	// Add count header
-	if query.Count != "" {
-		output.Headers["Prefer"] = fmt.Sprintf("count=%s", query.Count)
+	for table, limit := range query.EmbedLimits {
+		params.Add(table+".limit", fmt.Sprintf("%d", limit))
+	}
+	for table, offset := range query.EmbedOffsets {
+		params.Add(table+".offset", fmt.Sprintf("%d", offset))
 	}
+	return params
+}
 
-	// Single/maybeSingle headers
+// setAccept sets the Accept header for .single(), .maybeSingle(), .csv(),
+// .geojson() and .explain()
+func setAccept(query *SupabaseQuery, output *PostgRESTOutput, prefer *preferences) {
 	if query.Single {
 		output.Headers["Accept"] = "application/vnd.pgrst.object+json"
 	} else if query.MaybeSingle {
 		output.Headers["Accept"] = "application/vnd.pgrst.object+json"
-		output.Headers["Prefer"] = "return=representation"
+		prefer.set("return=representation")
 	}
 
-	// Upsert handling
-	if query.Upsert {
-		resolution := "resolution=merge-duplicates"
-		if query.OnConflict != "" {
-			resolution = fmt.Sprintf("resolution=%s", query.OnConflict)
+	// Response format: .csv(), .geojson(), then .explain() wrapping it
+	if query.Accept != "" {
+		output.Headers["Accept"] = query.Accept
+	}
+	if query.Explain != nil {
+		output.Headers["Accept"] = explainAccept(query.Explain, output.Headers["Accept"])
+	}
+}
+
+// rpcQuery adds .rpc() arguments to params, arrays as {a,b}
+func (c *Converter) rpcQuery(params url.Values, rpcParams interface{}) error {
+	if rpcParams == nil {
+		return nil
+	}
+	args, ok := rpcParams.(map[string]interface{})
+	if !ok {
+		return NewSemanticError(ErrCodeInvalidArgument,
+			"rpc arguments must be an object to be sent in the query string",
+			fmt.Sprintf("%v", rpcParams),
+			"pass the arguments as an object like {a: 1}, or drop {get: true}")
+	}
+
+	for name, value := range args {
+		switch v := value.(type) {
+		case []interface{}:
+			params.Add(name, c.arrayLiteral(v))
+		default:
+			params.Add(name, c.formatValue(v, ""))
 		}
-		output.Headers["Prefer"] = resolution
 	}
+	return nil
+}
 
-	// Build request body for mutations
-	if query.Data != nil {
-		bodyBytes, err := json.Marshal(query.Data)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+// setProfile selects a non-default schema: Accept-Profile for reads,
+// Content-Profile for writes, as supabase-js does
+func setProfile(output *PostgRESTOutput, schema string) {
+	if schema == "" {
+		return
+	}
+	if output.Method == "GET" || output.Method == "HEAD" {
+		output.Headers["Accept-Profile"] = schema
+	} else {
+		output.Headers["Content-Profile"] = schema
+	}
+}
+
+// embedNames returns the names filters use for the embedded resources of
+// a select: author for author:users!hint(...), posts for posts!inner(...)
+func embedNames(columns []string) map[string]bool {
+	names := map[string]bool{}
+	for _, column := range columns {
+		embed, _, found := strings.Cut(column, "(")
+		if !found {
+			continue
 		}
-		output.Body = string(bodyBytes)
-		output.Headers["Content-Type"] = "application/json"
+		embed, _, _ = strings.Cut(embed, "!")
+		name, _, _ := strings.Cut(embed, ":")
+		names[name] = true
 	}
+	return names
+}
 
-	// Set query string
-	if len(params) > 0 {
-		output.Query = params.Encode()
+// explainAccept builds the pgrst.plan media type for .explain(), e.g.
+// application/vnd.pgrst.plan+json; for="application/json"; options=analyze|verbose
+func explainAccept(explain *Explain, forMediaType string) string {
+	if forMediaType == "" {
+		forMediaType = "application/json"
 	}
 
-	return output, nil
+	options := []string{}
+	for _, option := range []struct {
+		name string
+		on   bool
+	}{
+		{"analyze", explain.Analyze},
+		{"verbose", explain.Verbose},
+		{"settings", explain.Settings},
+		{"buffers", explain.Buffers},
+		{"wal", explain.WAL},
+	} {
+		if option.on {
+			options = append(options, option.name)
+		}
+	}
+
+	accept := fmt.Sprintf("application/vnd.pgrst.plan+%s; for=%q", explain.Format, forMediaType)
+	if len(options) > 0 {
+		accept += "; options=" + strings.Join(options, "|")
+	}
+	return accept
+}
+
+// ResponseFormatWarnings notes when the Accept header of a converted
+// request makes PostgREST answer with something other than the JSON rows
+// the equivalent SQL returns
+func ResponseFormatWarnings(output *PostgRESTOutput) []string {
+	accept := output.Headers["Accept"]
+	switch {
+	case strings.HasPrefix(accept, "application/vnd.pgrst.plan"):
+		return []string{".explain() makes PostgREST return the query plan; the SQL shows the query being explained"}
+	case accept == "text/csv":
+		return []string{".csv() makes PostgREST return CSV text; the SQL returns the same rows as a table"}
+	case accept == "application/geo+json":
+		return []string{".geojson() makes PostgREST return a GeoJSON FeatureCollection; the SQL returns the plain rows"}
+	}
+	return nil
+}
+
+// filterParam returns the query parameter key and value of a filter. An
+// .or() group goes to or=(...), or posts.or=(...) on a referenced table.
+func (c *Converter) filterParam(filter Filter) (string, string) {
+	if filter.Operator == "or" {
+		key := "or"
+		if filter.Column != "" {
+			key = filter.Column + ".or"
+		}
+		return key, fmt.Sprintf("(%v)", filter.Value)
+	}
+	return filter.Column, c.formatFilter(filter)
 }
 
 // formatFilter formats a filter for PostgREST
@@ -179,11 +382,14 @@ func (c *Converter) formatValue(value interface{}, operator string) string {
 		if operator == "in" {
 			parts := []string{}
 			for _, item := range v {
-				parts = append(parts, c.formatValue(item, ""))
+				parts = append(parts, c.listItem(item))
 			}
 			return "(" + strings.Join(parts, ",") + ")"
 		}
-		// For array contains
+		// Array operators take a Postgres array literal
+		if operator == "cs" || operator == "cd" || operator == "ov" || strings.HasSuffix(operator, "(all)") || strings.HasSuffix(operator, "(any)") {
+			return c.arrayLiteral(v)
+		}
 		jsonBytes, _ := json.Marshal(v)
 		return string(jsonBytes)
 
@@ -197,36 +403,104 @@ func (c *Converter) formatValue(value interface{}, operator string) string {
 	}
 }
 
+// arrayLiteral formats values as a Postgres array literal like {a,b},
+// double quoting items that contain reserved characters
+func (c *Converter) arrayLiteral(values []interface{}) string {
+	parts := []string{}
+	for _, item := range values {
+		part := c.formatValue(item, "")
+		if part == "" || strings.ContainsAny(part, `,{}" \`) {
+			part = quoteListItem(part)
+		}
+		parts = append(parts, part)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// listItem formats an in.(...) list item. Numbers stay bare; strings are
+// double quoted only when they hold characters that delimit the list, e.g.
+// in.(active,"a,b","say \"hi\"")
+func (c *Converter) listItem(item interface{}) string {
+	part := c.formatValue(item, "")
+	if _, ok := item.(string); ok && (part == "" || strings.ContainsAny(part, `,()" \`)) {
+		return quoteListItem(part)
+	}
+	return part
+}
+
+// quoteListItem double quotes a list or array item, escaping \ and "
+func quoteListItem(item string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(item) + `"`
+}
+
 // handleSpecialOp handles special operations like RPC, auth, storage
 func (c *Converter) handleSpecialOp(query *SupabaseQuery) (*PostgRESTOutput, error) {
 	output := &PostgRESTOutput{
-		Headers:    make(map[string]string),
-		IsHTTPOnly: true,
-		Warnings:   []string{"This operation cannot be directly represented as SQL"},
+		Headers:  make(map[string]string),
+		Warnings: append([]string{}, query.Warnings...),
 	}
 
 	switch query.SpecialType {
 	case "rpc":
+		// Function calls map to SELECT * FROM fn(...), so they stay SQL-convertible
 		output.Method = "POST"
+		switch {
+		case query.Head:
+			output.Method = "HEAD"
+		case query.RPCGet:
+			output.Method = "GET"
+		}
 		output.Path = "/rpc/" + query.RPCFunction
 		output.Description = fmt.Sprintf("RPC call to function '%s'", query.RPCFunction)
 
-		if query.RPCParams != nil {
+		// Filters and modifiers chained after .rpc() apply to the
+		// function's result, as they do for a table
+		params := c.readParams(query, output)
+		if output.Method != "POST" {
+			// GET and HEAD pass the arguments in the query string
+			if err := c.rpcQuery(params, query.RPCParams); err != nil {
+				return nil, err
+			}
+		} else if query.RPCParams != nil {
 			bodyBytes, _ := json.Marshal(query.RPCParams)
 			output.Body = string(bodyBytes)
 			output.Headers["Content-Type"] = "application/json"
 		}
+		if len(params) > 0 {
+			output.Query = params.Encode()
+		}
+
+		prefer := &preferences{}
+		if query.Count != "" {
+			prefer.set("count=" + query.Count)
+		}
+		setAccept(query, output, prefer)
+		if len(prefer.values) > 0 {
+			output.Headers["Prefer"] = prefer.String()
+		}
+		setProfile(output, query.Schema)
 
 	case "auth":
+		output.IsHTTPOnly = true
 		output.Description = "Supabase Auth operation (not a PostgREST endpoint)"
-		output.Warnings = append(output.Warnings, "Auth operations use Supabase's Auth API, not PostgREST")
+		output.Warnings = append(output.Warnings, "This operation cannot be directly represented as SQL", "Auth operations use Supabase's Auth API, not PostgREST")
+		if err := c.authRequest(query, output); err != nil {
+			return nil, err
+		}
 
 	case "storage":
+		output.IsHTTPOnly = true
 		output.Description = "Supabase Storage operation (not a PostgREST endpoint)"
-		output.Warnings = append(output.Warnings, "Storage operations use Supabase's Storage API, not PostgREST")
+		output.Warnings = append(output.Warnings, "This operation cannot be directly represented as SQL", "Storage operations use Supabase's Storage API, not PostgREST")
+		if err := c.storageRequest(query, output); err != nil {
+			return nil, err
+		}
 
 	default:
-		return nil, fmt.Errorf("unknown special operation: %s", query.SpecialType)
+		return nil, NewUnsupportedError(ErrCodeUnknownSpecial,
+			fmt.Sprintf("unknown special operation: %s", query.SpecialType),
+			query.SpecialType,
+			"only .rpc(), .auth and .storage calls are supported")
 	}
 
 	return output, nil
@@ -2,6 +2,7 @@ package supabase
 
 import (
 	"encoding/json"
+	"errors"
 	"net/url"
 	"strings"
 	"testing"
@@ -159,7 +160,17 @@ func TestConverter_Filters(t *testing.T) {
 		{
 			name:      "in filter",
 			input:     "supabase.from('users').select('*').in('status', ['active', 'pending'])",
-			wantQuery: "select=*&status=in.('active','pending')",
+			wantQuery: "select=*&status=in.(active,pending)",
+		},
+		{
+			name:      "escaped quote",
+			input:     `supabase.from('users').select('*').eq('name', 'O\'Brien').limit(5)`,
+			wantQuery: "select=*&name=eq.O%27Brien&limit=5",
+		},
+		{
+			name:      "escaped double quote and backslash",
+			input:     `supabase.from('users').select('*').eq('bio', "say \"hi\", \\o/").limit(1)`,
+			wantQuery: "select=*&bio=eq.say+%22hi%22%2C+%5Co%2F&limit=1",
 		},
 	}
 
@@ -264,9 +275,15 @@ func TestConverter_LimitAndRange(t *testing.T) {
 			wantHeaders: map[string]string{"Range": "0-9"},
 		},
 		{
-			name:        "range with limit",
+			name:        "range then limit keeps the start",
 			input:       "supabase.from('users').select('*').range(10, 19).limit(5)",
-			wantQuery:   "select=*&limit=5",
+			wantQuery:   "select=*",
+			wantHeaders: map[string]string{"Range": "10-14"},
+		},
+		{
+			name:        "limit then range",
+			input:       "supabase.from('users').select('*').limit(5).range(10, 19)",
+			wantQuery:   "select=*",
 			wantHeaders: map[string]string{"Range": "10-19"},
 		},
 	}
@@ -293,6 +310,64 @@ func TestConverter_LimitAndRange(t *testing.T) {
 	}
 }
 
+func TestConverter_RangeParams(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+	c.RangeParams = true
+
+	tests := []struct {
+		name      string
+		input     string
+		wantQuery string
+	}{
+		{"range", "supabase.from('users').select('*').range(20, 29)", "select=*&limit=10&offset=20"},
+		{"range then limit", "supabase.from('users').select('*').range(20, 29).limit(5)", "select=*&limit=5&offset=20"},
+		{"limit then range", "supabase.from('users').select('*').limit(5).range(0, 9)", "select=*&limit=10&offset=0"},
+		{"limit alone", "supabase.from('users').select('*').limit(5)", "select=*&limit=5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			got, _ := url.QueryUnescape(result.Query)
+			queryParamsEqual(t, got, tt.wantQuery)
+			if _, ok := result.Headers["Range"]; ok {
+				t.Errorf("Range header = %q, want limit/offset params only", result.Headers["Range"])
+			}
+		})
+	}
+}
+
+func TestConverter_Headers(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+	c.Headers = map[string]string{"apikey": "anon-key", "Authorization": "Bearer user-jwt"}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"select", "supabase.from('users').select('*')"},
+		{"insert", "supabase.from('users').insert({name: 'John'})"},
+		{"auth placeholder replaced", "supabase.auth.getUser()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			for key, want := range c.Headers {
+				if got := result.Headers[key]; got != want {
+					t.Errorf("Header %v = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
 func TestConverter_SingleAndMaybeSingle(t *testing.T) {
 	c := NewConverter("http://localhost:3000")
 
@@ -494,12 +569,8 @@ func TestConverter_RPC(t *testing.T) {
 				}
 			}
 
-			if !result.IsHTTPOnly {
-				t.Error("RPC should be marked as HTTP only")
-			}
-
-			if len(result.Warnings) == 0 {
-				t.Error("RPC should have warnings")
+			if result.IsHTTPOnly {
+				t.Error("RPC should convert to SQL, not be marked as HTTP only")
 			}
 		})
 	}
@@ -652,3 +723,1526 @@ func TestConverter_Count(t *testing.T) {
 		})
 	}
 }
+
+func TestConverter_ModifierValidation(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	errorTests := []struct {
+		name       string
+		input      string
+		wantMethod string
+	}{
+		{
+			name:       "non-numeric limit",
+			input:      "supabase.from('users').select('*').limit('ten')",
+			wantMethod: "limit",
+		},
+		{
+			name:       "negative limit",
+			input:      "supabase.from('users').select('*').limit(-5)",
+			wantMethod: "limit",
+		},
+		{
+			name:       "empty limit",
+			input:      "supabase.from('users').select('*').limit()",
+			wantMethod: "limit",
+		},
+		{
+			name:       "non-numeric range",
+			input:      "supabase.from('users').select('*').range(0, 'end')",
+			wantMethod: "range",
+		},
+		{
+			name:       "inverted range",
+			input:      "supabase.from('users').select('*').range(10, 5)",
+			wantMethod: "range",
+		},
+		{
+			name:       "order options not an object",
+			input:      "supabase.from('users').select('*').order('name', 'desc')",
+			wantMethod: "order",
+		},
+		{
+			name:       "order ascending not a boolean",
+			input:      "supabase.from('users').select('*').order('name', {ascending: 'no'})",
+			wantMethod: "order",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Convert(tt.input)
+			if err == nil {
+				t.Fatalf("Convert() expected error, got nil")
+			}
+
+			var argErr *ArgumentError
+			if !errors.As(err, &argErr) {
+				t.Fatalf("Convert() error = %T, want *ArgumentError", err)
+			}
+			if argErr.Method != tt.wantMethod {
+				t.Errorf("ArgumentError.Method = %v, want %v", argErr.Method, tt.wantMethod)
+			}
+		})
+	}
+
+	t.Run("unknown order option warns", func(t *testing.T) {
+		result, err := c.Convert("supabase.from('users').select('*').order('name', {ascending: false, nulls: 'last'})")
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if !queryParamsEqual(t, result.Query, "order=name.desc") {
+			t.Errorf("Query params don't match: got %v", result.Query)
+		}
+		if len(result.Warnings) != 1 {
+			t.Errorf("Warnings = %v, want 1 warning", result.Warnings)
+		}
+	})
+}
+
+func TestConverter_Or(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name      string
+		input     string
+		wantQuery string
+	}{
+		{
+			name:      "top level",
+			input:     "supabase.from('users').select('*').or('age.lt.18,age.gt.65')",
+			wantQuery: "or=(age.lt.18,age.gt.65)",
+		},
+		{
+			name:      "nested and group",
+			input:     "supabase.from('users').select('*').or('status.eq.active,and(age.gte.18,age.lte.65)')",
+			wantQuery: "or=(status.eq.active,and(age.gte.18,age.lte.65))",
+		},
+		{
+			name:      "foreign table",
+			input:     "supabase.from('users').select('*, posts(*)').or('status.eq.draft,views.gt.100', {foreignTable: 'posts'})",
+			wantQuery: "posts.or=(status.eq.draft,views.gt.100)",
+		},
+		{
+			name:      "referenced table",
+			input:     "supabase.from('users').select('*, posts(*)').or('status.eq.draft', { referencedTable: 'posts' })",
+			wantQuery: "posts.or=(status.eq.draft)",
+		},
+		{
+			name:      "followed by other methods",
+			input:     "supabase.from('users').select('*').or('age.lt.18,age.gt.65').eq('active', true).limit(5)",
+			wantQuery: "or=(age.lt.18,age.gt.65)&active=eq.true&limit=5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			queryParamsEqual(t, result.Query, tt.wantQuery)
+		})
+	}
+}
+
+func TestConverter_Filter(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name      string
+		input     string
+		wantQuery string
+	}{
+		{
+			name:      "comparison",
+			input:     "supabase.from('users').select('*').filter('age', 'gte', 18)",
+			wantQuery: "age=gte.18",
+		},
+		{
+			name:      "embedded column",
+			input:     "supabase.from('users').select('*, posts(*)').filter('posts.status', 'eq', 'published')",
+			wantQuery: "posts.status=eq.published",
+		},
+		{
+			name:      "negated operator",
+			input:     "supabase.from('users').select('*').filter('status', 'not.eq', 'banned')",
+			wantQuery: "status=not.eq.banned",
+		},
+		{
+			name:      "raw in list",
+			input:     `supabase.from('users').select('*').filter('id', 'in', '(1,2,3)')`,
+			wantQuery: "id=in.(1,2,3)",
+		},
+		{
+			name:      "arbitrary operator",
+			input:     "supabase.from('events').select('*').filter('during', 'ov', '[2024-01-01,2024-02-01)')",
+			wantQuery: "during=ov.[2024-01-01,2024-02-01)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			queryParamsEqual(t, result.Query, tt.wantQuery)
+		})
+	}
+}
+
+func TestConverter_Match(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	result, err := c.Convert("supabase.from('users').select('*').match({status: 'active', role: 'admin', age: 30})")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result.Query != "age=eq.30&role=eq.admin&select=%2A&status=eq.active" {
+		t.Errorf("Query = %v", result.Query)
+	}
+
+	_, err = c.Convert("supabase.from('users').select('*').match('status')")
+	var argErr *ArgumentError
+	if !errors.As(err, &argErr) || argErr.Method != "match" {
+		t.Errorf("Convert() error = %v, want a .match() ArgumentError", err)
+	}
+}
+
+func TestConverter_RangeFilters(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name      string
+		input     string
+		wantQuery string
+	}{
+		{
+			name:      "overlaps array",
+			input:     "supabase.from('posts').select('*').overlaps('tags', ['go', 'sql'])",
+			wantQuery: "tags=ov.{go,sql}",
+		},
+		{
+			name:      "overlaps range",
+			input:     "supabase.from('reservations').select('*').overlaps('during', '[2000-01-01 12:45, 2000-01-01 13:15)')",
+			wantQuery: "during=ov.[2000-01-01 12:45, 2000-01-01 13:15)",
+		},
+		{
+			name:      "range greater than",
+			input:     "supabase.from('reservations').select('*').rangeGt('during', '[2000-01-02 08:00, 2000-01-02 09:00)')",
+			wantQuery: "during=sr.[2000-01-02 08:00, 2000-01-02 09:00)",
+		},
+		{
+			name:      "range greater or equal",
+			input:     "supabase.from('reservations').select('*').rangeGte('during', '[1,5)')",
+			wantQuery: "during=nxl.[1,5)",
+		},
+		{
+			name:      "range less than",
+			input:     "supabase.from('reservations').select('*').rangeLt('during', '[1,5)')",
+			wantQuery: "during=sl.[1,5)",
+		},
+		{
+			name:      "range less or equal",
+			input:     "supabase.from('reservations').select('*').rangeLte('during', '[1,5]')",
+			wantQuery: "during=nxr.[1,5]",
+		},
+		{
+			name:      "range adjacent",
+			input:     "supabase.from('reservations').select('*').rangeAdjacent('during', '[1,5)')",
+			wantQuery: "during=adj.[1,5)",
+		},
+		{
+			name:      "contains array with reserved characters",
+			input:     `supabase.from('posts').select('*').contains('tags', ['a,b', 'c'])`,
+			wantQuery: `tags=cs.{"a,b",c}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			queryParamsEqual(t, result.Query, tt.wantQuery)
+		})
+	}
+}
+
+func TestConverter_TextSearchOptions(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name      string
+		input     string
+		wantQuery string
+	}{
+		{
+			name:      "websearch with config",
+			input:     "supabase.from('posts').select('*').textSearch('title', 'fat cats', {type: 'websearch', config: 'english'})",
+			wantQuery: "title=wfts(english).fat cats",
+		},
+		{
+			name:      "plain",
+			input:     "supabase.from('posts').select('*').textSearch('title', 'fat cats', {type: 'plain'})",
+			wantQuery: "title=plfts.fat cats",
+		},
+		{
+			name:      "phrase",
+			input:     "supabase.from('posts').select('*').textSearch('title', 'fat cats', {type: 'phrase'})",
+			wantQuery: "title=phfts.fat cats",
+		},
+		{
+			name:      "config only",
+			input:     "supabase.from('posts').select('*').textSearch('title', 'cats', {config: 'french'})",
+			wantQuery: "title=fts(french).cats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			queryParamsEqual(t, result.Query, tt.wantQuery)
+		})
+	}
+
+	_, err := c.Convert("supabase.from('posts').select('*').textSearch('title', 'cats', {type: 'fuzzy'})")
+	var argErr *ArgumentError
+	if !errors.As(err, &argErr) || argErr.Method != "textSearch" {
+		t.Errorf("Convert() error = %v, want a .textSearch() ArgumentError", err)
+	}
+}
+
+func TestConverter_PatternLists(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name      string
+		input     string
+		column    string
+		wantValue string
+	}{
+		{
+			name:      "like all",
+			input:     "supabase.from('users').select('*').likeAllOf('name', ['%a%', '%e%'])",
+			column:    "name",
+			wantValue: "like(all).{%a%,%e%}",
+		},
+		{
+			name:      "like any",
+			input:     "supabase.from('users').select('*').likeAnyOf('name', ['A%', 'B%'])",
+			column:    "name",
+			wantValue: "like(any).{A%,B%}",
+		},
+		{
+			name:      "ilike all",
+			input:     "supabase.from('users').select('*').ilikeAllOf('email', ['%@example.com', 'admin%'])",
+			column:    "email",
+			wantValue: "ilike(all).{%@example.com,admin%}",
+		},
+		{
+			name:      "ilike any quotes reserved characters",
+			input:     "supabase.from('users').select('*').ilikeAnyOf('name', ['%john smith%', '%a,b%'])",
+			column:    "name",
+			wantValue: `ilike(any).{"%john smith%","%a,b%"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			params, err := url.ParseQuery(result.Query)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %v", err)
+			}
+			if got := params.Get(tt.column); got != tt.wantValue {
+				t.Errorf("%s = %q, want %q", tt.column, got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestConverter_NotOperators(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name      string
+		input     string
+		column    string
+		wantValue string
+	}{
+		{
+			name:      "in list",
+			input:     `supabase.from('users').select('*').not('status', 'in', '("banned","deleted")')`,
+			column:    "status",
+			wantValue: `not.in.("banned","deleted")`,
+		},
+		{
+			name:      "in list from array",
+			input:     `supabase.from('users').select('*').not('id', 'in', [1, 2, 3])`,
+			column:    "id",
+			wantValue: "not.in.(1,2,3)",
+		},
+		{
+			name:      "is null",
+			input:     `supabase.from('users').select('*').not('deleted_at', 'is', null)`,
+			column:    "deleted_at",
+			wantValue: "not.is.null",
+		},
+		{
+			name:      "like pattern",
+			input:     `supabase.from('users').select('*').not('email', 'like', '%@test.com')`,
+			column:    "email",
+			wantValue: "not.like.%@test.com",
+		},
+		{
+			name:      "contains array",
+			input:     `supabase.from('posts').select('*').not('tags', 'cs', ['draft'])`,
+			column:    "tags",
+			wantValue: "not.cs.{draft}",
+		},
+		{
+			name:      "numeric comparison",
+			input:     `supabase.from('users').select('*').not('age', 'gte', 18)`,
+			column:    "age",
+			wantValue: "not.gte.18",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			params, err := url.ParseQuery(result.Query)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %v", err)
+			}
+			if got := params.Get(tt.column); got != tt.wantValue {
+				t.Errorf("%s = %q, want %q", tt.column, got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestConverter_ResponseFormats(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name        string
+		input       string
+		wantAccept  string
+		wantWarning string
+	}{
+		{
+			name:        "csv",
+			input:       "supabase.from('users').select('*').csv()",
+			wantAccept:  "text/csv",
+			wantWarning: "CSV",
+		},
+		{
+			name:        "geojson",
+			input:       "supabase.from('places').select('*').geojson()",
+			wantAccept:  "application/geo+json",
+			wantWarning: "GeoJSON",
+		},
+		{
+			name:        "explain defaults",
+			input:       "supabase.from('users').select('*').explain()",
+			wantAccept:  `application/vnd.pgrst.plan+text; for="application/json"`,
+			wantWarning: "query plan",
+		},
+		{
+			name:        "explain with options",
+			input:       "supabase.from('users').select('*').eq('id', 1).explain({analyze: true, verbose: true, format: 'json'})",
+			wantAccept:  `application/vnd.pgrst.plan+json; for="application/json"; options=analyze|verbose`,
+			wantWarning: "query plan",
+		},
+		{
+			name:        "explain of csv",
+			input:       "supabase.from('users').select('*').csv().explain({buffers: true})",
+			wantAccept:  `application/vnd.pgrst.plan+text; for="text/csv"; options=buffers`,
+			wantWarning: "query plan",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got := result.Headers["Accept"]; got != tt.wantAccept {
+				t.Errorf("Accept = %q, want %q", got, tt.wantAccept)
+			}
+			warnings := ResponseFormatWarnings(result)
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.wantWarning) {
+				t.Errorf("ResponseFormatWarnings() = %v, want one mentioning %q", warnings, tt.wantWarning)
+			}
+		})
+	}
+
+	_, err := c.Convert("supabase.from('users').select('*').explain({format: 'yaml'})")
+	var argErr *ArgumentError
+	if !errors.As(err, &argErr) || argErr.Method != "explain" {
+		t.Errorf("Convert() error = %v, want an .explain() ArgumentError", err)
+	}
+}
+
+func TestConverter_MutationSelect(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name       string
+		input      string
+		wantMethod string
+		wantQuery  string
+	}{
+		{
+			name:       "insert",
+			input:      "supabase.from('users').insert({name: 'John'}).select('id')",
+			wantMethod: "POST",
+			wantQuery:  "select=id",
+		},
+		{
+			name:       "update",
+			input:      "supabase.from('users').update({status: 'active'}).eq('id', 1).select()",
+			wantMethod: "PATCH",
+			wantQuery:  "id=eq.1&select=*",
+		},
+		{
+			name:       "delete",
+			input:      "supabase.from('users').delete().eq('id', 1).select('id, name')",
+			wantMethod: "DELETE",
+			wantQuery:  "id=eq.1&select=id,name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Method != tt.wantMethod {
+				t.Errorf("Method = %v, want %v", result.Method, tt.wantMethod)
+			}
+			if result.Headers["Prefer"] != "return=representation" {
+				t.Errorf("Prefer = %q, want return=representation", result.Headers["Prefer"])
+			}
+			queryParamsEqual(t, result.Query, tt.wantQuery)
+		})
+	}
+
+	result, err := c.Convert("supabase.from('users').insert({name: 'John'})")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if _, ok := result.Headers["Prefer"]; ok || result.Query != "" {
+		t.Errorf("insert without select: Prefer = %q, Query = %q", result.Headers["Prefer"], result.Query)
+	}
+}
+
+func TestConverter_UpsertOptions(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name       string
+		input      string
+		wantQuery  string
+		wantPrefer string
+	}{
+		{
+			name:       "on conflict",
+			input:      "supabase.from('users').upsert({email: 'a@example.com', name: 'A'}, {onConflict: 'email'})",
+			wantQuery:  "on_conflict=email",
+			wantPrefer: "resolution=merge-duplicates",
+		},
+		{
+			name:       "ignore duplicates with count",
+			input:      "supabase.from('users').upsert({email: 'a@example.com'}, {onConflict: 'email', ignoreDuplicates: true, count: 'exact'})",
+			wantQuery:  "on_conflict=email",
+			wantPrefer: "count=exact,resolution=ignore-duplicates",
+		},
+		{
+			name:       "composite conflict target",
+			input:      "supabase.from('memberships').upsert({org_id: 1, user_id: 2}, {onConflict: 'org_id, user_id'})",
+			wantQuery:  "on_conflict=org_id,user_id",
+			wantPrefer: "resolution=merge-duplicates",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Headers["Prefer"] != tt.wantPrefer {
+				t.Errorf("Prefer = %q, want %q", result.Headers["Prefer"], tt.wantPrefer)
+			}
+			queryParamsEqual(t, result.Query, tt.wantQuery)
+		})
+	}
+
+	_, err := c.Convert("supabase.from('users').upsert({id: 1}, {count: 'all'})")
+	var argErr *ArgumentError
+	if !errors.As(err, &argErr) || argErr.Method != "upsert" {
+		t.Errorf("Convert() error = %v, want an .upsert() ArgumentError", err)
+	}
+}
+
+func TestConverter_PreferMerging(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name       string
+		input      string
+		wantPrefer string
+	}{
+		{
+			name:       "insert count",
+			input:      "supabase.from('users').insert({name: 'A'}, {count: 'exact'})",
+			wantPrefer: "count=exact",
+		},
+		{
+			name:       "update count with select",
+			input:      "supabase.from('users').update({status: 'active'}, {count: 'planned'}).eq('id', 1).select('id')",
+			wantPrefer: "count=planned,return=representation",
+		},
+		{
+			name:       "delete count",
+			input:      "supabase.from('users').delete({count: 'estimated'}).eq('id', 1)",
+			wantPrefer: "count=estimated",
+		},
+		{
+			name:       "upsert count, select and resolution",
+			input:      "supabase.from('users').upsert({id: 1}, {count: 'exact', ignoreDuplicates: true}).select()",
+			wantPrefer: "count=exact,return=representation,resolution=ignore-duplicates",
+		},
+		{
+			name:       "select count with maybeSingle",
+			input:      "supabase.from('users').select('*', {count: 'exact'}).eq('id', 1).maybeSingle()",
+			wantPrefer: "count=exact,return=representation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Headers["Prefer"] != tt.wantPrefer {
+				t.Errorf("Prefer = %q, want %q", result.Headers["Prefer"], tt.wantPrefer)
+			}
+		})
+	}
+
+	_, err := c.Convert("supabase.from('users').delete({count: true})")
+	var argErr *ArgumentError
+	if !errors.As(err, &argErr) || argErr.Method != "delete" {
+		t.Errorf("Convert() error = %v, want a .delete() ArgumentError", err)
+	}
+}
+
+func TestConverter_SelectHead(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	result, err := c.Convert("supabase.from('users').select('*', {count: 'exact', head: true}).eq('active', true)")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result.Method != "HEAD" {
+		t.Errorf("Method = %v, want HEAD", result.Method)
+	}
+	if result.Headers["Prefer"] != "count=exact" {
+		t.Errorf("Prefer = %q, want count=exact", result.Headers["Prefer"])
+	}
+	queryParamsEqual(t, result.Query, "active=eq.true")
+
+	result, err = c.Convert("supabase.from('users').select('*', {count: 'exact', head: false})")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result.Method != "GET" {
+		t.Errorf("Method = %v, want GET", result.Method)
+	}
+}
+
+func TestConverter_ReferencedTableModifiers(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name      string
+		input     string
+		wantQuery string
+	}{
+		{
+			name:      "order",
+			input:     "supabase.from('users').select('*, posts(*)').order('created_at', {referencedTable: 'posts', ascending: false})",
+			wantQuery: "posts.order=created_at.desc",
+		},
+		{
+			name:      "limit",
+			input:     "supabase.from('users').select('*, posts(*)').limit(5, {foreignTable: 'posts'})",
+			wantQuery: "posts.limit=5",
+		},
+		{
+			name:      "range",
+			input:     "supabase.from('users').select('*, posts(*)').range(10, 14, {foreignTable: 'posts'})",
+			wantQuery: "posts.offset=10&posts.limit=5",
+		},
+		{
+			name:      "mixed with top level",
+			input:     "supabase.from('users').select('*, posts(*)').order('name').limit(20).limit(3, {referencedTable: 'posts'})",
+			wantQuery: "order=name.asc&limit=20&posts.limit=3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			queryParamsEqual(t, result.Query, tt.wantQuery)
+			if _, ok := result.Headers["Range"]; ok {
+				t.Errorf("Range header should not be set for a referenced table: %v", result.Headers)
+			}
+		})
+	}
+}
+
+func TestConverter_EmbeddedSelect(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name       string
+		input      string
+		wantSelect string
+	}{
+		{
+			name:       "alias, fk hint and inner",
+			input:      "supabase.from('posts').select('id, author:users!posts_author_fkey(name), comments!inner(body)')",
+			wantSelect: "id,author:users!posts_author_fkey(name),comments!inner(body)",
+		},
+		{
+			name:       "embed with several columns",
+			input:      "supabase.from('users').select('id, posts(id, title, created_at)')",
+			wantSelect: "id,posts(id,title,created_at)",
+		},
+		{
+			name:       "nested embeds",
+			input:      "supabase.from('users').select(`name, posts ( title, comments ( body, author:users ( name ) ) )`)",
+			wantSelect: "name,posts(title,comments(body,author:users(name)))",
+		},
+		{
+			name:       "quoted column keeps its spaces",
+			input:      `supabase.from('users').select('id, "full name"')`,
+			wantSelect: `id,"full name"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			params, err := url.ParseQuery(result.Query)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %v", err)
+			}
+			if got := params.Get("select"); got != tt.wantSelect {
+				t.Errorf("select = %q, want %q", got, tt.wantSelect)
+			}
+		})
+	}
+}
+
+func TestConverter_EmbeddedFilters(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name        string
+		input       string
+		wantQuery   string
+		wantWarning bool
+	}{
+		{
+			name:      "eq on embed",
+			input:     "supabase.from('users').select('name, posts(title)').eq('posts.status', 'published')",
+			wantQuery: "posts.status=eq.published",
+		},
+		{
+			name:      "is null on aliased embed",
+			input:     "supabase.from('posts').select('title, author:users(name)').is('author.deleted_at', null)",
+			wantQuery: "author.deleted_at=is.null",
+		},
+		{
+			name:      "inner embed with fk hint",
+			input:     "supabase.from('posts').select('title, author:users!posts_author_fkey!inner(name)').eq('author.active', true)",
+			wantQuery: "author.active=eq.true",
+		},
+		{
+			name:        "aliased embed filtered by table name",
+			input:       "supabase.from('posts').select('title, author:users(name)').eq('users.active', true)",
+			wantQuery:   "users.active=eq.true",
+			wantWarning: true,
+		},
+		{
+			name:        "embed missing from select",
+			input:       "supabase.from('users').select('name').eq('posts.status', 'published')",
+			wantQuery:   "posts.status=eq.published",
+			wantWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			queryParamsEqual(t, result.Query, tt.wantQuery)
+			if got := len(result.Warnings) > 0; got != tt.wantWarning {
+				t.Errorf("Warnings = %v, want warning: %v", result.Warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestConverter_InValues(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name      string
+		input     string
+		wantValue string
+	}{
+		{
+			name:      "simple strings",
+			input:     "supabase.from('users').select('*').in('status', ['active', 'pending'])",
+			wantValue: "in.(active,pending)",
+		},
+		{
+			name:      "numbers stay unquoted",
+			input:     "supabase.from('users').select('*').in('status', [1, 2.5, 3])",
+			wantValue: "in.(1,2.5,3)",
+		},
+		{
+			name:      "reserved characters are double quoted",
+			input:     `supabase.from('users').select('*').in('status', ['a,b', 'c (d)', 'plain'])`,
+			wantValue: `in.("a,b","c (d)",plain)`,
+		},
+		{
+			name:      "double quotes are escaped",
+			input:     `supabase.from('users').select('*').in('status', ['say "hi"'])`,
+			wantValue: `in.("say \"hi\"")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			params, err := url.ParseQuery(result.Query)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %v", err)
+			}
+			if got := params.Get("status"); got != tt.wantValue {
+				t.Errorf("status = %q, want %q", got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestConverter_Schema(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name       string
+		input      string
+		wantMethod string
+		wantHeader string
+	}{
+		{
+			name:       "select",
+			input:      "supabase.schema('analytics').from('events').select('*')",
+			wantMethod: "GET",
+			wantHeader: "Accept-Profile",
+		},
+		{
+			name:       "insert",
+			input:      "supabase.schema('analytics').from('events').insert({name: 'click'})",
+			wantMethod: "POST",
+			wantHeader: "Content-Profile",
+		},
+		{
+			name:       "rpc",
+			input:      "supabase.schema('analytics').rpc('daily_totals', {day: '2024-01-01'})",
+			wantMethod: "POST",
+			wantHeader: "Content-Profile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Method != tt.wantMethod {
+				t.Errorf("Method = %v, want %v", result.Method, tt.wantMethod)
+			}
+			if got := result.Headers[tt.wantHeader]; got != "analytics" {
+				t.Errorf("%s = %q, want analytics (headers: %v)", tt.wantHeader, got, result.Headers)
+			}
+		})
+	}
+
+	result, err := c.Convert("supabase.from('events').select('*')")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if _, ok := result.Headers["Accept-Profile"]; ok {
+		t.Errorf("Accept-Profile should only be set with .schema(): %v", result.Headers)
+	}
+}
+
+func TestConverter_RPCOptions(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name       string
+		input      string
+		wantMethod string
+		wantQuery  string
+		wantBody   string
+		wantPrefer string
+	}{
+		{
+			name:       "get with count",
+			input:      "supabase.rpc('search_users', {term: 'ann', max_age: 30}, {get: true, count: 'exact'})",
+			wantMethod: "GET",
+			wantQuery:  "max_age=30&term=ann",
+			wantPrefer: "count=exact",
+		},
+		{
+			name:       "head",
+			input:      "supabase.rpc('active_users', {ids: [1, 2]}, {head: true, count: 'planned'})",
+			wantMethod: "HEAD",
+			wantQuery:  "ids=%7B1%2C2%7D",
+			wantPrefer: "count=planned",
+		},
+		{
+			name:       "post with count",
+			input:      "supabase.rpc('add_numbers', {a: 5, b: 3}, {count: 'exact'})",
+			wantMethod: "POST",
+			wantBody:   `{"a":5,"b":3}`,
+			wantPrefer: "count=exact",
+		},
+		{
+			name:       "get without arguments",
+			input:      "supabase.rpc('hello_world', undefined, {get: true})",
+			wantMethod: "GET",
+		},
+		{
+			name:       "post with filters and modifiers",
+			input:      "supabase.rpc('fn', {x: 1}).eq('id', 3).limit(2)",
+			wantMethod: "POST",
+			wantQuery:  "id=eq.3&limit=2",
+			wantBody:   `{"x":1}`,
+		},
+		{
+			name:       "get with select, order and count",
+			input:      "supabase.rpc('search_users', {term: 'ann'}, {get: true, count: 'exact'}).select('id,name').order('id')",
+			wantMethod: "GET",
+			wantQuery:  "order=id.asc&select=id%2Cname&term=ann",
+			wantPrefer: "count=exact",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Method != tt.wantMethod {
+				t.Errorf("Method = %v, want %v", result.Method, tt.wantMethod)
+			}
+			if result.Query != tt.wantQuery {
+				t.Errorf("Query = %q, want %q", result.Query, tt.wantQuery)
+			}
+			if result.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", result.Body, tt.wantBody)
+			}
+			if result.Headers["Prefer"] != tt.wantPrefer {
+				t.Errorf("Prefer = %q, want %q", result.Headers["Prefer"], tt.wantPrefer)
+			}
+		})
+	}
+}
+
+func TestConverter_AuthRequests(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name       string
+		input      string
+		wantMethod string
+		wantPath   string
+		wantQuery  string
+		wantBody   string
+		wantAuth   string
+	}{
+		{
+			name:       "sign up with metadata",
+			input:      `supabase.auth.signUp({email: 'a@example.com', password: 'secret', options: {data: {name: 'A'}, emailRedirectTo: 'https://app.example.com'}})`,
+			wantMethod: "POST",
+			wantPath:   "/auth/v1/signup",
+			wantQuery:  "redirect_to=https%3A%2F%2Fapp.example.com",
+			wantBody:   `{"data":{"name":"A"},"email":"a@example.com","password":"secret"}`,
+		},
+		{
+			name:       "sign in with password",
+			input:      `supabase.auth.signInWithPassword({email: 'a@example.com', password: 'secret'})`,
+			wantMethod: "POST",
+			wantPath:   "/auth/v1/token",
+			wantQuery:  "grant_type=password",
+			wantBody:   `{"email":"a@example.com","password":"secret"}`,
+		},
+		{
+			name:       "get user",
+			input:      `supabase.auth.getUser()`,
+			wantMethod: "GET",
+			wantPath:   "/auth/v1/user",
+			wantAuth:   "Bearer <ACCESS_TOKEN>",
+		},
+		{
+			name:       "reset password",
+			input:      `supabase.auth.resetPasswordForEmail('a@example.com')`,
+			wantMethod: "POST",
+			wantPath:   "/auth/v1/recover",
+			wantBody:   `{"email":"a@example.com"}`,
+		},
+		{
+			name:       "admin delete user",
+			input:      `supabase.auth.admin.deleteUser('715ed5db-f090-4b8c-a067-640ecee36aa0')`,
+			wantMethod: "DELETE",
+			wantPath:   "/auth/v1/admin/users/715ed5db-f090-4b8c-a067-640ecee36aa0",
+			wantAuth:   "Bearer <SUPABASE_SERVICE_ROLE_KEY>",
+		},
+		{
+			name:       "admin list users",
+			input:      `supabase.auth.admin.listUsers()`,
+			wantMethod: "GET",
+			wantPath:   "/auth/v1/admin/users",
+			wantAuth:   "Bearer <SUPABASE_SERVICE_ROLE_KEY>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !result.IsHTTPOnly {
+				t.Error("auth requests should be HTTP only")
+			}
+			if result.Method != tt.wantMethod || result.Path != tt.wantPath {
+				t.Errorf("request = %s %s, want %s %s", result.Method, result.Path, tt.wantMethod, tt.wantPath)
+			}
+			if result.Query != tt.wantQuery {
+				t.Errorf("Query = %q, want %q", result.Query, tt.wantQuery)
+			}
+			if result.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", result.Body, tt.wantBody)
+			}
+			if result.Headers["Authorization"] != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", result.Headers["Authorization"], tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestConverter_StorageRequests(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name       string
+		input      string
+		wantMethod string
+		wantPath   string
+		wantBody   string
+	}{
+		{
+			name:       "upload",
+			input:      `supabase.storage.from('avatars').upload('public/avatar1.png', avatarFile, {cacheControl: '3600', upsert: true, contentType: 'image/png'})`,
+			wantMethod: "POST",
+			wantPath:   "/storage/v1/object/avatars/public/avatar1.png",
+			wantBody:   "<contents of avatarFile>",
+		},
+		{
+			name:       "download",
+			input:      `supabase.storage.from('avatars').download('folder/avatar1.png')`,
+			wantMethod: "GET",
+			wantPath:   "/storage/v1/object/avatars/folder/avatar1.png",
+		},
+		{
+			name:       "list",
+			input:      `supabase.storage.from('avatars').list('folder', {limit: 10, offset: 0})`,
+			wantMethod: "POST",
+			wantPath:   "/storage/v1/object/list/avatars",
+			wantBody:   `{"limit":10,"offset":0,"prefix":"folder","sortBy":{"column":"name","order":"asc"}}`,
+		},
+		{
+			name:       "remove",
+			input:      `supabase.storage.from('avatars').remove(['folder/avatar1.png', 'folder/avatar2.png'])`,
+			wantMethod: "DELETE",
+			wantPath:   "/storage/v1/object/avatars",
+			wantBody:   `{"prefixes":["folder/avatar1.png","folder/avatar2.png"]}`,
+		},
+		{
+			name:       "signed url",
+			input:      `supabase.storage.from('avatars').createSignedUrl('folder/avatar1.png', 60)`,
+			wantMethod: "POST",
+			wantPath:   "/storage/v1/object/sign/avatars/folder/avatar1.png",
+			wantBody:   `{"expiresIn":60}`,
+		},
+		{
+			name:       "create bucket",
+			input:      `supabase.storage.createBucket('avatars', {public: false})`,
+			wantMethod: "POST",
+			wantPath:   "/storage/v1/bucket",
+			wantBody:   `{"id":"avatars","name":"avatars","public":false}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !result.IsHTTPOnly {
+				t.Error("storage requests should be HTTP only")
+			}
+			if result.Method != tt.wantMethod || result.Path != tt.wantPath {
+				t.Errorf("request = %s %s, want %s %s", result.Method, result.Path, tt.wantMethod, tt.wantPath)
+			}
+			if result.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", result.Body, tt.wantBody)
+			}
+		})
+	}
+
+	result, err := c.Convert(`supabase.storage.from('avatars').upload('a.png', file, {upsert: true, contentType: 'image/png'})`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result.Headers["x-upsert"] != "true" || result.Headers["Content-Type"] != "image/png" {
+		t.Errorf("upload headers = %v", result.Headers)
+	}
+}
+
+func TestConverter_ClientOnlyMethods(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"throwOnError", "supabase.from('users').select('*').eq('id', 1).throwOnError()"},
+		{"abortSignal", "supabase.from('users').select('*').eq('id', 1).abortSignal(controller.signal)"},
+		{"returns with type arguments", "supabase.from('users').select('*').eq('id', 1).returns<User[]>()"},
+		{"overrideTypes with nested type arguments", "supabase.from('users').select('*').eq('id', 1).overrideTypes<Array<{ id: number }>, { merge: false }>()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Method != "GET" || result.Path != "/users" {
+				t.Errorf("request = %s %s, want GET /users", result.Method, result.Path)
+			}
+			queryParamsEqual(t, result.Query, "select=*&id=eq.1")
+			if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "does not change the request") {
+				t.Errorf("Warnings = %v, want one about the skipped method", result.Warnings)
+			}
+		})
+	}
+}
+
+func TestConverter_VariablePlaceholders(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	result, err := c.Convert("supabase.from('users').select('*').eq('id', userId).eq('org_id', session.user.org)")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	params, _ := url.ParseQuery(result.Query)
+	if params.Get("id") != "eq.{userId}" || params.Get("org_id") != "eq.{session.user.org}" {
+		t.Errorf("Query = %q, want placeholders for userId and session.user.org", result.Query)
+	}
+	if len(result.Warnings) != 2 || !strings.Contains(result.Warnings[0], "{userId}") {
+		t.Errorf("Warnings = %v, want one per variable", result.Warnings)
+	}
+
+	result, err = c.Convert("supabase.from('users').insert(payload)")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result.Body != `"{payload}"` {
+		t.Errorf("Body = %s, want \"{payload}\"", result.Body)
+	}
+
+	result, err = c.Convert("supabase.from('users').update({name: newName, active: true, note: undefined}).eq('id', 1)")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Body), &body); err != nil {
+		t.Fatalf("Body %q is not JSON: %v", result.Body, err)
+	}
+	if body["name"] != "{newName}" || body["active"] != true {
+		t.Errorf("Body = %s, want name as the {newName} placeholder", result.Body)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "newName") {
+		t.Errorf("Warnings = %v, want one about newName", result.Warnings)
+	}
+}
+
+func TestConverter_FullStatements(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"destructuring with await", "const { data, error } = await supabase.from('users').select('*').eq('id', 1)", "select=*&id=eq.1"},
+		{"semicolon and later statements", "let result = await supabase.from('users').select('*').eq('id', 1); console.log(result.data)", "select=*&id=eq.1"},
+		{"calls before the query", "const id = Number(params.get('id')); const { data } = await supabase.from('users').select('*').eq('id', 1);", "select=*&id=eq.1"},
+		{"then and catch", "supabase.from('users').select('*').eq('id', 1).then(({ data }) => setUsers(data)).catch((e) => console.error(e))", "select=*&id=eq.1"},
+		{"multi-line statement", "const { data } = await supabase\n  .from('users')\n  .select('*')\n  .eq('id', 1);\n", "select=*&id=eq.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Method != "GET" || result.Path != "/users" {
+				t.Errorf("request = %s %s, want GET /users", result.Method, result.Path)
+			}
+			queryParamsEqual(t, result.Query, tt.want)
+			if len(result.Warnings) != 0 {
+				t.Errorf("Warnings = %v, want none", result.Warnings)
+			}
+		})
+	}
+
+	result, err := c.Convert("const { data: { users } } = await supabase.auth.admin.listUsers();")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result.Method != "GET" || result.Path != "/auth/v1/admin/users" {
+		t.Errorf("request = %s %s, want GET /auth/v1/admin/users", result.Method, result.Path)
+	}
+}
+
+func TestConverter_PythonDialect(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+	c.Dialect = DialectPython
+
+	tests := []struct {
+		name       string
+		input      string
+		wantMethod string
+		wantQuery  string
+		wantPrefer string
+	}{
+		{"select with execute", `supabase.table("users").select("*").eq("age", 18).execute()`, "GET", "select=*&age=eq.18", ""},
+		{"columns and count", `response = supabase.table("users").select("id", "name", count="exact").execute()`, "GET", "select=id,name", "count=exact"},
+		{"snake_case methods", `supabase.table("users").select("*").in_("id", [1, 2]).is_("deleted_at", "null").order("name", desc=True).limit(5).maybe_single().execute()`, "GET", "select=*&id=in.(1,2)&deleted_at=is.null&order=name.desc&limit=5", "return=representation"},
+		{"not_ attribute", `supabase.table("users").select("*").not_.is_("email", "null").execute()`, "GET", "select=*&email=not.is.null", ""},
+		{"full-text shortcut", `supabase.table("posts").select("*").wfts("body", "cat dog").execute()`, "GET", "select=*&body=wfts.cat dog", ""},
+		{"insert returns rows", `supabase.table("users").insert({"name": "Ann", "active": True}).execute()`, "POST", "select=*", "return=representation"},
+		{"minimal insert", `supabase.table("users").insert({"name": "Ann"}, returning="minimal").execute()`, "POST", "", ""},
+		{"upsert options", `supabase.table("users").upsert({"id": 1}, on_conflict="id", ignore_duplicates=True, returning="minimal").execute()`, "POST", "on_conflict=id", "resolution=ignore-duplicates"},
+		{"multi-line chain", "response = (\n    supabase.table(\"users\")\n    .select(\"*\")\n    .eq(\"id\", 1)\n    .execute()\n)", "GET", "select=*&id=eq.1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Method != tt.wantMethod {
+				t.Errorf("Method = %s, want %s", result.Method, tt.wantMethod)
+			}
+			got, _ := url.QueryUnescape(result.Query)
+			queryParamsEqual(t, got, tt.wantQuery)
+			if result.Headers["Prefer"] != tt.wantPrefer {
+				t.Errorf("Prefer = %q, want %q", result.Headers["Prefer"], tt.wantPrefer)
+			}
+		})
+	}
+
+	result, err := c.Convert(`supabase.table("users").insert({"name": "Ann", "active": True, "note": None}).execute()`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result.Body != `{"active":true,"name":"Ann","note":null}` {
+		t.Errorf("Body = %s, want True and None as JSON", result.Body)
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	for name, want := range map[string]Dialect{"": DialectJS, "js": DialectJS, "TypeScript": DialectJS, "py": DialectPython, "python": DialectPython, "dart": DialectDart, "flutter": DialectDart} {
+		got, err := ParseDialect(name)
+		if err != nil || got != want {
+			t.Errorf("ParseDialect(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseDialect("cobol"); err == nil {
+		t.Error("ParseDialect(\"cobol\") error = nil, want an error")
+	}
+
+	if got := DetectDialect(`supabase.table("users").select("*").execute()`); got != DialectPython {
+		t.Errorf("DetectDialect(python chain) = %q, want %q", got, DialectPython)
+	}
+	if got := DetectDialect("supabase.from('users').select().order('name', ascending: false)"); got != DialectDart {
+		t.Errorf("DetectDialect(dart chain) = %q, want %q", got, DialectDart)
+	}
+	if got := DetectDialect("supabase.from('users').select('*')"); got != DialectJS {
+		t.Errorf("DetectDialect(js chain) = %q, want %q", got, DialectJS)
+	}
+}
+
+func TestConverter_DartDialect(t *testing.T) {
+	c := NewConverter("http://localhost:3000")
+	c.Dialect = DialectDart
+
+	tests := []struct {
+		name       string
+		input      string
+		wantMethod string
+		wantQuery  string
+		wantPrefer string
+	}{
+		{"named order options", "final data = await supabase.from('users').select().eq('age', 18).order('name', ascending: false, nullsFirst: true);", "GET", "select=*&age=eq.18&order=name.desc.nullsfirst", ""},
+		{"renamed filters and count", "await supabase.from('users').select('id,name').inFilter('id', [1, 2]).isFilter('deleted_at', null).count(CountOption.exact)", "GET", "select=id,name&id=in.(1,2)&deleted_at=is.null", "count=exact"},
+		{"count only", "await supabase.from('users').count(CountOption.exact)", "HEAD", "select=*", "count=exact"},
+		{"upsert options", "await supabase.from('users').upsert({'id': 1}, onConflict: 'id', ignoreDuplicates: true)", "POST", "on_conflict=id", "resolution=ignore-duplicates"},
+		{"rpc params", "await supabase.rpc('add', params: {'a': 1, 'b': 2}, get: true)", "GET", "a=1&b=2", ""},
+		{"text search type enum", "await supabase.from('posts').select().textSearch('body', 'cat', config: 'english', type: TextSearchType.websearch)", "GET", "select=*&body=wfts(english).cat", ""},
+		{"referenced table range and then", "await supabase.from('users').select().range(0, 9, referencedTable: 'posts').then((rows) => print(rows));", "GET", "select=*&posts.limit=10&posts.offset=0", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Method != tt.wantMethod {
+				t.Errorf("Method = %s, want %s", result.Method, tt.wantMethod)
+			}
+			got, _ := url.QueryUnescape(result.Query)
+			queryParamsEqual(t, got, tt.wantQuery)
+			if result.Headers["Prefer"] != tt.wantPrefer {
+				t.Errorf("Prefer = %q, want %q", result.Headers["Prefer"], tt.wantPrefer)
+			}
+		})
+	}
+
+	t.Run("interpolated variable", func(t *testing.T) {
+		result, err := c.Convert("await supabase.from('users').select().eq('id', '$userId')")
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		params, _ := url.ParseQuery(result.Query)
+		if params.Get("id") != "eq.{userId}" || len(result.Warnings) != 1 {
+			t.Errorf("Query = %q, Warnings = %v, want the {userId} placeholder and a warning", result.Query, result.Warnings)
+		}
+	})
+
+	t.Run("auth named arguments", func(t *testing.T) {
+		result, err := c.Convert("await supabase.auth.signUp(email: 'a@example.com', password: 'secret', data: {'name': 'Ann'})")
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if result.Path != "/auth/v1/signup" || result.Body != `{"data":{"name":"Ann"},"email":"a@example.com","password":"secret"}` {
+			t.Errorf("request = %s %s, want POST /auth/v1/signup with data in the body", result.Path, result.Body)
+		}
+	})
+
+	t.Run("storage file options", func(t *testing.T) {
+		result, err := c.Convert("await supabase.storage.from('avatars').upload('a.png', file, fileOptions: const FileOptions(upsert: true, contentType: 'image/png'))")
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if result.Headers["x-upsert"] != "true" || result.Headers["Content-Type"] != "image/png" {
+			t.Errorf("upload headers = %v", result.Headers)
+		}
+	})
+}
+
+func TestConverter_ConvertToSQL(t *testing.T) {
+	c := NewConverter("")
+
+	tests := []struct {
+		name    string
+		input   string
+		wantSQL string
+	}{
+		{"range pages the rows", "supabase.from('users').select('*').order('id').range(20, 29)", "SELECT * FROM users ORDER BY id ASC LIMIT 10 OFFSET 20"},
+		{"smaller limit narrows the range", "supabase.from('users').select('*').order('id').range(0, 9).limit(5)", "SELECT * FROM users ORDER BY id ASC LIMIT 5"},
+		{"single reads one row", "supabase.from('users').select('*').eq('id', 1).single()", "SELECT * FROM users WHERE id = 1 LIMIT 1"},
+		{"upsert merges duplicates", "supabase.from('users').upsert({id: 1, name: 'Ann'}, {onConflict: 'id'})", "INSERT INTO users (id, name) VALUES (1, 'Ann') ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name"},
+		{"upsert ignores duplicates", "supabase.from('users').upsert({id: 1}, {onConflict: 'id', ignoreDuplicates: true})", "INSERT INTO users (id) VALUES (1) ON CONFLICT (id) DO NOTHING"},
+		{"head count", "supabase.from('users').select('*', {count: 'exact', head: true}).eq('active', true)", "SELECT count(*) FROM users WHERE active = true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.ConvertToSQL(tt.input)
+			if err != nil {
+				t.Fatalf("ConvertToSQL() error = %v", err)
+			}
+			if result.SQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", result.SQL, tt.wantSQL)
+			}
+		})
+	}
+
+	t.Run("count metadata", func(t *testing.T) {
+		result, err := c.ConvertToSQL("supabase.from('users').select('id', {count: 'exact'}).gt('age', 18)")
+		if err != nil {
+			t.Fatalf("ConvertToSQL() error = %v", err)
+		}
+		if result.Metadata["count_sql"] != "SELECT count(*) FROM users WHERE age > 18" {
+			t.Errorf("Metadata = %v, want the count query", result.Metadata)
+		}
+	})
+
+	t.Run("auth has no SQL", func(t *testing.T) {
+		_, err := c.ConvertToSQL("supabase.auth.signOut()")
+		var convErr *ConversionError
+		if !errors.As(err, &convErr) || convErr.Code != ErrCodeHTTPOnly {
+			t.Errorf("ConvertToSQL() error = %v, want %s", err, ErrCodeHTTPOnly)
+		}
+	})
+}
+
+func TestConverter_ErrorCodes(t *testing.T) {
+	c := NewConverter("")
+
+	tests := []struct {
+		name     string
+		input    string
+		wantCode string
+		wantType string
+	}{
+		{"not a query", "const x = 1", ErrCodeNoQuery, "syntax"},
+		{"unbalanced rpc", "supabase.rpc('add', {a: 1}", ErrCodeUnbalanced, "syntax"},
+		{"unbalanced filter", "supabase.from('users').select('*').eq('name', 'O\\'Brien'", ErrCodeUnbalanced, "syntax"},
+		{"unbalanced auth call", "supabase.auth.signUp({email: 'a@b.co'", ErrCodeUnbalanced, "syntax"},
+		{"invalid argument", "supabase.from('users').select('*').limit('ten')", ErrCodeInvalidArgument, "semantic"},
+		{"unknown method", "supabase.from('users').select('*').paginate(2)", ErrCodeUnknownMethod, "unsupported"},
+		{"rpc get with array arguments", "supabase.rpc('add', [1, 2], {get: true})", ErrCodeInvalidArgument, "semantic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Convert(tt.input)
+			var convErr *ConversionError
+			if !errors.As(err, &convErr) {
+				t.Fatalf("Convert() error = %v, want a *ConversionError", err)
+			}
+			if convErr.Code != tt.wantCode || convErr.Type != tt.wantType {
+				t.Errorf("error = %s/%s, want %s/%s", convErr.Code, convErr.Type, tt.wantCode, tt.wantType)
+			}
+			if convErr.Hint == "" {
+				t.Error("Hint is empty")
+			}
+		})
+	}
+
+	t.Run("argument errors stay reachable", func(t *testing.T) {
+		_, err := c.Convert("supabase.from('users').select('*').limit(-1)")
+		var argErr *ArgumentError
+		if !errors.As(err, &argErr) || argErr.Method != "limit" {
+			t.Errorf("Convert() error = %v, want an *ArgumentError for limit", err)
+		}
+	})
+}
+
+func TestConverter_V1Syntax(t *testing.T) {
+	c := NewConverter("")
+
+	tests := []struct {
+		name        string
+		input       string
+		wantMethod  string
+		wantPath    string
+		wantQuery   string
+		wantPrefer  string
+		wantWarning string
+	}{
+		{"array shorthand", "supabase.from('posts').select('*').cs('tags', ['a', 'b'])", "GET", "/posts", "select=*&tags=cs.{a,b}", "", "use .contains() in v2"},
+		{"range shorthand", "supabase.from('rooms').select('*').adj('during', '[1,5)')", "GET", "/rooms", "select=*&during=adj.[1,5)", "", "use .rangeAdjacent() in v2"},
+		{"full-text shorthand keeps config", "supabase.from('posts').select('*').plfts('body', 'fat cat', {config: 'english'})", "GET", "/posts", "select=*&body=plfts(english).fat cat", "", "use .textSearch() in v2"},
+		{"insert with upsert", "supabase.from('users').insert([{id: 1}], {upsert: true, onConflict: 'id'})", "POST", "/users", "on_conflict=id", "resolution=merge-duplicates", "use .upsert() in v2"},
+		{"returning minimal", "supabase.from('users').delete({returning: 'minimal'}).eq('id', 1)", "DELETE", "/users", "id=eq.1", "", "v2 returns no rows unless .select() is chained"},
+		{"returning representation", "supabase.from('users').update({active: false}, {returning: 'representation'}).eq('id', 1)", "PATCH", "/users", "select=*&id=eq.1", "return=representation", "chain .select() in v2"},
+		{"password sign in", "supabase.auth.signIn({email: 'a@example.com', password: 'secret'})", "POST", "/auth/v1/token", "grant_type=password", "", "use auth.signInWithPassword() in v2"},
+		{"magic link sign in", "supabase.auth.signIn({email: 'a@example.com'})", "POST", "/auth/v1/otp", "", "", "use auth.signInWithOtp() in v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Method != tt.wantMethod || result.Path != tt.wantPath {
+				t.Errorf("request = %s %s, want %s %s", result.Method, result.Path, tt.wantMethod, tt.wantPath)
+			}
+			got, _ := url.QueryUnescape(result.Query)
+			queryParamsEqual(t, got, tt.wantQuery)
+			if result.Headers["Prefer"] != tt.wantPrefer {
+				t.Errorf("Prefer = %q, want %q", result.Headers["Prefer"], tt.wantPrefer)
+			}
+			found := false
+			for _, w := range result.Warnings {
+				if strings.Contains(w, "supabase-js v1") && strings.Contains(w, tt.wantWarning) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Warnings = %v, want one mentioning %q", result.Warnings, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package supabase
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// namedArgPattern matches a Dart named argument such as ascending: false
+var namedArgPattern = regexp.MustCompile(`^([A-Za-z_]\w*)\s*:(.*)$`)
+
+// dartMethods renames supabase-dart methods whose names differ from
+// supabase-js; is and in are reserved words in Dart
+var dartMethods = map[string]string{
+	"isFilter": "is",
+	"inFilter": "in",
+}
+
+// dartFutureMethods end a query chain; their callbacks run on the response
+var dartFutureMethods = map[string]bool{"then": true, "catchError": true, "whenComplete": true, "onError": true}
+
+// dartMergedOptions are named arguments whose object is spliced into the
+// options object rather than nested under its name
+var dartMergedOptions = map[string]bool{"options": true, "fileOptions": true, "searchOptions": true, "attributes": true}
+
+// dartCall is one method call of a Dart chain being rewritten
+type dartCall struct {
+	name    string
+	args    []string
+	options []string
+}
+
+// dartToJS rewrites a supabase-dart query such as
+//
+//	await supabase.from('users').select().order('name', ascending: false).count(CountOption.exact)
+//
+// as the supabase-js chain the parser reads:
+//
+//	supabase.from('users').select('*', {count: 'exact'}).order('name', {ascending: false})
+//
+// Named arguments become an options object, option classes such as
+// FileOptions(upsert: true) become objects, enum values such as
+// CountOption.exact become strings and .count() moves into the select.
+// String interpolation like '$userId' becomes the {userId} placeholder.
+func dartToJS(input string) string {
+	loc := clientStartPattern.FindStringIndex(input)
+	if loc == nil {
+		return input
+	}
+
+	pos := loc[0] + strings.IndexByte(input[loc[0]:], '.')
+	client := strings.TrimSpace(input[loc[0]:pos])
+	var calls []dartCall
+	count := ""
+	area := ""
+	for {
+		match := chainStepPattern.FindStringSubmatchIndex(input[pos:])
+		if match == nil {
+			break
+		}
+		name := input[pos+match[2] : pos+match[3]]
+		if dartFutureMethods[name] {
+			break
+		}
+		next := pos + match[1]
+		if match[6] < 0 {
+			// An attribute such as auth, admin or storage
+			if area == "" && (name == "auth" || name == "storage") {
+				area = name
+			}
+			calls = append(calls, dartCall{name: name, args: nil})
+			pos = next
+			continue
+		}
+
+		closing := closingParen(input, next)
+		if closing < 0 {
+			// Leave unbalanced calls for the parser to report
+			return input
+		}
+		pos = closing + 1
+
+		call := dartCall{name: name, args: []string{}}
+		if renamed, ok := dartMethods[name]; ok {
+			call.name = renamed
+		}
+		named := map[string]string{}
+		var keys []string
+		for _, arg := range splitArguments(strings.TrimSpace(input[next:closing])) {
+			if m := namedArgPattern.FindStringSubmatch(arg); m != nil {
+				keys = append(keys, m[1])
+				named[m[1]] = dartValue(m[2])
+				continue
+			}
+			call.args = append(call.args, dartValue(arg))
+		}
+
+		switch {
+		case name == "execute":
+			continue
+		case name == "count" && area == "":
+			count = "'exact'"
+			if len(call.args) > 0 {
+				count = call.args[0]
+			}
+			continue
+		case name == "rpc":
+			if params, ok := named["params"]; ok {
+				call.args = append(call.args, params)
+			} else if len(keys) > 0 {
+				call.args = append(call.args, "{}")
+			}
+		case area == "storage" && name == "list":
+			if path, ok := named["path"]; ok {
+				call.args = append([]string{path}, call.args...)
+			}
+		}
+
+		var nested []string
+		for _, key := range keys {
+			value := named[key]
+			switch {
+			case key == "params" && name == "rpc", key == "path" && area == "storage" && name == "list":
+			case dartMergedOptions[key]:
+				if inner := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, "{"), "}")); inner != "" {
+					call.options = append(call.options, inner)
+				}
+			case area == "auth" && len(call.args) == 0 && authOptionFields[key] != "":
+				// signUp(email:, password:, data:) nests data under options
+				nested = append(nested, key+": "+value)
+			default:
+				call.options = append(call.options, key+": "+value)
+			}
+		}
+		if len(nested) > 0 {
+			call.options = append(call.options, "options: {"+strings.Join(nested, ", ")+"}")
+		}
+		calls = append(calls, call)
+	}
+
+	if count != "" {
+		counted := false
+		for i := range calls {
+			if calls[i].name == "select" {
+				if len(calls[i].args) == 0 {
+					calls[i].args = []string{"'*'"}
+				}
+				calls[i].options = append(calls[i].options, "count: "+count)
+				counted = true
+			}
+		}
+		if !counted {
+			// .count() without .select() only asks for the count
+			calls = append(calls, dartCall{name: "select", args: []string{"'*'"}, options: []string{"count: " + count, "head: true"}})
+		}
+	}
+
+	var chain strings.Builder
+	chain.WriteString(client)
+	for _, call := range calls {
+		chain.WriteString("." + call.name)
+		if call.args == nil {
+			continue
+		}
+		args := call.args
+		if len(call.options) > 0 {
+			args = append(args, "{"+strings.Join(call.options, ", ")+"}")
+		}
+		chain.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	return chain.String()
+}
+
+// dartValue rewrites a Dart expression as JavaScript: enum values such as
+// CountOption.exact become strings, option classes such as
+// FileOptions(upsert: true) become objects, const and new are dropped and
+// string interpolation becomes {name} placeholders
+func dartValue(value string) string {
+	value = strings.TrimSpace(value)
+	if expr, ok := dartInterpolatedVariable(value); ok {
+		return expr
+	}
+
+	var b strings.Builder
+	var quote rune
+	raw := false
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case quote != 0:
+			switch {
+			case ch == '\\' && !raw && i+1 < len(runes):
+				b.WriteRune(ch)
+				i++
+				ch = runes[i]
+			case ch == quote:
+				quote = 0
+			case ch == '$' && !raw && i+1 < len(runes):
+				// '$name' and '${expr}' interpolation
+				end := i + 1
+				if runes[end] == '{' {
+					for end < len(runes) && runes[end] != '}' {
+						end++
+					}
+					b.WriteString("{" + string(runes[i+2:end]) + "}")
+					i = end
+					continue
+				}
+				for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+					end++
+				}
+				if end > i+1 {
+					b.WriteString("{" + string(runes[i+1:end]) + "}")
+					i = end - 1
+					continue
+				}
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+			raw = false
+		case unicode.IsLetter(ch) || ch == '_':
+			start := i
+			for i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '_' || runes[i+1] == '.') {
+				i++
+			}
+			word := string(runes[start : i+1])
+			next := i + 1
+			for next < len(runes) && unicode.IsSpace(runes[next]) {
+				next++
+			}
+			switch {
+			case word == "r" && next < len(runes) && (runes[next] == '\'' || runes[next] == '"'):
+				// r'...' is a raw string without interpolation
+				quote = runes[next]
+				raw = true
+				b.WriteRune(quote)
+				i = next
+			case word == "const" || word == "new":
+				i = next - 1
+			case next < len(runes) && runes[next] == '(' && unicode.IsUpper(runes[start]):
+				// FileOptions(upsert: true) is an options object
+				closing := closingParen(value, len(string(runes[:next+1])))
+				if closing < 0 {
+					b.WriteString(word)
+					continue
+				}
+				b.WriteString(dartObject(value[len(string(runes[:next+1])):closing]))
+				i = len([]rune(value[:closing]))
+			case unicode.IsUpper(runes[start]) && strings.Contains(word, "."):
+				// CountOption.exact or TextSearchType.websearch
+				b.WriteString("'" + word[strings.LastIndexByte(word, '.')+1:] + "'")
+			default:
+				b.WriteString(word)
+			}
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+// dartInterpolatedVariable returns userId for the string '$userId' or
+// '${user.id}', which stands for the variable itself
+func dartInterpolatedVariable(value string) (string, bool) {
+	if len(value) < 4 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] || value[1] != '$' {
+		return "", false
+	}
+	expr := value[2 : len(value)-1]
+	if strings.HasPrefix(expr, "{") && strings.HasSuffix(expr, "}") {
+		expr = expr[1 : len(expr)-1]
+	} else if strings.Contains(expr, ".") {
+		// '$user.id' interpolates only user
+		return "", false
+	}
+	if !variablePattern.MatchString(expr) {
+		return "", false
+	}
+	return expr, true
+}
+
+// dartObject turns the named arguments of an option class constructor into
+// an object literal
+func dartObject(argsStr string) string {
+	var entries []string
+	for _, arg := range splitArguments(strings.TrimSpace(argsStr)) {
+		if m := namedArgPattern.FindStringSubmatch(arg); m != nil {
+			entries = append(entries, m[1]+": "+dartValue(m[2]))
+		}
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
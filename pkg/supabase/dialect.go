@@ -0,0 +1,49 @@
+package supabase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Dialect is the client library syntax a Converter reads
+type Dialect string
+
+const (
+	DialectJS     Dialect = "js"     // supabase-js, the default
+	DialectPython Dialect = "python" // supabase-py
+	DialectDart   Dialect = "dart"   // supabase-dart and supabase_flutter
+)
+
+// ParseDialect returns the dialect for a name such as "js" or "python"
+func ParseDialect(name string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "js", "javascript", "ts", "typescript":
+		return DialectJS, nil
+	case "py", "python":
+		return DialectPython, nil
+	case "dart", "flutter":
+		return DialectDart, nil
+	}
+	return "", fmt.Errorf("unknown dialect %q (expected js, python or dart)", name)
+}
+
+// pythonPattern spots supabase-py only syntax: .table(), .execute() and
+// the .not_ attribute
+var pythonPattern = regexp.MustCompile(`\.\s*(?:table\s*\(|execute\s*\(|not_\s*\.)`)
+
+// dartPattern spots supabase-dart only syntax: the isFilter/inFilter
+// methods, named arguments such as ascending: false and option classes
+// such as CountOption.exact or FileOptions(...)
+var dartPattern = regexp.MustCompile(`\.\s*(?:isFilter|inFilter)\s*\(|,\s*(?:ascending|nullsFirst|referencedTable|foreignTable|onConflict|ignoreDuplicates|defaultToNull|params|fileOptions)\s*:|\b(?:CountOption|TextSearchType|FetchOptions|FileOptions|SearchOptions)\b`)
+
+// DetectDialect guesses the client library a query was written for
+func DetectDialect(input string) Dialect {
+	switch {
+	case pythonPattern.MatchString(input):
+		return DialectPython
+	case dartPattern.MatchString(input):
+		return DialectDart
+	}
+	return DialectJS
+}
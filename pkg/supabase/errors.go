@@ -0,0 +1,82 @@
+package supabase
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error codes of a ConversionError
+const (
+	ErrCodeNoQuery         = "ERR_SYNTAX_NO_QUERY"            // No .from(), .rpc(), .auth or .storage call found
+	ErrCodeUnbalanced      = "ERR_SYNTAX_UNBALANCED"          // A call's parentheses do not match
+	ErrCodeInvalidArgument = "ERR_SEMANTIC_INVALID_ARGUMENT"  // A method argument could not be converted
+	ErrCodeUnknownMethod   = "ERR_UNSUPPORTED_METHOD"         // A chained method the converter does not know
+	ErrCodeHTTPOnly        = "ERR_UNSUPPORTED_HTTP_ONLY"      // An auth or storage call, which has no SQL form
+	ErrCodeUnknownSpecial  = "ERR_UNSUPPORTED_SPECIAL_METHOD" // A special operation other than rpc, auth or storage
+)
+
+// ConversionError represents a conversion error with context, as in
+// pkg/reverse: Type is "syntax" for JavaScript that could not be read,
+// "semantic" for calls with invalid arguments and "unsupported" for
+// methods and operations that have no PostgREST or SQL equivalent
+type ConversionError struct {
+	Code    string // Error code (e.g., ERR_SYNTAX_NO_QUERY)
+	Type    string // Error type: "syntax", "semantic", "unsupported"
+	Message string // Human-readable error message
+	Input   string // Input that caused error
+	Hint    string // Suggestion for fix
+	Err     error  // Underlying error, such as an *ArgumentError
+}
+
+func (e *ConversionError) Error() string {
+	return e.Message
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// NewSyntaxError creates a syntax error
+func NewSyntaxError(code, message, input, hint string) *ConversionError {
+	return &ConversionError{
+		Code:    code,
+		Type:    "syntax",
+		Message: message,
+		Input:   input,
+		Hint:    hint,
+	}
+}
+
+// NewSemanticError creates a semantic error
+func NewSemanticError(code, message, input, hint string) *ConversionError {
+	return &ConversionError{
+		Code:    code,
+		Type:    "semantic",
+		Message: message,
+		Input:   input,
+		Hint:    hint,
+	}
+}
+
+// NewUnsupportedError creates an unsupported feature error
+func NewUnsupportedError(code, message, input, hint string) *ConversionError {
+	return &ConversionError{
+		Code:    code,
+		Type:    "unsupported",
+		Message: message,
+		Input:   input,
+		Hint:    hint,
+	}
+}
+
+// methodError wraps an error from a method call in a ConversionError,
+// keeping it reachable with errors.As
+func methodError(err error, method MethodCall) error {
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		return err
+	}
+	wrapped := NewSemanticError(ErrCodeInvalidArgument, err.Error(), "."+method.Name+"("+strings.Join(method.Args, ", ")+")", "check the arguments against the supabase-js v2 reference")
+	wrapped.Err = err
+	return wrapped
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Parse parses a Supabase JS query string into a SupabaseQuery
@@ -16,8 +18,13 @@ func Parse(input string) (*SupabaseQuery, error) {
 	// Remove line breaks and extra whitespace for easier parsing
 	input = regexp.MustCompile(`\s+`).ReplaceAllString(input, " ")
 
+	// Drop the statement around the query: const { data } = await ...;
+	input = extractStatement(input)
+
 	query := &SupabaseQuery{
-		Headers: make(map[string]string),
+		Headers:      make(map[string]string),
+		EmbedLimits:  make(map[string]int),
+		EmbedOffsets: make(map[string]int),
 	}
 
 	// Extract method chain
@@ -29,7 +36,7 @@ func Parse(input string) (*SupabaseQuery, error) {
 	// Parse each method call
 	for _, method := range methods {
 		if err := parseMethod(query, method); err != nil {
-			return nil, err
+			return nil, methodError(err, method)
 		}
 	}
 
@@ -43,87 +50,249 @@ func Parse(input string) (*SupabaseQuery, error) {
 
 // MethodCall represents a single method call
 type MethodCall struct {
-	Name string
-	Args []string
+	Name      string
+	Args      []string
+	Variables []string // Variables used in the arguments, left as {name} placeholders
 }
 
 // extractMethodChain extracts method calls from the input
 func extractMethodChain(input string) ([]MethodCall, error) {
 	// Match pattern: supabase.from('table').method(args).method(args)...
 
-	// First, find the starting point (either supabase.from or client.from)
-	fromPattern := regexp.MustCompile(`(?:supabase|client)\.from\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	// First, find the starting point (either supabase.from or client.from),
+	// optionally after .schema('name')
+	fromPattern := regexp.MustCompile(schemaPrefix + `\.from\s*\(\s*['"]([^'"]+)['"]\s*\)`)
 	matches := fromPattern.FindStringSubmatch(input)
 	matchIndices := fromPattern.FindStringSubmatchIndex(input)
 
-	if len(matches) < 2 {
+	if len(matches) < 3 {
 		// Try to find if it's an RPC call
-		rpcPattern := regexp.MustCompile(`(?:supabase|client)\.rpc\s*\(\s*['"]([^'"]+)['"]`)
+		rpcPattern := regexp.MustCompile(schemaPrefix + `\.rpc\s*\(\s*['"]([^'"]+)['"]`)
 		rpcMatches := rpcPattern.FindStringSubmatch(input)
-		if len(rpcMatches) >= 2 {
+		if len(rpcMatches) >= 3 {
 			// Handle RPC separately
-			return parseRPC(input, rpcMatches[1])
+			methods, err := parseRPC(input, rpcMatches[2])
+			return withSchema(rpcMatches[1], methods), err
 		}
 
 		// Check for auth or storage
 		if strings.Contains(input, ".auth") {
-			return parseSpecialOp(input, "auth")
+			return parseAuthCall(input)
 		}
 		if strings.Contains(input, ".storage") {
-			return parseSpecialOp(input, "storage")
+			return parseStorageCall(input)
 		}
 
-		return nil, fmt.Errorf("no valid Supabase query found - expected .from(), .rpc(), .auth, or .storage")
+		return nil, NewSyntaxError(ErrCodeNoQuery,
+			"no valid Supabase query found - expected .from(), .rpc(), .auth, or .storage",
+			input,
+			"start the query with supabase.from('table'), supabase.rpc('fn'), supabase.auth or supabase.storage")
 	}
 
-	tableName := matches[1]
+	tableName := matches[2]
 	remaining := input[matchIndices[1]:]
 
 	// Extract all method calls
 	methods := []MethodCall{{Name: "from", Args: []string{tableName}}}
 
-	// Pattern to match .method(args)
-	methodPattern := regexp.MustCompile(`\.(\w+)\s*\(([^)]*)\)`)
-	methodMatches := methodPattern.FindAllStringSubmatch(remaining, -1)
+	calls, err := scanMethodCalls(remaining)
+	if err != nil {
+		return nil, err
+	}
+	methods = append(methods, calls...)
 
-	for _, match := range methodMatches {
-		methodName := match[1]
-		argsStr := strings.TrimSpace(match[2])
+	return withSchema(matches[1], methods), nil
+}
 
-		args := []string{}
-		if argsStr != "" {
-			args = parseArguments(argsStr)
+// clientStartPattern finds where a query starts in a larger statement
+var clientStartPattern = regexp.MustCompile(`\b(?:supabase|client)\s*\.`)
+
+// chainStepPattern matches one .name step of a chain, with any TypeScript
+// type arguments and the opening parenthesis of a call
+var chainStepPattern = regexp.MustCompile(`^\s*\.\s*(\w+)\s*(<[^()]*>\s*)?(\()?`)
+
+// promiseMethods end a query chain; their callbacks run on the response
+var promiseMethods = map[string]bool{"then": true, "catch": true, "finally": true}
+
+// extractStatement returns just the query chain from a JavaScript
+// statement such as const { data, error } = await supabase.from('users')
+// .select('*'); dropping what comes before the client, a trailing
+// .then()/.catch() and everything after the chain ends. Spaces between
+// the steps of the chain are removed.
+func extractStatement(input string) string {
+	loc := clientStartPattern.FindStringIndex(input)
+	if loc == nil {
+		return input
+	}
+	input = input[loc[0]:]
+
+	pos := strings.IndexByte(input, '.')
+	var chain strings.Builder
+	chain.WriteString(strings.TrimSpace(input[:pos]))
+	for {
+		match := chainStepPattern.FindStringSubmatchIndex(input[pos:])
+		if match == nil {
+			break
+		}
+		name := input[pos+match[2] : pos+match[3]]
+		if promiseMethods[name] {
+			break
+		}
+		chain.WriteString("." + name)
+		next := pos + match[1]
+		if match[6] >= 0 {
+			closing := closingParen(input, next)
+			if closing < 0 {
+				// Leave unbalanced calls for the parser to report
+				return input
+			}
+			if match[4] >= 0 {
+				chain.WriteString(strings.TrimSpace(input[pos+match[4] : pos+match[5]]))
+			}
+			chain.WriteString(input[next-1 : closing+1])
+			next = closing + 1
 		}
+		pos = next
+	}
+	return chain.String()
+}
+
+// schemaPrefix matches supabase or client, then an optional
+// .schema('name') whose name is the first submatch
+const schemaPrefix = `(?:supabase|client)(?:\s*\.schema\s*\(\s*['"]([^'"]+)['"]\s*\))?`
 
-		methods = append(methods, MethodCall{Name: methodName, Args: args})
+// withSchema puts a schema('name') call ahead of the chain when one was given
+func withSchema(schema string, methods []MethodCall) []MethodCall {
+	if schema == "" {
+		return methods
 	}
+	return append([]MethodCall{{Name: "schema", Args: []string{schema}}}, methods...)
+}
+
+// methodNamePattern matches the start of a .method( call, skipping
+// TypeScript type arguments as in .returns<User[]>(
+var methodNamePattern = regexp.MustCompile(`^\.\s*(\w+)\s*(?:<[^()]*>\s*)?\(`)
+
+// scanMethodCalls reads the .method(args) calls of a chain, matching
+// parentheses outside quotes so arguments like 'and(a.eq.1,b.eq.2)' stay
+// whole. A call whose parentheses never close is a syntax error.
+func scanMethodCalls(chain string) ([]MethodCall, error) {
+	methods := []MethodCall{}
+	for i := 0; i < len(chain); i++ {
+		match := methodNamePattern.FindStringSubmatch(chain[i:])
+		if match == nil {
+			continue
+		}
+
+		start := i + len(match[0])
+		end := closingParen(chain, start)
+		if end < 0 {
+			return nil, NewSyntaxError(ErrCodeUnbalanced,
+				fmt.Sprintf("unbalanced parentheses in .%s(...) call", match[1]),
+				chain,
+				"close every ( opened in the call's arguments and every quote opened in a string")
+		}
 
+		args, variables := parseArguments(chain[start:end])
+		methods = append(methods, MethodCall{Name: match[1], Args: args, Variables: variables})
+		i = end
+	}
 	return methods, nil
 }
 
-// parseArguments parses method arguments
-func parseArguments(argsStr string) []string {
+// closingParen returns the index of the ) that closes a call whose
+// arguments start at s[start], or -1 when it is unbalanced. Inside quotes
+// a backslash escapes the next character, as in 'O\'Brien'.
+func closingParen(s string, start int) int {
+	depth := 1
+	var quote rune
+	escaped := false
+	for i, ch := range s[start:] {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if ch == '\\' {
+				escaped = true
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				return start + i
+			}
+		}
+	}
+	return -1
+}
+
+// parseArguments parses method arguments. Variables such as userId, or
+// inside objects like {name: user.name}, become {userId} placeholders and
+// are also returned by name.
+func parseArguments(argsStr string) ([]string, []string) {
 	// Handle simple cases first
 	argsStr = strings.TrimSpace(argsStr)
 	if argsStr == "" {
-		return []string{}
+		return []string{}, nil
 	}
 
-	// Try to parse as JSON for complex objects (if starting with { or [, and no commas outside)
-	if strings.HasPrefix(argsStr, "{") || strings.HasPrefix(argsStr, "[") {
-		return []string{argsStr}
+	args := splitArguments(argsStr)
+
+	// Clean up quoted strings, including `template literals`
+	var variables []string
+	for i, arg := range args {
+		arg = strings.TrimSpace(arg)
+		switch {
+		case (strings.HasPrefix(arg, "'") && strings.HasSuffix(arg, "'")) ||
+			(strings.HasPrefix(arg, "\"") && strings.HasSuffix(arg, "\"")) ||
+			(len(arg) >= 2 && strings.HasPrefix(arg, "`") && strings.HasSuffix(arg, "`")):
+			args[i] = unescapeJS(arg[1 : len(arg)-1])
+		case variablePattern.MatchString(arg) && !jsLiterals[arg] && arg != "undefined":
+			args[i] = "{" + arg + "}"
+			variables = append(variables, arg)
+		case strings.HasPrefix(arg, "{") || strings.HasPrefix(arg, "["):
+			_, nested := jsLiteralToJSON(arg)
+			variables = append(variables, nested...)
+			args[i] = arg
+		default:
+			args[i] = arg
+		}
 	}
 
-	// Split by comma for multiple args, respecting quotes and brackets
+	return args, variables
+}
+
+// splitArguments splits method arguments at top-level commas, respecting
+// quotes (with backslash escapes) and brackets, so objects and arrays like
+// {a: 1, b: 2} stay one argument
+func splitArguments(argsStr string) []string {
 	args := []string{}
 	depth := 0
 	inQuote := false
+	escaped := false
 	quoteChar := rune(0)
 	current := ""
 
 	for _, ch := range argsStr {
+		// Keep escaped characters, like the quote in 'O\'Brien', inside the string
+		if escaped {
+			escaped = false
+			current += string(ch)
+			continue
+		}
+		if ch == '\\' && inQuote {
+			escaped = true
+			current += string(ch)
+			continue
+		}
+
 		// Handle entering/exiting quotes
-		if (ch == '\'' || ch == '"') && !inQuote {
+		if (ch == '\'' || ch == '"' || ch == '`') && !inQuote {
 			inQuote = true
 			quoteChar = ch
 			current += string(ch)
@@ -164,23 +333,60 @@ func parseArguments(argsStr string) []string {
 		args = append(args, strings.TrimSpace(current))
 	}
 
-	// Clean up quoted strings
-	for i, arg := range args {
-		arg = strings.TrimSpace(arg)
-		if (strings.HasPrefix(arg, "'") && strings.HasSuffix(arg, "'")) ||
-			(strings.HasPrefix(arg, "\"") && strings.HasSuffix(arg, "\"")) {
-			args[i] = arg[1 : len(arg)-1]
-		} else {
-			args[i] = arg
+	return args
+}
+
+// unescapeJS resolves the backslash escapes of a JavaScript string body,
+// so 'O\'Brien' becomes O'Brien
+func unescapeJS(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '\\' || i+1 == len(runes) {
+			b.WriteRune(runes[i])
+			continue
+		}
+		i++
+		switch runes[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteRune(runes[i])
 		}
 	}
-
-	return args
+	return b.String()
 }
 
+// variablePattern matches a JavaScript variable or member expression such
+// as userId or session.user.id
+var variablePattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*$`)
+
+// jsLiterals are the bare words that are values rather than variables
+var jsLiterals = map[string]bool{"true": true, "false": true, "null": true}
+
 // parseMethod parses a single method call and updates the query
 func parseMethod(query *SupabaseQuery, method MethodCall) error {
+	method = upgradeV1(query, method)
+
+	for _, variable := range method.Variables {
+		switch method.Name {
+		case "throwOnError", "abortSignal", "returns", "overrideTypes":
+		default:
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".%s() uses the variable %s; it was left as the placeholder {%s}", method.Name, variable, variable))
+		}
+	}
+
 	switch method.Name {
+	case "schema":
+		if len(method.Args) > 0 {
+			query.Schema = method.Args[0]
+		}
+
 	case "from":
 		if len(method.Args) > 0 {
 			query.Table = method.Args[0]
@@ -188,15 +394,14 @@ func parseMethod(query *SupabaseQuery, method MethodCall) error {
 
 	case "select":
 		if len(method.Args) > 0 {
-			// Parse select columns
-			cols := strings.Split(method.Args[0], ",")
-			for _, col := range cols {
-				query.Select = append(query.Select, strings.TrimSpace(col))
-			}
+			query.Select = append(query.Select, parseSelectColumns(method.Args[0])...)
 		} else {
 			query.Select = []string{"*"}
 		}
-		query.Operation = "select"
+		// .insert(...).select('id') returns the written rows rather than reading
+		if query.Operation == "" {
+			query.Operation = "select"
+		}
 
 		// Check for options in second argument (e.g., {count: 'exact'})
 		if len(method.Args) >= 2 {
@@ -205,6 +410,10 @@ func parseMethod(query *SupabaseQuery, method MethodCall) error {
 				if count, ok := optsMap["count"].(string); ok {
 					query.Count = count
 				}
+				// {head: true} fetches only the count, not the rows
+				if head, ok := optsMap["head"].(bool); ok {
+					query.Head = head
+				}
 			}
 		}
 
@@ -213,6 +422,11 @@ func parseMethod(query *SupabaseQuery, method MethodCall) error {
 		if len(method.Args) > 0 {
 			query.Data = parseJSON(method.Args[0])
 		}
+		if len(method.Args) >= 2 {
+			if err := parseMutationOptions(query, method.Name, method.Args[1]); err != nil {
+				return err
+			}
+		}
 
 	case "upsert":
 		query.Operation = "insert"
@@ -220,15 +434,30 @@ func parseMethod(query *SupabaseQuery, method MethodCall) error {
 		if len(method.Args) > 0 {
 			query.Data = parseJSON(method.Args[0])
 		}
+		if len(method.Args) >= 2 {
+			if err := parseUpsertOptions(query, method.Args[1]); err != nil {
+				return err
+			}
+		}
 
 	case "update":
 		query.Operation = "update"
 		if len(method.Args) > 0 {
 			query.Data = parseJSON(method.Args[0])
 		}
+		if len(method.Args) >= 2 {
+			if err := parseMutationOptions(query, method.Name, method.Args[1]); err != nil {
+				return err
+			}
+		}
 
 	case "delete":
 		query.Operation = "delete"
+		if len(method.Args) >= 1 {
+			if err := parseMutationOptions(query, method.Name, method.Args[0]); err != nil {
+				return err
+			}
+		}
 
 	// Filter methods
 	case "eq":
@@ -303,6 +532,16 @@ func parseMethod(query *SupabaseQuery, method MethodCall) error {
 			})
 		}
 
+	case "likeAllOf", "likeAnyOf", "ilikeAllOf", "ilikeAnyOf":
+		// .likeAnyOf('name', ['%a%', '%b%']) is name=like(any).{%a%,%b%}
+		if len(method.Args) >= 2 {
+			query.Filters = append(query.Filters, Filter{
+				Column:   method.Args[0],
+				Operator: patternOperators[method.Name],
+				Value:    parseJSON(method.Args[1]),
+			})
+		}
+
 	case "is":
 		if len(method.Args) >= 2 {
 			query.Filters = append(query.Filters, Filter{
@@ -341,54 +580,142 @@ func parseMethod(query *SupabaseQuery, method MethodCall) error {
 			})
 		}
 
+	case "or":
+		// .or('age.lt.18,age.gt.65', {foreignTable: 'posts'})
+		if len(method.Args) >= 1 {
+			query.Filters = append(query.Filters, Filter{
+				Column:   referencedTable(method.Args[1:]),
+				Operator: "or",
+				Value:    method.Args[0],
+			})
+		}
+
+	case "filter":
+		// .filter('age', 'gte', 18) passes the operator through as is
+		if len(method.Args) >= 3 {
+			operator, negate := strings.CutPrefix(strings.TrimSpace(method.Args[1]), "not.")
+			query.Filters = append(query.Filters, Filter{
+				Column:   method.Args[0],
+				Operator: operator,
+				Value:    parseValue(method.Args[2]),
+				Negate:   negate,
+			})
+		}
+
+	case "match":
+		// .match({status: 'active', role: 'admin'}) is one eq filter per key
+		if len(method.Args) == 0 {
+			return &ArgumentError{Method: "match", Message: "missing query argument"}
+		}
+		columns, ok := parseJSON(method.Args[0]).(map[string]interface{})
+		if !ok {
+			return &ArgumentError{Method: "match", Arg: method.Args[0], Message: "query must be an object like {status: 'active'}"}
+		}
+		keys := make([]string, 0, len(columns))
+		for key := range columns {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			query.Filters = append(query.Filters, Filter{
+				Column:   key,
+				Operator: "eq",
+				Value:    columns[key],
+			})
+		}
+
+	case "overlaps", "rangeGt", "rangeGte", "rangeLt", "rangeLte", "rangeAdjacent":
+		// Range methods take a range literal like '[1,5)'; overlaps also
+		// takes an array like ['a', 'b']
+		if len(method.Args) >= 2 {
+			var value interface{} = method.Args[1]
+			if method.Name == "overlaps" {
+				value = parseJSON(method.Args[1])
+			}
+			query.Filters = append(query.Filters, Filter{
+				Column:   method.Args[0],
+				Operator: rangeOperators[method.Name],
+				Value:    value,
+			})
+		}
+
 	case "textSearch":
 		if len(method.Args) >= 2 {
+			operator, err := parseTextSearchOptions(query, method.Args[2:])
+			if err != nil {
+				return err
+			}
 			query.Filters = append(query.Filters, Filter{
 				Column:   method.Args[0],
-				Operator: "fts",
+				Operator: operator,
 				Value:    method.Args[1],
 			})
 		}
 
 	// Modifiers
 	case "order":
-		if len(method.Args) >= 1 {
-			col := method.Args[0]
-			ascending := true
-			nullsFirst := false
-
-			if len(method.Args) >= 2 {
-				opts := parseJSON(method.Args[1])
-				if optsMap, ok := opts.(map[string]interface{}); ok {
-					if asc, ok := optsMap["ascending"].(bool); ok {
-						ascending = asc
-					}
-					if nf, ok := optsMap["nullsFirst"].(bool); ok {
-						nullsFirst = nf
-					}
-				}
-			}
-
-			query.Order = append(query.Order, OrderBy{
-				Column:     col,
-				Ascending:  ascending,
-				NullsFirst: nullsFirst,
-			})
+		order, err := parseOrderArgs(query, method.Args)
+		if err != nil {
+			return err
 		}
+		query.Order = append(query.Order, order)
 
 	case "limit":
-		if len(method.Args) >= 1 {
-			if limit, err := strconv.Atoi(method.Args[0]); err == nil {
-				query.Limit = &limit
-			}
+		limit, err := parseIntArg("limit", method.Args, 0, "count")
+		if err != nil {
+			return err
+		}
+		if limit < 0 {
+			return &ArgumentError{Method: "limit", Arg: method.Args[0], Message: "count must not be negative"}
 		}
+		if table := modifierTable(query, "limit", method.Args[1:]); table != "" {
+			query.EmbedLimits[table] = limit
+			break
+		}
+		query.setLimit(limit)
 
 	case "range":
-		if len(method.Args) >= 2 {
-			from, _ := strconv.Atoi(method.Args[0])
-			to, _ := strconv.Atoi(method.Args[1])
-			query.Range = &Range{From: from, To: to}
+		from, err := parseIntArg("range", method.Args, 0, "from")
+		if err != nil {
+			return err
+		}
+		to, err := parseIntArg("range", method.Args, 1, "to")
+		if err != nil {
+			return err
+		}
+		if from < 0 || to < from {
+			return &ArgumentError{
+				Method:  "range",
+				Arg:     strings.Join(method.Args[:2], ", "),
+				Message: "expected 0 <= from <= to",
+			}
+		}
+		if table := modifierTable(query, "range", method.Args[2:]); table != "" {
+			// An embed has no Range header, so it pages with limit and offset
+			query.EmbedOffsets[table] = from
+			query.EmbedLimits[table] = to - from + 1
+			break
 		}
+		query.setRange(from, to)
+
+	case "csv":
+		query.Accept = "text/csv"
+
+	case "geojson":
+		query.Accept = "application/geo+json"
+
+	case "explain":
+		explain, err := parseExplainArgs(query, method.Args)
+		if err != nil {
+			return err
+		}
+		query.Explain = explain
+
+	case "throwOnError", "abortSignal", "returns", "overrideTypes":
+		// These change how supabase-js reports errors or types the
+		// result, not the request it sends
+		query.Warnings = append(query.Warnings, fmt.Sprintf(".%s() does not change the request and was skipped", method.Name))
+		return nil
 
 	case "single":
 		query.Single = true
@@ -403,34 +730,428 @@ func parseMethod(query *SupabaseQuery, method MethodCall) error {
 		if len(method.Args) >= 1 {
 			query.RPCFunction = method.Args[0]
 		}
-		if len(method.Args) >= 2 {
+		// .rpc('fn', undefined, {get: true}) passes no arguments
+		if len(method.Args) >= 2 && method.Args[1] != "undefined" && method.Args[1] != "null" {
 			query.RPCParams = parseJSON(method.Args[1])
 		}
+		if len(method.Args) >= 3 {
+			if err := parseRPCOptions(query, method.Args[2]); err != nil {
+				return err
+			}
+		}
 
 	case "auth":
 		query.IsSpecialOp = true
 		query.SpecialType = "auth"
+		if len(method.Args) > 0 {
+			query.SpecialMethod = method.Args[0]
+			query.SpecialArgs = method.Args[1:]
+		}
+
+	case "bucket":
+		if len(method.Args) > 0 {
+			query.Bucket = method.Args[0]
+		}
 
 	case "storage":
 		query.IsSpecialOp = true
 		query.SpecialType = "storage"
+		if len(method.Args) > 0 {
+			query.SpecialMethod = method.Args[0]
+			query.SpecialArgs = method.Args[1:]
+		}
 
 	// Negation filter
 	case "not":
 		if len(method.Args) >= 3 {
-			// .not('column', 'operator', 'value')
+			// .not('column', 'operator', 'value'); the value is usually raw
+			// PostgREST text like '("a","b")', but may be a JS array or object
+			value := parseValue(method.Args[2])
+			if arg := strings.TrimSpace(method.Args[2]); strings.HasPrefix(arg, "[") || strings.HasPrefix(arg, "{") {
+				value = parseJSON(arg)
+			}
 			query.Filters = append(query.Filters, Filter{
 				Column:   method.Args[0],
-				Operator: method.Args[1],
-				Value:    parseValue(method.Args[2]),
+				Operator: strings.TrimSpace(method.Args[1]),
+				Value:    value,
 				Negate:   true,
 			})
 		}
+
+	default:
+		return NewUnsupportedError(ErrCodeUnknownMethod,
+			fmt.Sprintf("unsupported method .%s()", method.Name),
+			"."+method.Name+"("+strings.Join(method.Args, ", ")+")",
+			"only supabase-js v2 query builder, filter and modifier methods can be converted")
 	}
 
 	return nil
 }
 
+// patternOperators maps the pattern list filter methods to PostgREST operators
+var patternOperators = map[string]string{
+	"likeAllOf":  "like(all)",
+	"likeAnyOf":  "like(any)",
+	"ilikeAllOf": "ilike(all)",
+	"ilikeAnyOf": "ilike(any)",
+}
+
+// rangeOperators maps the array and range filter methods to PostgREST operators
+var rangeOperators = map[string]string{
+	"overlaps":      "ov",
+	"rangeGt":       "sr",
+	"rangeGte":      "nxl",
+	"rangeLt":       "sl",
+	"rangeLte":      "nxr",
+	"rangeAdjacent": "adj",
+}
+
+// referencedTable returns the referencedTable (or older foreignTable)
+// option of a filter or modifier, if any
+func referencedTable(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	optsMap, ok := parseJSON(args[0]).(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{"referencedTable", "foreignTable"} {
+		if table, ok := optsMap[key].(string); ok {
+			return table
+		}
+	}
+	return ""
+}
+
+// parseExplainArgs parses .explain({analyze, verbose, settings, buffers, wal, format})
+func parseExplainArgs(query *SupabaseQuery, args []string) (*Explain, error) {
+	explain := &Explain{Format: "text"}
+	if len(args) == 0 {
+		return explain, nil
+	}
+
+	optsMap, ok := parseJSON(args[0]).(map[string]interface{})
+	if !ok {
+		return nil, &ArgumentError{Method: "explain", Arg: args[0], Message: "options must be an object like {analyze: true}"}
+	}
+
+	flags := map[string]*bool{
+		"analyze":  &explain.Analyze,
+		"verbose":  &explain.Verbose,
+		"settings": &explain.Settings,
+		"buffers":  &explain.Buffers,
+		"wal":      &explain.WAL,
+	}
+	for key, val := range optsMap {
+		if flag, ok := flags[key]; ok {
+			b, ok := val.(bool)
+			if !ok {
+				return nil, &ArgumentError{Method: "explain", Arg: args[0], Message: key + " must be true or false"}
+			}
+			*flag = b
+			continue
+		}
+		if key == "format" {
+			format, _ := val.(string)
+			if format != "text" && format != "json" {
+				return nil, &ArgumentError{Method: "explain", Arg: args[0], Message: "format must be 'text' or 'json'"}
+			}
+			explain.Format = format
+			continue
+		}
+		query.Warnings = append(query.Warnings, fmt.Sprintf(".explain() option %q is not supported and was ignored", key))
+	}
+
+	return explain, nil
+}
+
+// parseUpsertOptions parses .upsert() {onConflict, ignoreDuplicates, count} options
+func parseUpsertOptions(query *SupabaseQuery, arg string) error {
+	optsMap, ok := parseJSON(arg).(map[string]interface{})
+	if !ok {
+		return &ArgumentError{Method: "upsert", Arg: arg, Message: "options must be an object like {onConflict: 'email'}"}
+	}
+
+	for key, val := range optsMap {
+		switch key {
+		case "onConflict":
+			columns, ok := val.(string)
+			if !ok || strings.TrimSpace(columns) == "" {
+				return &ArgumentError{Method: "upsert", Arg: arg, Message: "onConflict must be a comma separated column list"}
+			}
+			query.OnConflict = strings.ReplaceAll(columns, " ", "")
+		case "ignoreDuplicates":
+			ignore, ok := val.(bool)
+			if !ok {
+				return &ArgumentError{Method: "upsert", Arg: arg, Message: "ignoreDuplicates must be true or false"}
+			}
+			query.IgnoreDuplicates = ignore
+		case "count":
+			count, err := parseCountOption("upsert", arg, val)
+			if err != nil {
+				return err
+			}
+			query.Count = count
+		default:
+			if parseV1MutationOption(query, "upsert", key, val) {
+				continue
+			}
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".upsert() option %q is not supported and was ignored", key))
+		}
+	}
+
+	return nil
+}
+
+// parseRPCOptions parses .rpc() {get, head, count} options
+func parseRPCOptions(query *SupabaseQuery, arg string) error {
+	optsMap, ok := parseJSON(arg).(map[string]interface{})
+	if !ok {
+		return &ArgumentError{Method: "rpc", Arg: arg, Message: "options must be an object like {get: true}"}
+	}
+
+	for key, val := range optsMap {
+		switch key {
+		case "get", "head":
+			b, ok := val.(bool)
+			if !ok {
+				return &ArgumentError{Method: "rpc", Arg: arg, Message: key + " must be true or false"}
+			}
+			if key == "get" {
+				query.RPCGet = b
+			} else {
+				query.Head = b
+			}
+		case "count":
+			count, err := parseCountOption("rpc", arg, val)
+			if err != nil {
+				return err
+			}
+			query.Count = count
+		default:
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".rpc() option %q is not supported and was ignored", key))
+		}
+	}
+
+	return nil
+}
+
+// parseMutationOptions parses the {count} options of .insert(), .update()
+// and .delete()
+func parseMutationOptions(query *SupabaseQuery, methodName string, arg string) error {
+	optsMap, ok := parseJSON(arg).(map[string]interface{})
+	if !ok {
+		return &ArgumentError{Method: methodName, Arg: arg, Message: "options must be an object like {count: 'exact'}"}
+	}
+
+	for key, val := range optsMap {
+		switch key {
+		case "count":
+			count, err := parseCountOption(methodName, arg, val)
+			if err != nil {
+				return err
+			}
+			query.Count = count
+		default:
+			if parseV1MutationOption(query, methodName, key, val) {
+				continue
+			}
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".%s() option %q is not supported and was ignored", methodName, key))
+		}
+	}
+
+	return nil
+}
+
+// parseCountOption checks a count option is exact, planned or estimated
+func parseCountOption(methodName string, arg string, val interface{}) (string, error) {
+	count, ok := val.(string)
+	if !ok || (count != "exact" && count != "planned" && count != "estimated") {
+		return "", &ArgumentError{Method: methodName, Arg: arg, Message: "count must be 'exact', 'planned' or 'estimated'"}
+	}
+	return count, nil
+}
+
+// setLimit applies .limit(count). Like supabase-js, a later .limit()
+// replaces the row count of an earlier .range(), which keeps its start.
+func (q *SupabaseQuery) setLimit(count int) {
+	q.Limit = &count
+	if q.Range != nil {
+		q.Range.To = q.Range.From + count - 1
+	}
+}
+
+// setRange applies .range(from, to), which pages with offset=from and
+// limit=to-from+1, replacing any earlier .limit()
+func (q *SupabaseQuery) setRange(from, to int) {
+	limit := to - from + 1
+	q.Range = &Range{From: from, To: to}
+	q.Limit, q.Offset = &limit, &from
+}
+
+// modifierTable returns the referencedTable (or foreignTable) option of
+// .limit() or .range(), warning about any other option
+func modifierTable(query *SupabaseQuery, methodName string, args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	optsMap, ok := parseJSON(args[0]).(map[string]interface{})
+	if !ok {
+		query.Warnings = append(query.Warnings, fmt.Sprintf(".%s() options must be an object and were ignored: %s", methodName, args[0]))
+		return ""
+	}
+	for key := range optsMap {
+		if key != "referencedTable" && key != "foreignTable" {
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".%s() option %q is not supported and was ignored", methodName, key))
+		}
+	}
+	return referencedTable(args)
+}
+
+// parseSelectColumns splits a select string into its top-level items,
+// keeping embeds like author:users!posts_author_fkey(name, email) and
+// comments!inner(body) whole. Whitespace outside double quotes is dropped,
+// as supabase-js does.
+func parseSelectColumns(columns string) []string {
+	var cleaned strings.Builder
+	quoted := false
+	for _, ch := range columns {
+		if ch == '"' {
+			quoted = !quoted
+		}
+		if !quoted && unicode.IsSpace(ch) {
+			continue
+		}
+		cleaned.WriteRune(ch)
+	}
+
+	items := []string{}
+	var current strings.Builder
+	depth := 0
+	quoted = false
+	for _, ch := range cleaned.String() {
+		switch {
+		case ch == '"':
+			quoted = !quoted
+		case quoted:
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			items = append(items, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(ch)
+	}
+	if current.Len() > 0 {
+		items = append(items, current.String())
+	}
+	return items
+}
+
+// parseIntArg parses the integer argument at index i of a modifier call
+func parseIntArg(methodName string, args []string, i int, argName string) (int, error) {
+	if len(args) <= i {
+		return 0, &ArgumentError{Method: methodName, Message: "missing " + argName + " argument"}
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(args[i]))
+	if err != nil {
+		return 0, &ArgumentError{Method: methodName, Arg: args[i], Message: argName + " must be an integer"}
+	}
+	return n, nil
+}
+
+// parseOrderArgs parses .order(column, {ascending, nullsFirst}) arguments.
+// Unknown option keys are kept as warnings; invalid values are errors.
+func parseOrderArgs(query *SupabaseQuery, args []string) (OrderBy, error) {
+	if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
+		return OrderBy{}, &ArgumentError{Method: "order", Message: "missing column argument"}
+	}
+
+	order := OrderBy{Column: args[0], Ascending: true}
+	if len(args) < 2 {
+		return order, nil
+	}
+
+	optsMap, ok := parseJSON(args[1]).(map[string]interface{})
+	if !ok {
+		return OrderBy{}, &ArgumentError{Method: "order", Arg: args[1], Message: "options must be an object like {ascending: false}"}
+	}
+
+	for key, val := range optsMap {
+		switch key {
+		case "ascending":
+			asc, ok := val.(bool)
+			if !ok {
+				return OrderBy{}, &ArgumentError{Method: "order", Arg: args[1], Message: "ascending must be true or false"}
+			}
+			order.Ascending = asc
+		case "nullsFirst":
+			nf, ok := val.(bool)
+			if !ok {
+				return OrderBy{}, &ArgumentError{Method: "order", Arg: args[1], Message: "nullsFirst must be true or false"}
+			}
+			order.NullsFirst = nf
+		case "referencedTable", "foreignTable":
+			table, ok := val.(string)
+			if !ok || table == "" {
+				return OrderBy{}, &ArgumentError{Method: "order", Arg: args[1], Message: key + " must be a table name"}
+			}
+			order.Table = table
+		default:
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".order() option %q is not supported and was ignored", key))
+		}
+	}
+
+	return order, nil
+}
+
+// textSearchTypes maps the .textSearch() type option to its operator prefix
+var textSearchTypes = map[string]string{
+	"plain":     "pl",
+	"phrase":    "ph",
+	"websearch": "w",
+}
+
+// parseTextSearchOptions turns .textSearch() {type, config} options into
+// the operator, e.g. wfts(english) for {type: 'websearch', config: 'english'}
+func parseTextSearchOptions(query *SupabaseQuery, args []string) (string, error) {
+	if len(args) == 0 {
+		return "fts", nil
+	}
+
+	optsMap, ok := parseJSON(args[0]).(map[string]interface{})
+	if !ok {
+		return "", &ArgumentError{Method: "textSearch", Arg: args[0], Message: "options must be an object like {type: 'websearch'}"}
+	}
+
+	prefix, config := "", ""
+	for key, val := range optsMap {
+		switch key {
+		case "type":
+			typ, _ := val.(string)
+			p, ok := textSearchTypes[typ]
+			if !ok {
+				return "", &ArgumentError{Method: "textSearch", Arg: args[0], Message: "type must be 'plain', 'phrase' or 'websearch'"}
+			}
+			prefix = p
+		case "config":
+			c, ok := val.(string)
+			if !ok || c == "" {
+				return "", &ArgumentError{Method: "textSearch", Arg: args[0], Message: "config must be a text search configuration name"}
+			}
+			config = "(" + c + ")"
+		default:
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".textSearch() option %q is not supported and was ignored", key))
+		}
+	}
+
+	return prefix + "fts" + config, nil
+}
+
 // parseValue parses a value argument
 func parseValue(val string) interface{} {
 	val = strings.TrimSpace(val)
@@ -467,15 +1188,9 @@ func parseJSON(str string) interface{} {
 		return result
 	}
 
-	// Try to convert JavaScript object literal to JSON
-	// Convert unquoted keys to quoted keys: {foo: 'bar'} -> {"foo": "bar"}
-	jsToJSON := str
-
-	// Replace single quotes with double quotes for strings
-	jsToJSON = regexp.MustCompile(`'([^']*)'`).ReplaceAllString(jsToJSON, `"$1"`)
-
-	// Add quotes around unquoted keys
-	jsToJSON = regexp.MustCompile(`(\w+):`).ReplaceAllString(jsToJSON, `"$1":`)
+	// Try to convert JavaScript object literal to JSON:
+	// {foo: 'bar',} -> {"foo": "bar"}
+	jsToJSON, _ := jsLiteralToJSON(str)
 
 	// Try parsing again
 	if err := json.Unmarshal([]byte(jsToJSON), &result); err == nil {
@@ -486,6 +1201,80 @@ func parseJSON(str string) interface{} {
 	return str
 }
 
+// jsLiteralToJSON rewrites a JavaScript object or array literal as JSON:
+// keys get double quotes, single-quoted strings become double-quoted and
+// trailing commas are dropped. Text inside strings is left alone, so
+// 'https://example.com' keeps its colon. Variables used as values become
+// "{name}" placeholders and are returned by name; undefined becomes null.
+func jsLiteralToJSON(js string) (string, []string) {
+	var b strings.Builder
+	var variables []string
+	runes := []rune(js)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case ch == '\'' || ch == '"':
+			// Copy the string, re-quoting it with double quotes
+			b.WriteByte('"')
+			for i++; i < len(runes) && runes[i] != ch; i++ {
+				switch {
+				case runes[i] == '\\' && i+1 < len(runes):
+					i++
+					if runes[i] == '\'' {
+						b.WriteRune('\'')
+					} else {
+						b.WriteRune('\\')
+						b.WriteRune(runes[i])
+					}
+				case runes[i] == '"':
+					b.WriteString(`\"`)
+				default:
+					b.WriteRune(runes[i])
+				}
+			}
+			b.WriteByte('"')
+
+		case unicode.IsLetter(ch) || ch == '_' || ch == '$':
+			// An identifier: a key when a colon follows
+			start := i
+			for i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '_' || runes[i+1] == '$' ||
+				(runes[i+1] == '.' && i+2 < len(runes) && (unicode.IsLetter(runes[i+2]) || runes[i+2] == '_' || runes[i+2] == '$'))) {
+				i++
+			}
+			word := string(runes[start : i+1])
+			next := i + 1
+			for next < len(runes) && unicode.IsSpace(runes[next]) {
+				next++
+			}
+			switch {
+			case next < len(runes) && runes[next] == ':':
+				word = `"` + word + `"`
+			case word == "undefined":
+				word = "null"
+			case !jsLiterals[word]:
+				variables = append(variables, word)
+				word = `"{` + word + `}"`
+			}
+			b.WriteString(word)
+
+		case ch == ',':
+			// Drop a trailing comma before } or ]
+			next := i + 1
+			for next < len(runes) && unicode.IsSpace(runes[next]) {
+				next++
+			}
+			if next < len(runes) && (runes[next] == '}' || runes[next] == ']') {
+				continue
+			}
+			b.WriteRune(ch)
+
+		default:
+			b.WriteRune(ch)
+		}
+	}
+	return b.String(), variables
+}
+
 // parseArrayArg parses an array argument like [1,2,3]
 func parseArrayArg(arg string) []interface{} {
 	arg = strings.TrimSpace(arg)
@@ -501,36 +1290,45 @@ func parseArrayArg(arg string) []interface{} {
 		arg = arg[1 : len(arg)-1]
 	}
 
+	// JS arrays usually quote strings with single quotes
+	if arr, ok := parseJSON("[" + arg + "]").([]interface{}); ok {
+		return arr
+	}
+
 	parts := strings.Split(arg, ",")
 	result := []interface{}{}
 	for _, part := range parts {
-		result = append(result, parseValue(strings.TrimSpace(part)))
+		part = strings.TrimSpace(part)
+		if len(part) >= 2 && (part[0] == '\'' || part[0] == '"') && part[len(part)-1] == part[0] {
+			part = part[1 : len(part)-1]
+		}
+		result = append(result, parseValue(part))
 	}
 
 	return result
 }
 
-// parseRPC handles RPC method calls
+// parseRPC handles RPC method calls and the filters and modifiers chained
+// after them:
+// .rpc('function_name', {params}, {get, head, count}).eq('id', 1).limit(5)
 func parseRPC(input string, functionName string) ([]MethodCall, error) {
-	// Extract parameters if present
-	// Pattern: .rpc('function_name', {params})
-	rpcPattern := regexp.MustCompile(`\.rpc\s*\(\s*['"]` + regexp.QuoteMeta(functionName) + `['"]\s*(?:,\s*(.+))?\)`)
-	matches := rpcPattern.FindStringSubmatch(input)
-
-	result := MethodCall{Name: "rpc", Args: []string{functionName}}
-
-	// If there are parameters (second argument)
-	if len(matches) > 1 && matches[1] != "" {
-		paramsStr := strings.TrimSpace(matches[1])
-		result.Args = append(result.Args, paramsStr)
+	rpcPattern := regexp.MustCompile(`\.\s*rpc\s*\(`)
+	loc := rpcPattern.FindStringIndex(input)
+	if loc == nil {
+		return []MethodCall{{Name: "rpc", Args: []string{functionName}}}, nil
 	}
 
-	return []MethodCall{result}, nil
-}
-
-// parseSpecialOp handles special operations like auth and storage
-func parseSpecialOp(input string, opType string) ([]MethodCall, error) {
-	return []MethodCall{{Name: opType, Args: []string{}}}, nil
+	calls, err := scanMethodCalls(input[loc[0]:])
+	if err != nil {
+		return nil, err
+	}
+	if len(calls) == 0 {
+		return nil, NewSyntaxError(ErrCodeUnbalanced,
+			fmt.Sprintf("unbalanced parentheses in .rpc('%s', ...) call", functionName),
+			input,
+			"close every ( opened in the call's arguments")
+	}
+	return calls, nil
 }
 
 // validate validates the parsed query
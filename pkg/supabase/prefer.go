@@ -0,0 +1,27 @@
+package supabase
+
+import "strings"
+
+// preferences builds a Prefer header from the count=, return=,
+// resolution= and other preferences set along a query, in the order they
+// were first set. Setting a preference again replaces its value.
+type preferences struct {
+	values []string
+}
+
+// set adds a key=value preference, replacing an earlier value for the key
+func (p *preferences) set(preference string) {
+	key, _, _ := strings.Cut(preference, "=")
+	for i, existing := range p.values {
+		if existingKey, _, _ := strings.Cut(existing, "="); existingKey == key {
+			p.values[i] = preference
+			return
+		}
+	}
+	p.values = append(p.values, preference)
+}
+
+// String joins the preferences as supabase-js does, e.g. count=exact,return=representation
+func (p *preferences) String() string {
+	return strings.Join(p.values, ",")
+}
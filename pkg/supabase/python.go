@@ -0,0 +1,263 @@
+package supabase
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// pythonStepPattern matches one .name step of a Python chain and the
+// opening parenthesis when it is a call; not_ and auth are attributes
+var pythonStepPattern = regexp.MustCompile(`^\s*\.\s*(\w+)\s*(\()?`)
+
+// keywordArgPattern matches a Python keyword argument such as count="exact"
+var keywordArgPattern = regexp.MustCompile(`^([A-Za-z_]\w*)\s*=([^=].*)$`)
+
+// pythonMethods renames supabase-py methods whose supabase-js name is not
+// just the camelCase form
+var pythonMethods = map[string]string{
+	"table": "from",
+	"cs":    "contains",
+	"cd":    "containedBy",
+	"ov":    "overlaps",
+	"sl":    "rangeLt",
+	"sr":    "rangeGt",
+	"nxl":   "rangeGte",
+	"nxr":   "rangeLte",
+	"adj":   "rangeAdjacent",
+}
+
+// pythonTextSearch maps the supabase-py full-text search shortcuts to the
+// .textSearch() type option
+var pythonTextSearch = map[string]string{
+	"fts":   "",
+	"plfts": "plain",
+	"phfts": "phrase",
+	"wfts":  "websearch",
+}
+
+// pythonMutations return the changed rows unless returning="minimal"
+var pythonMutations = map[string]bool{"insert": true, "update": true, "upsert": true, "delete": true}
+
+// pythonToJS rewrites a supabase-py query such as
+//
+//	supabase.table("users").select("id", "name", count="exact").execute()
+//
+// as the supabase-js chain the parser reads:
+//
+//	supabase.from("users").select("id,name", {count: "exact"})
+//
+// Method names become camelCase, keyword arguments become an options
+// object, .not_.op() becomes .not(column, 'op', value) and .execute() is
+// dropped. Mutations get a .select(), as supabase-py asks for the changed
+// rows by default.
+func pythonToJS(input string) string {
+	input = strings.ReplaceAll(input, "\\\n", " ")
+	loc := clientStartPattern.FindStringIndex(input)
+	if loc == nil {
+		return input
+	}
+
+	pos := loc[0] + strings.IndexByte(input[loc[0]:], '.')
+	var chain strings.Builder
+	chain.WriteString(strings.TrimSpace(input[loc[0]:pos]))
+	negate := false
+	for {
+		match := pythonStepPattern.FindStringSubmatchIndex(input[pos:])
+		if match == nil {
+			break
+		}
+		name := input[pos+match[2] : pos+match[3]]
+		next := pos + match[1]
+		if match[4] < 0 {
+			if name == "not_" {
+				negate = true
+			} else {
+				chain.WriteString("." + name)
+			}
+			pos = next
+			continue
+		}
+
+		closing := closingParen(input, next)
+		if closing < 0 {
+			// Leave unbalanced calls for the parser to report
+			return input
+		}
+		chain.WriteString(pythonCall(name, input[next:closing], negate))
+		negate = false
+		pos = closing + 1
+	}
+	return chain.String()
+}
+
+// pythonCall rewrites one supabase-py method call as supabase-js
+func pythonCall(name, argsStr string, negate bool) string {
+	if name == "execute" {
+		return ""
+	}
+
+	jsName, renamed := pythonMethods[name]
+	if !renamed {
+		jsName = camelCase(name)
+	}
+	var options []string
+	if typ, ok := pythonTextSearch[name]; ok {
+		jsName = "textSearch"
+		if typ != "" {
+			options = append(options, "type: '"+typ+"'")
+		}
+	}
+
+	var positional []string
+	returning := "representation"
+	for _, arg := range splitArguments(strings.TrimSpace(argsStr)) {
+		keyword := keywordArgPattern.FindStringSubmatch(arg)
+		if keyword == nil {
+			positional = append(positional, pythonValue(arg))
+			continue
+		}
+		key, value := keyword[1], pythonValue(keyword[2])
+		switch {
+		case key == "returning":
+			returning = strings.Trim(value, `'"`)
+		case key == "upsert" && jsName == "insert":
+			if value == "true" {
+				jsName = "upsert"
+			}
+		default:
+			options = append(options, pythonOption(key, value)...)
+		}
+	}
+
+	switch jsName {
+	case "select":
+		// supabase-py takes each column as its own argument
+		columns := []string{}
+		for _, column := range positional {
+			columns = append(columns, strings.Trim(column, `'"`))
+		}
+		if len(columns) == 0 {
+			columns = []string{"*"}
+		}
+		positional = []string{`"` + strings.Join(columns, ",") + `"`}
+	case "rpc":
+		if len(positional) == 1 && len(options) > 0 {
+			positional = append(positional, "{}")
+		}
+	case "textSearch":
+		for i, option := range options {
+			options[i] = strings.Replace(option, "web_search", "websearch", 1)
+		}
+	}
+
+	if negate && len(positional) > 0 {
+		positional = append([]string{positional[0], "'" + jsName + "'"}, positional[1:]...)
+		jsName = "not"
+	}
+	if len(options) > 0 {
+		positional = append(positional, "{"+strings.Join(options, ", ")+"}")
+	}
+
+	call := "." + jsName + "(" + strings.Join(positional, ", ") + ")"
+	if pythonMutations[jsName] && returning != "minimal" {
+		call += ".select()"
+	}
+	return call
+}
+
+// pythonOption rewrites a keyword argument as supabase-js option entries
+func pythonOption(key, value string) []string {
+	switch key {
+	case "desc":
+		switch value {
+		case "true":
+			return []string{"ascending: false"}
+		case "false":
+			return []string{"ascending: true"}
+		}
+		return nil
+	case "nullsfirst", "nulls_first":
+		return []string{"nullsFirst: " + value}
+	case "foreign_table", "reference_table", "referenced_table":
+		return []string{"referencedTable: " + value}
+	case "options", "file_options":
+		// A dict of options merges into the options object
+		if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+			if inner := strings.TrimSpace(value[1 : len(value)-1]); inner != "" {
+				return []string{inner}
+			}
+			return nil
+		}
+	}
+	return []string{camelCase(key) + ": " + value}
+}
+
+// pythonValue rewrites a Python literal as JavaScript: True, False and None
+// become true, false and null, enum members such as CountMethod.exact
+// become strings and f-string prefixes are dropped. Text inside strings is
+// left alone.
+func pythonValue(value string) string {
+	var b strings.Builder
+	var quote rune
+	runes := []rune(strings.TrimSpace(value))
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' && i+1 < len(runes) {
+				b.WriteRune(ch)
+				i++
+				ch = runes[i]
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case unicode.IsLetter(ch) || ch == '_':
+			start := i
+			for i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '_' || runes[i+1] == '.') {
+				i++
+			}
+			word := string(runes[start : i+1])
+			if i+1 < len(runes) && (runes[i+1] == '\'' || runes[i+1] == '"') {
+				// f"...", r"..." and b"..." string prefixes
+				continue
+			}
+			b.WriteString(pythonWord(word))
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+// pythonWord rewrites a bare Python word outside strings
+func pythonWord(word string) string {
+	switch word {
+	case "True":
+		return "true"
+	case "False":
+		return "false"
+	case "None":
+		return "null"
+	}
+	for _, enum := range []string{"CountMethod.", "ReturnMethod."} {
+		if member, ok := strings.CutPrefix(word, enum); ok {
+			return "'" + member + "'"
+		}
+	}
+	return word
+}
+
+// camelCase turns a snake_case name such as maybe_single or in_ into
+// maybeSingle or in
+func camelCase(name string) string {
+	parts := strings.Split(strings.Trim(name, "_"), "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
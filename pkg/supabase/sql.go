@@ -0,0 +1,80 @@
+package supabase
+
+import (
+	"maps"
+
+	"sql2postgrest/pkg/reverse"
+)
+
+// SQLOutput is a Supabase query converted to SQL
+type SQLOutput struct {
+	*reverse.SQLResult                  // SQL, warnings from both steps and metadata
+	PostgREST          *PostgRESTOutput // The equivalent PostgREST request
+}
+
+// ConvertToSQL converts a Supabase query straight to SQL. The request
+// handed to the reverse converter is built from the parsed query rather
+// than re-read from a URL, so .range() pagination, .single(), count and
+// upsert options all carry over to the SQL. Auth and storage calls have no
+// SQL form and fail with ErrCodeHTTPOnly.
+func (c *Converter) ConvertToSQL(input string, opts ...reverse.Option) (*SQLOutput, error) {
+	query, err := c.parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := c.toPostgREST(query)
+	if err != nil {
+		return nil, err
+	}
+	if output.IsHTTPOnly {
+		return nil, NewUnsupportedError(ErrCodeHTTPOnly,
+			output.Description+" has no SQL form",
+			input,
+			"auth and storage calls use Supabase's own APIs, not PostgREST; convert them to an HTTP request instead")
+	}
+
+	req, err := sqlRequest(query, output)
+	if err != nil {
+		return nil, err
+	}
+	result, err := reverse.NewConverter(opts...).ConvertRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Warnings = append(append(append([]string{}, output.Warnings...), result.Warnings...), ResponseFormatWarnings(output)...)
+	result.HTTPRequest = &reverse.HTTPRequest{
+		Method:  output.Method,
+		URL:     c.BaseURL + output.Path,
+		Headers: output.Headers,
+		Body:    output.Body,
+	}
+	if output.Query != "" {
+		result.HTTPRequest.URL += "?" + output.Query
+	}
+
+	return &SQLOutput{SQLResult: result, PostgREST: output}, nil
+}
+
+// sqlRequest builds the reverse converter's request for a query. Select,
+// filters and order are read from the query string, which writes them
+// without loss; the limit and offset come from the query itself rather
+// than its Range header, and the headers carry .single(), count and the
+// upsert resolution.
+func sqlRequest(query *SupabaseQuery, output *PostgRESTOutput) (*reverse.PostgRESTRequest, error) {
+	headers := maps.Clone(output.Headers)
+	delete(headers, "Range")
+
+	req, err := reverse.ParsePostgRESTRequestWithHeaders(output.Method, output.Path, output.Query, []byte(output.Body), headers)
+	if err != nil {
+		return nil, err
+	}
+	if !query.IsSpecialOp {
+		req.Limit, req.Offset = query.Limit, nil
+		if query.Offset != nil && *query.Offset > 0 {
+			req.Offset = query.Offset
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,218 @@
+package supabase
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parseStorageCall reads supabase.storage.from('bucket').method(args) or a
+// bucket-level supabase.storage.method(args) into a bucket call and a
+// storage call whose first argument is the method name
+func parseStorageCall(input string) ([]MethodCall, error) {
+	_, chain, _ := strings.Cut(input, ".storage")
+	calls, err := scanMethodCalls(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := []MethodCall{}
+	if len(calls) > 0 && calls[0].Name == "from" {
+		methods = append(methods, MethodCall{Name: "bucket", Args: calls[0].Args, Variables: calls[0].Variables})
+		calls = calls[1:]
+	}
+	if len(calls) == 0 {
+		return append(methods, MethodCall{Name: "storage", Args: []string{}}), nil
+	}
+	return append(methods, MethodCall{Name: "storage", Args: append([]string{calls[0].Name}, calls[0].Args...), Variables: calls[0].Variables}), nil
+}
+
+// storageRequest fills output with the Storage API request for a
+// supabase.storage call. Unknown methods only get a warning.
+func (c *Converter) storageRequest(query *SupabaseQuery, output *PostgRESTOutput) error {
+	method := query.SpecialMethod
+	args := query.SpecialArgs
+	bucket := query.Bucket
+
+	arg := func(i int, name string) (string, error) {
+		if len(args) <= i || strings.TrimSpace(args[i]) == "" {
+			return "", &ArgumentError{Method: method, Message: "missing " + name + " argument"}
+		}
+		return args[i], nil
+	}
+	object := func(i int) map[string]interface{} {
+		if len(args) <= i {
+			return nil
+		}
+		optsMap, _ := parseJSON(args[i]).(map[string]interface{})
+		return optsMap
+	}
+
+	var body interface{}
+	switch method {
+	case "upload", "update":
+		path, err := arg(0, "path")
+		if err != nil {
+			return err
+		}
+		output.Method = "POST"
+		if method == "update" {
+			output.Method = "PUT"
+		}
+		output.Path = "/storage/v1/object/" + bucket + "/" + path
+
+		contentType := "text/plain;charset=UTF-8"
+		opts := object(2)
+		if ct, ok := opts["contentType"].(string); ok {
+			contentType = ct
+		}
+		output.Headers["Content-Type"] = contentType
+		if cacheControl, ok := opts["cacheControl"]; ok {
+			output.Headers["cache-control"] = fmt.Sprintf("max-age=%v", cacheControl)
+		}
+		if upsert, ok := opts["upsert"].(bool); ok && upsert {
+			output.Headers["x-upsert"] = "true"
+		}
+		file := "file"
+		if len(args) >= 2 {
+			// A File variable arrives as a {placeholder}
+			file = strings.TrimSuffix(strings.TrimPrefix(args[1], "{"), "}")
+		}
+		output.Body = fmt.Sprintf("<contents of %s>", file)
+		output.Warnings = append(output.Warnings, "the request body is the raw file contents of "+file)
+
+	case "download":
+		path, err := arg(0, "path")
+		if err != nil {
+			return err
+		}
+		output.Method = "GET"
+		output.Path = "/storage/v1/object/" + bucket + "/" + path
+
+	case "getPublicUrl":
+		path, err := arg(0, "path")
+		if err != nil {
+			return err
+		}
+		output.Method = "GET"
+		output.Path = "/storage/v1/object/public/" + bucket + "/" + path
+		output.Warnings = append(output.Warnings, "getPublicUrl builds the URL locally without a request; this GET fetches the public object")
+
+	case "list":
+		prefix := ""
+		if len(args) > 0 && args[0] != "undefined" {
+			prefix = args[0]
+		}
+		listBody := map[string]interface{}{
+			"prefix": prefix,
+			"limit":  100,
+			"offset": 0,
+			"sortBy": map[string]interface{}{"column": "name", "order": "asc"},
+		}
+		for key, val := range object(1) {
+			listBody[key] = val
+		}
+		output.Method = "POST"
+		output.Path = "/storage/v1/object/list/" + bucket
+		body = listBody
+
+	case "remove":
+		paths, err := arg(0, "paths")
+		if err != nil {
+			return err
+		}
+		output.Method = "DELETE"
+		output.Path = "/storage/v1/object/" + bucket
+		body = map[string]interface{}{"prefixes": parseArrayArg(paths)}
+
+	case "createSignedUrl":
+		path, err := arg(0, "path")
+		if err != nil {
+			return err
+		}
+		expiresIn, err := arg(1, "expiresIn")
+		if err != nil {
+			return err
+		}
+		output.Method = "POST"
+		output.Path = "/storage/v1/object/sign/" + bucket + "/" + path
+		body = map[string]interface{}{"expiresIn": parseValue(expiresIn)}
+
+	case "createSignedUrls":
+		paths, err := arg(0, "paths")
+		if err != nil {
+			return err
+		}
+		expiresIn, err := arg(1, "expiresIn")
+		if err != nil {
+			return err
+		}
+		output.Method = "POST"
+		output.Path = "/storage/v1/object/sign/" + bucket
+		body = map[string]interface{}{"expiresIn": parseValue(expiresIn), "paths": parseArrayArg(paths)}
+
+	case "move", "copy":
+		from, err := arg(0, "fromPath")
+		if err != nil {
+			return err
+		}
+		to, err := arg(1, "toPath")
+		if err != nil {
+			return err
+		}
+		output.Method = "POST"
+		output.Path = "/storage/v1/object/" + method
+		body = map[string]interface{}{"bucketId": bucket, "sourceKey": from, "destinationKey": to}
+
+	case "listBuckets":
+		output.Method = "GET"
+		output.Path = "/storage/v1/bucket"
+
+	case "getBucket", "deleteBucket", "emptyBucket":
+		id, err := arg(0, "bucket id")
+		if err != nil {
+			return err
+		}
+		output.Method = map[string]string{"getBucket": "GET", "deleteBucket": "DELETE", "emptyBucket": "POST"}[method]
+		output.Path = "/storage/v1/bucket/" + id
+		if method == "emptyBucket" {
+			output.Path += "/empty"
+		}
+
+	case "createBucket", "updateBucket":
+		id, err := arg(0, "bucket id")
+		if err != nil {
+			return err
+		}
+		bucketBody := map[string]interface{}{"id": id, "name": id}
+		for key, val := range object(1) {
+			bucketBody[key] = val
+		}
+		output.Method = "POST"
+		output.Path = "/storage/v1/bucket"
+		if method == "updateBucket" {
+			output.Method = "PUT"
+			output.Path += "/" + id
+		}
+		body = bucketBody
+
+	default:
+		if method != "" {
+			output.Warnings = append(output.Warnings, fmt.Sprintf("storage.%s does not map to a single Storage API request", method))
+		}
+		return nil
+	}
+
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		output.Body = string(bodyBytes)
+		output.Headers["Content-Type"] = "application/json"
+	}
+	output.Headers["apikey"] = "<SUPABASE_ANON_KEY>"
+	output.Headers["Authorization"] = "Bearer <ACCESS_TOKEN>"
+	output.Description = fmt.Sprintf("Supabase Storage %s: %s %s", method, output.Method, output.Path)
+	return nil
+}
@@ -1,36 +1,51 @@
 package supabase
 
+import "fmt"
+
 // SupabaseQuery represents a parsed Supabase JS query
 type SupabaseQuery struct {
 	Table      string            // Table name from .from()
+	Schema     string            // Schema from .schema(), if not the default
 	Operation  string            // select, insert, update, delete, rpc
 	Select     []string          // Columns from .select()
 	Filters    []Filter          // Filter conditions
 	Order      []OrderBy         // Order by clauses
-	Limit      *int              // Limit value
-	Offset     *int              // Offset value
-	Range      *Range            // Range (alternative to limit/offset)
+	Limit      *int              // Limit value, also set by .range()
+	Offset     *int              // Offset value, set by .range()
+	Range      *Range            // .range() bounds, kept in step with Limit and Offset
+	EmbedLimits map[string]int   // Limits of referenced tables, from {referencedTable} options
+	EmbedOffsets map[string]int  // Offsets of referenced tables, from .range() {referencedTable}
 	Single     bool              // .single() was called
 	MaybeSingle bool             // .maybeSingle() was called
 	Data       interface{}       // Data for insert/update
 	Upsert     bool              // .upsert() instead of .insert()
-	OnConflict string            // Column for upsert conflict
+	OnConflict string            // Columns for upsert conflict, comma separated
+	IgnoreDuplicates bool        // Upsert skips conflicting rows instead of merging
 	Count      string            // Count option: exact, planned, estimated
+	Head       bool              // .select() {head: true}: a HEAD request, no rows
 	Headers    map[string]string // Custom headers
+	Accept     string            // Response media type from .csv() or .geojson()
+	Explain    *Explain          // .explain() options
 
 	// RPC specific
 	RPCFunction string      // Function name for .rpc()
 	RPCParams   interface{} // Parameters for .rpc()
+	RPCGet      bool        // .rpc() {get: true}: GET with the parameters in the query string
 
 	// Special operations (auth, storage, etc.)
-	IsSpecialOp bool   // True for .auth, .storage, .rpc
-	SpecialType string // "auth", "storage", "rpc"
+	IsSpecialOp   bool     // True for .auth, .storage, .rpc
+	SpecialType   string   // "auth", "storage", "rpc"
+	SpecialMethod string   // Method called, e.g. "signUp" or "admin.deleteUser"
+	SpecialArgs   []string // Its arguments
+	Bucket        string   // Storage bucket from .storage.from()
+
+	Warnings []string // Notes about ignored or approximated arguments
 }
 
 // Filter represents a Supabase filter condition
 type Filter struct {
-	Column   string      // Column name
-	Operator string      // eq, neq, gt, gte, lt, lte, like, ilike, is, in, contains, etc.
+	Column   string      // Column name; for "or", the referenced table if any
+	Operator string      // eq, neq, gt, gte, lt, lte, like, ilike, is, in, contains, or, etc.
 	Value    interface{} // Filter value
 	Negate   bool        // .not modifier
 }
@@ -38,10 +53,21 @@ type Filter struct {
 // OrderBy represents an order clause
 type OrderBy struct {
 	Column     string // Column to order by
+	Table      string // Referenced (embedded) table it orders, if any
 	Ascending  bool   // true for asc, false for desc
 	NullsFirst bool   // nulls first/last
 }
 
+// Explain holds the .explain() options
+type Explain struct {
+	Analyze  bool
+	Verbose  bool
+	Settings bool
+	Buffers  bool
+	WAL      bool
+	Format   string // text or json
+}
+
 // Range represents a range query
 type Range struct {
 	From int
@@ -67,3 +93,14 @@ type ConversionResult struct {
 	Warnings  []string // All warnings
 	Metadata  map[string]string
 }
+
+// ArgumentError reports a method argument that could not be converted
+type ArgumentError struct {
+	Method  string // Method name, e.g. "limit"
+	Arg     string // Offending argument text
+	Message string // What is wrong with it
+}
+
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf(".%s(%s): %s", e.Method, e.Arg, e.Message)
+}
@@ -0,0 +1,143 @@
+package supabase
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// v1FilterMethods maps the operator shorthands of supabase-js v1, which v2
+// dropped, to the v2 filter methods
+var v1FilterMethods = map[string]string{
+	"cs":    "contains",
+	"cd":    "containedBy",
+	"ov":    "overlaps",
+	"sl":    "rangeLt",
+	"sr":    "rangeGt",
+	"nxl":   "rangeGte",
+	"nxr":   "rangeLte",
+	"adj":   "rangeAdjacent",
+	"fts":   "textSearch",
+	"plfts": "textSearch",
+	"phfts": "textSearch",
+	"wfts":  "textSearch",
+}
+
+// v1AuthMethods maps supabase-js v1 auth methods to their v2 replacements;
+// signIn is split by its arguments in v1SignIn
+var v1AuthMethods = map[string]string{
+	"session": "getSession",
+	"user":    "getUser",
+	"update":  "updateUser",
+}
+
+// upgradeV1 rewrites a supabase-js v1 call to its v2 form, warning with
+// the v2 equivalent so the pasted code can be updated too
+func upgradeV1(query *SupabaseQuery, method MethodCall) MethodCall {
+	if v2, ok := v1FilterMethods[method.Name]; ok {
+		query.Warnings = append(query.Warnings, fmt.Sprintf(".%s() is supabase-js v1 syntax; use .%s() in v2", method.Name, v2))
+		if typ := textSearchType(method.Name); typ != "" {
+			method.Args = withTextSearchType(method.Args, typ)
+		}
+		method.Name = v2
+		return method
+	}
+
+	if method.Name != "auth" || len(method.Args) == 0 {
+		return method
+	}
+	v2, ok := v1AuthMethods[method.Args[0]]
+	if method.Args[0] == "signIn" {
+		v2, ok = v1SignIn(method.Args[1:]), true
+	}
+	if ok {
+		query.Warnings = append(query.Warnings, fmt.Sprintf("auth.%s() is supabase-js v1 syntax; use auth.%s() in v2", method.Args[0], v2))
+		method.Args = append([]string{v2}, method.Args[1:]...)
+	}
+	return method
+}
+
+// textSearchType returns the .textSearch() type of a v1 full-text shorthand
+func textSearchType(name string) string {
+	switch name {
+	case "plfts":
+		return "plain"
+	case "phfts":
+		return "phrase"
+	case "wfts":
+		return "websearch"
+	}
+	return ""
+}
+
+// withTextSearchType adds {type} to the options of .textSearch(column,
+// query, options), keeping any config
+func withTextSearchType(args []string, typ string) []string {
+	if len(args) < 2 {
+		return args
+	}
+	opts := map[string]interface{}{}
+	if len(args) > 2 {
+		if parsed, ok := parseJSON(args[2]).(map[string]interface{}); ok {
+			opts = parsed
+		}
+	}
+	opts["type"] = typ
+	encoded, _ := json.Marshal(opts)
+	return []string{args[0], args[1], string(encoded)}
+}
+
+// v1SignIn picks the v2 method for auth.signIn(credentials): OAuth for a
+// provider, a password sign in when there is a password, and otherwise a
+// magic link or OTP
+func v1SignIn(args []string) string {
+	credentials := map[string]interface{}{}
+	if len(args) > 0 {
+		if parsed, ok := parseJSON(args[0]).(map[string]interface{}); ok {
+			credentials = parsed
+		}
+	}
+	switch {
+	case credentials["provider"] != nil:
+		return "signInWithOAuth"
+	case credentials["password"] != nil:
+		return "signInWithPassword"
+	}
+	return "signInWithOtp"
+}
+
+// parseV1MutationOption reads the options supabase-js v1 took on
+// .insert(), .upsert(), .update() and .delete(), and reports whether key
+// was one of them
+func parseV1MutationOption(query *SupabaseQuery, methodName, key string, val interface{}) bool {
+	switch {
+	case key == "returning":
+		switch val {
+		case "minimal":
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".%s() option returning: 'minimal' is supabase-js v1 syntax; v2 returns no rows unless .select() is chained, so it was dropped", methodName))
+		case "representation":
+			if len(query.Select) == 0 {
+				query.Select = []string{"*"}
+			}
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".%s() option returning: 'representation' is supabase-js v1 syntax; chain .select() in v2", methodName))
+		default:
+			query.Warnings = append(query.Warnings, fmt.Sprintf(".%s() option returning: %v is not supported and was ignored", methodName, val))
+		}
+		return true
+
+	case key == "upsert" && methodName == "insert":
+		if upsert, _ := val.(bool); upsert {
+			query.Upsert = true
+		}
+		query.Warnings = append(query.Warnings, ".insert() option upsert is supabase-js v1 syntax; use .upsert() in v2")
+		return true
+
+	case key == "onConflict" && methodName == "insert":
+		if columns, ok := val.(string); ok {
+			query.OnConflict = strings.ReplaceAll(columns, " ", "")
+		}
+		query.Warnings = append(query.Warnings, ".insert() option onConflict is supabase-js v1 syntax; pass it to .upsert() in v2")
+		return true
+	}
+	return false
+}